@@ -0,0 +1,277 @@
+package audit
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kalmhq/kalm/api/log"
+	"go.uber.org/zap"
+)
+
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionExec    = "exec"
+	ActionExecEnd = "execEnd"
+)
+
+// Changes of resources larger than this are not recorded
+const maxResourceSize = 64 * 1024
+
+// Each sink has a queue of entries to write. Once it's full, entries are dropped after waiting for the timeout.
+const (
+	sinkQueueSize      = 1024
+	sinkEnqueueTimeout = 100 * time.Millisecond
+)
+
+const redacted = "******"
+
+// keys of resource fields whose values are never recorded, matched case-insensitively
+var sensitiveKeys = []string{"password", "secret", "token", "privatekey", "hmackey"}
+
+// Entry records a mutating call or an exec session.
+// Changes are the diff between the existing resource and the submitted one, Params are the parameters of exec sessions.
+type Entry struct {
+	Time              time.Time         `json:"time"`
+	Subject           string            `json:"subject"`
+	Groups            []string          `json:"groups,omitempty"`
+	Impersonation     string            `json:"impersonation,omitempty"`
+	ImpersonationType string            `json:"impersonationType,omitempty"`
+	AccessTokenName   string            `json:"accessTokenName,omitempty"`
+	ClientIP          string            `json:"clientIP,omitempty"`
+	Action            string            `json:"action"`
+	Method            string            `json:"method,omitempty"`
+	Namespace         string            `json:"namespace,omitempty"`
+	Resource          string            `json:"resource"`
+	Changes           []Change          `json:"changes,omitempty"`
+	Params            map[string]string `json:"params,omitempty"`
+	Status            int               `json:"status"`
+	Error             string            `json:"error,omitempty"`
+}
+
+func (e *Entry) Failed() bool {
+	return e.Status >= 400 || e.Error != ""
+}
+
+type Filter struct {
+	Subject         string
+	AccessTokenName string
+	Action          string
+	Namespace       string
+	// prefix of the resource
+	Resource string
+	Since    time.Time
+	Until    time.Time
+	// only returns failed entries if true
+	Failed bool
+	Limit  int
+}
+
+func (f *Filter) Match(e *Entry) bool {
+	if f.Subject != "" && f.Subject != e.Subject && f.Subject != e.Impersonation {
+		return false
+	}
+
+	if f.AccessTokenName != "" && f.AccessTokenName != e.AccessTokenName {
+		return false
+	}
+
+	if f.Action != "" && f.Action != e.Action {
+		return false
+	}
+
+	if f.Namespace != "" && f.Namespace != e.Namespace {
+		return false
+	}
+
+	if f.Resource != "" && !strings.HasPrefix(e.Resource, f.Resource) {
+		return false
+	}
+
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+
+	if !f.Until.IsZero() && e.Time.After(f.Until) {
+		return false
+	}
+
+	if f.Failed && !e.Failed() {
+		return false
+	}
+
+	return true
+}
+
+// Auditor keeps the recent entries in memory to serve queries, and writes all entries to the sinks for durable storage.
+// The entries in memory are of this instance only, they are lost on restart.
+type Auditor struct {
+	mut       *sync.RWMutex
+	entries   []*Entry
+	next      int
+	retention int
+
+	sinkQueues []*sinkQueue
+}
+
+type sinkQueue struct {
+	sink    Sink
+	entries chan *Entry
+	dropped uint64
+}
+
+func NewAuditor(retention int, sinks ...Sink) *Auditor {
+	if retention <= 0 {
+		retention = 1000
+	}
+
+	auditor := &Auditor{
+		mut:       &sync.RWMutex{},
+		entries:   make([]*Entry, 0, retention),
+		retention: retention,
+	}
+
+	for _, sink := range sinks {
+		queue := &sinkQueue{sink: sink, entries: make(chan *Entry, sinkQueueSize)}
+		auditor.sinkQueues = append(auditor.sinkQueues, queue)
+		go queue.writeLoop()
+	}
+
+	return auditor
+}
+
+// Sinks may be slow, e.g. a webhook timing out, entries are written in a goroutine of each sink
+// to not block requests or the other sinks.
+func (q *sinkQueue) writeLoop() {
+	for entry := range q.entries {
+		if err := q.sink.Write(entry); err != nil {
+			log.Error("write audit entry error", zap.String("sink", q.sink.Name()), zap.Error(err))
+		}
+	}
+}
+
+func (q *sinkQueue) enqueue(entry *Entry) {
+	select {
+	case q.entries <- entry:
+		return
+	default:
+	}
+
+	timer := time.NewTimer(sinkEnqueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case q.entries <- entry:
+	case <-timer.C:
+		dropped := atomic.AddUint64(&q.dropped, 1)
+		log.Error("audit entry is dropped, the queue of the sink is full",
+			zap.String("sink", q.sink.Name()), zap.Uint64("dropped", dropped))
+	}
+}
+
+// DroppedEntries returns the number of entries dropped by each sink since started
+func (a *Auditor) DroppedEntries() map[string]uint64 {
+	res := make(map[string]uint64, len(a.sinkQueues))
+
+	for _, queue := range a.sinkQueues {
+		res[queue.sink.Name()] = atomic.LoadUint64(&queue.dropped)
+	}
+
+	return res
+}
+
+func (a *Auditor) Record(entry *Entry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	a.mut.Lock()
+	if len(a.entries) < a.retention {
+		a.entries = append(a.entries, entry)
+	} else {
+		a.entries[a.next] = entry
+	}
+	a.next = (a.next + 1) % a.retention
+	a.mut.Unlock()
+
+	for _, queue := range a.sinkQueues {
+		queue.enqueue(entry)
+	}
+}
+
+// Query returns the retained entries matching the filter, the newest first
+func (a *Auditor) Query(filter *Filter) []*Entry {
+	a.mut.RLock()
+	defer a.mut.RUnlock()
+
+	res := make([]*Entry, 0)
+
+	for i := 1; i <= len(a.entries); i++ {
+		entry := a.entries[(a.next-i+len(a.entries))%len(a.entries)]
+
+		if !filter.Match(entry) {
+			continue
+		}
+
+		res = append(res, entry)
+
+		if filter.Limit > 0 && len(res) >= filter.Limit {
+			break
+		}
+	}
+
+	return res
+}
+
+func redact(obj interface{}) interface{} {
+	switch v := obj.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if isSensitiveKey(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redact(value)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redact(v[i])
+		}
+	}
+
+	return obj
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+
+	// values of secrets
+	if key == "data" {
+		return true
+	}
+
+	for _, sensitiveKey := range sensitiveKeys {
+		if strings.Contains(key, sensitiveKey) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var defaultAuditor = NewAuditor(0)
+
+func InitDefaultAuditor(retention int, sinks ...Sink) {
+	defaultAuditor = NewAuditor(retention, sinks...)
+}
+
+func DefaultAuditor() *Auditor {
+	return defaultAuditor
+}
+
+func Record(entry *Entry) {
+	defaultAuditor.Record(entry)
+}
@@ -0,0 +1,185 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAuditorQuery(t *testing.T) {
+	auditor := NewAuditor(3)
+	now := time.Now()
+
+	auditor.Record(&Entry{Time: now.Add(-4 * time.Minute), Subject: "foo@bar.com", Action: ActionCreate, Namespace: "ns1", Resource: "applications/ns1", Status: 201})
+	auditor.Record(&Entry{Time: now.Add(-3 * time.Minute), Subject: "foo@bar.com", Action: ActionUpdate, Namespace: "ns1", Resource: "applications/ns1/components/web", Status: 200})
+	auditor.Record(&Entry{Time: now.Add(-2 * time.Minute), Subject: "bar@bar.com", Action: ActionDelete, Namespace: "ns2", Resource: "applications/ns2/components/web", Status: 401})
+	auditor.Record(&Entry{Time: now.Add(-1 * time.Minute), Subject: "bar@bar.com", Action: ActionExec, Namespace: "ns2", Resource: "pods/web-0", Status: 200})
+
+	// the oldest entry is dropped
+	entries := auditor.Query(&Filter{})
+	assert.Len(t, entries, 3)
+	assert.Equal(t, ActionExec, entries[0].Action)
+	assert.Equal(t, ActionUpdate, entries[2].Action)
+
+	assert.Len(t, auditor.Query(&Filter{Subject: "bar@bar.com"}), 2)
+	assert.Len(t, auditor.Query(&Filter{Namespace: "ns1"}), 1)
+	assert.Len(t, auditor.Query(&Filter{Resource: "applications/"}), 2)
+	assert.Len(t, auditor.Query(&Filter{Failed: true}), 1)
+	assert.Len(t, auditor.Query(&Filter{Since: now.Add(-150 * time.Second)}), 2)
+	assert.Len(t, auditor.Query(&Filter{Until: now.Add(-150 * time.Second)}), 1)
+	assert.Len(t, auditor.Query(&Filter{Limit: 1}), 1)
+}
+
+type blockingSink struct {
+	unblock chan struct{}
+}
+
+func (s *blockingSink) Name() string {
+	return "blocking"
+}
+
+func (s *blockingSink) Write(entry *Entry) error {
+	<-s.unblock
+	return nil
+}
+
+type countingSink struct {
+	written chan *Entry
+}
+
+func (s *countingSink) Name() string {
+	return "counting"
+}
+
+func (s *countingSink) Write(entry *Entry) error {
+	s.written <- entry
+	return nil
+}
+
+func TestAuditorDropsEntriesOfFullSink(t *testing.T) {
+	blocking := &blockingSink{unblock: make(chan struct{})}
+	defer close(blocking.unblock)
+
+	counting := &countingSink{written: make(chan *Entry, sinkQueueSize+2)}
+	auditor := NewAuditor(10, blocking, counting)
+
+	// one entry is being written by the blocking sink, the others fill its queue
+	for i := 0; i < sinkQueueSize+2; i++ {
+		auditor.Record(&Entry{Action: ActionCreate})
+	}
+
+	assert.Equal(t, map[string]uint64{"blocking": 1, "counting": 0}, auditor.DroppedEntries())
+
+	// the other sinks are not blocked
+	for i := 0; i < sinkQueueSize+2; i++ {
+		select {
+		case <-counting.written:
+		case <-time.After(time.Second):
+			t.Fatal("entries are not written to the counting sink")
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	existing := `{"name":"web","image":"nginx:1","env":[{"name":"A","value":"1"}],"labels":{"app":"web","tier":"fe"},` +
+		`"password":"old","data":{"key":"value"},"status":{"ready":true}}`
+	submitted := `{"name":"web","image":"nginx:2","env":[{"name":"A","value":"2"}],"labels":{"app":"web","team":"a"},` +
+		`"password":"new","data":{"key":"value"},"sso":{"clientSecret":"abc"}}`
+
+	changes := Diff([]byte(existing), []byte(submitted))
+
+	assert.Equal(t, []Change{
+		{Path: "/env", Old: []interface{}{map[string]interface{}{"name": "A", "value": "1"}}, New: []interface{}{map[string]interface{}{"name": "A", "value": "2"}}},
+		{Path: "/image", Old: "nginx:1", New: "nginx:2"},
+		{Path: "/labels/team", New: "a"},
+		{Path: "/labels/tier", Old: "fe"},
+		{Path: "/password", Old: redacted, New: redacted},
+		{Path: "/sso", New: map[string]interface{}{"clientSecret": redacted}},
+	}, changes)
+
+	// created and deleted resources
+	assert.Equal(t, []Change{{Path: "/password", New: redacted}, {Path: "/user", New: "foo"}}, Diff(nil, []byte(`{"user":"foo","password":"bar"}`)))
+	assert.Equal(t, []Change{{Path: "", Old: map[string]interface{}{"user": "foo", "token": redacted}}}, Diff([]byte(`{"user":"foo","token":"bar"}`), nil))
+
+	assert.Nil(t, Diff(nil, []byte("not json")))
+	assert.Nil(t, Diff(nil, nil))
+}
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	sink, err := NewFileSink(filepath.Join(dir, "audit.log"))
+	assert.Nil(t, err)
+
+	assert.Nil(t, sink.Write(&Entry{Subject: "foo@bar.com", Action: ActionCreate}))
+	assert.Nil(t, sink.Write(&Entry{Subject: "bar@bar.com", Action: ActionDelete}))
+
+	file, err := os.Open(filepath.Join(dir, "audit.log"))
+	assert.Nil(t, err)
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		var entry Entry
+		assert.Nil(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "bar@bar.com", entries[1].Subject)
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received Entry
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL)
+	assert.Nil(t, sink.Write(&Entry{Subject: "foo@bar.com", Action: ActionCreate}))
+	assert.Equal(t, "foo@bar.com", received.Subject)
+
+	failedServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failedServer.Close()
+
+	assert.NotNil(t, NewWebhookSink(failedServer.URL).Write(&Entry{}))
+}
+
+func TestKubernetesEventSink(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	sink := NewKubernetesEventSink(clientset, "kalm-system")
+
+	assert.Nil(t, sink.Write(&Entry{Time: time.Now(), Subject: "foo@bar.com", Action: ActionDelete, Namespace: "ns1", Resource: "applications/ns1", Status: 401, Error: "unauthorized"}))
+	assert.Nil(t, sink.Write(&Entry{Time: time.Now().Add(time.Second), Subject: "foo@bar.com", Action: ActionCreate, Resource: "registries", Status: 201}))
+
+	events, err := clientset.CoreV1().Events("ns1").List(context.Background(), metaV1.ListOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, events.Items, 1)
+	assert.Equal(t, coreV1.EventTypeWarning, events.Items[0].Type)
+	assert.Equal(t, "foo@bar.com delete applications/ns1, status 401: unauthorized", events.Items[0].Message)
+
+	events, err = clientset.CoreV1().Events("kalm-system").List(context.Background(), metaV1.ListOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, events.Items, 1)
+	assert.Equal(t, coreV1.EventTypeNormal, events.Items[0].Type)
+}
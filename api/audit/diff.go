@@ -0,0 +1,115 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Change is a changed field of a resource, the path is the json pointer of the field.
+// Old is absent if the field is added, New is absent if the field is removed.
+type Change struct {
+	Path string      `json:"path"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// Diff returns the changes from the existing json resource to the submitted one, either of them may be empty
+// when the resource is created or deleted. Values of sensitive fields are redacted, only their paths are recorded.
+// The api representation of a resource may have read-only top level fields, e.g. the status of a component,
+// so top level fields absent in the submitted resource are not regarded as removed.
+// Resources which are not json or too large are not recorded.
+func Diff(existing, submitted []byte) []Change {
+	if len(existing) > maxResourceSize || len(submitted) > maxResourceSize {
+		return nil
+	}
+
+	oldObj := unmarshalResource(existing)
+	newObj := unmarshalResource(submitted)
+
+	var changes []Change
+
+	oldMap, oldIsMap := oldObj.(map[string]interface{})
+	newMap, newIsMap := newObj.(map[string]interface{})
+
+	switch {
+	case newIsMap && (oldIsMap || oldObj == nil):
+		for _, key := range sortedKeys(newMap) {
+			diffValues(&changes, "/"+jsonPointerEscaper.Replace(key), oldMap[key], newMap[key], isSensitiveKey(key))
+		}
+	default:
+		diffValues(&changes, "", oldObj, newObj, false)
+	}
+
+	return changes
+}
+
+func unmarshalResource(bs []byte) interface{} {
+	if len(bs) == 0 {
+		return nil
+	}
+
+	var obj interface{}
+
+	if err := json.Unmarshal(bs, &obj); err != nil {
+		return nil
+	}
+
+	return obj
+}
+
+func diffValues(changes *[]Change, path string, oldValue, newValue interface{}, sensitive bool) {
+	if reflect.DeepEqual(oldValue, newValue) {
+		return
+	}
+
+	if sensitive {
+		*changes = append(*changes, Change{Path: path, Old: redactedIfPresent(oldValue), New: redactedIfPresent(newValue)})
+		return
+	}
+
+	oldMap, oldIsMap := oldValue.(map[string]interface{})
+	newMap, newIsMap := newValue.(map[string]interface{})
+
+	if !oldIsMap || !newIsMap {
+		*changes = append(*changes, Change{Path: path, Old: redact(oldValue), New: redact(newValue)})
+		return
+	}
+
+	keys := sortedKeys(oldMap)
+
+	for key := range newMap {
+		if _, exist := oldMap[key]; !exist {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		diffValues(changes, path+"/"+jsonPointerEscaper.Replace(key), oldMap[key], newMap[key], isSensitiveKey(key))
+	}
+}
+
+func redactedIfPresent(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	return redacted
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
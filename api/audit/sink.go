@@ -0,0 +1,170 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type Sink interface {
+	Name() string
+	Write(entry *Entry) error
+}
+
+var _ Sink = &FileSink{}
+var _ Sink = &WebhookSink{}
+var _ Sink = &KubernetesEventSink{}
+
+// FileSink appends entries to a file as json lines
+type FileSink struct {
+	mut  *sync.Mutex
+	file *os.File
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSink{mut: &sync.Mutex{}, file: file}, nil
+}
+
+func (s *FileSink) Name() string {
+	return "file"
+}
+
+func (s *FileSink) Write(entry *Entry) error {
+	bs, err := json.Marshal(entry)
+
+	if err != nil {
+		return err
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	_, err = s.file.Write(append(bs, '\n'))
+
+	return err
+}
+
+// WebhookSink posts each entry as json to the url
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+func (s *WebhookSink) Write(entry *Entry) error {
+	bs, err := json.Marshal(entry)
+
+	if err != nil {
+		return err
+	}
+
+	res, err := s.client.Post(s.url, "application/json", bytes.NewReader(bs))
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("audit webhook responded with status %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// KubernetesEventSink creates an event for each entry,
+// in the namespace of the entry or in the fallback namespace for cluster resources.
+type KubernetesEventSink struct {
+	clientset         kubernetes.Interface
+	fallbackNamespace string
+}
+
+func NewKubernetesEventSink(clientset kubernetes.Interface, fallbackNamespace string) *KubernetesEventSink {
+	return &KubernetesEventSink{
+		clientset:         clientset,
+		fallbackNamespace: fallbackNamespace,
+	}
+}
+
+func (s *KubernetesEventSink) Name() string {
+	return "kubernetesEvent"
+}
+
+func (s *KubernetesEventSink) Write(entry *Entry) error {
+	_, err := s.clientset.CoreV1().Events(s.namespaceOf(entry)).Create(context.Background(), s.buildEvent(entry), metaV1.CreateOptions{})
+	return err
+}
+
+func (s *KubernetesEventSink) namespaceOf(entry *Entry) string {
+	if entry.Namespace != "" {
+		return entry.Namespace
+	}
+
+	return s.fallbackNamespace
+}
+
+func (s *KubernetesEventSink) buildEvent(entry *Entry) *coreV1.Event {
+	namespace := s.namespaceOf(entry)
+
+	eventType := coreV1.EventTypeNormal
+	if entry.Failed() {
+		eventType = coreV1.EventTypeWarning
+	}
+
+	subject := entry.Subject
+	if entry.Impersonation != "" {
+		subject = fmt.Sprintf("%s (as %s)", entry.Subject, entry.Impersonation)
+	}
+
+	message := fmt.Sprintf("%s %s %s, status %d", subject, entry.Action, entry.Resource, entry.Status)
+	if entry.Error != "" {
+		message = fmt.Sprintf("%s: %s", message, entry.Error)
+	}
+
+	eventTime := metaV1.NewTime(entry.Time)
+
+	return &coreV1.Event{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      fmt.Sprintf("kalm-audit.%x", entry.Time.UnixNano()),
+			Namespace: namespace,
+		},
+		InvolvedObject: coreV1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Namespace",
+			Name:       namespace,
+		},
+		Reason:         "Audit",
+		Message:        message,
+		Type:           eventType,
+		Source:         coreV1.EventSource{Component: "kalm-api"},
+		FirstTimestamp: eventTime,
+		LastTimestamp:  eventTime,
+		Count:          1,
+	}
+}
@@ -0,0 +1,49 @@
+package auth_proxy
+
+import "strings"
+
+// The istio sidecar appends an element of the peer to the x-forwarded-client-cert header of mutual TLS connections.
+// Elements before it are set by the previous hops, or sent by the client, so only the last one can be trusted.
+// LastXFCCPeerURI returns the URI, the SPIFFE identity, of the peer in the last element.
+func LastXFCCPeerURI(xfcc string) string {
+	if xfcc == "" {
+		return ""
+	}
+
+	elements := splitXFCC(xfcc, ',')
+
+	for _, pair := range splitXFCC(elements[len(elements)-1], ';') {
+		kv := strings.SplitN(pair, "=", 2)
+
+		if len(kv) == 2 && strings.EqualFold(strings.TrimSpace(kv[0]), "URI") {
+			return strings.Trim(strings.TrimSpace(kv[1]), `"`)
+		}
+	}
+
+	return ""
+}
+
+// values containing the separators are double quoted, quotes in them are escaped with a backslash
+func splitXFCC(s string, sep rune) []string {
+	var rst []string
+
+	quoted := false
+	escaped := false
+	start := 0
+
+	for i, c := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case c == sep && !quoted:
+			rst = append(rst, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(rst, s[start:])
+}
@@ -0,0 +1,29 @@
+package auth_proxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLastXFCCPeerURI(t *testing.T) {
+	gateway := "spiffe://cluster.local/ns/istio-system/sa/istio-ingressgateway-service-account"
+
+	assert.Equal(t, "", LastXFCCPeerURI(""))
+	assert.Equal(t, gateway, LastXFCCPeerURI(
+		`By=spiffe://cluster.local/ns/default/sa/default;Hash=abc;Subject="";URI=`+gateway,
+	))
+
+	// the elements sent by the client are ignored
+	assert.Equal(t, gateway, LastXFCCPeerURI(
+		`By=spiffe://cluster.local/ns/default/sa/default;URI=spiffe://cluster.local/ns/default/sa/attacker,`+
+			`By=spiffe://cluster.local/ns/default/sa/default;Subject="CN=a,O=b;c";URI=`+gateway,
+	))
+
+	assert.Equal(t, "spiffe://cluster.local/ns/default/sa/other", LastXFCCPeerURI(
+		`URI=`+gateway+`,By=spiffe://cluster.local/ns/default/sa/default;Subject="CN=\"x,y\"";URI=spiffe://cluster.local/ns/default/sa/other`,
+	))
+
+	// the header is removed by envoy for plain text connections, an element without the uri isn't trusted either
+	assert.Equal(t, "", LastXFCCPeerURI(`By=spiffe://cluster.local/ns/default/sa/default;Hash=abc`))
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+)
+
+// accessTokenUsageTracker counts requests made with access tokens to enforce the quotas.
+// The usages are saved to the status of access tokens periodically instead of on every request.
+// Quotas are counted by each api server instance, so they are approximate, a token may be used up to
+// the quota times the number of instances in a period. The used count in the status is the total of all instances.
+type accessTokenUsageTracker struct {
+	mut    *sync.Mutex
+	usages map[string]*accessTokenUsage
+}
+
+type accessTokenUsage struct {
+	// requests in the current quota period
+	periodStart time.Time
+	periodCount int
+
+	// usages not saved to the status yet
+	unsavedCount int
+	lastUsedAt   time.Time
+	lastUsedIP   string
+}
+
+func newAccessTokenUsageTracker() *accessTokenUsageTracker {
+	return &accessTokenUsageTracker{
+		mut:    &sync.Mutex{},
+		usages: make(map[string]*accessTokenUsage),
+	}
+}
+
+// use records a request made with the access token, false is returned if the quota is exceeded
+func (t *accessTokenUsageTracker) use(accessToken *v1alpha1.AccessToken, ip string, now time.Time) bool {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	usage, ok := t.usages[accessToken.Name]
+
+	if !ok {
+		usage = &accessTokenUsage{periodStart: now}
+		t.usages[accessToken.Name] = usage
+	}
+
+	if quota := accessToken.Spec.Quota; quota != nil {
+		if now.Sub(usage.periodStart) >= time.Duration(quota.PeriodSeconds)*time.Second {
+			usage.periodStart = now
+			usage.periodCount = 0
+		}
+
+		if usage.periodCount >= quota.Requests {
+			return false
+		}
+	}
+
+	usage.periodCount++
+	usage.unsavedCount++
+	usage.lastUsedAt = now
+	usage.lastUsedIP = ip
+
+	return true
+}
+
+// takeUnsaved returns the usages which are not saved yet and marks them saved
+func (t *accessTokenUsageTracker) takeUnsaved() map[string]accessTokenUsage {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	res := make(map[string]accessTokenUsage)
+
+	for name, usage := range t.usages {
+		if usage.unsavedCount == 0 {
+			continue
+		}
+
+		res[name] = *usage
+		usage.unsavedCount = 0
+	}
+
+	return res
+}
+
+func (t *accessTokenUsageTracker) forget(name string) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	delete(t.usages, name)
+}
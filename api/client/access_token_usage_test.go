@@ -0,0 +1,29 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAccessTokenUsageQuotaPeriod(t *testing.T) {
+	tracker := newAccessTokenUsageTracker()
+
+	accessToken := &v1alpha1.AccessToken{
+		ObjectMeta: metaV1.ObjectMeta{Name: "token"},
+		Spec: v1alpha1.AccessTokenSpec{
+			Quota: &v1alpha1.AccessTokenQuota{Requests: 1, PeriodSeconds: 60},
+		},
+	}
+
+	now := time.Now()
+	assert.True(t, tracker.use(accessToken, "", now))
+	assert.False(t, tracker.use(accessToken, "", now.Add(59*time.Second)))
+	assert.True(t, tracker.use(accessToken, "", now.Add(60*time.Second)))
+
+	tracker.forget("token")
+	assert.Len(t, tracker.takeUnsaved(), 0)
+}
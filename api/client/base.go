@@ -29,11 +29,15 @@ type ClientInfo struct {
 	Groups            []string     `json:"groups"`
 	Impersonation     string       `json:"impersonation"`
 	ImpersonationType string       `json:"impersonationType"`
+
+	// set if the client is authenticated by an access token
+	AccessTokenName    string `json:"-"`
+	AccessTokenCreator string `json:"-"`
 }
 
 type ClientManager interface {
 	GetDefaultClusterConfig() *rest.Config
-	GetClientInfoFromToken(token string) (*ClientInfo, error)
+	GetClientInfoFromToken(token string, clientIP string) (*ClientInfo, error)
 	GetClientInfoFromContext(c echo.Context) (*ClientInfo, error)
 	SetImpersonation(client *ClientInfo, impersonation string)
 
@@ -50,6 +54,7 @@ type ClientManager interface {
 
 	// special resources
 	CanOperateHttpRoute(c *ClientInfo, action string, route *resources.HttpRoute) bool
+	CanOperateTcpRoute(c *ClientInfo, action string, route *resources.TcpRoute) bool
 	PermissionsGreaterThanOrEqualToAccessToken(c *ClientInfo, accessToken *resources.AccessToken) bool
 
 	GetRBACEnforcer() rbac.Enforcer
@@ -201,6 +206,31 @@ func (m *BaseClientManager) CanOperateHttpRoute(c *ClientInfo, action string, ro
 	return true
 }
 
+// tcp routes open ports on the ingress gateway for all namespaces, same as http routes
+func (m *BaseClientManager) CanOperateTcpRoute(c *ClientInfo, action string, route *resources.TcpRoute) bool {
+	if c == nil || route == nil {
+		return false
+	}
+
+	for _, dest := range route.TcpRouteSpec.Destinations {
+		parts := strings.Split(dest.Host, ".")
+		if len(parts) < 2 {
+			return false
+		}
+	}
+
+	switch action {
+	case "view":
+		return m.CanViewNamespace(c, "*")
+	case "edit":
+		return m.CanEditNamespace(c, "*")
+	case "manage":
+		return m.CanManageNamespace(c, "*")
+	default:
+		return false
+	}
+}
+
 func extractAuthTokenFromClientRequestContext(c echo.Context) string {
 	req := c.Request()
 
@@ -23,7 +23,7 @@ func (m *FakeClientManager) GetDefaultClusterConfig() *rest.Config {
 func (m *FakeClientManager) SetImpersonation(_ *ClientInfo, _ string) {
 }
 
-func (m *FakeClientManager) GetClientInfoFromToken(token string) (*ClientInfo, error) {
+func (m *FakeClientManager) GetClientInfoFromToken(token string, _ string) (*ClientInfo, error) {
 	if token == "" {
 		return nil, errors.NewUnauthorized("No token found in request header")
 	}
@@ -41,7 +41,7 @@ func (m *FakeClientManager) GetClientInfoFromToken(token string) (*ClientInfo, e
 
 func (m *FakeClientManager) GetClientInfoFromContext(c echo.Context) (*ClientInfo, error) {
 	token := extractAuthTokenFromClientRequestContext(c)
-	return m.GetClientInfoFromToken(token)
+	return m.GetClientInfoFromToken(token, c.RealIP())
 }
 
 func ToFakeToken(email string, roles ...string) string {
@@ -19,7 +19,7 @@ func (m *LocalClientManager) GetDefaultClusterConfig() *rest.Config {
 	return m.ClusterConfig
 }
 
-func (m *LocalClientManager) GetClientInfoFromToken(_ string) (*ClientInfo, error) {
+func (m *LocalClientManager) GetClientInfoFromToken(_ string, _ string) (*ClientInfo, error) {
 	return nil, errors.NewUnauthorized("auth via token is not allowed in local client manager")
 }
 
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -23,7 +24,9 @@ import (
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
 	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type StandardClientManager struct {
@@ -41,7 +44,13 @@ type StandardClientManager struct {
 	Applications  map[string]*coreV1.Namespace
 	AccessTokens  map[string]*v1alpha1.AccessToken
 	RoleBindings  map[string]*v1alpha1.RoleBinding
+	Roles         map[string]*v1alpha1.Role
 	StopWatchChan chan struct{}
+
+	accessTokenUsages *accessTokenUsageTracker
+
+	// maps the groups of sso users, built from the group mappings of the sso config
+	ssoGroupMapper *v1alpha1.GroupMapper
 }
 
 func BuildClusterRolePolicies() string {
@@ -73,6 +82,37 @@ g, role_{{ .name }}_owner, role_{{ .name }}_editor
 	return strBuffer.String()
 }
 
+// custom roles are granted in the namespace of the binding, or in all namespaces if the binding is in kalm-system
+func BuildCustomRolePolicies(role *v1alpha1.Role, namespace string) string {
+	var sb strings.Builder
+
+	policyNamespace := namespace
+
+	if v1alpha1.IsKalmSystemNamespace(namespace) {
+		policyNamespace = rbac.AnyNamespace
+	}
+
+	sb.WriteString(fmt.Sprintf("# %s custom role %s policies\n", namespace, role.Name))
+
+	for _, rule := range role.Spec.Rules {
+		for _, action := range rbac.ImpliedActions(rule.Action) {
+			sb.WriteString(fmt.Sprintf(
+				"p, %s, %s, %s, %s\n",
+				customRoleToPolicyValue(namespace, role.Name),
+				action,
+				policyNamespace,
+				rule.Resource,
+			))
+		}
+	}
+
+	return sb.String()
+}
+
+func customRoleToPolicyValue(ns, role string) string {
+	return fmt.Sprintf("custom_role_%s_%s", ns, role)
+}
+
 func GetPoliciesFromAccessToken(accessToken *resources.AccessToken) [][]string {
 	var res = [][]string{}
 	for _, rule := range accessToken.Rules {
@@ -148,6 +188,9 @@ func (m *StandardClientManager) UpdatePolicies() {
 		}
 	}
 
+	// policies of custom roles are only generated for the namespaces they are bound in
+	customRoles := make(map[string]string)
+
 	for _, roleBinding := range m.RoleBindings {
 		if roleBinding.Spec.ExpiredAt != nil && roleBinding.Spec.ExpiredAt.Time.Before(time.Now()) {
 			continue
@@ -163,14 +206,39 @@ func (m *StandardClientManager) UpdatePolicies() {
 			continue
 		}
 
+		policyRole := roleValueToPolicyValue(roleBinding.Namespace, roleBinding.Spec.Role)
+
+		if !v1alpha1.IsBuiltinRole(roleBinding.Spec.Role) {
+			role, exist := m.Roles[roleBinding.Spec.Role]
+
+			if !exist {
+				continue
+			}
+
+			policyRole = customRoleToPolicyValue(roleBinding.Namespace, role.Name)
+			customRoles[policyRole] = BuildCustomRolePolicies(role, roleBinding.Namespace)
+		}
+
 		sb.WriteString(fmt.Sprintf("# policies for rolebinding %s\n", roleBinding.Name))
 		sb.WriteString(fmt.Sprintf(
 			"g, %s, %s\n",
 			safeSubject,
-			roleValueToPolicyValue(roleBinding.Namespace, roleBinding.Spec.Role)),
+			policyRole),
 		)
 	}
 
+	customRoleNames := make([]string, 0, len(customRoles))
+
+	for name := range customRoles {
+		customRoleNames = append(customRoleNames, name)
+	}
+
+	sort.Strings(customRoleNames)
+
+	for _, name := range customRoleNames {
+		sb.WriteString(customRoles[name])
+	}
+
 	m.PolicyAdapter.SetPoliciesString(sb.String())
 
 	if err := m.RBACEnforcer.LoadPolicy(); err != nil {
@@ -183,26 +251,39 @@ func (m *StandardClientManager) GetDefaultClusterConfig() *rest.Config {
 	return m.ClusterConfig
 }
 
-func (m *StandardClientManager) GetClientInfoFromToken(tokenString string) (*ClientInfo, error) {
+func (m *StandardClientManager) GetClientInfoFromToken(tokenString string, clientIP string) (*ClientInfo, error) {
 	m.mut.RLock()
 	defer m.mut.RUnlock()
 
 	accessToken, ok := m.AccessTokens[v1alpha1.GetAccessTokenNameFromToken(tokenString)]
 
-	if !ok {
+	if !ok || !accessToken.Spec.VerifyToken(tokenString) {
 		return nil, errors.NewUnauthorized("access token not exist")
 	}
 
-	if accessToken.Spec.ExpiredAt != nil && accessToken.Spec.ExpiredAt.Time.Before(time.Now()) {
+	now := time.Now()
+
+	if accessToken.Spec.ExpiredAt != nil && accessToken.Spec.ExpiredAt.Time.Before(now) {
 		return nil, errors.NewUnauthorized("access token is expired")
 	}
 
+	if !accessToken.Spec.AllowsIP(clientIP) {
+		return nil, errors.NewUnauthorized(fmt.Sprintf("access token is not allowed to be used from %s", clientIP))
+	}
+
+	if !m.accessTokenUsages.use(accessToken, clientIP, now) {
+		return nil, errors.NewTooManyRequests("access token quota exceeded", 0)
+	}
+
 	clientInfo := &ClientInfo{
 		Cfg:           m.ClusterConfig,
 		Name:          accessToken.Name,
 		Email:         accessToken.Name,
 		EmailVerified: false,
 		Groups:        []string{},
+
+		AccessTokenName:    accessToken.Name,
+		AccessTokenCreator: accessToken.Spec.Creator,
 	}
 
 	return clientInfo, nil
@@ -228,7 +309,7 @@ func (m *StandardClientManager) SetImpersonation(clientInfo *ClientInfo, rawImpe
 func (m *StandardClientManager) GetClientInfoFromContext(c echo.Context) (*ClientInfo, error) {
 	// If the Authorization Header is not empty, use the bearer token as k8s token.
 	if token := extractAuthTokenFromClientRequestContext(c); token != "" {
-		clientInfo, err := m.GetClientInfoFromToken(token)
+		clientInfo, err := m.GetClientInfoFromToken(token, c.RealIP())
 		if err != nil {
 			return nil, err
 		}
@@ -257,6 +338,10 @@ func (m *StandardClientManager) GetClientInfoFromContext(c echo.Context) (*Clien
 		clientInfo.Cfg = m.ClusterConfig
 		clientInfo.Impersonation = ""
 
+		m.mut.RLock()
+		clientInfo.Groups = m.ssoGroupMapper.Map(clientInfo.Groups)
+		m.mut.RUnlock()
+
 		if clientInfo.Groups == nil {
 			clientInfo.Groups = []string{}
 		}
@@ -299,15 +384,89 @@ func NewStandardClientManager(cfg *rest.Config, staticPolicies string) *Standard
 		Applications:      make(map[string]*coreV1.Namespace),
 		AccessTokens:      make(map[string]*v1alpha1.AccessToken),
 		RoleBindings:      make(map[string]*v1alpha1.RoleBinding),
+		Roles:             make(map[string]*v1alpha1.Role),
 		StopWatchChan:     make(chan struct{}),
+		accessTokenUsages: newAccessTokenUsageTracker(),
 	}
 
 	go setupResourcesWatcher(cfg, manager)
 	go policyRegenerateLoop(manager)
+	go accessTokenMaintainLoop(manager)
 
 	return manager
 }
 
+// Run periodically to save usages of access tokens, and to hash tokens created before tokens are hashed
+func accessTokenMaintainLoop(manager *StandardClientManager) {
+	k8sClient, err := ctrlClient.New(manager.ClusterConfig, ctrlClient.Options{})
+
+	if err != nil {
+		log.Error("new client error", zap.Error(err))
+		return
+	}
+
+	for {
+		time.Sleep(30 * time.Second)
+		manager.saveAccessTokenUsages(k8sClient)
+		manager.hashPlaintextAccessTokens(k8sClient)
+	}
+}
+
+// Other api server instances save usages of the same access token too,
+// the used count is added to the latest status and retried on conflicts.
+func (m *StandardClientManager) saveAccessTokenUsages(k8sClient ctrlClient.Client) {
+	for name, usage := range m.accessTokenUsages.takeUnsaved() {
+		m.mut.RLock()
+		accessToken, ok := m.AccessTokens[name]
+		m.mut.RUnlock()
+
+		if !ok {
+			continue
+		}
+
+		key := ctrlClient.ObjectKey{Namespace: accessToken.Namespace, Name: accessToken.Name}
+
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			var latest v1alpha1.AccessToken
+
+			if err := k8sClient.Get(context.Background(), key, &latest); err != nil {
+				return err
+			}
+
+			latest.Status.UsedCount += usage.unsavedCount
+			latest.Status.LastUsedAt = int(usage.lastUsedAt.Unix())
+			latest.Status.LastUsedIP = usage.lastUsedIP
+
+			return k8sClient.Status().Update(context.Background(), &latest)
+		})
+
+		if err != nil && !errors.IsNotFound(err) {
+			log.Error("fail to update status of access token", zap.String("name", name), zap.Error(err))
+		}
+	}
+}
+
+func (m *StandardClientManager) hashPlaintextAccessTokens(k8sClient ctrlClient.Client) {
+	var plaintextTokens []*v1alpha1.AccessToken
+
+	m.mut.RLock()
+	for _, accessToken := range m.AccessTokens {
+		if accessToken.Spec.TokenHash == "" && accessToken.Spec.Token != "" {
+			plaintextTokens = append(plaintextTokens, accessToken)
+		}
+	}
+	m.mut.RUnlock()
+
+	for _, accessToken := range plaintextTokens {
+		copied := accessToken.DeepCopy()
+		copied.Spec.SetToken(copied.Spec.Token)
+
+		if err := k8sClient.Update(context.Background(), copied); err != nil {
+			log.Error("fail to hash access token", zap.String("name", accessToken.Name), zap.Error(err))
+		}
+	}
+}
+
 // Run per minute to remove expired access tokens and role bindings
 func policyRegenerateLoop(manager *StandardClientManager) {
 	for {
@@ -390,6 +549,38 @@ func setupResourcesWatcher(cfg *rest.Config, manager *StandardClientManager) {
 		panic(err)
 	}
 
+	if informer, err := informerCache.GetInformer(context.Background(), &v1alpha1.Role{}); err == nil {
+		informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				manager.mut.Lock()
+				defer manager.mut.Unlock()
+				if role, ok := obj.(*v1alpha1.Role); ok {
+					manager.Roles[role.Name] = role
+					manager.UpdatePolicies()
+				}
+			},
+			DeleteFunc: func(obj interface{}) {
+				manager.mut.Lock()
+				defer manager.mut.Unlock()
+				if role, ok := obj.(*v1alpha1.Role); ok {
+					delete(manager.Roles, role.Name)
+					manager.UpdatePolicies()
+				}
+			},
+			UpdateFunc: func(oldObj, obj interface{}) {
+				manager.mut.Lock()
+				defer manager.mut.Unlock()
+				if role, ok := obj.(*v1alpha1.Role); ok {
+					manager.Roles[role.Name] = role
+					manager.UpdatePolicies()
+				}
+			},
+		})
+	} else {
+		log.Error("get informer error", zap.Error(err))
+		panic(err)
+	}
+
 	if informer, err := informerCache.GetInformer(context.Background(), &v1alpha1.AccessToken{}); err == nil {
 		informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
 			AddFunc: func(obj interface{}) {
@@ -405,6 +596,7 @@ func setupResourcesWatcher(cfg *rest.Config, manager *StandardClientManager) {
 				defer manager.mut.Unlock()
 				if accessToken, ok := obj.(*v1alpha1.AccessToken); ok {
 					delete(manager.AccessTokens, accessToken.Name)
+					manager.accessTokenUsages.forget(accessToken.Name)
 					manager.UpdatePolicies()
 				}
 			},
@@ -422,9 +614,55 @@ func setupResourcesWatcher(cfg *rest.Config, manager *StandardClientManager) {
 		panic(err)
 	}
 
+	if informer, err := informerCache.GetInformer(context.Background(), &v1alpha1.SingleSignOnConfig{}); err == nil {
+		informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				if ssoConfig, ok := obj.(*v1alpha1.SingleSignOnConfig); ok {
+					manager.setSSOGroupMappings(ssoConfig, false)
+				}
+			},
+			DeleteFunc: func(obj interface{}) {
+				if ssoConfig, ok := obj.(*v1alpha1.SingleSignOnConfig); ok {
+					manager.setSSOGroupMappings(ssoConfig, true)
+				}
+			},
+			UpdateFunc: func(oldObj, obj interface{}) {
+				if ssoConfig, ok := obj.(*v1alpha1.SingleSignOnConfig); ok {
+					manager.setSSOGroupMappings(ssoConfig, false)
+				}
+			},
+		})
+	} else {
+		log.Error("get informer error", zap.Error(err))
+		panic(err)
+	}
+
 	informerCache.Start(manager.StopWatchChan)
 }
 
+// only the group mappings of the kalm sso config are used
+func (m *StandardClientManager) setSSOGroupMappings(ssoConfig *v1alpha1.SingleSignOnConfig, deleted bool) {
+	if ssoConfig.Namespace != controllers.KALM_DEX_NAMESPACE || ssoConfig.Name != resources.SSO_NAME {
+		return
+	}
+
+	var mapper *v1alpha1.GroupMapper
+
+	if !deleted {
+		var err error
+
+		if mapper, err = v1alpha1.NewGroupMapper(ssoConfig.Spec.GroupMappings); err != nil {
+			log.Error("invalid sso group mappings", zap.Error(err))
+			mapper = nil
+		}
+	}
+
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	m.ssoGroupMapper = mapper
+}
+
 func getNamespacedName(metaObj metaV1.ObjectMeta) string {
 	return fmt.Sprintf("%s-%s", metaObj.Namespace, metaObj.Name)
 }
@@ -1,13 +1,29 @@
 package client
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"path/filepath"
+	"sync"
 	"testing"
 
+	"github.com/kalmhq/kalm/api/rbac"
+	"github.com/kalmhq/kalm/api/resources"
 	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/kalmhq/kalm/controller/controllers"
+	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/deprecated/scheme"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 )
 
@@ -37,3 +53,205 @@ func TestPolicyUpdate(t *testing.T) {
 		fmt.Println("group policy:", g)
 	}
 }
+
+func TestCustomRolePolicies(t *testing.T) {
+	policyAdapter := rbac.NewStringPolicyAdapter(``)
+
+	clientMgr := &StandardClientManager{
+		BaseClientManager: NewBaseClientManager(policyAdapter),
+		PolicyAdapter:     policyAdapter,
+		mut:               &sync.RWMutex{},
+		Applications:      make(map[string]*coreV1.Namespace),
+		AccessTokens:      make(map[string]*v1alpha1.AccessToken),
+		RoleBindings:      make(map[string]*v1alpha1.RoleBinding),
+		Roles:             make(map[string]*v1alpha1.Role),
+	}
+
+	role := &v1alpha1.Role{
+		ObjectMeta: metaV1.ObjectMeta{Name: "job-operator"},
+		Spec: v1alpha1.RoleSpec{
+			Rules: []v1alpha1.RoleRule{
+				{Action: rbac.ActionView, Resource: "pods/*"},
+				{Action: rbac.ActionEdit, Resource: "components/*"},
+				{Action: rbac.ActionManage, Resource: "secrets/*"},
+			},
+		},
+	}
+
+	clientMgr.Roles[role.Name] = role
+
+	clientMgr.RoleBindings["ns1-foo"] = &v1alpha1.RoleBinding{
+		ObjectMeta: metaV1.ObjectMeta{Name: "foo", Namespace: "ns1"},
+		Spec: v1alpha1.RoleBindingSpec{
+			Subject:     "foo@bar.com",
+			SubjectType: v1alpha1.SubjectTypeUser,
+			Role:        role.Name,
+		},
+	}
+
+	clientMgr.RoleBindings["kalm-system-bar"] = &v1alpha1.RoleBinding{
+		ObjectMeta: metaV1.ObjectMeta{Name: "bar", Namespace: v1alpha1.KalmSystemNamespace},
+		Spec: v1alpha1.RoleBindingSpec{
+			Subject:     "bar@bar.com",
+			SubjectType: v1alpha1.SubjectTypeUser,
+			Role:        role.Name,
+		},
+	}
+
+	clientMgr.RoleBindings["ns1-baz"] = &v1alpha1.RoleBinding{
+		ObjectMeta: metaV1.ObjectMeta{Name: "baz", Namespace: "ns1"},
+		Spec: v1alpha1.RoleBindingSpec{
+			Subject:     "baz@bar.com",
+			SubjectType: v1alpha1.SubjectTypeUser,
+			Role:        "not-exist",
+		},
+	}
+
+	clientMgr.UpdatePolicies()
+
+	foo := ToSafeSubject("foo@bar.com", v1alpha1.SubjectTypeUser)
+	assert.True(t, clientMgr.RBACEnforcer.CanView(foo, "ns1", "pods/web-0"))
+	assert.True(t, clientMgr.RBACEnforcer.CanEdit(foo, "ns1", "components/web"))
+	assert.False(t, clientMgr.RBACEnforcer.CanEdit(foo, "ns1", "pods/web-0"))
+	assert.False(t, clientMgr.RBACEnforcer.CanView(foo, "ns2", "pods/web-0"))
+
+	// edit implies view, manage implies edit and view
+	assert.True(t, clientMgr.RBACEnforcer.CanView(foo, "ns1", "components/web"))
+	assert.False(t, clientMgr.RBACEnforcer.CanManage(foo, "ns1", "components/web"))
+	assert.True(t, clientMgr.RBACEnforcer.CanManage(foo, "ns1", "secrets/db"))
+	assert.True(t, clientMgr.RBACEnforcer.CanEdit(foo, "ns1", "secrets/db"))
+	assert.True(t, clientMgr.RBACEnforcer.CanView(foo, "ns1", "secrets/db"))
+
+	bar := ToSafeSubject("bar@bar.com", v1alpha1.SubjectTypeUser)
+	assert.True(t, clientMgr.RBACEnforcer.CanView(bar, "ns2", "pods/web-0"))
+	assert.True(t, clientMgr.RBACEnforcer.CanEdit(bar, "ns2", "components/web"))
+	assert.False(t, clientMgr.RBACEnforcer.CanManage(bar, "ns2", "components/web"))
+
+	baz := ToSafeSubject("baz@bar.com", v1alpha1.SubjectTypeUser)
+	assert.False(t, clientMgr.RBACEnforcer.CanView(baz, "ns1", "pods/web-0"))
+
+	// bindings are kept when the role is deleted, but grant nothing
+	delete(clientMgr.Roles, role.Name)
+	clientMgr.UpdatePolicies()
+	assert.False(t, clientMgr.RBACEnforcer.CanView(foo, "ns1", "pods/web-0"))
+}
+
+func TestGetClientInfoFromAccessToken(t *testing.T) {
+	clientMgr := &StandardClientManager{
+		mut:               &sync.RWMutex{},
+		AccessTokens:      make(map[string]*v1alpha1.AccessToken),
+		accessTokenUsages: newAccessTokenUsageTracker(),
+	}
+
+	token, name := v1alpha1.GenerateAccessToken()
+
+	accessToken := &v1alpha1.AccessToken{
+		ObjectMeta: metaV1.ObjectMeta{Name: name},
+		Spec: v1alpha1.AccessTokenSpec{
+			Creator:    "foo@bar.com",
+			AllowedIPs: []string{"10.0.0.0/8"},
+			Quota:      &v1alpha1.AccessTokenQuota{Requests: 2, PeriodSeconds: 3600},
+		},
+	}
+	accessToken.Spec.SetToken(token)
+	clientMgr.AccessTokens[name] = accessToken
+
+	clientInfo, err := clientMgr.GetClientInfoFromToken(token, "10.1.2.3")
+	assert.Nil(t, err)
+	assert.Equal(t, name, clientInfo.AccessTokenName)
+	assert.Equal(t, "foo@bar.com", clientInfo.AccessTokenCreator)
+
+	// a token with the same name but a different secret
+	wrongSecret := token[:len(token)-1] + "0"
+	if wrongSecret == token {
+		wrongSecret = token[:len(token)-1] + "1"
+	}
+
+	_, err = clientMgr.GetClientInfoFromToken(wrongSecret, "10.1.2.3")
+	assert.True(t, errors.IsUnauthorized(err))
+
+	_, err = clientMgr.GetClientInfoFromToken(token, "192.168.1.1")
+	assert.True(t, errors.IsUnauthorized(err))
+
+	_, err = clientMgr.GetClientInfoFromToken(token, "10.1.2.3")
+	assert.Nil(t, err)
+
+	// the quota is exceeded
+	_, err = clientMgr.GetClientInfoFromToken(token, "10.1.2.3")
+	assert.True(t, errors.IsTooManyRequests(err))
+
+	usages := clientMgr.accessTokenUsages.takeUnsaved()
+	assert.Equal(t, 2, usages[name].unsavedCount)
+	assert.Equal(t, "10.1.2.3", usages[name].lastUsedIP)
+	assert.Len(t, clientMgr.accessTokenUsages.takeUnsaved(), 0)
+}
+
+func TestSSOGroupMappings(t *testing.T) {
+	clientMgr := &StandardClientManager{
+		mut: &sync.RWMutex{},
+	}
+
+	clientMgr.setSSOGroupMappings(&v1alpha1.SingleSignOnConfig{
+		ObjectMeta: metaV1.ObjectMeta{Name: resources.SSO_NAME, Namespace: controllers.KALM_DEX_NAMESPACE},
+		Spec: v1alpha1.SingleSignOnConfigSpec{
+			GroupMappings: []v1alpha1.GroupMapping{
+				{Type: v1alpha1.GroupMappingTypePrefix, Match: "kalmhq:", Groups: []string{"kalm-members"}},
+			},
+		},
+	}, false)
+
+	getGroups := func() []string {
+		claims, _ := json.Marshal(map[string]interface{}{"email": "foo@bar.com", "groups": []string{"kalmhq:devs"}})
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Kalm-Sso-Userinfo", base64.RawStdEncoding.EncodeToString(claims))
+
+		clientInfo, err := clientMgr.GetClientInfoFromContext(echo.New().NewContext(req, httptest.NewRecorder()))
+		assert.Nil(t, err)
+		return clientInfo.Groups
+	}
+
+	assert.Equal(t, []string{"kalmhq:devs", "kalm-members"}, getGroups())
+
+	// sso configs other than the kalm sso config are ignored
+	clientMgr.setSSOGroupMappings(&v1alpha1.SingleSignOnConfig{
+		ObjectMeta: metaV1.ObjectMeta{Name: "other", Namespace: controllers.KALM_DEX_NAMESPACE},
+	}, true)
+	assert.Equal(t, []string{"kalmhq:devs", "kalm-members"}, getGroups())
+
+	clientMgr.setSSOGroupMappings(&v1alpha1.SingleSignOnConfig{
+		ObjectMeta: metaV1.ObjectMeta{Name: resources.SSO_NAME, Namespace: controllers.KALM_DEX_NAMESPACE},
+	}, true)
+	assert.Equal(t, []string{"kalmhq:devs"}, getGroups())
+}
+
+func TestSaveAccessTokenUsages(t *testing.T) {
+	s := runtime.NewScheme()
+	assert.Nil(t, v1alpha1.AddToScheme(s))
+
+	token, name := v1alpha1.GenerateAccessToken()
+	accessToken := &v1alpha1.AccessToken{ObjectMeta: metaV1.ObjectMeta{Name: name}}
+	accessToken.Spec.SetToken(token)
+
+	// the usages saved by another api server instance
+	saved := accessToken.DeepCopy()
+	saved.Status.UsedCount = 5
+	k8sClient := fake.NewFakeClientWithScheme(s, saved)
+
+	clientMgr := &StandardClientManager{
+		mut:               &sync.RWMutex{},
+		AccessTokens:      map[string]*v1alpha1.AccessToken{name: accessToken},
+		accessTokenUsages: newAccessTokenUsageTracker(),
+	}
+
+	_, err := clientMgr.GetClientInfoFromToken(token, "10.1.2.3")
+	assert.Nil(t, err)
+	_, err = clientMgr.GetClientInfoFromToken(token, "10.1.2.3")
+	assert.Nil(t, err)
+
+	clientMgr.saveAccessTokenUsages(k8sClient)
+
+	var latest v1alpha1.AccessToken
+	assert.Nil(t, k8sClient.Get(context.Background(), ctrlClient.ObjectKey{Name: name}, &latest))
+	assert.Equal(t, 7, latest.Status.UsedCount)
+	assert.Equal(t, "10.1.2.3", latest.Status.LastUsedIP)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/kalmhq/kalm/api/log"
 	"github.com/kalmhq/kalm/api/server"
 	"github.com/kalmhq/kalm/api/utils"
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
 	"github.com/kalmhq/kalm/controller/controllers"
 	"github.com/kalmhq/kalm/controller/validation"
 	"github.com/labstack/echo/v4"
@@ -44,6 +45,9 @@ var logger *zap.Logger
 var issuerIsGoogle bool
 var issuerIsInternalDex bool
 
+// maps the groups in claims before checking the granted groups
+var groupMapper *v1alpha1.GroupMapper
+
 // CSRF protection and pass payload
 type OauthState struct {
 	Nonce       string
@@ -114,6 +118,32 @@ func getOauth2Config() *oauth2.Config {
 	return oauth2Config
 }
 
+// The group mappings of the sso config are passed by env. Groups are not mapped if the mappings are invalid,
+// which only grants less access.
+func initGroupMapper() {
+	rawMappings := os.Getenv(v1alpha1.ENV_KALM_SSO_GROUP_MAPPINGS)
+
+	if rawMappings == "" {
+		return
+	}
+
+	var mappings []v1alpha1.GroupMapping
+
+	if err := json.Unmarshal([]byte(rawMappings), &mappings); err != nil {
+		logger.Error("parse group mappings failed.", zap.Error(err))
+		return
+	}
+
+	mapper, err := v1alpha1.NewGroupMapper(mappings)
+
+	if err != nil {
+		logger.Error("invalid group mappings.", zap.Error(err))
+		return
+	}
+
+	groupMapper = mapper
+}
+
 func removeExtAuthPathPrefix(path string) string {
 	if strings.HasPrefix(path, "/"+ENVOY_EXT_AUTH_PATH_PREFIX) {
 		// remove prefix "/" + ENVOY_EXT_AUTH_PATH_PREFIX
@@ -196,6 +226,14 @@ func handleExtAuthz(c echo.Context) error {
 	//   - If `let-pass-if-has-bearer-token` header is explicitly declared
 	//   - There is a bearerAuthorization token
 	if shouldLetPass(c) {
+		// the bearer token is checked by the upstream
+		setRateLimitSubject(c, controllers.KALM_RATE_LIMIT_ANONYMOUS_SUBJECT)
+		return c.NoContent(200)
+	}
+
+	// the client certificate is verified by the ingress gateway, let it pass if its subject is granted
+	if isCertSubjectGranted(c) {
+		setRateLimitSubject(c, "cert:"+c.Request().Header.Get(controllers.KALM_CLIENT_CERT_SUBJECT_HEADER))
 		return c.NoContent(200)
 	}
 
@@ -284,10 +322,17 @@ func handleExtAuthz(c echo.Context) error {
 	parts := strings.Split(token.IDTokenString, ".")
 	c.Response().Header().Set(controllers.KALM_SSO_USERINFO_HEADER, parts[1])
 	c.Response().Header().Set(controllers.KALM_AUTH_EMAIL, claims.Email)
+	setRateLimitSubject(c, "email:"+claims.Email)
 
 	return c.NoContent(200)
 }
 
+// The subject is counted by the rate limit of routes with the subject key.
+// It's always set, so that the value sent by the client is overridden by the ext_authz filter.
+func setRateLimitSubject(c echo.Context, subject string) {
+	c.Response().Header().Set(controllers.KALM_RATE_LIMIT_SUBJECT_HEADER, subject)
+}
+
 // When a user's id_token has expired, but the refresh_token is still valid, multiple requests may be received in a short time window.
 // But refresh_token is not allowed to be used twice. We can't let all the requests to refresh token at the same time.
 // So a condition variable is used to ensure that only one process sends a refresh request,
@@ -410,15 +455,42 @@ func shouldLetPass(c echo.Context) bool {
 		strings.HasPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
 }
 
+// `kalm-client-cert-subject` is set by the ingress gateway for mutual TLS connections,
+// `kalm-sso-granted-cert-subjects` is the allowed cert subjects of the protected endpoint.
+// The subject is only trusted if the request is sent by the ingress gateway, which is the peer
+// in the last element of the x-forwarded-client-cert header appended by the sidecar.
+func isCertSubjectGranted(c echo.Context) bool {
+	subject := c.Request().Header.Get(controllers.KALM_CLIENT_CERT_SUBJECT_HEADER)
+	grantedSubjects := c.Request().Header.Get(controllers.KALM_SSO_GRANTED_CERT_SUBJECTS_HEADER)
+
+	if subject == "" || grantedSubjects == "" {
+		return false
+	}
+
+	if auth_proxy.LastXFCCPeerURI(c.Request().Header.Get(controllers.XFCC_HEADER)) != controllers.KALM_INGRESS_GATEWAY_PRINCIPAL {
+		return false
+	}
+
+	for _, s := range strings.Split(grantedSubjects, "|") {
+		if s == subject {
+			return true
+		}
+	}
+
+	return false
+}
+
 // When auth-proxy works as a ext_authz filter in envoy, the request will come along with
 // `kalm-sso-granted-groups` and `kalm-sso-granted-emails`.
-// If the `email` in the claims is in `kalm-sso-granted-emails` OR the `groups` in the claims have intersections with `kalm-sso-granted-groups`,
+// If the `email` in the claims is in `kalm-sso-granted-emails` OR the `groups` in the claims, after applying the group mappings,
+// have intersections with `kalm-sso-granted-groups`,
 // then the request is considered authorized, otherwise, the request will be blocked.
 func isAuthorized(c echo.Context, claims *Claims) bool {
 	grantedGroups := c.Request().Header.Get(controllers.KALM_SSO_GRANTED_GROUPS_HEADER)
 	grantedEmails := c.Request().Header.Get(controllers.KALM_SSO_GRANTED_EMAILS_HEADER)
+	claimsGroups := groupMapper.Map(claims.Groups)
 	logger.Info(fmt.Sprintf("granted groups: %s, emails: %s", grantedGroups, grantedEmails))
-	logger.Info(fmt.Sprintf("claims groups: %s, email: %s", claims.Groups, claims.Email))
+	logger.Info(fmt.Sprintf("claims groups: %s, mapped groups: %s, email: %s", claims.Groups, claimsGroups, claims.Email))
 
 	if grantedGroups != "" {
 		groups := strings.Split(grantedGroups, "|")
@@ -428,7 +500,7 @@ func isAuthorized(c echo.Context, claims *Claims) bool {
 			gm[g] = struct{}{}
 		}
 
-		for _, g := range claims.Groups {
+		for _, g := range claimsGroups {
 			if _, ok := gm[g]; ok {
 				return true
 			}
@@ -691,6 +763,8 @@ func handleLog(c echo.Context) error {
 
 func main() {
 	logger = log.NewLogger(false)
+	initGroupMapper()
+
 	e := server.NewEchoInstance()
 
 	// oidc auth proxy handlers
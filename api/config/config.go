@@ -22,6 +22,13 @@ type Config struct {
 	CorsAllowedOrigins            cli.StringSlice
 
 	EnableAdminServerDebugRoutes bool
+
+	AuditLogFilePath      string
+	AuditWebhookURL       string
+	AuditKubernetesEvents bool
+	AuditRetainedEntries  int
+
+	ExecRecordingsDir string
 }
 
 type BaseDomainConfig struct {
@@ -1,11 +1,12 @@
 package handler
 
 import (
+	"time"
+
 	"github.com/kalmhq/kalm/api/errors"
 	"github.com/kalmhq/kalm/api/resources"
 	"github.com/kalmhq/kalm/controller/api/v1alpha1"
 	"github.com/labstack/echo/v4"
-	"k8s.io/apimachinery/pkg/util/rand"
 )
 
 // installer
@@ -14,6 +15,7 @@ func (h *ApiHandler) InstallAccessTokensHandlers(e *echo.Group) {
 	e.GET("/access_tokens", h.handleListAccessTokens)
 	e.POST("/access_tokens", h.handleCreateAccessToken)
 	e.DELETE("/access_tokens", h.handleDeleteAccessToken)
+	e.POST("/access_tokens/:name/rotate", h.handleRotateAccessToken)
 }
 
 // handlers
@@ -38,9 +40,11 @@ func (h *ApiHandler) handleCreateAccessToken(c echo.Context) error {
 	}
 
 	// Set sensitive fields
-	accessToken.Token = rand.String(128)
+	token, name := v1alpha1.GenerateAccessToken()
 	accessToken.Creator = currentUser.Name
-	accessToken.Name = v1alpha1.GetAccessTokenNameFromToken(accessToken.Token)
+	accessToken.Name = name
+	accessToken.RotatedFrom = ""
+	accessToken.SetToken(token)
 
 	if !h.clientManager.PermissionsGreaterThanOrEqualToAccessToken(currentUser, accessToken) {
 		return resources.InsufficientPermissionsError
@@ -52,9 +56,56 @@ func (h *ApiHandler) handleCreateAccessToken(c echo.Context) error {
 		return err
 	}
 
+	// the token is only responded once, only the hash is saved
+	accessToken.Token = token
+
 	return c.JSON(201, accessToken)
 }
 
+func (h *ApiHandler) handleRotateAccessToken(c echo.Context) error {
+	currentUser := getCurrentUser(c)
+
+	var req resources.RotateAccessTokenRequest
+
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	gracePeriodSeconds := resources.DefaultAccessTokenRotationGracePeriodSeconds
+
+	if req.GracePeriodSeconds != nil {
+		if *req.GracePeriodSeconds < 0 {
+			return errors.NewBadRequest("gracePeriodSeconds can't be negative")
+		}
+
+		gracePeriodSeconds = *req.GracePeriodSeconds
+	}
+
+	var accessToken v1alpha1.AccessToken
+
+	if err := h.resourceManager.Get("", c.Param("name"), &accessToken); err != nil {
+		return err
+	}
+
+	if !h.clientManager.PermissionsGreaterThanOrEqualToAccessToken(currentUser, resources.BuildAccessTokenFromResource(&accessToken)) {
+		return resources.InsufficientPermissionsError
+	}
+
+	if accessToken.Spec.ExpiredAt != nil && accessToken.Spec.ExpiredAt.Time.Before(time.Now()) {
+		return errors.NewBadRequest("expired access token can't be rotated")
+	}
+
+	successor, token, err := h.resourceManager.RotateAccessToken(&accessToken, time.Duration(gracePeriodSeconds)*time.Second)
+
+	if err != nil {
+		return err
+	}
+
+	successor.Token = token
+
+	return c.JSON(201, successor)
+}
+
 func (h *ApiHandler) handleDeleteAccessToken(c echo.Context) error {
 	currentUser := getCurrentUser(c)
 
@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/kalmhq/kalm/api/resources"
 	"github.com/kalmhq/kalm/controller/api/v1alpha1"
@@ -143,3 +144,72 @@ func (suite *AccessTokenTestSuite) TestCreateAndDelete() {
 		},
 	})
 }
+
+func (suite *AccessTokenTestSuite) TestCreateAndRotate() {
+	key := resources.AccessToken{
+		AccessTokenSpec: &v1alpha1.AccessTokenSpec{
+			Rules: []v1alpha1.AccessTokenRule{
+				{
+					Verb:      "view",
+					Namespace: "*",
+					Name:      "*",
+					Kind:      "*",
+				},
+			},
+			AllowedIPs: []string{"10.0.0.0/8"},
+			Creator:    "test",
+		},
+	}
+
+	var created resources.AccessToken
+
+	// the token is only responded on creation, only the hash is saved
+	suite.DoTestRequest(&TestRequestContext{
+		Roles: []string{
+			GetClusterOwnerRole(),
+		},
+		Method: http.MethodPost,
+		Body:   key,
+		Path:   "/v1alpha1/access_tokens",
+		TestWithRoles: func(rec *ResponseRecorder) {
+			rec.BodyAsJSON(&created)
+			suite.Equal(201, rec.Code)
+			suite.Equal(created.Name, v1alpha1.GetAccessTokenNameFromToken(created.Token))
+			suite.Equal(v1alpha1.AccessTokenPrefix+created.Name, created.TokenPrefix)
+			suite.Empty(created.TokenHash)
+		},
+	})
+
+	var accessToken v1alpha1.AccessToken
+	suite.Nil(suite.Get("", created.Name, &accessToken))
+	suite.Empty(accessToken.Spec.Token)
+	suite.True(accessToken.Spec.VerifyToken(created.Token))
+
+	gracePeriodSeconds := 60
+
+	suite.DoTestRequest(&TestRequestContext{
+		Roles: []string{
+			GetClusterOwnerRole(),
+		},
+		Method: http.MethodPost,
+		Body:   resources.RotateAccessTokenRequest{GracePeriodSeconds: &gracePeriodSeconds},
+		Path:   "/v1alpha1/access_tokens/" + created.Name + "/rotate",
+		TestWithoutRoles: func(rec *ResponseRecorder) {
+			suite.IsUnauthorizedError(rec)
+		},
+		TestWithRoles: func(rec *ResponseRecorder) {
+			var successor resources.AccessToken
+			rec.BodyAsJSON(&successor)
+			suite.Equal(201, rec.Code)
+			suite.NotEqual(created.Name, successor.Name)
+			suite.NotEqual(created.Token, successor.Token)
+			suite.Equal(created.Name, successor.RotatedFrom)
+			suite.Equal(created.AllowedIPs, successor.AllowedIPs)
+		},
+	})
+
+	// the old token expires after the grace period
+	suite.Nil(suite.Get("", created.Name, &accessToken))
+	suite.NotNil(accessToken.Spec.ExpiredAt)
+	suite.True(accessToken.Spec.ExpiredAt.Time.Before(time.Now().Add(2 * time.Minute)))
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/kalmhq/kalm/controller/api/v1alpha1"
 	"github.com/labstack/echo/v4"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/rand"
 )
 
 type Policies []string
@@ -72,14 +71,12 @@ func (h *ApiHandler) handleDebugCleanAccessTokens(c echo.Context) error {
 }
 
 func (h *ApiHandler) handleCreateTemporaryAdmin(c echo.Context) error {
-	ownerToken := rand.String(128)
-	ownerTokenName := v1alpha1.GetAccessTokenNameFromToken(ownerToken)
+	ownerToken, ownerTokenName := v1alpha1.GenerateAccessToken()
 	ownerAccessToken := &v1alpha1.AccessToken{
 		ObjectMeta: metaV1.ObjectMeta{
 			Name: ownerTokenName,
 		},
 		Spec: v1alpha1.AccessTokenSpec{
-			Token: ownerToken,
 			Rules: []v1alpha1.AccessTokenRule{
 				{
 					Verb:      "view",
@@ -105,14 +102,12 @@ func (h *ApiHandler) handleCreateTemporaryAdmin(c echo.Context) error {
 		},
 	}
 
-	viewerToken := rand.String(128)
-	viewerTokenName := v1alpha1.GetAccessTokenNameFromToken(viewerToken)
+	viewerToken, viewerTokenName := v1alpha1.GenerateAccessToken()
 	viewerAccessToken := &v1alpha1.AccessToken{
 		ObjectMeta: metaV1.ObjectMeta{
 			Name: viewerTokenName,
 		},
 		Spec: v1alpha1.AccessTokenSpec{
-			Token: viewerToken,
 			Rules: []v1alpha1.AccessTokenRule{
 				{
 					Verb:      "view",
@@ -126,14 +121,12 @@ func (h *ApiHandler) handleCreateTemporaryAdmin(c echo.Context) error {
 		},
 	}
 
-	editorToken := rand.String(128)
-	editorTokenName := v1alpha1.GetAccessTokenNameFromToken(editorToken)
+	editorToken, editorTokenName := v1alpha1.GenerateAccessToken()
 	editorAccessToken := &v1alpha1.AccessToken{
 		ObjectMeta: metaV1.ObjectMeta{
 			Name: editorTokenName,
 		},
 		Spec: v1alpha1.AccessTokenSpec{
-			Token: editorToken,
 			Rules: []v1alpha1.AccessTokenRule{
 				{
 					Verb:      "view",
@@ -153,6 +146,10 @@ func (h *ApiHandler) handleCreateTemporaryAdmin(c echo.Context) error {
 		},
 	}
 
+	ownerAccessToken.Spec.SetToken(ownerToken)
+	viewerAccessToken.Spec.SetToken(viewerToken)
+	editorAccessToken.Spec.SetToken(editorToken)
+
 	if err := h.resourceManager.Create(ownerAccessToken); err != nil {
 		return err
 	}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/kalmhq/kalm/api/errors"
 	"github.com/kalmhq/kalm/api/resources"
@@ -18,6 +19,7 @@ func (h *ApiHandler) InstallApplicationsHandlers(e *echo.Group) {
 	e.GET("/applications", h.handleGetApplications)
 	e.POST("/applications", h.handleCreateApplication)
 	e.GET("/applications/:name", h.handleGetApplicationDetails, h.setApplicationIntoContext)
+	e.PUT("/applications/:name", h.handleUpdateApplication, h.setApplicationIntoContext)
 	e.DELETE("/applications/:name", h.handleDeleteApplication, h.setApplicationIntoContext)
 }
 
@@ -101,6 +103,41 @@ func (h *ApiHandler) handleCreateApplication(c echo.Context) error {
 	return c.JSON(http.StatusCreated, res)
 }
 
+func (h *ApiHandler) handleUpdateApplication(c echo.Context) error {
+	namespace := h.getApplicationFromContext(c)
+	currentUser := getCurrentUser(c)
+	h.MustCanEdit(currentUser, namespace.Name, "applications/"+namespace.Name)
+
+	var app resources.Application
+
+	if err := c.Bind(&app); err != nil {
+		return err
+	}
+
+	if app.MaintenancePage != "" && !strings.HasPrefix(app.MaintenancePage, "/") {
+		return errors.NewBadRequest("maintenance page should be an absolute path of the application files")
+	}
+
+	// recording is for compliance, only owners of the application can turn it on or off
+	if app.RecordExecSessions != resources.BuildApplicationFromNamespace(namespace).RecordExecSessions {
+		h.MustCanManage(currentUser, namespace.Name, "applications/"+namespace.Name)
+	}
+
+	app.SetAnnotations(namespace)
+
+	if err := h.resourceManager.Update(namespace); err != nil {
+		return err
+	}
+
+	res, err := h.resourceManager.BuildApplicationDetails(namespace)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(200, res)
+}
+
 func (h *ApiHandler) handleDeleteApplication(c echo.Context) error {
 	currentUser := getCurrentUser(c)
 	h.MustCanEdit(currentUser, "*", "applications/*")
@@ -132,5 +169,9 @@ func bindKalmNamespaceFromRequestBody(c echo.Context) (*coreV1.Namespace, error)
 		},
 	}
 
+	if ns.Maintenance || ns.MaintenancePage != "" || ns.RecordExecSessions {
+		ns.SetAnnotations(&coreV1Namespace)
+	}
+
 	return &coreV1Namespace, nil
 }
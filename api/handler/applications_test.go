@@ -6,7 +6,9 @@ import (
 	"testing"
 
 	"github.com/kalmhq/kalm/api/resources"
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
 	"github.com/stretchr/testify/suite"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type ApplicationsHandlerTestSuite struct {
@@ -149,6 +151,47 @@ func (suite *ApplicationsHandlerTestSuite) TestDeleteApplication() {
 	})
 }
 
+func (suite *ApplicationsHandlerTestSuite) TestGetApplicationStartupOrder() {
+	name := "startup-order-test"
+	suite.ensureNamespaceExist(name)
+
+	for _, component := range []v1alpha1.Component{
+		{
+			ObjectMeta: metaV1.ObjectMeta{Namespace: name, Name: "web"},
+			Spec:       v1alpha1.ComponentSpec{Image: "nginx", StartAfterComponents: []string{"db"}},
+		},
+		{
+			ObjectMeta: metaV1.ObjectMeta{Namespace: name, Name: "db"},
+			Spec:       v1alpha1.ComponentSpec{Image: "mysql"},
+		},
+		{
+			ObjectMeta: metaV1.ObjectMeta{Namespace: name, Name: "worker"},
+			Spec:       v1alpha1.ComponentSpec{Image: "nginx", StartAfterComponents: []string{"queue"}},
+		},
+	} {
+		component := component
+		suite.Nil(suite.Create(&component))
+	}
+
+	suite.DoTestRequest(&TestRequestContext{
+		Roles: []string{
+			GetViewerRoleOfNamespace(name),
+		},
+		Namespace: name,
+		Method:    http.MethodGet,
+		Path:      "/v1alpha1/applications/" + name,
+		TestWithRoles: func(rec *ResponseRecorder) {
+			var res resources.ApplicationDetails
+			rec.BodyAsJSON(&res)
+
+			suite.Equal(200, rec.Code)
+			suite.Equal([][]string{{"db"}, {"web"}}, res.StartupOrder)
+			suite.Empty(res.ComponentsInDependencyLoop)
+			suite.Equal([]string{"worker"}, res.ComponentsBlockedByMissingDependencies)
+		},
+	})
+}
+
 func TestApplicationsHandlerTestSuite(t *testing.T) {
 	suite.Run(t, new(ApplicationsHandlerTestSuite))
 }
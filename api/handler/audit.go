@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kalmhq/kalm/api/audit"
+	"github.com/kalmhq/kalm/api/client"
+	kalmErrors "github.com/kalmhq/kalm/api/errors"
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/labstack/echo/v4"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+func auditActionOfMethod(method string) string {
+	switch method {
+	case http.MethodPost:
+		return audit.ActionCreate
+	case http.MethodDelete:
+		return audit.ActionDelete
+	default:
+		return audit.ActionUpdate
+	}
+}
+
+// status of the response the error will be rendered into by the error handler
+func auditStatusOfError(err error) int {
+	if errWithCode, ok := err.(kalmErrors.ErrorWithCode); ok {
+		return errWithCode.StatusCode()
+	}
+
+	if statusError, ok := err.(*errors.StatusError); ok && statusError.Status().Code > 0 {
+		return int(statusError.Status().Code)
+	}
+
+	if _, ok := err.(v1alpha1.KalmValidateErrorList); ok {
+		return http.StatusBadRequest
+	}
+
+	if httpError, ok := err.(*echo.HTTPError); ok {
+		return httpError.Code
+	}
+
+	return http.StatusInternalServerError
+}
+
+func newAuditEntry(clientInfo *client.ClientInfo, action, namespace, resource string) *audit.Entry {
+	entry := &audit.Entry{
+		Time:      time.Now(),
+		Action:    action,
+		Namespace: namespace,
+		Resource:  resource,
+	}
+
+	setAuditEntrySubject(entry, clientInfo)
+
+	return entry
+}
+
+func setAuditEntrySubject(entry *audit.Entry, clientInfo *client.ClientInfo) {
+	entry.Subject = chooseFirstNonEmpty(clientInfo.Email, clientInfo.Name)
+	entry.Groups = clientInfo.Groups
+	entry.Impersonation = clientInfo.Impersonation
+	entry.ImpersonationType = clientInfo.ImpersonationType
+	entry.AccessTokenName = clientInfo.AccessTokenName
+}
+
+// recordAudit is deferred by the handlers of mutating calls to record the entry once the call is handled.
+// Permission check failures are panics, they are recorded and recovered in the outer middleware.
+func recordAudit(c echo.Context, entry *audit.Entry, err *error) {
+	if r := recover(); r != nil {
+		if er, ok := r.(error); ok {
+			entry.Status = auditStatusOfError(er)
+			entry.Error = er.Error()
+		} else {
+			entry.Status = http.StatusInternalServerError
+		}
+
+		audit.Record(entry)
+		panic(r)
+	}
+
+	if *err != nil {
+		entry.Status = auditStatusOfError(*err)
+		entry.Error = (*err).Error()
+	} else {
+		entry.Status = c.Response().Status
+	}
+
+	audit.Record(entry)
+}
+
+// AuditMiddleware records every mutating call, including the ones failed or denied by permission checks
+func (h *ApiHandler) AuditMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) (err error) {
+		req := c.Request()
+
+		if !isMutatingMethod(req.Method) {
+			return next(c)
+		}
+
+		var body []byte
+
+		if req.Body != nil {
+			body, _ = ioutil.ReadAll(req.Body)
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		entry := newAuditEntry(
+			getCurrentUser(c),
+			auditActionOfMethod(req.Method),
+			chooseFirstNonEmpty(c.Param("applicationName"), c.Param("namespace")),
+			strings.TrimPrefix(req.URL.Path, "/v1alpha1/"),
+		)
+		entry.Method = req.Method
+		entry.ClientIP = c.RealIP()
+
+		// a created resource doesn't exist yet, and the POSTs of actions, e.g. cordoning a node, have nothing to get
+		var existing []byte
+		if req.Method != http.MethodPost {
+			existing = getExistingResource(c)
+		}
+
+		entry.Changes = audit.Diff(existing, body)
+
+		defer recordAudit(c, entry, &err)
+
+		return next(c)
+	}
+}
+
+// getExistingResource returns the resource served by the GET of the same path with the same credentials,
+// e.g. the component of PUT /v1alpha1/applications/ns/components/web. Nil is returned if there isn't one.
+func getExistingResource(c echo.Context) []byte {
+	req := c.Request().Clone(c.Request().Context())
+	req.Method = http.MethodGet
+	req.Body = http.NoBody
+	req.ContentLength = 0
+
+	res := &bufferedResponseWriter{header: make(http.Header)}
+	c.Echo().ServeHTTP(res, req)
+
+	if res.status != http.StatusOK {
+		return nil
+	}
+
+	return res.body.Bytes()
+}
+
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedResponseWriter) Write(bs []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	return w.body.Write(bs)
+}
+
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+// handleListAuditEntries serves the entries retained in memory of the replica handling the request.
+// It's best-effort, entries are lost on restart and differ between replicas, the durable history is in the sinks.
+func (h *ApiHandler) handleListAuditEntries(c echo.Context) error {
+	h.MustCanViewCluster(getCurrentUser(c))
+
+	filter := &audit.Filter{
+		Subject:         c.QueryParam("subject"),
+		AccessTokenName: c.QueryParam("accessTokenName"),
+		Action:          c.QueryParam("action"),
+		Namespace:       c.QueryParam("namespace"),
+		Resource:        c.QueryParam("resource"),
+		Failed:          c.QueryParam("failed") == "true",
+	}
+
+	var err error
+
+	if since := c.QueryParam("since"); since != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			return kalmErrors.NewBadRequest("since should be in RFC3339 format")
+		}
+	}
+
+	if until := c.QueryParam("until"); until != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, until); err != nil {
+			return kalmErrors.NewBadRequest("until should be in RFC3339 format")
+		}
+	}
+
+	if limit := c.QueryParam("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit < 0 {
+			return kalmErrors.NewBadRequest("limit should be a non-negative integer")
+		}
+	}
+
+	return c.JSON(http.StatusOK, audit.DefaultAuditor().Query(filter))
+}
+
+// exec terminal sessions are recorded when they are requested and when they end,
+// along with the id of the session recording if the application records exec sessions
+func recordExecAudit(conn *WSConn, action string, m *WSPodResourceRequest, recordingID string, err error) {
+	entry := newAuditEntry(conn.clientInfo, action, m.Namespace, "pods/"+m.PodName)
+	entry.ClientIP = conn.clientIP
+
+	entry.Params = map[string]string{"container": m.Container}
+	if recordingID != "" {
+		entry.Params["recording"] = recordingID
+	}
+
+	entry.Status = http.StatusOK
+
+	if err != nil {
+		entry.Status = auditStatusOfError(err)
+		entry.Error = err.Error()
+	}
+
+	audit.Record(entry)
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kalmhq/kalm/api/audit"
+	"github.com/kalmhq/kalm/api/client"
+	"github.com/kalmhq/kalm/api/server"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditMiddleware(t *testing.T) {
+	audit.InitDefaultAuditor(10)
+
+	clientManager := client.NewFakeClientManager(nil, client.BuildClusterRolePolicies()+GrantUserRoles("viewer@kalm.dev", GetClusterViewerRole()))
+	h := &ApiHandler{clientManager: clientManager}
+
+	e := server.NewEchoInstance()
+	e.Use(PermissionPanicRecoverMiddleware)
+
+	g := e.Group("/v1alpha1", h.GetUserMiddleware, h.RequireUserMiddleware, h.AuditMiddleware)
+	g.POST("/registries", func(c echo.Context) error {
+		return c.JSON(http.StatusCreated, map[string]string{})
+	})
+	g.GET("/registries/:name", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"name": c.Param("name"), "username": "foo", "password": "bar"})
+	})
+	g.PUT("/registries/:name", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{})
+	})
+	g.DELETE("/applications/:name", func(c echo.Context) error {
+		h.MustCanManageCluster(getCurrentUser(c))
+		return c.NoContent(http.StatusOK)
+	})
+	g.GET("/audit", h.handleListAuditEntries)
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+client.ToFakeToken("viewer@kalm.dev"))
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request(http.MethodPost, "/v1alpha1/registries", `{"name":"reg","username":"foo","password":"bar"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	rec = request(http.MethodPut, "/v1alpha1/registries/reg", `{"name":"reg","username":"bar","password":"baz"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = request(http.MethodDelete, "/v1alpha1/applications/ns1", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// reads are not recorded
+	rec = request(http.MethodGet, "/v1alpha1/audit", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var entries []audit.Entry
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &entries))
+	assert.Len(t, entries, 3)
+
+	assert.Equal(t, "viewer@kalm.dev", entries[0].Subject)
+	assert.Equal(t, audit.ActionDelete, entries[0].Action)
+	assert.Equal(t, "applications/ns1", entries[0].Resource)
+	assert.Equal(t, http.StatusUnauthorized, entries[0].Status)
+	assert.NotEmpty(t, entries[0].Error)
+
+	// the changes against the resource of the GET of the same path
+	assert.Equal(t, audit.ActionUpdate, entries[1].Action)
+	assert.Equal(t, []audit.Change{
+		{Path: "/password", Old: "******", New: "******"},
+		{Path: "/username", Old: "foo", New: "bar"},
+	}, entries[1].Changes)
+
+	assert.Equal(t, audit.ActionCreate, entries[2].Action)
+	assert.Equal(t, "registries", entries[2].Resource)
+	assert.Equal(t, http.StatusCreated, entries[2].Status)
+	assert.Equal(t, []audit.Change{
+		{Path: "/name", New: "reg"},
+		{Path: "/password", New: "******"},
+		{Path: "/username", New: "foo"},
+	}, entries[2].Changes)
+
+	rec = request(http.MethodGet, "/v1alpha1/audit?failed=true", "")
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &entries))
+	assert.Len(t, entries, 1)
+
+	rec = request(http.MethodGet, "/v1alpha1/audit?since=yesterday", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/kalmhq/kalm/api/resources"
+	"github.com/labstack/echo/v4"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+func (h *ApiHandler) handleListComponentRevisions(c echo.Context) error {
+	currentUser := getCurrentUser(c)
+	h.MustCanView(currentUser, c.Param("applicationName"), "components/"+c.Param("name"))
+
+	component, err := h.resourceManager.GetComponent(c.Param("applicationName"), c.Param("name"))
+
+	if err != nil {
+		return err
+	}
+
+	revisions, err := h.resourceManager.GetComponentRevisions(component.Namespace, component.Name)
+
+	if err != nil {
+		return err
+	}
+
+	res := make([]*resources.ComponentRevision, 0, len(revisions))
+
+	for i := range revisions {
+		revision, err := resources.BuildComponentRevisionResponse(&revisions[i], component.Status.CurrentRevision)
+
+		if err != nil {
+			return err
+		}
+
+		res = append(res, revision)
+	}
+
+	return c.JSON(200, res)
+}
+
+func (h *ApiHandler) handleDiffComponentRevisions(c echo.Context) error {
+	currentUser := getCurrentUser(c)
+	h.MustCanView(currentUser, c.Param("applicationName"), "components/"+c.Param("name"))
+
+	from, err := h.getComponentRevisionFromParam(c, c.QueryParam("from"))
+
+	if err != nil {
+		return err
+	}
+
+	to, err := h.getComponentRevisionFromParam(c, c.QueryParam("to"))
+
+	if err != nil {
+		return err
+	}
+
+	changes, err := resources.DiffComponentSpecs(from.Spec, to.Spec)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(200, changes)
+}
+
+// The spec of the revision is applied as a normal update, so it's checked by the admission webhook again.
+// Protected endpoint and plugins are not a part of the spec, they are not rolled back.
+func (h *ApiHandler) handleRollbackComponent(c echo.Context) error {
+	currentUser := getCurrentUser(c)
+	h.MustCanEdit(currentUser, c.Param("applicationName"), "components/"+c.Param("name"))
+
+	revision, err := h.getComponentRevisionFromParam(c, c.Param("revision"))
+
+	if err != nil {
+		return err
+	}
+
+	component, err := h.resourceManager.GetComponent(c.Param("applicationName"), c.Param("name"))
+
+	if err != nil {
+		return err
+	}
+
+	copied := component.DeepCopy()
+	copied.Spec = *revision.Spec
+	setComponentChangedBy(copied, currentUser, fmt.Sprintf("rollback to revision %d", revision.Revision))
+
+	if err := h.resourceManager.ApplyComponent(copied); err != nil {
+		return err
+	}
+
+	component, err = h.resourceManager.GetComponent(c.Param("applicationName"), c.Param("name"))
+
+	if err != nil {
+		return err
+	}
+
+	res, err := h.componentResponse(component)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(200, res)
+}
+
+func (h *ApiHandler) getComponentRevisionFromParam(c echo.Context, param string) (*resources.ComponentRevision, error) {
+	number, err := strconv.ParseInt(param, 10, 64)
+
+	if err != nil {
+		return nil, errors.NewBadRequest(fmt.Sprintf("invalid revision: %s", param))
+	}
+
+	revision, err := h.resourceManager.GetComponentRevision(c.Param("applicationName"), c.Param("name"), number)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resources.BuildComponentRevisionResponse(revision, 0)
+}
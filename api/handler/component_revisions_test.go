@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/kalmhq/kalm/api/resources"
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/kalmhq/kalm/controller/controllers"
+	"github.com/stretchr/testify/suite"
+	appsV1 "k8s.io/api/apps/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type ComponentRevisionsHandlerTestSuite struct {
+	WithControllerTestSuite
+
+	namespace string
+}
+
+func (suite *ComponentRevisionsHandlerTestSuite) SetupSuite() {
+	suite.WithControllerTestSuite.SetupSuite()
+	suite.namespace = "kalm-component-revisions-test"
+	suite.ensureNamespaceExist(suite.namespace)
+}
+
+func (suite *ComponentRevisionsHandlerTestSuite) createRevision(componentName string, number int64, spec v1alpha1.ComponentSpec, changedBy string) {
+	data, err := json.Marshal(spec)
+	suite.Nil(err)
+
+	suite.Nil(suite.Create(&appsV1.ControllerRevision{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:        controllers.GetComponentRevisionName(componentName, number),
+			Namespace:   suite.namespace,
+			Labels:      map[string]string{controllers.KalmLabelComponentRevisionKey: componentName},
+			Annotations: map[string]string{v1alpha1.AnnoChangedBy: changedBy},
+		},
+		Data:     runtime.RawExtension{Raw: data},
+		Revision: number,
+	}))
+}
+
+func (suite *ComponentRevisionsHandlerTestSuite) TestComponentRevisionsHandler() {
+	oldSpec := v1alpha1.ComponentSpec{
+		Image: "nginx:1.18",
+		Env:   []v1alpha1.EnvVar{{Name: "FOO", Value: "foo"}},
+	}
+
+	newSpec := v1alpha1.ComponentSpec{
+		Image: "nginx:1.19",
+		Env:   []v1alpha1.EnvVar{{Name: "FOO", Value: "bar"}},
+	}
+
+	component := &v1alpha1.Component{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      "web",
+			Namespace: suite.namespace,
+		},
+		Spec: newSpec,
+	}
+	suite.Nil(suite.Create(component))
+	component.Status.CurrentRevision = 2
+	suite.Nil(suite.client.Status().Update(suite.ctx, component))
+
+	suite.createRevision("web", 1, oldSpec, "foo@bar.com")
+	suite.createRevision("web", 2, newSpec, "bar@bar.com")
+
+	// list, the latest first
+	suite.DoTestRequest(&TestRequestContext{
+		Roles: []string{
+			GetViewerRoleOfNamespace(suite.namespace),
+		},
+		Namespace: suite.namespace,
+		Method:    http.MethodGet,
+		Path:      "/v1alpha1/applications/" + suite.namespace + "/components/web/revisions",
+		TestWithoutRoles: func(rec *ResponseRecorder) {
+			suite.IsUnauthorizedError(rec, "view")
+		},
+		TestWithRoles: func(rec *ResponseRecorder) {
+			var res []resources.ComponentRevision
+			rec.BodyAsJSON(&res)
+
+			suite.Equal(200, rec.Code)
+			suite.Len(res, 2)
+			suite.Equal(int64(2), res[0].Revision)
+			suite.True(res[0].Current)
+			suite.Equal("nginx:1.18", res[1].Image)
+			suite.Equal("foo@bar.com", res[1].ChangedBy)
+			suite.False(res[1].Current)
+		},
+	})
+
+	// diff
+	suite.DoTestRequest(&TestRequestContext{
+		Roles: []string{
+			GetViewerRoleOfNamespace(suite.namespace),
+		},
+		Namespace: suite.namespace,
+		Method:    http.MethodGet,
+		Path:      "/v1alpha1/applications/" + suite.namespace + "/components/web/revisions/diff?from=1&to=2",
+		TestWithoutRoles: func(rec *ResponseRecorder) {
+			suite.IsUnauthorizedError(rec, "view")
+		},
+		TestWithRoles: func(rec *ResponseRecorder) {
+			var res []resources.ComponentRevisionChange
+			rec.BodyAsJSON(&res)
+
+			suite.Equal(200, rec.Code)
+			suite.Equal([]resources.ComponentRevisionChange{
+				{Path: ".env[0].value", From: "foo", To: "bar"},
+				{Path: ".image", From: "nginx:1.18", To: "nginx:1.19"},
+			}, res)
+		},
+	})
+
+	// rollback
+	suite.DoTestRequest(&TestRequestContext{
+		Roles: []string{
+			GetEditorRoleOfNamespace(suite.namespace),
+		},
+		Namespace: suite.namespace,
+		Method:    http.MethodPost,
+		Path:      "/v1alpha1/applications/" + suite.namespace + "/components/web/revisions/1/rollback",
+		TestWithoutRoles: func(rec *ResponseRecorder) {
+			suite.IsUnauthorizedError(rec, "edit")
+		},
+		TestWithRoles: func(rec *ResponseRecorder) {
+			suite.Equal(200, rec.Code)
+		},
+	})
+
+	comp, err := suite.getComponent(suite.namespace, "web")
+	suite.Nil(err)
+	suite.Equal("nginx:1.18", comp.Spec.Image)
+	suite.Equal("foo", comp.Spec.Env[0].Value)
+	suite.Equal("rollback to revision 1", comp.Annotations[v1alpha1.AnnoChangeCause])
+	suite.NotEmpty(comp.Annotations[v1alpha1.AnnoChangedBy])
+
+	// revisions of other components can't be used
+	rec := suite.NewRequestWithIdentity(
+		http.MethodPost,
+		"/v1alpha1/applications/"+suite.namespace+"/components/api/revisions/1/rollback",
+		nil,
+		"foo@bar.com",
+		GetEditorRoleOfNamespace(suite.namespace),
+	)
+	suite.Equal(404, rec.Code)
+}
+
+func TestComponentRevisionsHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(ComponentRevisionsHandlerTestSuite))
+}
@@ -19,6 +19,9 @@ func (h *ApiHandler) InstallComponentsHandlers(e *echo.Group) {
 	e.DELETE("/applications/:applicationName/components/:name", h.handleDeleteComponent)
 	e.POST("/applications/:applicationName/components", h.handleCreateComponent)
 	e.POST("/applications/:applicationName/components/:name/jobs", h.handleTriggerJob)
+	e.GET("/applications/:applicationName/components/:name/revisions", h.handleListComponentRevisions)
+	e.GET("/applications/:applicationName/components/:name/revisions/diff", h.handleDiffComponentRevisions)
+	e.POST("/applications/:applicationName/components/:name/revisions/:revision/rollback", h.handleRollbackComponent)
 }
 
 func (h *ApiHandler) handleListComponents(c echo.Context) error {
@@ -83,6 +86,7 @@ func (h *ApiHandler) handleCreateComponent(c echo.Context) error {
 	}
 
 	crdComponent := getCrdComponent(component)
+	setComponentChangedBy(crdComponent, currentUser, "")
 
 	// permission, check if component try to re-use disk from other ns
 	if err := h.checkPermissionOnVolume(currentUser, crdComponent.Spec.Volumes); err != nil {
@@ -128,8 +132,9 @@ func (h *ApiHandler) handleUpdateComponent(c echo.Context) error {
 	h.MustCanEdit(currentUser, c.Param("applicationName"), "components/"+component.Name)
 
 	crdComponent := getCrdComponent(component)
+	setComponentChangedBy(crdComponent, currentUser, "")
 
-	if err := h.resourceManager.Apply(crdComponent); err != nil {
+	if err := h.resourceManager.ApplyComponent(crdComponent); err != nil {
 		return err
 	}
 
@@ -241,6 +246,24 @@ func getCrdComponent(component *resources.Component) *v1alpha1.Component {
 	return crdComponent
 }
 
+// setComponentChangedBy records who changes the spec of the component, the webhook keeps it by the generation of the change
+// and the controller saves it in the revision of that generation.
+func setComponentChangedBy(component *v1alpha1.Component, clientInfo *client2.ClientInfo, cause string) {
+	if component.Annotations == nil {
+		component.Annotations = make(map[string]string)
+	}
+
+	if clientInfo.AccessTokenName != "" {
+		component.Annotations[v1alpha1.AnnoChangedBy] = clientInfo.AccessTokenCreator
+		component.Annotations[v1alpha1.AnnoChangedByAccessToken] = clientInfo.AccessTokenName
+	} else {
+		component.Annotations[v1alpha1.AnnoChangedBy] = clientInfo.Email
+		component.Annotations[v1alpha1.AnnoChangedByAccessToken] = ""
+	}
+
+	component.Annotations[v1alpha1.AnnoChangeCause] = cause
+}
+
 func bindResourcesComponentFromRequestBody(c echo.Context) (*resources.Component, error) {
 	var component resources.Component
 
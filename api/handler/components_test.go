@@ -153,6 +153,38 @@ func (suite *ComponentTestSuite) TestBasicRequestOfComponents() {
 	})
 }
 
+func (suite *ComponentTestSuite) TestUpdateComponentRemovesEntries() {
+	path := fmt.Sprintf("/v1alpha1/applications/%s/components", suite.namespace)
+
+	rec := suite.NewRequestWithIdentity(http.MethodPost, path, resources.Component{
+		Name: "remove-entries",
+		ComponentSpec: &v1alpha1.ComponentSpec{
+			Image: "foo",
+			Env: []v1alpha1.EnvVar{
+				{Name: "A", Value: "a"},
+				{Name: "B", Value: "b"},
+			},
+		},
+	}, "foo@bar.com", GetEditorRoleOfNamespace(suite.namespace))
+	suite.Equal(201, rec.Code)
+
+	rec = suite.NewRequestWithIdentity(http.MethodPut, path+"/remove-entries", resources.Component{
+		Name: "remove-entries",
+		ComponentSpec: &v1alpha1.ComponentSpec{
+			Image: "foo",
+			Env: []v1alpha1.EnvVar{
+				{Name: "A", Value: "a"},
+			},
+		},
+	}, "foo@bar.com", GetEditorRoleOfNamespace(suite.namespace))
+	suite.Equal(200, rec.Code)
+
+	var res resources.Component
+	rec.BodyAsJSON(&res)
+	suite.Len(res.Env, 1)
+	suite.Equal("A", res.Env[0].Name)
+}
+
 func (suite *ComponentTestSuite) TestCreateComponentWithPVCAsVolume() {
 	sc := "kalm-standard"
 	reqComp := resources.Component{
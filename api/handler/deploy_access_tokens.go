@@ -6,13 +6,13 @@ import (
 	"github.com/labstack/echo/v4"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/util/rand"
 )
 
 func (h *ApiHandler) InstallDeployAccessTokenHandlers(e *echo.Group) {
 	e.GET("/deploy_access_tokens", h.handleListDeployAccessTokens)
 	e.POST("/deploy_access_tokens", h.handleCreateDeployAccessToken)
 	e.DELETE("/deploy_access_tokens", h.handleDeleteAccessToken)
+	e.POST("/deploy_access_tokens/:name/rotate", h.handleRotateAccessToken)
 }
 
 func (h *ApiHandler) handleListDeployAccessTokens(c echo.Context) error {
@@ -57,17 +57,22 @@ func (h *ApiHandler) handleCreateDeployAccessToken(c echo.Context) error {
 	}
 
 	// Set sensitive fields
-	accessToken.Token = rand.String(128)
+	token, name := v1alpha1.GenerateAccessToken()
 	if accessToken.Creator == "" {
 		accessToken.Creator = firstNotEmptyStr(currentUser.Name, currentUser.Email)
 	}
-	accessToken.Name = v1alpha1.GetAccessTokenNameFromToken(accessToken.Token)
+	accessToken.Name = name
+	accessToken.RotatedFrom = ""
+	accessToken.SetToken(token)
 
 	accessToken, err = h.resourceManager.CreateDeployAccessToken(accessToken)
 	if err != nil {
 		return err
 	}
 
+	// the token is only responded once, only the hash is saved
+	accessToken.Token = token
+
 	return c.JSON(201, accessToken)
 }
 
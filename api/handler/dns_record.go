@@ -19,12 +19,19 @@ func (h *ApiHandler) InstallDNSRecordHandlers(e *echo.Group) {
 }
 
 type DNSRecord struct {
-	Domain    string `json:"domain"`
-	DNSType   string `json:"dnsType"`
-	DNSTarget string `json:"dnsTarget"`
+	Domain     string   `json:"domain"`
+	DNSType    string   `json:"dnsType"`
+	DNSTarget  string   `json:"dnsTarget"`
+	DNSTargets []string `json:"dnsTargets,omitempty"`
+	TTL        int      `json:"ttl,omitempty"`
+	Priority   int      `json:"priority,omitempty"`
+	Weight     int      `json:"weight,omitempty"`
+	Port       int      `json:"port,omitempty"`
 	//resp
-	Name         string `json:"name"`
-	IsConfigured bool   `json:"isConfigured"`
+	Name                string       `json:"name"`
+	IsConfigured        bool         `json:"isConfigured"`
+	ActualTargets       []string     `json:"actualTargets,omitempty"`
+	LastDriftDetectedAt *metav1.Time `json:"lastDriftDetectedAt,omitempty"`
 }
 
 func (h *ApiHandler) handleListDNSRecords(c echo.Context) error {
@@ -73,7 +80,7 @@ func (h *ApiHandler) handleCreateDNSRecord(c echo.Context) error {
 	return c.JSON(201, wrapDNSRecordAsResp(record))
 }
 
-// can only update DNSTarget
+// can only update targets, ttl, priority, weight and port, the domain and type identify the record
 func (h *ApiHandler) handleUpdateDNSRecord(c echo.Context) error {
 	curUser := getCurrentUser(c)
 
@@ -84,14 +91,18 @@ func (h *ApiHandler) handleUpdateDNSRecord(c echo.Context) error {
 		return err
 	}
 
-	name := c.Param("name")
 	dnsRecord := v1alpha1.DNSRecord{}
 
-	if err := h.resourceManager.Get("", name, &dnsRecord); err != nil {
+	if err := h.resourceManager.Get("", res.Name, &dnsRecord); err != nil {
 		return err
 	}
 
 	dnsRecord.Spec.DNSTarget = res.Spec.DNSTarget
+	dnsRecord.Spec.DNSTargets = res.Spec.DNSTargets
+	dnsRecord.Spec.TTL = res.Spec.TTL
+	dnsRecord.Spec.Priority = res.Spec.Priority
+	dnsRecord.Spec.Weight = res.Spec.Weight
+	dnsRecord.Spec.Port = res.Spec.Port
 	if err := h.resourceManager.Update(&dnsRecord); err != nil {
 		return err
 	}
@@ -124,9 +135,14 @@ func getDNSRecordFromContext(ctx echo.Context) (*v1alpha1.DNSRecord, error) {
 	return &v1alpha1.DNSRecord{
 		ObjectMeta: metav1.ObjectMeta{Name: name},
 		Spec: v1alpha1.DNSRecordSpec{
-			Domain:    record.Domain,
-			DNSType:   v1alpha1.DNSType(record.DNSType),
-			DNSTarget: record.DNSTarget,
+			Domain:     record.Domain,
+			DNSType:    v1alpha1.DNSType(record.DNSType),
+			DNSTarget:  record.DNSTarget,
+			DNSTargets: record.DNSTargets,
+			TTL:        record.TTL,
+			Priority:   record.Priority,
+			Weight:     record.Weight,
+			Port:       record.Port,
 		},
 	}, nil
 }
@@ -143,11 +159,18 @@ func getNameOfDNSRecord(r DNSRecord) string {
 
 func wrapDNSRecordAsResp(record *v1alpha1.DNSRecord) DNSRecord {
 	return DNSRecord{
-		Name:         record.Name,
-		Domain:       record.Spec.Domain,
-		DNSType:      string(record.Spec.DNSType),
-		DNSTarget:    record.Spec.DNSTarget,
-		IsConfigured: record.Status.IsConfigured,
+		Name:                record.Name,
+		Domain:              record.Spec.Domain,
+		DNSType:             string(record.Spec.DNSType),
+		DNSTarget:           record.Spec.DNSTarget,
+		DNSTargets:          record.Spec.DNSTargets,
+		TTL:                 record.Spec.TTL,
+		Priority:            record.Spec.Priority,
+		Weight:              record.Spec.Weight,
+		Port:                record.Spec.Port,
+		IsConfigured:        record.Status.IsConfigured,
+		ActualTargets:       record.Status.ActualTargets,
+		LastDriftDetectedAt: record.Status.LastDriftDetectedAt,
 	}
 }
 
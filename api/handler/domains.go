@@ -37,6 +37,8 @@ func (h *ApiHandler) handleListDomains(c echo.Context) error {
 				Domain:     "*." + baseAppDomain,
 				RecordType: "CNAME",
 				Target:     "",
+				// the base app domain is managed by kalm
+				VerificationStatus: v1alpha1.DomainVerificationVerified,
 			},
 		}, domains...)
 	}
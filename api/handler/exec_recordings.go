@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/kalmhq/kalm/api/errors"
+	"github.com/kalmhq/kalm/api/recording"
+	"github.com/kalmhq/kalm/api/resources"
+	"github.com/labstack/echo/v4"
+)
+
+func (h *ApiHandler) handleListExecRecordings(c echo.Context) error {
+	h.MustCanManageCluster(getCurrentUser(c))
+	store := recording.DefaultStore()
+
+	if store == nil {
+		return c.JSON(http.StatusOK, []*recording.Recording{})
+	}
+
+	recordings, err := store.List(c.QueryParam("namespace"))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, recordings)
+}
+
+func (h *ApiHandler) handleDownloadExecRecording(c echo.Context) error {
+	h.MustCanManageCluster(getCurrentUser(c))
+	store := recording.DefaultStore()
+
+	if store == nil {
+		return errors.NewNotFound("exec session recording is not enabled")
+	}
+
+	rec, err := store.Get(c.Param("id"))
+
+	if err == recording.ErrNotFound {
+		return errors.NewNotFound(err.Error())
+	} else if err != nil {
+		return err
+	}
+
+	content, err := store.Open(rec.ID)
+
+	if err == recording.ErrNotFound {
+		return errors.NewNotFound(err.Error())
+	} else if err != nil {
+		return err
+	}
+
+	defer content.Close()
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", rec.ID+".cast"))
+
+	return c.Stream(http.StatusOK, "application/x-asciicast", content)
+}
+
+// newExecSessionRecorder returns a recorder if the application of the pod records exec sessions,
+// the session is refused if the application requires recording but there is no store to save it.
+func (h *ApiHandler) newExecSessionRecorder(conn *WSConn, m *WSPodResourceRequest) (*recording.Recorder, string, error) {
+	namespace, err := h.resourceManager.GetNamespace(m.Namespace)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !resources.BuildApplicationFromNamespace(namespace).RecordExecSessions {
+		return nil, "", nil
+	}
+
+	store := recording.DefaultStore()
+
+	if store == nil {
+		return nil, "", fmt.Errorf("application %s requires exec sessions to be recorded, but recording is not enabled", m.Namespace)
+	}
+
+	now := time.Now()
+	rec := &recording.Recording{
+		ID:        recording.NewRecordingID(now),
+		Namespace: m.Namespace,
+		PodName:   m.PodName,
+		Container: m.Container,
+		Subject:   chooseFirstNonEmpty(conn.clientInfo.Email, conn.clientInfo.Name),
+		StartTime: now,
+	}
+
+	var w io.WriteCloser
+
+	if w, err = store.Create(rec); err != nil {
+		return nil, "", err
+	}
+
+	return recording.NewRecorder(w, m.Namespace+"/"+m.PodName, now), rec.ID, nil
+}
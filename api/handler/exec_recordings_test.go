@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kalmhq/kalm/api/client"
+	"github.com/kalmhq/kalm/api/recording"
+	"github.com/kalmhq/kalm/api/server"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecRecordings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recordings")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := recording.NewDirStore(dir)
+	assert.Nil(t, err)
+	recording.InitDefaultStore(store)
+	defer recording.InitDefaultStore(nil)
+
+	now := time.Now()
+	rec := &recording.Recording{
+		ID:        recording.NewRecordingID(now),
+		Namespace: "ns1",
+		PodName:   "web-0",
+		Subject:   "foo@bar.com",
+		StartTime: now,
+	}
+
+	w, err := store.Create(rec)
+	assert.Nil(t, err)
+	recorder := recording.NewRecorder(w, "ns1/web-0", now)
+	recorder.Output([]byte("$ ls\r\n"))
+	assert.Nil(t, recorder.Close())
+
+	clientManager := client.NewFakeClientManager(nil, client.BuildClusterRolePolicies()+
+		GrantUserRoles("owner@kalm.dev", GetClusterOwnerRole())+
+		GrantUserRoles("viewer@kalm.dev", GetClusterViewerRole()))
+	h := &ApiHandler{clientManager: clientManager}
+
+	e := server.NewEchoInstance()
+	e.Use(PermissionPanicRecoverMiddleware)
+
+	g := e.Group("/v1alpha1", h.GetUserMiddleware, h.RequireUserMiddleware)
+	g.GET("/execrecordings", h.handleListExecRecordings)
+	g.GET("/execrecordings/:id", h.handleDownloadExecRecording)
+
+	request := func(email, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+client.ToFakeToken(email))
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	res := request("viewer@kalm.dev", "/v1alpha1/execrecordings")
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+
+	res = request("owner@kalm.dev", "/v1alpha1/execrecordings?namespace=ns1")
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	var recordings []*recording.Recording
+	assert.Nil(t, json.Unmarshal(res.Body.Bytes(), &recordings))
+	assert.Len(t, recordings, 1)
+	assert.Equal(t, rec.ID, recordings[0].ID)
+	assert.NotNil(t, recordings[0].EndTime)
+
+	res = request("owner@kalm.dev", "/v1alpha1/execrecordings?namespace=ns2")
+	assert.Nil(t, json.Unmarshal(res.Body.Bytes(), &recordings))
+	assert.Len(t, recordings, 0)
+
+	res = request("owner@kalm.dev", "/v1alpha1/execrecordings/"+rec.ID)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "application/x-asciicast", res.Header().Get(echo.HeaderContentType))
+	assert.Contains(t, res.Header().Get(echo.HeaderContentDisposition), rec.ID+".cast")
+	assert.Contains(t, res.Body.String(), `"$ ls\r\n"`)
+
+	res = request("owner@kalm.dev", "/v1alpha1/execrecordings/not-exist")
+	assert.Equal(t, http.StatusNotFound, res.Code)
+}
@@ -45,7 +45,7 @@ func (h *ApiHandler) InstallMainRoutes(e *echo.Echo) {
 	gv1Alpha1.GET("/logs", h.logWebsocketHandler)
 	gv1Alpha1.GET("/exec", h.execWebsocketHandler)
 
-	var gv1Alpha1WithAuth = gv1Alpha1.Group("", h.GetUserMiddleware, h.RequireUserMiddleware)
+	var gv1Alpha1WithAuth = gv1Alpha1.Group("", h.GetUserMiddleware, h.RequireUserMiddleware, h.AuditMiddleware)
 
 	// initialize the cluster
 	gv1Alpha1WithAuth.POST("/initialize", h.handleInitializeCluster)
@@ -61,6 +61,7 @@ func (h *ApiHandler) InstallMainRoutes(e *echo.Echo) {
 
 	h.InstallApplicationsHandlers(gv1Alpha1WithAuth)
 	h.InstallComponentsHandlers(gv1Alpha1WithAuth)
+	h.InstallSecretsHandlers(gv1Alpha1WithAuth)
 	h.InstallRegistriesHandlers(gv1Alpha1WithAuth)
 	h.InstallDomainHandlers(gv1Alpha1WithAuth)
 
@@ -74,6 +75,11 @@ func (h *ApiHandler) InstallMainRoutes(e *echo.Echo) {
 	gv1Alpha1WithAuth.PUT("/rolebindings", h.handleUpdateRoleBinding)
 	gv1Alpha1WithAuth.DELETE("/rolebindings/:namespace/:name", h.handleDeleteRoleBinding)
 
+	gv1Alpha1WithAuth.GET("/roles", h.handleListRoles)
+	gv1Alpha1WithAuth.POST("/roles", h.handleCreateRole)
+	gv1Alpha1WithAuth.PUT("/roles", h.handleUpdateRole)
+	gv1Alpha1WithAuth.DELETE("/roles/:name", h.handleDeleteRole)
+
 	gv1Alpha1WithAuth.GET("/serviceaccounts/:name", h.handleGetServiceAccount)
 
 	gv1Alpha1WithAuth.GET("/nodes", h.handleListNodes)
@@ -81,6 +87,7 @@ func (h *ApiHandler) InstallMainRoutes(e *echo.Echo) {
 	gv1Alpha1WithAuth.POST("/nodes/:name/uncordon", h.handleUncordonNode)
 
 	h.InstallHttpRouteHandlers(gv1Alpha1WithAuth)
+	h.InstallTcpRouteHandlers(gv1Alpha1WithAuth)
 	h.InstallHttpCertIssuerHandlers(gv1Alpha1WithAuth)
 	h.InstallHttpsCertsHandlers(gv1Alpha1WithAuth)
 
@@ -104,6 +111,11 @@ func (h *ApiHandler) InstallMainRoutes(e *echo.Echo) {
 	h.InstallACMEServerHandlers(gv1Alpha1WithAuth)
 
 	gv1Alpha1WithAuth.GET("/settings", h.handleListSettings)
+
+	gv1Alpha1WithAuth.GET("/audit", h.handleListAuditEntries)
+
+	gv1Alpha1WithAuth.GET("/execrecordings", h.handleListExecRecordings)
+	gv1Alpha1WithAuth.GET("/execrecordings/:id", h.handleDownloadExecRecording)
 }
 
 func NewApiHandler(clientManager client.ClientManager) *ApiHandler {
@@ -11,6 +11,7 @@ import (
 
 func (h *ApiHandler) InstallHttpsCertsHandlers(e *echo.Group) {
 	e.GET("/httpscerts", h.handleListHttpsCerts)
+	e.GET("/httpscerts/expiring", h.handleListExpiringHttpsCerts)
 	e.GET("/httpscerts/:name", h.handleGetHttpsCert)
 	e.POST("/httpscerts", h.handleCreateHttpsCert)
 	e.POST("/httpscerts/upload", h.handleUploadHttpsCert)
@@ -34,6 +35,18 @@ func (h *ApiHandler) handleListHttpsCerts(c echo.Context) error {
 	return c.JSON(200, httpsCerts)
 }
 
+func (h *ApiHandler) handleListExpiringHttpsCerts(c echo.Context) error {
+	h.MustCanViewCluster(getCurrentUser(c))
+
+	httpsCerts, err := h.resourceManager.GetExpiringHttpsCerts()
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(200, httpsCerts)
+}
+
 func (h *ApiHandler) handleGetHttpsCert(c echo.Context) error {
 	// TODO: certs are required to support http route
 	// h.MustCanManageCluster(getCurrentUser(c))
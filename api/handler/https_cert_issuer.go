@@ -64,6 +64,15 @@ func (h *ApiHandler) handleCreateHttpsCertIssuer(c echo.Context) (err error) {
 		}
 	}
 
+	if httpsCertIssuer.ACME != nil {
+		acme, err := h.resourceManager.BuildACMEIssuerSpec(httpsCertIssuer)
+		if err != nil {
+			return err
+		}
+
+		resource.Spec.ACME = acme
+	}
+
 	err = h.resourceManager.Create(&resource)
 	if err != nil {
 		return err
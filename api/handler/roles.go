@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/kalmhq/kalm/api/resources"
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/labstack/echo/v4"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func (h *ApiHandler) handleListRoles(c echo.Context) error {
+	h.MustCanManageCluster(getCurrentUser(c))
+	var roleList v1alpha1.RoleList
+
+	if err := h.resourceManager.List(&roleList); err != nil {
+		return err
+	}
+
+	roles := roleList.Items
+
+	res := make([]*resources.Role, 0, len(roles))
+
+	for i := range roles {
+		res = append(res, &resources.Role{
+			Name:     roles[i].Name,
+			RoleSpec: &roles[i].Spec,
+		})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *ApiHandler) handleCreateRole(c echo.Context) error {
+	h.MustCanManageCluster(getCurrentUser(c))
+	role, err := getRoleFromContext(c)
+
+	if err != nil {
+		return err
+	}
+
+	if err := h.resourceManager.Create(role); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, role)
+}
+
+func (h *ApiHandler) handleUpdateRole(c echo.Context) error {
+	h.MustCanManageCluster(getCurrentUser(c))
+	role, err := getRoleFromContext(c)
+
+	if err != nil {
+		return err
+	}
+
+	var fetched v1alpha1.Role
+	if err := h.resourceManager.Get("", role.Name, &fetched); err != nil {
+		return err
+	}
+
+	copied := fetched.DeepCopy()
+	copied.Spec = role.Spec
+
+	if err := h.resourceManager.Patch(copied, client.MergeFrom(&fetched)); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, copied)
+}
+
+func (h *ApiHandler) handleDeleteRole(c echo.Context) error {
+	h.MustCanManageCluster(getCurrentUser(c))
+	var fetched v1alpha1.Role
+
+	if err := h.resourceManager.Get("", c.Param("name"), &fetched); err != nil {
+		return err
+	}
+
+	if err := h.resourceManager.Delete(&fetched); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func getRoleFromContext(c echo.Context) (*v1alpha1.Role, error) {
+	var role resources.Role
+
+	if err := c.Bind(&role); err != nil {
+		return nil, err
+	}
+
+	if role.RoleSpec == nil {
+		role.RoleSpec = &v1alpha1.RoleSpec{}
+	}
+
+	return &v1alpha1.Role{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name: role.Name,
+		},
+		Spec: *role.RoleSpec,
+	}, nil
+}
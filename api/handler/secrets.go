@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/kalmhq/kalm/api/resources"
+	"github.com/labstack/echo/v4"
+)
+
+func (h *ApiHandler) InstallSecretsHandlers(e *echo.Group) {
+	e.GET("/applications/:applicationName/secrets", h.handleListSecrets)
+	e.POST("/applications/:applicationName/secrets", h.handleCreateSecret)
+	e.PUT("/applications/:applicationName/secrets/:name", h.handleUpdateSecret)
+	e.DELETE("/applications/:applicationName/secrets/:name", h.handleDeleteSecret)
+}
+
+// handlers
+
+func (h *ApiHandler) handleListSecrets(c echo.Context) error {
+	currentUser := getCurrentUser(c)
+	h.MustCanEdit(currentUser, c.Param("applicationName"), "secrets/*")
+
+	list, err := h.resourceManager.GetApplicationSecrets(c.Param("applicationName"))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(200, list)
+}
+
+func (h *ApiHandler) handleCreateSecret(c echo.Context) (err error) {
+	currentUser := getCurrentUser(c)
+	h.MustCanEdit(currentUser, c.Param("applicationName"), "secrets/*")
+
+	var secret *resources.ApplicationSecret
+	if secret, err = bindApplicationSecretFromRequestBody(c); err != nil {
+		return err
+	}
+
+	if secret, err = h.resourceManager.CreateApplicationSecret(c.Param("applicationName"), secret); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, secret)
+}
+
+func (h *ApiHandler) handleUpdateSecret(c echo.Context) (err error) {
+	currentUser := getCurrentUser(c)
+	h.MustCanEdit(currentUser, c.Param("applicationName"), "secrets/"+c.Param("name"))
+
+	var secret *resources.ApplicationSecret
+	if secret, err = bindApplicationSecretFromRequestBody(c); err != nil {
+		return err
+	}
+
+	if secret.Name != c.Param("name") {
+		return fmt.Errorf("Name in body and url are mismatched")
+	}
+
+	if secret, err = h.resourceManager.UpdateApplicationSecret(c.Param("applicationName"), secret); err != nil {
+		return err
+	}
+
+	return c.JSON(200, secret)
+}
+
+func (h *ApiHandler) handleDeleteSecret(c echo.Context) error {
+	currentUser := getCurrentUser(c)
+	h.MustCanEdit(currentUser, c.Param("applicationName"), "secrets/"+c.Param("name"))
+
+	if err := h.resourceManager.DeleteApplicationSecret(c.Param("applicationName"), c.Param("name")); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func bindApplicationSecretFromRequestBody(c echo.Context) (*resources.ApplicationSecret, error) {
+	var secret resources.ApplicationSecret
+
+	if err := c.Bind(&secret); err != nil {
+		return nil, err
+	}
+
+	return &secret, nil
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kalmhq/kalm/api/resources"
+	"github.com/stretchr/testify/suite"
+	coreV1 "k8s.io/api/core/v1"
+)
+
+type SecretsHandlerTestSuite struct {
+	WithControllerTestSuite
+
+	namespace string
+}
+
+func (suite *SecretsHandlerTestSuite) SetupSuite() {
+	suite.WithControllerTestSuite.SetupSuite()
+	suite.namespace = "kalm-secrets-test"
+	suite.ensureNamespaceExist(suite.namespace)
+}
+
+func (suite *SecretsHandlerTestSuite) TestSecretsHandler() {
+	secret := resources.ApplicationSecret{
+		Name: "db",
+		Data: map[string]string{"password": "foo"},
+	}
+
+	// create
+	suite.DoTestRequest(&TestRequestContext{
+		Roles: []string{
+			GetEditorRoleOfNamespace(suite.namespace),
+		},
+		Namespace: suite.namespace,
+		Method:    http.MethodPost,
+		Path:      "/v1alpha1/applications/" + suite.namespace + "/secrets",
+		Body:      secret,
+		TestWithoutRoles: func(rec *ResponseRecorder) {
+			suite.IsUnauthorizedError(rec, "edit")
+		},
+		TestWithRoles: func(rec *ResponseRecorder) {
+			var res resources.ApplicationSecret
+			rec.BodyAsJSON(&res)
+
+			suite.Equal(201, rec.Code)
+			suite.Equal([]string{"password"}, res.Keys)
+			suite.Nil(res.Data)
+		},
+	})
+
+	// rotate
+	secret.Data = map[string]string{"password": "bar", "user": "admin"}
+	suite.DoTestRequest(&TestRequestContext{
+		Roles: []string{
+			GetEditorRoleOfNamespace(suite.namespace),
+		},
+		Namespace: suite.namespace,
+		Method:    http.MethodPut,
+		Path:      "/v1alpha1/applications/" + suite.namespace + "/secrets/db",
+		Body:      secret,
+		TestWithoutRoles: func(rec *ResponseRecorder) {
+			suite.IsUnauthorizedError(rec, "edit")
+		},
+		TestWithRoles: func(rec *ResponseRecorder) {
+			suite.Equal(200, rec.Code)
+		},
+	})
+
+	var s coreV1.Secret
+	suite.Nil(suite.Get(suite.namespace, "db", &s))
+	suite.Equal("bar", string(s.Data["password"]))
+
+	// list, values are never returned
+	suite.DoTestRequest(&TestRequestContext{
+		Roles: []string{
+			GetEditorRoleOfNamespace(suite.namespace),
+		},
+		Namespace: suite.namespace,
+		Method:    http.MethodGet,
+		Path:      "/v1alpha1/applications/" + suite.namespace + "/secrets",
+		TestWithoutRoles: func(rec *ResponseRecorder) {
+			suite.IsUnauthorizedError(rec, "edit")
+		},
+		TestWithRoles: func(rec *ResponseRecorder) {
+			var res []resources.ApplicationSecret
+			rec.BodyAsJSON(&res)
+
+			suite.Len(res, 1)
+			suite.Equal([]string{"password", "user"}, res[0].Keys)
+			suite.NotContains(rec.BodyAsString(), "admin")
+		},
+	})
+
+	// delete
+	suite.DoTestRequest(&TestRequestContext{
+		Roles: []string{
+			GetEditorRoleOfNamespace(suite.namespace),
+		},
+		Namespace: suite.namespace,
+		Method:    http.MethodDelete,
+		Path:      "/v1alpha1/applications/" + suite.namespace + "/secrets/db",
+		TestWithoutRoles: func(rec *ResponseRecorder) {
+			suite.IsUnauthorizedError(rec, "edit")
+		},
+		TestWithRoles: func(rec *ResponseRecorder) {
+			suite.Equal(http.StatusNoContent, rec.Code)
+		},
+	})
+}
+
+func TestSecretsHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(SecretsHandlerTestSuite))
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/kalmhq/kalm/api/resources"
+	"github.com/labstack/echo/v4"
+)
+
+func (h *ApiHandler) InstallTcpRouteHandlers(e *echo.Group) {
+	e.GET("/tcproutes", h.handleListTcpRoutes)
+	e.POST("/tcproutes", h.handleCreateTcpRoute)
+	e.PUT("/tcproutes/:name", h.handleUpdateTcpRoute)
+	e.DELETE("/tcproutes/:name", h.handleDeleteTcpRoute)
+}
+
+func (h *ApiHandler) handleListTcpRoutes(c echo.Context) error {
+	list, err := h.resourceManager.GetTcpRoutes()
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(200, h.filterAuthorizedTcpRoutes(c, list))
+}
+
+func (h *ApiHandler) handleCreateTcpRoute(c echo.Context) (err error) {
+	var route *resources.TcpRoute
+	if route, err = getTcpRouteFromContext(c); err != nil {
+		return err
+	}
+
+	if !h.clientManager.CanOperateTcpRoute(getCurrentUser(c), "edit", route) {
+		return resources.InsufficientPermissionsError
+	}
+
+	if route, err = h.resourceManager.CreateTcpRoute(route); err != nil {
+		return err
+	}
+
+	return c.JSON(201, route)
+}
+
+func (h *ApiHandler) handleUpdateTcpRoute(c echo.Context) (err error) {
+	var route *resources.TcpRoute
+	if route, err = getTcpRouteFromContext(c); err != nil {
+		return err
+	}
+
+	route.Name = c.Param("name")
+
+	if !h.clientManager.CanOperateTcpRoute(getCurrentUser(c), "edit", route) {
+		return resources.InsufficientPermissionsError
+	}
+
+	if route, err = h.resourceManager.UpdateTcpRoute(route); err != nil {
+		return err
+	}
+
+	return c.JSON(200, route)
+}
+
+func (h *ApiHandler) handleDeleteTcpRoute(c echo.Context) (err error) {
+	route, err := h.resourceManager.GetTcpRoute(c.Param("name"))
+	if err != nil {
+		return err
+	}
+
+	if !h.clientManager.CanOperateTcpRoute(getCurrentUser(c), "edit", route) {
+		return resources.InsufficientPermissionsError
+	}
+
+	if err = h.resourceManager.DeleteTcpRoute(route.Name); err != nil {
+		return err
+	}
+
+	return c.NoContent(200)
+}
+
+func getTcpRouteFromContext(c echo.Context) (*resources.TcpRoute, error) {
+	var route resources.TcpRoute
+
+	if err := c.Bind(&route); err != nil {
+		return nil, err
+	}
+
+	if route.TcpRouteSpec == nil {
+		return nil, fmt.Errorf("must provide route spec")
+	}
+
+	return &route, nil
+}
+
+func (h *ApiHandler) filterAuthorizedTcpRoutes(c echo.Context, records []*resources.TcpRoute) []*resources.TcpRoute {
+	rst := make([]*resources.TcpRoute, 0, len(records))
+
+	for _, record := range records {
+		if h.clientManager.CanOperateTcpRoute(getCurrentUser(c), "view", record) {
+			rst = append(rst, record)
+		}
+	}
+
+	return rst
+}
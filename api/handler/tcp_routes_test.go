@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kalmhq/kalm/api/resources"
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/stretchr/testify/suite"
+)
+
+type TcpRoutesHandlerTestSuite struct {
+	WithControllerTestSuite
+}
+
+func (suite *TcpRoutesHandlerTestSuite) SetupSuite() {
+	suite.WithControllerTestSuite.SetupSuite()
+	suite.ensureNamespaceExist("test-tcp-routes")
+}
+
+func (suite *TcpRoutesHandlerTestSuite) TestTcpRoutesHandler() {
+	route := resources.TcpRoute{
+		TcpRouteSpec: &v1alpha1.TcpRouteSpec{
+			Protocol: v1alpha1.TcpRouteProtocolTCP,
+			Port:     5432,
+			Destinations: []v1alpha1.HttpRouteDestination{
+				{
+					Host:   "postgres.test-tcp-routes.svc.cluster.local:5432",
+					Weight: 1,
+				},
+			},
+		},
+		Name: "test-tcp-routes",
+	}
+
+	// create a route
+	suite.DoTestRequest(&TestRequestContext{
+		Roles: []string{
+			GetClusterEditorRole(),
+		},
+		Method: http.MethodPost,
+		Path:   "/v1alpha1/tcproutes",
+		Body:   route,
+		TestWithoutRoles: func(rec *ResponseRecorder) {
+			suite.IsUnauthorizedError(rec)
+		},
+		TestWithRoles: func(rec *ResponseRecorder) {
+			suite.EqualValues(201, rec.Code)
+		},
+	})
+
+	// list routes
+	suite.DoTestRequest(&TestRequestContext{
+		Roles: []string{
+			GetClusterViewerRole(),
+		},
+		Method: http.MethodGet,
+		Path:   "/v1alpha1/tcproutes",
+		TestWithRoles: func(rec *ResponseRecorder) {
+			var routesRes []*resources.TcpRoute
+			rec.BodyAsJSON(&routesRes)
+			suite.EqualValues(1, len(routesRes))
+			suite.EqualValues("test-tcp-routes", routesRes[0].Name)
+			suite.EqualValues(5432, routesRes[0].Port)
+		},
+	})
+
+	// update the route
+	route.TcpRouteSpec.Port = 15432
+	suite.DoTestRequest(&TestRequestContext{
+		Roles: []string{
+			GetClusterEditorRole(),
+		},
+		Method: http.MethodPut,
+		Path:   "/v1alpha1/tcproutes/test-tcp-routes",
+		Body:   route,
+		TestWithoutRoles: func(rec *ResponseRecorder) {
+			suite.IsUnauthorizedError(rec)
+		},
+		TestWithRoles: func(rec *ResponseRecorder) {
+			var routeRes resources.TcpRoute
+			rec.BodyAsJSON(&routeRes)
+			suite.EqualValues(200, rec.Code)
+			suite.EqualValues(15432, routeRes.Port)
+		},
+	})
+
+	// delete the route
+	suite.DoTestRequest(&TestRequestContext{
+		Roles: []string{
+			GetClusterEditorRole(),
+		},
+		Method: http.MethodDelete,
+		Path:   "/v1alpha1/tcproutes/test-tcp-routes",
+		TestWithoutRoles: func(rec *ResponseRecorder) {
+			suite.IsUnauthorizedError(rec)
+		},
+		TestWithRoles: func(rec *ResponseRecorder) {
+			suite.EqualValues(200, rec.Code)
+		},
+	})
+
+	suite.DoTestRequest(&TestRequestContext{
+		Roles: []string{
+			GetClusterEditorRole(),
+		},
+		Method: http.MethodGet,
+		Path:   "/v1alpha1/tcproutes",
+		TestWithRoles: func(rec *ResponseRecorder) {
+			var routesRes []*resources.TcpRoute
+			rec.BodyAsJSON(&routesRes)
+			suite.EqualValues(0, len(routesRes))
+		},
+	})
+}
+
+func TestTcpRoutesHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(TcpRoutesHandlerTestSuite))
+}
@@ -1,15 +1,17 @@
 package handler
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/kalmhq/kalm/api/audit"
 	"github.com/kalmhq/kalm/api/auth"
+	kalmClient "github.com/kalmhq/kalm/api/client"
 	"github.com/kalmhq/kalm/api/resources"
-	"github.com/kalmhq/kalm/controller/api/v1alpha1"
 	"github.com/kalmhq/kalm/controller/controllers"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
@@ -23,7 +25,15 @@ type DeployWebhookCallParams struct {
 	ImageTag      string `json:"imageTag"`
 }
 
-func (h *ApiHandler) handleDeployWebhookCall(c echo.Context) error {
+// The caller is authenticated by the deploy key in the handler rather than the user middlewares,
+// so the call is recorded here instead of by the audit middleware.
+func (h *ApiHandler) handleDeployWebhookCall(c echo.Context) (err error) {
+	entry := newAuditEntry(&kalmClient.ClientInfo{}, audit.ActionUpdate, "", "webhook/components")
+	entry.Method = c.Request().Method
+	entry.ClientIP = c.RealIP()
+
+	defer recordAudit(c, entry, &err)
+
 	var callParams DeployWebhookCallParams
 
 	if err := c.Bind(&callParams); err != nil {
@@ -48,12 +58,17 @@ func (h *ApiHandler) handleDeployWebhookCall(c echo.Context) error {
 		return fmt.Errorf("componentName can't be blank")
 	}
 
-	clientInfo, err := h.clientManager.GetClientInfoFromToken(callParams.DeployKey)
+	entry.Namespace = callParams.Namespace
+	entry.Resource = "applications/" + callParams.Namespace + "/components/" + callParams.ComponentName
+
+	clientInfo, err := h.clientManager.GetClientInfoFromToken(callParams.DeployKey, c.RealIP())
 
 	if err != nil {
 		return err
 	}
 
+	setAuditEntrySubject(entry, clientInfo)
+
 	builder := resources.NewResourceManager(clientInfo.Cfg, h.logger)
 
 	if builder == nil {
@@ -81,6 +96,11 @@ func (h *ApiHandler) handleDeployWebhookCall(c echo.Context) error {
 
 	updateTs := int(time.Now().Unix())
 	copiedComp.Annotations[controllers.AnnoLastUpdatedByWebhook] = strconv.Itoa(updateTs)
+	setComponentChangedBy(copiedComp, clientInfo, "deploy webhook")
+
+	existing, _ := json.Marshal(crdComp)
+	submitted, _ := json.Marshal(copiedComp)
+	entry.Changes = audit.Diff(existing, submitted)
 
 	if err := h.resourceManager.Patch(copiedComp, client.MergeFrom(crdComp)); err != nil {
 		h.logger.Info("fail updating component", zap.String("name", copiedComp.Name), zap.Int("time", updateTs))
@@ -89,19 +109,6 @@ func (h *ApiHandler) handleDeployWebhookCall(c echo.Context) error {
 
 	h.logger.Info("updating component", zap.String("name", copiedComp.Name), zap.Int("time", updateTs))
 
-	var accessToken v1alpha1.AccessToken
-	if err := h.resourceManager.Get("", clientInfo.Name, &accessToken); err == nil {
-		copiedKey := accessToken.DeepCopy()
-		copiedKey.Status.UsedCount += 1
-		copiedKey.Status.LastUsedAt = updateTs
-
-		if err := h.resourceManager.Patch(copiedKey, client.MergeFrom(&accessToken)); err != nil {
-			h.logger.Error("fail update status of access token", zap.Error(err))
-		}
-	} else {
-		h.logger.Error("fail to get access token", zap.Error(err))
-	}
-
 	return c.JSON(http.StatusOK, map[string]string{
 		"status": "Success",
 	})
@@ -10,6 +10,8 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/kalmhq/kalm/api/audit"
+	"github.com/kalmhq/kalm/api/recording"
 	"github.com/kalmhq/kalm/api/resources"
 	"go.uber.org/zap"
 
@@ -31,6 +33,7 @@ type WSConn struct {
 
 	clientInfo    *client.ClientInfo
 	clientManager client.ClientManager
+	clientIP      string
 
 	podResourceRequest chan *WSPodResourceRequest
 	writeLock          *sync.Mutex
@@ -136,9 +139,12 @@ type TerminalSession struct {
 	namespace string
 
 	podName string
+
+	// not nil if the session is recorded
+	recorder *recording.Recorder
 }
 
-func NewTerminalSession(conn *WSConn, ctx context.Context, ns, podName string) *TerminalSession {
+func NewTerminalSession(conn *WSConn, ctx context.Context, ns, podName string, recorder *recording.Recorder) *TerminalSession {
 	return &TerminalSession{
 		conn,
 		make(chan []byte),
@@ -146,12 +152,17 @@ func NewTerminalSession(conn *WSConn, ctx context.Context, ns, podName string) *
 		ctx,
 		ns,
 		podName,
+		recorder,
 	}
 }
 
 func (t *TerminalSession) Next() *remotecommand.TerminalSize {
 	select {
 	case size := <-t.sizeChan:
+		if t.recorder != nil {
+			t.recorder.Resize(size.Width, size.Height)
+		}
+
 		return size
 	case <-t.ctx.Done():
 		return nil
@@ -168,6 +179,10 @@ func (t *TerminalSession) Read(p []byte) (int, error) {
 }
 
 func (t *TerminalSession) Write(p []byte) (int, error) {
+	if t.recorder != nil {
+		t.recorder.Output(p)
+	}
+
 	err := t.wsConn.WriteJSON(&WSPodDataResponse{
 		Type:      WSResponseTypeExecStdout,
 		Namespace: t.namespace,
@@ -226,7 +241,7 @@ func wsReadLoop(conn *WSConn, clientManager client.ClientManager) (err error) {
 				continue
 			}
 
-			if clientInfo, err := clientManager.GetClientInfoFromToken(m.AuthToken); err == nil {
+			if clientInfo, err := clientManager.GetClientInfoFromToken(m.AuthToken, conn.clientIP); err == nil {
 				clientManager.SetImpersonation(clientInfo, m.Impersonation)
 				conn.clientInfo = clientInfo
 				res.Status = StatusOK
@@ -257,7 +272,13 @@ func wsReadLoop(conn *WSConn, clientManager client.ClientManager) (err error) {
 				}
 			case WSRequestTypeExecStartSession, WSRequestTypeExecStdin, WSRequestTypeExecResize:
 				if !conn.clientManager.CanEdit(conn.clientInfo, m.Namespace, "pods/"+m.PodName) {
-					res.Message = resources.NoObjectEditorRoleError(m.Namespace, "pods/"+m.PodName).Error()
+					err := resources.NoObjectEditorRoleError(m.Namespace, "pods/"+m.PodName)
+
+					if m.Type == WSRequestTypeExecStartSession {
+						recordExecAudit(conn, audit.ActionExec, &m, "", err)
+					}
+
+					res.Message = err.Error()
 					break OuterSwitch
 				}
 			}
@@ -453,7 +474,7 @@ func startExecTerminalSession(conn *WSConn, shell string, terminalSession *Termi
 	return err
 }
 
-func handleExecRequests(conn *WSConn) {
+func (h *ApiHandler) handleExecRequests(conn *WSConn) {
 	podRegistrations := make(map[string]context.CancelFunc)
 	terminalSessions := make(map[string]*TerminalSession)
 	mut := &sync.Mutex{}
@@ -478,15 +499,40 @@ func handleExecRequests(conn *WSConn) {
 					oldStop()
 				}
 
-				session := NewTerminalSession(conn, ctx, m.Namespace, m.PodName)
+				recorder, recordingID, err := h.newExecSessionRecorder(conn, m)
+
+				if err != nil {
+					stop()
+					log.Error("Start Exec Session Recording Error", zap.Error(err))
+					recordExecAudit(conn, audit.ActionExec, m, "", err)
+
+					_ = conn.WriteJSON(&WSPodDataResponse{
+						Type:      WSResponseTypeExecDisconnected,
+						Namespace: m.Namespace,
+						PodName:   m.PodName,
+						Data:      err.Error(),
+					})
+
+					continue
+				}
+
+				session := NewTerminalSession(conn, ctx, m.Namespace, m.PodName, recorder)
 
 				mut.Lock()
 				podRegistrations[key] = stop
 				terminalSessions[key] = session
 				mut.Unlock()
 
+				recordExecAudit(conn, audit.ActionExec, m, recordingID, nil)
+
 				go func() {
 					defer func() {
+						if recorder != nil {
+							if err := recorder.Close(); err != nil {
+								log.Error("Exec Session Recording Error", zap.String("recording", recordingID), zap.Error(err))
+							}
+						}
+
 						stop()
 						mut.Lock()
 						delete(podRegistrations, key)
@@ -504,6 +550,8 @@ func handleExecRequests(conn *WSConn) {
 						}
 					}
 
+					recordExecAudit(conn, audit.ActionExecEnd, m, recordingID, err)
+
 					var data string
 
 					if err != nil {
@@ -570,6 +618,7 @@ func (h *ApiHandler) prepareWSConnection(c echo.Context) (*WSConn, error) {
 		podResourceRequest: make(chan *WSPodResourceRequest),
 		writeLock:          &sync.Mutex{},
 		clientManager:      h.clientManager,
+		clientIP:           c.RealIP(),
 	}
 
 	clientInfo, err := h.clientManager.GetClientInfoFromContext(c)
@@ -611,7 +660,7 @@ func (h *ApiHandler) execWebsocketHandler(c echo.Context) error {
 		_ = conn.Close()
 	}()
 
-	go handleExecRequests(conn)
+	go h.handleExecRequests(conn)
 	_ = wsReadLoop(conn, h.clientManager)
 
 	return nil
@@ -6,6 +6,7 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"github.com/kalmhq/kalm/api/log"
+	"github.com/kalmhq/kalm/api/recording"
 	"golang.org/x/net/http2"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -14,6 +15,7 @@ import (
 	"sort"
 
 	_ "github.com/joho/godotenv/autoload"
+	"github.com/kalmhq/kalm/api/audit"
 	"github.com/kalmhq/kalm/api/client"
 	"github.com/kalmhq/kalm/api/config"
 	"github.com/kalmhq/kalm/api/handler"
@@ -21,7 +23,9 @@ import (
 	"github.com/kalmhq/kalm/api/resources"
 	"github.com/kalmhq/kalm/api/server"
 	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/kalmhq/kalm/controller/controllers"
 	"github.com/urfave/cli/v2"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 )
 
@@ -90,6 +94,38 @@ func main() {
 				Destination: &runningConfig.EnableAdminServerDebugRoutes,
 				EnvVars:     []string{"ENABLE_DEBUG_APIS"},
 			},
+			&cli.StringFlag{
+				Name:        "audit-log-file",
+				Usage:       "Append audit entries of mutating api calls and exec sessions to the file as json lines.",
+				Destination: &runningConfig.AuditLogFilePath,
+				EnvVars:     []string{"AUDIT_LOG_FILE"},
+			},
+			&cli.StringFlag{
+				Name:        "audit-webhook-url",
+				Usage:       "Post audit entries of mutating api calls and exec sessions to the url as json.",
+				Destination: &runningConfig.AuditWebhookURL,
+				EnvVars:     []string{"AUDIT_WEBHOOK_URL"},
+			},
+			&cli.BoolFlag{
+				Name:        "audit-kubernetes-events",
+				Value:       false,
+				Usage:       "Create a kubernetes event for each audit entry, in the namespace of the application or in kalm-system.",
+				Destination: &runningConfig.AuditKubernetesEvents,
+				EnvVars:     []string{"AUDIT_KUBERNETES_EVENTS"},
+			},
+			&cli.IntFlag{
+				Name:        "audit-retained-entries",
+				Value:       1000,
+				Usage:       "The number of recent audit entries kept in memory and served by the audit api. They are lost on restart and not shared between replicas, set a durable sink to keep the history.",
+				Destination: &runningConfig.AuditRetainedEntries,
+				EnvVars:     []string{"AUDIT_RETAINED_ENTRIES"},
+			},
+			&cli.StringFlag{
+				Name:        "exec-recordings-dir",
+				Usage:       "Save recordings of exec sessions to applications which opt in to the directory, exec sessions to these applications are refused if it's not set.",
+				Destination: &runningConfig.ExecRecordingsDir,
+				EnvVars:     []string{"EXEC_RECORDINGS_DIR"},
+			},
 			&cli.BoolFlag{
 				Name:        "verbose",
 				Value:       false,
@@ -192,6 +228,55 @@ func startMetricServer(cfg *rest.Config) {
 	_ = resources.StartMetricScraper(context.Background(), cfg)
 }
 
+// the auditor is shared by the main server and the privileged localhost server
+func installAuditor(runningConfig *config.Config, k8sClientConfig *rest.Config) {
+	var sinks []audit.Sink
+
+	if runningConfig.AuditLogFilePath != "" {
+		sink, err := audit.NewFileSink(runningConfig.AuditLogFilePath)
+
+		if err != nil {
+			panic(err)
+		}
+
+		sinks = append(sinks, sink)
+	}
+
+	if runningConfig.AuditWebhookURL != "" {
+		sinks = append(sinks, audit.NewWebhookSink(runningConfig.AuditWebhookURL))
+	}
+
+	if runningConfig.AuditKubernetesEvents {
+		clientset, err := kubernetes.NewForConfig(k8sClientConfig)
+
+		if err != nil {
+			panic(err)
+		}
+
+		sinks = append(sinks, audit.NewKubernetesEventSink(clientset, controllers.KalmSystemNamespace))
+	}
+
+	if len(sinks) == 0 {
+		log.Info("no audit sink is set, audit entries are only kept in memory of this replica and lost on restart")
+	}
+
+	audit.InitDefaultAuditor(runningConfig.AuditRetainedEntries, sinks...)
+}
+
+func installExecRecordingStore(runningConfig *config.Config) {
+	if runningConfig.ExecRecordingsDir == "" {
+		return
+	}
+
+	store, err := recording.NewDirStore(runningConfig.ExecRecordingsDir)
+
+	if err != nil {
+		panic(err)
+	}
+
+	recording.InitDefaultStore(store)
+}
+
 func run(runningConfig *config.Config) {
 	if err := v1alpha1.AddToScheme(scheme.Scheme); err != nil {
 		panic(err)
@@ -203,6 +288,9 @@ func run(runningConfig *config.Config) {
 		panic(err)
 	}
 
+	installAuditor(runningConfig, k8sClientConfig)
+	installExecRecordingStore(runningConfig)
+
 	go func() {
 		if runningConfig.IsInCluster() {
 			startMetricServer(k8sClientConfig)
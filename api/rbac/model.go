@@ -10,6 +10,19 @@ const (
 	ActionManage = "manage"
 )
 
+// ImpliedActions returns the action and the actions it implies, manage implies edit, edit implies view.
+// Built-in roles inherit them by role hierarchy, the rules of custom roles are expanded with them.
+func ImpliedActions(action string) []string {
+	switch action {
+	case ActionManage:
+		return []string{ActionManage, ActionEdit, ActionView}
+	case ActionEdit:
+		return []string{ActionEdit, ActionView}
+	default:
+		return []string{action}
+	}
+}
+
 const (
 	AnyResource = "*"
 )
@@ -0,0 +1,160 @@
+package recording
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Terminal size used in the header if the client doesn't tell the size before the first output
+const (
+	DefaultWidth  = 80
+	DefaultHeight = 24
+)
+
+const (
+	EventTypeOutput = "o"
+	EventTypeResize = "r"
+)
+
+// Header of asciicast v2 files, https://github.com/asciinema/asciinema/blob/develop/doc/asciicast-v2.md
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Recorder writes the output and resize events of a terminal session in asciicast v2 format.
+// The header is written along with the first event, so the size of the terminal can be included.
+type Recorder struct {
+	mut *sync.Mutex
+	w   io.WriteCloser
+
+	header        Header
+	headerWritten bool
+	start         time.Time
+
+	// the incomplete utf-8 sequence at the end of the last output
+	pending []byte
+
+	err error
+}
+
+func NewRecorder(w io.WriteCloser, title string, start time.Time) *Recorder {
+	return &Recorder{
+		mut: &sync.Mutex{},
+		w:   w,
+		header: Header{
+			Version:   2,
+			Width:     DefaultWidth,
+			Height:    DefaultHeight,
+			Timestamp: start.Unix(),
+			Title:     title,
+			Env:       map[string]string{"TERM": "xterm"},
+		},
+		start: start,
+	}
+}
+
+func (r *Recorder) Output(data []byte) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	data = append(r.pending, data...)
+	data, r.pending = splitIncompleteRune(data)
+
+	if len(data) == 0 {
+		return
+	}
+
+	r.writeEvent(EventTypeOutput, string(data))
+}
+
+func (r *Recorder) Resize(width, height uint16) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	if !r.headerWritten {
+		r.header.Width = int(width)
+		r.header.Height = int(height)
+		return
+	}
+
+	r.writeEvent(EventTypeResize, fmt.Sprintf("%dx%d", width, height))
+}
+
+// Close flushes the pending output and closes the underlying writer.
+// Errors happened during recording are returned, the recording is incomplete in that case.
+func (r *Recorder) Close() error {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	if len(r.pending) > 0 {
+		r.writeEvent(EventTypeOutput, string(r.pending))
+		r.pending = nil
+	}
+
+	if !r.headerWritten {
+		r.writeLine(r.header)
+	}
+
+	if err := r.w.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+
+	return r.err
+}
+
+func (r *Recorder) writeEvent(eventType, data string) {
+	if !r.headerWritten {
+		r.writeLine(r.header)
+		r.headerWritten = true
+	}
+
+	// microsecond precision is enough for replay
+	elapsed := math.Round(time.Since(r.start).Seconds()*1e6) / 1e6
+
+	r.writeLine([]interface{}{elapsed, eventType, data})
+}
+
+func (r *Recorder) writeLine(v interface{}) {
+	if r.err != nil {
+		return
+	}
+
+	bs, err := json.Marshal(v)
+
+	if err != nil {
+		r.err = err
+		return
+	}
+
+	_, r.err = r.w.Write(append(bs, '\n'))
+}
+
+// The output of a terminal may be split in the middle of a multi-byte character,
+// the incomplete tail is kept until the rest of the character arrives.
+func splitIncompleteRune(data []byte) ([]byte, []byte) {
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		start := len(data) - i
+
+		if !utf8.RuneStart(data[start]) {
+			continue
+		}
+
+		if utf8.FullRune(data[start:]) {
+			return data, nil
+		}
+
+		return data[:start], append([]byte(nil), data[start:]...)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,99 @@
+package recording
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type bufferCloser struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *bufferCloser) Close() error {
+	b.closed = true
+	return nil
+}
+
+func parseCast(t *testing.T, content string) (Header, [][]interface{}) {
+	lines := strings.Split(strings.TrimSpace(content), "\n")
+
+	var header Header
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &header))
+
+	var events [][]interface{}
+
+	for _, line := range lines[1:] {
+		var event []interface{}
+		assert.Nil(t, json.Unmarshal([]byte(line), &event))
+		events = append(events, event)
+	}
+
+	return header, events
+}
+
+func TestRecorder(t *testing.T) {
+	buf := &bufferCloser{}
+	start := time.Now()
+	recorder := NewRecorder(buf, "ns1/web-0", start)
+
+	// the size before the first output is in the header
+	recorder.Resize(120, 40)
+	recorder.Output([]byte("$ ls\r\n"))
+	recorder.Resize(100, 30)
+
+	// "中" is split into two outputs
+	zh := []byte("中")
+	recorder.Output(append([]byte("a"), zh[:1]...))
+	recorder.Output(append(zh[1:], 'b'))
+
+	assert.Nil(t, recorder.Close())
+	assert.True(t, buf.closed)
+
+	header, events := parseCast(t, buf.String())
+	assert.Equal(t, 2, header.Version)
+	assert.Equal(t, 120, header.Width)
+	assert.Equal(t, 40, header.Height)
+	assert.Equal(t, start.Unix(), header.Timestamp)
+	assert.Equal(t, "ns1/web-0", header.Title)
+
+	assert.Len(t, events, 4)
+	assert.Equal(t, EventTypeOutput, events[0][1])
+	assert.Equal(t, "$ ls\r\n", events[0][2])
+	assert.Equal(t, EventTypeResize, events[1][1])
+	assert.Equal(t, "100x30", events[1][2])
+	assert.Equal(t, "a", events[2][2])
+	assert.Equal(t, "中b", events[3][2])
+}
+
+func TestRecorderWithoutOutput(t *testing.T) {
+	buf := &bufferCloser{}
+	recorder := NewRecorder(buf, "", time.Now())
+	assert.Nil(t, recorder.Close())
+
+	header, events := parseCast(t, buf.String())
+	assert.Equal(t, DefaultWidth, header.Width)
+	assert.Equal(t, DefaultHeight, header.Height)
+	assert.Len(t, events, 0)
+}
+
+func TestSplitIncompleteRune(t *testing.T) {
+	zh := []byte("中")
+
+	complete, rest := splitIncompleteRune([]byte("abc"))
+	assert.Equal(t, "abc", string(complete))
+	assert.Len(t, rest, 0)
+
+	complete, rest = splitIncompleteRune(append([]byte("abc"), zh[:2]...))
+	assert.Equal(t, "abc", string(complete))
+	assert.Equal(t, zh[:2], rest)
+
+	complete, rest = splitIncompleteRune(append([]byte("abc"), zh...))
+	assert.Equal(t, "abc中", string(complete))
+	assert.Len(t, rest, 0)
+}
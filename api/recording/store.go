@@ -0,0 +1,209 @@
+package recording
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrNotFound = fmt.Errorf("recording not found")
+
+var recordingIDRegex = regexp.MustCompile(`^[0-9a-zA-Z-]+$`)
+
+// Recording is the metadata of a recorded exec session
+type Recording struct {
+	ID        string     `json:"id"`
+	Namespace string     `json:"namespace"`
+	PodName   string     `json:"podName"`
+	Container string     `json:"container,omitempty"`
+	Subject   string     `json:"subject"`
+	StartTime time.Time  `json:"startTime"`
+	EndTime   *time.Time `json:"endTime,omitempty"`
+}
+
+// Store is the storage backend of recordings
+type Store interface {
+	// Create saves the metadata and returns the writer of the recording content,
+	// the metadata is saved again with the end time when the writer is closed.
+	Create(recording *Recording) (io.WriteCloser, error)
+	// List returns the recordings of the namespace, or of all namespaces if it's empty, the newest first
+	List(namespace string) ([]*Recording, error)
+	Get(id string) (*Recording, error)
+	Open(id string) (io.ReadCloser, error)
+}
+
+func NewRecordingID(now time.Time) string {
+	bs := make([]byte, 4)
+	_, _ = rand.Read(bs)
+	return fmt.Sprintf("%s-%s", now.UTC().Format("20060102T150405"), hex.EncodeToString(bs))
+}
+
+var _ Store = &DirStore{}
+
+// DirStore saves each recording as <id>.cast, along with the metadata in <id>.json
+type DirStore struct {
+	dir string
+	mut *sync.Mutex
+}
+
+func NewDirStore(dir string) (*DirStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	return &DirStore{dir: dir, mut: &sync.Mutex{}}, nil
+}
+
+func (s *DirStore) Create(recording *Recording) (io.WriteCloser, error) {
+	if !recordingIDRegex.MatchString(recording.ID) {
+		return nil, fmt.Errorf("invalid recording id: %s", recording.ID)
+	}
+
+	if err := s.saveMeta(recording); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(s.castPath(recording.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &dirStoreWriter{File: file, store: s, recording: recording}, nil
+}
+
+func (s *DirStore) List(namespace string) ([]*Recording, error) {
+	files, err := ioutil.ReadDir(s.dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*Recording, 0)
+
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+
+		recording, err := s.Get(strings.TrimSuffix(file.Name(), ".json"))
+
+		if err != nil {
+			continue
+		}
+
+		if namespace != "" && recording.Namespace != namespace {
+			continue
+		}
+
+		res = append(res, recording)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].StartTime.After(res[j].StartTime)
+	})
+
+	return res, nil
+}
+
+func (s *DirStore) Get(id string) (*Recording, error) {
+	if !recordingIDRegex.MatchString(id) {
+		return nil, ErrNotFound
+	}
+
+	bs, err := ioutil.ReadFile(s.metaPath(id))
+
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	var recording Recording
+
+	if err := json.Unmarshal(bs, &recording); err != nil {
+		return nil, err
+	}
+
+	return &recording, nil
+}
+
+func (s *DirStore) Open(id string) (io.ReadCloser, error) {
+	if !recordingIDRegex.MatchString(id) {
+		return nil, ErrNotFound
+	}
+
+	file, err := os.Open(s.castPath(id))
+
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+
+	return file, err
+}
+
+func (s *DirStore) saveMeta(recording *Recording) error {
+	bs, err := json.Marshal(recording)
+
+	if err != nil {
+		return err
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	// write to a temp file first, so the metadata is never read half written
+	tmpPath := s.metaPath(recording.ID) + ".tmp"
+
+	if err := ioutil.WriteFile(tmpPath, bs, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, s.metaPath(recording.ID))
+}
+
+func (s *DirStore) castPath(id string) string {
+	return filepath.Join(s.dir, id+".cast")
+}
+
+func (s *DirStore) metaPath(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+type dirStoreWriter struct {
+	*os.File
+	store     *DirStore
+	recording *Recording
+}
+
+func (w *dirStoreWriter) Close() error {
+	if err := w.File.Close(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	w.recording.EndTime = &now
+
+	return w.store.saveMeta(w.recording)
+}
+
+var defaultStore Store
+
+// InitDefaultStore sets the store of exec session recordings, recording is unavailable if it's not set
+func InitDefaultStore(store Store) {
+	defaultStore = store
+}
+
+func DefaultStore() Store {
+	return defaultStore
+}
@@ -0,0 +1,73 @@
+package recording
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recordings")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := NewDirStore(dir)
+	assert.Nil(t, err)
+
+	now := time.Now()
+
+	for i, namespace := range []string{"ns1", "ns2", "ns1"} {
+		startTime := now.Add(time.Duration(i) * time.Minute)
+
+		w, err := store.Create(&Recording{
+			ID:        NewRecordingID(startTime),
+			Namespace: namespace,
+			PodName:   "web-0",
+			Subject:   "foo@bar.com",
+			StartTime: startTime,
+		})
+		assert.Nil(t, err)
+
+		_, err = w.Write([]byte("content"))
+		assert.Nil(t, err)
+
+		if i < 2 {
+			assert.Nil(t, w.Close())
+		}
+	}
+
+	recordings, err := store.List("")
+	assert.Nil(t, err)
+	assert.Len(t, recordings, 3)
+
+	// the newest first, the last one is still recording
+	assert.Nil(t, recordings[0].EndTime)
+	assert.NotNil(t, recordings[1].EndTime)
+
+	recordings, err = store.List("ns1")
+	assert.Nil(t, err)
+	assert.Len(t, recordings, 2)
+
+	recording, err := store.Get(recordings[1].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "ns1", recording.Namespace)
+
+	r, err := store.Open(recording.ID)
+	assert.Nil(t, err)
+	content, err := ioutil.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, "content", string(content))
+	assert.Nil(t, r.Close())
+
+	_, err = store.Get("../secret")
+	assert.Equal(t, ErrNotFound, err)
+
+	_, err = store.Open("not-exist")
+	assert.Equal(t, ErrNotFound, err)
+
+	_, err = store.Create(&Recording{ID: "../secret"})
+	assert.NotNil(t, err)
+}
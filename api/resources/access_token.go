@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"time"
+
 	"github.com/kalmhq/kalm/controller/api/v1alpha1"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -36,10 +38,15 @@ func (resourceManager *ResourceManager) CreateAccessToken(accessToken *AccessTok
 	return BuildAccessTokenFromResource(resAccessToken), nil
 }
 
+// The token and its hash are never responded, the token is only shown once when it's created
 func BuildAccessTokenFromResource(dk *v1alpha1.AccessToken) *AccessToken {
+	spec := dk.Spec.DeepCopy()
+	spec.Token = ""
+	spec.TokenHash = ""
+
 	return &AccessToken{
 		Name:            dk.Name,
-		AccessTokenSpec: &dk.Spec,
+		AccessTokenSpec: spec,
 	}
 }
 
@@ -60,6 +67,47 @@ func (resourceManager *ResourceManager) GetAccessTokens(listOptions ...client.Li
 	return rst, nil
 }
 
+// the old token of a rotation keeps working in the grace period, so clients can switch to the new token
+const DefaultAccessTokenRotationGracePeriodSeconds = 24 * 60 * 60
+
+type RotateAccessTokenRequest struct {
+	GracePeriodSeconds *int `json:"gracePeriodSeconds"`
+}
+
+// RotateAccessToken creates a successor of the access token with the same permissions and restrictions,
+// and makes the access token expire after the grace period. The new token is returned along with the successor.
+func (resourceManager *ResourceManager) RotateAccessToken(accessToken *v1alpha1.AccessToken, gracePeriod time.Duration) (*AccessToken, string, error) {
+	token, name := v1alpha1.GenerateAccessToken()
+
+	successor := &v1alpha1.AccessToken{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:   name,
+			Labels: accessToken.Labels,
+		},
+		Spec: *accessToken.Spec.DeepCopy(),
+	}
+
+	successor.Spec.RotatedFrom = accessToken.Name
+	successor.Spec.SetToken(token)
+
+	if err := resourceManager.Create(successor); err != nil {
+		return nil, "", err
+	}
+
+	expiredAt := metaV1.NewTime(time.Now().Add(gracePeriod))
+
+	if accessToken.Spec.ExpiredAt == nil || accessToken.Spec.ExpiredAt.After(expiredAt.Time) {
+		copied := accessToken.DeepCopy()
+		copied.Spec.ExpiredAt = &expiredAt
+
+		if err := resourceManager.Patch(copied, client.MergeFrom(accessToken)); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return BuildAccessTokenFromResource(successor), token, nil
+}
+
 var AccessTokenTypeLabelKey = "tokenType"
 var DeployAccessTokenLabelValue = "deployAccessToken"
 
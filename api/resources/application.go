@@ -8,6 +8,7 @@ import (
 	"github.com/kalmhq/kalm/controller/controllers"
 	batchV1 "k8s.io/api/batch/v1"
 	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -93,6 +94,13 @@ type ApplicationDetails struct {
 	IstioMetricHistories *IstioMetricHistories `json:"istioMetricHistories"`
 	Roles                []string              `json:"roles"`
 	Status               string                `json:"status"` // Active or Terminating
+
+	// components grouped by the order they start in, see StartAfterComponents of components
+	StartupOrder [][]string `json:"startupOrder"`
+	// components which never start because of a loop in StartAfterComponents
+	ComponentsInDependencyLoop []string `json:"componentsInDependencyLoop,omitempty"`
+	// components which never start because a component in their StartAfterComponents doesn't exist
+	ComponentsBlockedByMissingDependencies []string `json:"componentsBlockedByMissingDependencies,omitempty"`
 }
 
 type CreateOrUpdateApplicationRequest struct {
@@ -101,6 +109,49 @@ type CreateOrUpdateApplicationRequest struct {
 
 type Application struct {
 	Name string `json:"name"`
+
+	// requests of routes to the application are responded with 503 and the maintenance page
+	Maintenance bool `json:"maintenance,omitempty"`
+	// path of the maintenance page in the files of the application, a default page is used if it's empty
+	MaintenancePage string `json:"maintenancePage,omitempty"`
+
+	// exec terminal sessions to pods of the application are recorded for replay
+	RecordExecSessions bool `json:"recordExecSessions,omitempty"`
+}
+
+func BuildApplicationFromNamespace(namespace *coreV1.Namespace) *Application {
+	return &Application{
+		Name:               namespace.Name,
+		Maintenance:        namespace.Annotations[v1alpha1.KalmMaintenanceAnnotationName] == v1alpha1.KalmMaintenanceAnnotationValue,
+		MaintenancePage:    namespace.Annotations[v1alpha1.KalmMaintenancePageAnnotationName],
+		RecordExecSessions: namespace.Annotations[v1alpha1.KalmRecordExecSessionsAnnotationName] == v1alpha1.KalmRecordExecSessionsAnnotationValue,
+	}
+}
+
+// SetAnnotations sets the annotations of the application settings,
+// the maintenance annotations are read by the http route controller.
+func (app *Application) SetAnnotations(namespace *coreV1.Namespace) {
+	if namespace.Annotations == nil {
+		namespace.Annotations = make(map[string]string)
+	}
+
+	if app.Maintenance {
+		namespace.Annotations[v1alpha1.KalmMaintenanceAnnotationName] = v1alpha1.KalmMaintenanceAnnotationValue
+	} else {
+		delete(namespace.Annotations, v1alpha1.KalmMaintenanceAnnotationName)
+	}
+
+	if app.MaintenancePage != "" {
+		namespace.Annotations[v1alpha1.KalmMaintenancePageAnnotationName] = app.MaintenancePage
+	} else {
+		delete(namespace.Annotations, v1alpha1.KalmMaintenancePageAnnotationName)
+	}
+
+	if app.RecordExecSessions {
+		namespace.Annotations[v1alpha1.KalmRecordExecSessionsAnnotationName] = v1alpha1.KalmRecordExecSessionsAnnotationValue
+	} else {
+		delete(namespace.Annotations, v1alpha1.KalmRecordExecSessionsAnnotationName)
+	}
 }
 
 func (resourceManager *ResourceManager) GetNamespace(name string) (*coreV1.Namespace, error) {
@@ -168,16 +219,29 @@ func (resourceManager *ResourceManager) BuildApplicationDetails(namespace *coreV
 		}
 	}
 
+	componentListChan := resourceManager.GetComponentListChannel(nsName, metaV1.ListOptions{})
+	components := <-componentListChan.List
+
+	if err := <-componentListChan.Error; err != nil {
+		return nil, err
+	}
+
+	startupOrder, componentsInLoop, componentsBlocked := v1alpha1.ResolveComponentStartupOrder(components)
+	if startupOrder == nil {
+		startupOrder = [][]string{}
+	}
+
 	return &ApplicationDetails{
-		Application: &Application{
-			Name: nsName,
-		},
+		Application: BuildApplicationFromNamespace(namespace),
 		Metrics: MetricHistories{
 			CPU:    applicationMetric.CPU,
 			Memory: applicationMetric.Memory,
 		},
-		IstioMetricHistories: istioMetricHistories,
-		Status:               string(namespace.Status.Phase),
+		IstioMetricHistories:                   istioMetricHistories,
+		Status:                                 string(namespace.Status.Phase),
+		StartupOrder:                           startupOrder,
+		ComponentsInDependencyLoop:             componentsInLoop,
+		ComponentsBlockedByMissingDependencies: componentsBlocked,
 	}, nil
 }
 
@@ -33,6 +33,35 @@ func (resourceManager *ResourceManager) GetComponent(namespace, name string) (*v
 	return component, nil
 }
 
+// ApplyComponent overwrites the spec of an existing component, annotations of the given component are merged.
+// The component is updated as a whole, so list and map entries removed from the spec are removed as well.
+// The update is rejected with a conflict if the component is changed after the resourceVersion of the given
+// component, or after it's fetched here if the given component doesn't have one.
+func (resourceManager *ResourceManager) ApplyComponent(component *v1alpha1.Component) error {
+	fetched, err := resourceManager.GetComponent(component.Namespace, component.Name)
+
+	if err != nil {
+		return err
+	}
+
+	copied := fetched.DeepCopy()
+	copied.Spec = component.Spec
+
+	if component.ResourceVersion != "" {
+		copied.ResourceVersion = component.ResourceVersion
+	}
+
+	for k, v := range component.Annotations {
+		if copied.Annotations == nil {
+			copied.Annotations = make(map[string]string)
+		}
+
+		copied.Annotations[k] = v
+	}
+
+	return resourceManager.Update(copied)
+}
+
 func (resourceManager *ResourceManager) GetComponentListChannel(namespaces string, listOptions metaV1.ListOptions) *ComponentListChannel {
 	channel := &ComponentListChannel{
 		List:  make(chan []v1alpha1.Component, 1),
@@ -102,6 +131,9 @@ type ComponentDetails struct {
 	Services             []ServiceStatus       `json:"services"`
 	Pods                 []PodStatus           `json:"pods"`
 	Jobs                 []JobStatus           `json:"jobs,omitempty"`
+
+	// rollout, replica and health state reported by the component controller
+	Status v1alpha1.ComponentStatus `json:"status"`
 }
 
 func (resourceManager *ResourceManager) BuildComponentDetails(
@@ -203,6 +235,7 @@ func (resourceManager *ResourceManager) BuildComponentDetails(
 		},
 		IstioMetricHistories: istioMetricRst,
 		Pods:                 podsStatus,
+		Status:               component.Status,
 	}
 
 	if component.Spec.WorkloadType == v1alpha1.WorkloadTypeCronjob {
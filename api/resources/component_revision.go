@@ -0,0 +1,170 @@
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/kalmhq/kalm/controller/controllers"
+	appsV1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type ComponentRevision struct {
+	Revision          int64                   `json:"revision"`
+	Current           bool                    `json:"current"`
+	Image             string                  `json:"image"`
+	ChangedBy         string                  `json:"changedBy,omitempty"`
+	AccessToken       string                  `json:"accessToken,omitempty"`
+	ChangeCause       string                  `json:"changeCause,omitempty"`
+	CreationTimestamp metaV1.Time             `json:"creationTimestamp"`
+	Spec              *v1alpha1.ComponentSpec `json:"spec"`
+}
+
+type ComponentRevisionChange struct {
+	// e.g. .image, .env[0].value
+	Path string      `json:"path"`
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+func BuildComponentRevisionResponse(revision *appsV1.ControllerRevision, currentRevision int64) (*ComponentRevision, error) {
+	spec, err := controllers.DecodeComponentRevision(revision)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &ComponentRevision{
+		Revision:          revision.Revision,
+		Current:           revision.Revision == currentRevision,
+		Image:             spec.Image,
+		ChangedBy:         revision.Annotations[v1alpha1.AnnoChangedBy],
+		AccessToken:       revision.Annotations[v1alpha1.AnnoChangedByAccessToken],
+		ChangeCause:       revision.Annotations[v1alpha1.AnnoChangeCause],
+		CreationTimestamp: revision.CreationTimestamp,
+		Spec:              spec,
+	}, nil
+}
+
+// GetComponentRevisions returns the revision history of a component, the latest first.
+func (resourceManager *ResourceManager) GetComponentRevisions(namespace, componentName string) ([]appsV1.ControllerRevision, error) {
+	var revisionList appsV1.ControllerRevisionList
+
+	if err := resourceManager.List(
+		&revisionList,
+		client.InNamespace(namespace),
+		client.MatchingLabels{controllers.KalmLabelComponentRevisionKey: componentName},
+	); err != nil {
+		return nil, err
+	}
+
+	revisions := revisionList.Items
+	controllers.SortComponentRevisions(revisions)
+
+	for i, j := 0, len(revisions)-1; i < j; i, j = i+1, j-1 {
+		revisions[i], revisions[j] = revisions[j], revisions[i]
+	}
+
+	return revisions, nil
+}
+
+func (resourceManager *ResourceManager) GetComponentRevision(namespace, componentName string, revision int64) (*appsV1.ControllerRevision, error) {
+	var controllerRevision appsV1.ControllerRevision
+
+	if err := resourceManager.Get(namespace, controllers.GetComponentRevisionName(componentName, revision), &controllerRevision); err != nil {
+		return nil, err
+	}
+
+	if controllerRevision.Labels[controllers.KalmLabelComponentRevisionKey] != componentName {
+		return nil, errors.NewNotFound(appsV1.Resource("controllerrevisions"), controllerRevision.Name)
+	}
+
+	return &controllerRevision, nil
+}
+
+// DiffComponentSpecs returns the changed fields between two specs, sorted by path.
+func DiffComponentSpecs(from, to *v1alpha1.ComponentSpec) ([]ComponentRevisionChange, error) {
+	fromValue, err := toJSONValue(from)
+	if err != nil {
+		return nil, err
+	}
+
+	toValue, err := toJSONValue(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := diffJSONValues("", fromValue, toValue, []ComponentRevisionChange{})
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes, nil
+}
+
+func toJSONValue(obj interface{}) (interface{}, error) {
+	bts, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	err = json.Unmarshal(bts, &value)
+
+	return value, err
+}
+
+// Objects are compared field by field and arrays item by item, anything else as a whole.
+func diffJSONValues(path string, from, to interface{}, changes []ComponentRevisionChange) []ComponentRevisionChange {
+	if reflect.DeepEqual(from, to) {
+		return changes
+	}
+
+	fromMap, fromIsMap := from.(map[string]interface{})
+	toMap, toIsMap := to.(map[string]interface{})
+
+	if fromIsMap && toIsMap {
+		keys := make(map[string]bool)
+		for k := range fromMap {
+			keys[k] = true
+		}
+		for k := range toMap {
+			keys[k] = true
+		}
+
+		for k := range keys {
+			changes = diffJSONValues(path+"."+k, fromMap[k], toMap[k], changes)
+		}
+
+		return changes
+	}
+
+	fromSlice, fromIsSlice := from.([]interface{})
+	toSlice, toIsSlice := to.([]interface{})
+
+	if fromIsSlice && toIsSlice {
+		for i := 0; i < len(fromSlice) || i < len(toSlice); i++ {
+			var fromItem, toItem interface{}
+
+			if i < len(fromSlice) {
+				fromItem = fromSlice[i]
+			}
+
+			if i < len(toSlice) {
+				toItem = toSlice[i]
+			}
+
+			changes = diffJSONValues(fmt.Sprintf("%s[%d]", path, i), fromItem, toItem, changes)
+		}
+
+		return changes
+	}
+
+	return append(changes, ComponentRevisionChange{Path: path, From: from, To: to})
+}
@@ -1,20 +1,39 @@
 package resources
 
-import "github.com/kalmhq/kalm/controller/api/v1alpha1"
+import (
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 type Domain struct {
 	Name       string `json:"name"`
 	Domain     string `json:"domain"`
 	RecordType string `json:"recordType"`
 	Target     string `json:"target"`
+
+	// users set a TXT record with the name and the token, or a CNAME to target, to verify the domain
+	VerificationTXTRecordName string                            `json:"verificationTXTRecordName,omitempty"`
+	VerificationToken         string                            `json:"verificationToken,omitempty"`
+	VerificationStatus        v1alpha1.DomainVerificationStatus `json:"verificationStatus,omitempty"`
+	VerificationMessage       string                            `json:"verificationMessage,omitempty"`
+	LastCheckedAt             *metav1.Time                      `json:"lastCheckedAt,omitempty"`
+	VerifiedAt                *metav1.Time                      `json:"verifiedAt,omitempty"`
+	FailedAt                  *metav1.Time                      `json:"failedAt,omitempty"`
 }
 
 func WrapDomainAsResp(d v1alpha1.Domain) Domain {
 	return Domain{
-		Name:       d.Name,
-		Domain:     d.Spec.Domain,
-		RecordType: string(d.Spec.DNSType),
-		Target:     d.Spec.DNSTarget,
+		Name:                      d.Name,
+		Domain:                    d.Spec.Domain,
+		RecordType:                string(d.Spec.DNSType),
+		Target:                    d.Spec.DNSTarget,
+		VerificationTXTRecordName: d.VerificationTXTRecordName(),
+		VerificationToken:         d.Status.VerificationToken,
+		VerificationStatus:        d.Status.VerificationStatus,
+		VerificationMessage:       d.Status.VerificationMessage,
+		LastCheckedAt:             d.Status.LastCheckedAt,
+		VerifiedAt:                d.Status.VerifiedAt,
+		FailedAt:                  d.Status.FailedAt,
 	}
 }
 
@@ -27,6 +27,9 @@ type HttpsCert struct {
 
 	HttpsCertIssuer string   `json:"httpsCertIssuer,omitempty"`
 	Domains         []string `json:"domains,omitempty"`
+
+	// PEM encoded CA bundle to verify client certificates, enables mutual TLS on the domains
+	ClientCACert string `json:"clientCACert,omitempty"`
 }
 
 type HttpsCertResp struct {
@@ -36,6 +39,8 @@ type HttpsCertResp struct {
 	IsSignedByPublicTrustedCA         bool              `json:"isSignedByTrustedCA,omitempty"`
 	ExpireTimestamp                   int64             `json:"expireTimestamp,omitempty"`
 	WildcardCertDNSChallengeDomainMap map[string]string `json:"wildcardCertDNSChallengeDomainMap,omitempty"`
+	ExpiringSoon                      bool              `json:"expiringSoon,omitempty"`
+	RenewalFailing                    bool              `json:"renewalFailing,omitempty"`
 }
 
 var ReasonForNoReadyConditions = "no feedback on cert status yet"
@@ -67,6 +72,7 @@ func BuildHttpsCertResponse(httpsCert *v1alpha1.HttpsCert) *HttpsCertResp {
 			Name:          httpsCert.Name,
 			IsSelfManaged: httpsCert.Spec.IsSelfManaged,
 			Domains:       httpsCert.Spec.Domains,
+			ClientCACert:  httpsCert.Spec.ClientCACert,
 		},
 		Ready:  ready,
 		Reason: reason,
@@ -88,6 +94,19 @@ func BuildHttpsCertResponse(httpsCert *v1alpha1.HttpsCert) *HttpsCertResp {
 
 	resp.WildcardCertDNSChallengeDomainMap = httpsCert.Status.WildcardCertDNSChallengeDomainMap
 
+	for _, cond := range httpsCert.Status.Conditions {
+		if cond.Status != coreV1.ConditionTrue {
+			continue
+		}
+
+		switch cond.Type {
+		case v1alpha1.HttpsCertConditionExpiringSoon:
+			resp.ExpiringSoon = true
+		case v1alpha1.HttpsCertConditionRenewalFailing:
+			resp.RenewalFailing = true
+		}
+	}
+
 	return &resp
 }
 
@@ -118,6 +137,25 @@ func (resourceManager *ResourceManager) GetHttpsCerts(options ...client.ListOpti
 	return httpsCerts, nil
 }
 
+// GetExpiringHttpsCerts returns the certs expiring soon or failing to renew
+func (resourceManager *ResourceManager) GetExpiringHttpsCerts() ([]*HttpsCertResp, error) {
+	httpsCerts, err := resourceManager.GetHttpsCerts()
+
+	if err != nil {
+		return nil, err
+	}
+
+	rst := []*HttpsCertResp{}
+
+	for _, cert := range httpsCerts {
+		if cert.ExpiringSoon || cert.RenewalFailing {
+			rst = append(rst, cert)
+		}
+	}
+
+	return rst, nil
+}
+
 func (resourceManager *ResourceManager) CreateAutoManagedHttpsCert(cert *HttpsCert) (*HttpsCertResp, error) {
 
 	// by default, cert use our default http01Issuer
@@ -152,6 +190,7 @@ func (resourceManager *ResourceManager) CreateAutoManagedHttpsCert(cert *HttpsCe
 		Spec: v1alpha1.HttpsCertSpec{
 			HttpsCertIssuer: cert.HttpsCertIssuer,
 			Domains:         cert.Domains,
+			ClientCACert:    cert.ClientCACert,
 		},
 	}
 
@@ -191,6 +230,7 @@ func (resourceManager *ResourceManager) UpdateAutoManagedCert(cert *HttpsCert) (
 
 	res.Spec.Domains = cert.Domains
 	res.Spec.HttpsCertIssuer = cert.HttpsCertIssuer
+	res.Spec.ClientCACert = cert.ClientCACert
 
 	err = resourceManager.Update(&res)
 	if err != nil {
@@ -244,17 +284,18 @@ func (resourceManager *ResourceManager) UpdateSelfManagedCert(cert *HttpsCert) (
 	go func() {
 		defer wg2.Done()
 
-		// update domains
+		// update domains and client CA
 		if err2 = resourceManager.Get("", cert.Name, &res); err2 != nil {
 			return
 		}
 
-		if strings.Join(res.Spec.Domains, ",") == strings.Join(domains, ",") {
+		if strings.Join(res.Spec.Domains, ",") == strings.Join(domains, ",") && res.Spec.ClientCACert == cert.ClientCACert {
 			return
 		}
 
 		// ensure domains updated
 		res.Spec.Domains = x509Cert.DNSNames
+		res.Spec.ClientCACert = cert.ClientCACert
 		err2 = resourceManager.Update(&res)
 	}()
 
@@ -326,6 +367,7 @@ func (resourceManager *ResourceManager) CreateSelfManagedHttpsCert(cert *HttpsCe
 			IsSelfManaged:             true,
 			SelfManagedCertSecretName: certSecretName,
 			Domains:                   domains,
+			ClientCACert:              cert.ClientCACert,
 		},
 	}
 
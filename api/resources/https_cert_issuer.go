@@ -14,8 +14,29 @@ type HttpsCertIssuer struct {
 	CAForTest      *v1alpha1.CAForTestIssuer `json:"caForTest,omitempty"`
 	ACMECloudFlare *AccountAndSecret         `json:"acmeCloudFlare,omitempty"`
 	HTTP01         *v1alpha1.HTTP01Issuer    `json:"http01,omitempty"`
+	ACME           *ACMEIssuer               `json:"acme,omitempty"`
 }
 
+// ACMEIssuer is a generic ACME issuer, the secrets are saved in the cert-manager namespace
+// and are not shown for list.
+type ACMEIssuer struct {
+	Server        string `json:"server"`
+	Email         string `json:"email,omitempty"`
+	SkipTLSVerify bool   `json:"skipTLSVerify,omitempty"`
+	// external account binding, ignored if the key id is empty
+	EABKeyID        string `json:"eabKeyID,omitempty"`
+	EABHMACKey      string `json:"eabHMACKey,omitempty"`
+	EABKeyAlgorithm string `json:"eabKeyAlgorithm,omitempty"`
+	// http01 or dns01, dns01 challenges are solved with the api of the DNSProvider
+	Solver      string `json:"solver"`
+	DNSProvider string `json:"dnsProvider,omitempty"`
+}
+
+const (
+	ACMESolverHTTP01 = "http01"
+	ACMESolverDNS01  = "dns01"
+)
+
 type AccountAndSecret struct {
 	Account string `json:"account"`
 	Secret  string `json:"secret"`
@@ -48,6 +69,10 @@ func (resourceManager *ResourceManager) GetHttpsCertIssuerList() ([]HttpsCertIss
 			issuer.HTTP01 = ele.Spec.HTTP01
 		}
 
+		if ele.Spec.ACME != nil {
+			issuer.ACME = buildACMEIssuerFromResource(ele.Spec.ACME)
+		}
+
 		rst = append(rst, issuer)
 	}
 
@@ -58,6 +83,77 @@ func GenerateSecretNameForACME(issuer HttpsCertIssuer) string {
 	return "kalm-sec-acme-" + issuer.Name
 }
 
+func GenerateSecretNameForACMEEAB(issuer HttpsCertIssuer) string {
+	return "kalm-sec-acme-eab-" + issuer.Name
+}
+
+func buildACMEIssuerFromResource(acme *v1alpha1.ACMEIssuer) *ACMEIssuer {
+	issuer := &ACMEIssuer{
+		Server:        acme.Server,
+		Email:         acme.Email,
+		SkipTLSVerify: acme.SkipTLSVerify,
+	}
+
+	if eab := acme.ExternalAccountBinding; eab != nil {
+		issuer.EABKeyID = eab.KeyID
+		issuer.EABHMACKey = "***" //won't show for list
+		issuer.EABKeyAlgorithm = eab.KeyAlgorithm
+	}
+
+	if acme.DNS01 != nil {
+		issuer.Solver = ACMESolverDNS01
+		issuer.DNSProvider = acme.DNS01.DNSProvider
+	} else {
+		issuer.Solver = ACMESolverHTTP01
+	}
+
+	return issuer
+}
+
+// BuildACMEIssuerSpec saves the secrets of the issuer and builds the spec referencing them.
+// Secrets are kept if they are empty in the request, e.g. updating other fields of the issuer.
+func (resourceManager *ResourceManager) BuildACMEIssuerSpec(hcIssuer HttpsCertIssuer) (*v1alpha1.ACMEIssuer, error) {
+	acme := hcIssuer.ACME
+	secNs := controllers.CertManagerNamespace
+
+	spec := &v1alpha1.ACMEIssuer{
+		Server:        acme.Server,
+		Email:         acme.Email,
+		SkipTLSVerify: acme.SkipTLSVerify,
+	}
+
+	if acme.EABKeyID != "" {
+		secName := GenerateSecretNameForACMEEAB(hcIssuer)
+
+		if acme.EABHMACKey != "" {
+			if err := resourceManager.ReconcileSecretKeyForIssuer(secNs, secName, v1alpha1.ACMEExternalAccountBindingSecretKey, acme.EABHMACKey); err != nil {
+				return nil, err
+			}
+		}
+
+		spec.ExternalAccountBinding = &v1alpha1.ACMEExternalAccountBinding{
+			KeyID:         acme.EABKeyID,
+			KeySecretName: secName,
+			KeyAlgorithm:  acme.EABKeyAlgorithm,
+		}
+	}
+
+	switch acme.Solver {
+	case ACMESolverHTTP01:
+		spec.HTTP01 = &v1alpha1.ACMEHTTP01Solver{}
+	case ACMESolverDNS01:
+		if acme.DNSProvider == "" {
+			return nil, fmt.Errorf("dnsProvider is required for the %s solver", ACMESolverDNS01)
+		}
+
+		spec.DNS01 = &v1alpha1.ACMEDNS01Solver{DNSProvider: acme.DNSProvider}
+	default:
+		return nil, fmt.Errorf("solver should be one of %s and %s", ACMESolverHTTP01, ACMESolverDNS01)
+	}
+
+	return spec, nil
+}
+
 func (resourceManager *ResourceManager) UpdateHttpsCertIssuer(hcIssuer HttpsCertIssuer) (HttpsCertIssuer, error) {
 	var res v1alpha1.HttpsCertIssuer
 
@@ -68,7 +164,8 @@ func (resourceManager *ResourceManager) UpdateHttpsCertIssuer(hcIssuer HttpsCert
 
 	if (res.Spec.CAForTest == nil) != (hcIssuer.CAForTest == nil) ||
 		(res.Spec.ACMECloudFlare == nil) != (hcIssuer.ACMECloudFlare == nil) ||
-		(res.Spec.HTTP01 == nil) != (hcIssuer.HTTP01 == nil) {
+		(res.Spec.HTTP01 == nil) != (hcIssuer.HTTP01 == nil) ||
+		(res.Spec.ACME == nil) != (hcIssuer.ACME == nil) {
 		return HttpsCertIssuer{}, fmt.Errorf("can not change type of HttpsCertIssuer")
 	}
 
@@ -96,6 +193,15 @@ func (resourceManager *ResourceManager) UpdateHttpsCertIssuer(hcIssuer HttpsCert
 		}
 	}
 
+	if hcIssuer.ACME != nil {
+		acme, err := resourceManager.BuildACMEIssuerSpec(hcIssuer)
+		if err != nil {
+			return HttpsCertIssuer{}, err
+		}
+
+		res.Spec.ACME = acme
+	}
+
 	err = resourceManager.Update(&res)
 	if err != nil {
 		return HttpsCertIssuer{}, err
@@ -109,6 +215,10 @@ func (resourceManager *ResourceManager) DeleteHttpsCertIssuer(name string) error
 }
 
 func (resourceManager *ResourceManager) ReconcileSecretForIssuer(secNs, secName string, secret string) error {
+	return resourceManager.ReconcileSecretKeyForIssuer(secNs, secName, "content", secret)
+}
+
+func (resourceManager *ResourceManager) ReconcileSecretKeyForIssuer(secNs, secName, key, secret string) error {
 	expectedSec := coreV1.Secret{
 		ObjectMeta: metaV1.ObjectMeta{
 			Name:      secName,
@@ -118,7 +228,7 @@ func (resourceManager *ResourceManager) ReconcileSecretForIssuer(secNs, secName
 			},
 		},
 		Data: map[string][]byte{
-			"content": []byte(secret),
+			key: []byte(secret),
 		},
 	}
 
@@ -2,8 +2,30 @@ package resources
 
 import (
 	"testing"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
 )
 
 func TestCleanToResName(t *testing.T) {
 
 }
+
+func TestBuildHttpsCertResponseExpiry(t *testing.T) {
+	cert := &v1alpha1.HttpsCert{
+		Status: v1alpha1.HttpsCertStatus{
+			Conditions: []v1alpha1.HttpsCertCondition{
+				{Type: v1alpha1.HttpsCertConditionReady, Status: coreV1.ConditionTrue},
+				{Type: v1alpha1.HttpsCertConditionExpiringSoon, Status: coreV1.ConditionFalse},
+				{Type: v1alpha1.HttpsCertConditionRenewalFailing, Status: coreV1.ConditionTrue},
+			},
+		},
+	}
+
+	resp := BuildHttpsCertResponse(cert)
+
+	assert.Equal(t, string(coreV1.ConditionTrue), resp.Ready)
+	assert.False(t, resp.ExpiringSoon)
+	assert.True(t, resp.RenewalFailing)
+}
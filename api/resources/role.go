@@ -0,0 +1,8 @@
+package resources
+
+import "github.com/kalmhq/kalm/controller/api/v1alpha1"
+
+type Role struct {
+	*v1alpha1.RoleSpec
+	Name string `json:"name" validate:"required"`
+}
@@ -1,7 +1,12 @@
 package resources
 
 import (
+	"sort"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
 	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -47,3 +52,114 @@ func (resourceManager *ResourceManager) GetSecret(ns, secName string) (coreV1.Se
 
 	return sec, nil
 }
+
+// ApplicationSecret is a secret managed through the api, values are write-only.
+type ApplicationSecret struct {
+	Name string `json:"name"`
+
+	// only accepted in requests, values are never returned
+	Data map[string]string `json:"data,omitempty"`
+
+	Keys              []string `json:"keys"`
+	CreationTimestamp int64    `json:"creationTimestamp"`
+}
+
+func BuildApplicationSecretResponse(secret *coreV1.Secret) *ApplicationSecret {
+	keys := make([]string, 0, len(secret.Data))
+
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return &ApplicationSecret{
+		Name:              secret.Name,
+		Keys:              keys,
+		CreationTimestamp: secret.CreationTimestamp.Unix(),
+	}
+}
+
+func (resourceManager *ResourceManager) GetApplicationSecrets(namespace string) ([]*ApplicationSecret, error) {
+	var secretList coreV1.SecretList
+
+	if err := resourceManager.List(
+		&secretList,
+		client.InNamespace(namespace),
+		client.MatchingLabels{v1alpha1.KalmLabelApplicationSecretKey: "true"},
+	); err != nil {
+		return nil, err
+	}
+
+	res := make([]*ApplicationSecret, len(secretList.Items))
+
+	for i := range secretList.Items {
+		res[i] = BuildApplicationSecretResponse(&secretList.Items[i])
+	}
+
+	return res, nil
+}
+
+// getApplicationSecret only returns secrets managed through the api
+func (resourceManager *ResourceManager) getApplicationSecret(namespace, name string) (*coreV1.Secret, error) {
+	var secret coreV1.Secret
+
+	if err := resourceManager.Get(namespace, name, &secret); err != nil {
+		return nil, err
+	}
+
+	if secret.Labels[v1alpha1.KalmLabelApplicationSecretKey] != "true" {
+		return nil, errors.NewNotFound(coreV1.Resource("secrets"), name)
+	}
+
+	return &secret, nil
+}
+
+func (resourceManager *ResourceManager) CreateApplicationSecret(namespace string, secret *ApplicationSecret) (*ApplicationSecret, error) {
+	s := &coreV1.Secret{
+		ObjectMeta: metaV1.ObjectMeta{
+			Namespace: namespace,
+			Name:      secret.Name,
+			Labels: map[string]string{
+				v1alpha1.KalmLabelApplicationSecretKey: "true",
+			},
+		},
+		Type:       coreV1.SecretTypeOpaque,
+		StringData: secret.Data,
+	}
+
+	if err := resourceManager.Create(s); err != nil {
+		return nil, err
+	}
+
+	return BuildApplicationSecretResponse(s), nil
+}
+
+// UpdateApplicationSecret rotates the secret, its data is replaced as a whole.
+// Components referencing the secret are restarted by the controller.
+func (resourceManager *ResourceManager) UpdateApplicationSecret(namespace string, secret *ApplicationSecret) (*ApplicationSecret, error) {
+	s, err := resourceManager.getApplicationSecret(namespace, secret.Name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.Data = nil
+	s.StringData = secret.Data
+
+	if err := resourceManager.Update(s); err != nil {
+		return nil, err
+	}
+
+	return BuildApplicationSecretResponse(s), nil
+}
+
+func (resourceManager *ResourceManager) DeleteApplicationSecret(namespace, name string) error {
+	s, err := resourceManager.getApplicationSecret(namespace, name)
+
+	if err != nil {
+		return err
+	}
+
+	return resourceManager.Delete(s)
+}
@@ -85,6 +85,7 @@ type ProtectedEndpoint struct {
 	Ports                       []uint32 `json:"ports"`
 	Groups                      []string `json:"groups"`
 	AllowToPassIfHasBearerToken bool     `json:"allowToPassIfHasBearerToken,omitempty"`
+	AllowedCertSubjects         []string `json:"allowedCertSubjects,omitempty"`
 }
 
 type SSOConfig struct {
@@ -167,6 +168,7 @@ func ProtectedEndpointCRDToProtectedEndpoint(endpoint *v1alpha1.ProtectedEndpoin
 		Ports:                       endpoint.Spec.Ports,
 		Groups:                      endpoint.Spec.Groups,
 		AllowToPassIfHasBearerToken: endpoint.Spec.AllowToPassIfHasBearerToken,
+		AllowedCertSubjects:         endpoint.Spec.AllowedCertSubjects,
 	}
 
 	// import for frontend
@@ -216,6 +218,7 @@ func (resourceManager *ResourceManager) CreateProtectedEndpoint(ep *ProtectedEnd
 			Ports:                       ep.Ports,
 			Groups:                      ep.Groups,
 			AllowToPassIfHasBearerToken: ep.AllowToPassIfHasBearerToken,
+			AllowedCertSubjects:         ep.AllowedCertSubjects,
 		},
 	}
 
@@ -243,6 +246,7 @@ func (resourceManager *ResourceManager) UpdateProtectedEndpoint(ep *ProtectedEnd
 			Ports:                       ep.Ports,
 			Groups:                      ep.Groups,
 			AllowToPassIfHasBearerToken: ep.AllowToPassIfHasBearerToken,
+			AllowedCertSubjects:         ep.AllowedCertSubjects,
 		},
 	}
 
@@ -0,0 +1,85 @@
+package resources
+
+import (
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type TcpRoute struct {
+	*v1alpha1.TcpRouteSpec `json:",inline"`
+	DestinationsStatus     []v1alpha1.HttpRouteDestinationStatus `json:"destinationsStatus,omitempty"`
+	PortExposed            bool                                  `json:"portExposed"`
+	PortMessage            string                                `json:"portMessage,omitempty"`
+	Name                   string                                `json:"name"`
+}
+
+func (resourceManager *ResourceManager) GetTcpRoute(name string) (*TcpRoute, error) {
+	var route v1alpha1.TcpRoute
+
+	if err := resourceManager.Get("", name, &route); err != nil {
+		return nil, err
+	}
+
+	return BuildTcpRouteFromResource(&route), nil
+}
+
+func (resourceManager *ResourceManager) GetTcpRoutes(listOptions ...client.ListOption) ([]*TcpRoute, error) {
+	var routes v1alpha1.TcpRouteList
+	if err := resourceManager.List(&routes, listOptions...); err != nil {
+		return nil, err
+	}
+
+	res := make([]*TcpRoute, len(routes.Items))
+
+	for i := range routes.Items {
+		res[i] = BuildTcpRouteFromResource(&routes.Items[i])
+	}
+
+	return res, nil
+}
+
+func BuildTcpRouteFromResource(route *v1alpha1.TcpRoute) *TcpRoute {
+	return &TcpRoute{
+		TcpRouteSpec:       &route.Spec,
+		DestinationsStatus: route.Status.DestinationsStatus,
+		PortExposed:        route.Status.PortExposed,
+		PortMessage:        route.Status.PortMessage,
+		Name:               route.Name,
+	}
+}
+
+func (resourceManager *ResourceManager) CreateTcpRoute(routeSpec *TcpRoute) (*TcpRoute, error) {
+	route := &v1alpha1.TcpRoute{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name: routeSpec.Name,
+		},
+		Spec: *routeSpec.TcpRouteSpec,
+	}
+
+	if err := resourceManager.Create(route); err != nil {
+		return nil, err
+	}
+
+	return BuildTcpRouteFromResource(route), nil
+}
+
+func (resourceManager *ResourceManager) UpdateTcpRoute(routeSpec *TcpRoute) (*TcpRoute, error) {
+	route := &v1alpha1.TcpRoute{}
+
+	if err := resourceManager.Get("", routeSpec.Name, route); err != nil {
+		return nil, err
+	}
+
+	route.Spec = *routeSpec.TcpRouteSpec
+
+	if err := resourceManager.Update(route); err != nil {
+		return nil, err
+	}
+
+	return BuildTcpRouteFromResource(route), nil
+}
+
+func (resourceManager *ResourceManager) DeleteTcpRoute(name string) error {
+	return resourceManager.Delete(&v1alpha1.TcpRoute{ObjectMeta: metaV1.ObjectMeta{Name: name}})
+}
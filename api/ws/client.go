@@ -31,6 +31,7 @@ type Client struct {
 	stopWatcher   chan struct{}
 	clientManager client.ClientManager
 	clientInfo    *client.ClientInfo
+	clientIP      string
 	logger        *zap.Logger
 	isWatching    bool
 }
@@ -86,7 +87,7 @@ func (c *Client) read() {
 		_ = json.Unmarshal(messageBytes, &reqMessage)
 
 		if c.clientInfo == nil {
-			clientInfo, err := c.clientManager.GetClientInfoFromToken(reqMessage.Token)
+			clientInfo, err := c.clientManager.GetClientInfoFromToken(reqMessage.Token, c.clientIP)
 
 			if err != nil {
 				log.Error("new config error", zap.Error(err))
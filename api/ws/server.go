@@ -42,6 +42,7 @@ func (h *WsHandler) Serve(c echo.Context) error {
 		done:          make(chan struct{}),
 		stopWatcher:   make(chan struct{}),
 		clientManager: h.clientManager,
+		clientIP:      c.RealIP(),
 		logger:        h.logger,
 	}
 
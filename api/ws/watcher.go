@@ -37,6 +37,7 @@ func StartWatching(c *Client) {
 
 	registerWatchHandler(c, &informerCache, &v1alpha1.Component{}, buildComponentResMessage)
 	registerWatchHandler(c, &informerCache, &v1alpha1.HttpRoute{}, buildHttpRouteResMessage)
+	registerWatchHandler(c, &informerCache, &v1alpha1.TcpRoute{}, buildTcpRouteResMessage)
 	registerWatchHandler(c, &informerCache, &v1alpha1.HttpsCert{}, buildHttpsCertResMessage)
 	registerWatchHandler(c, &informerCache, &v1alpha1.DockerRegistry{}, buildRegistryResMessage)
 	registerWatchHandler(c, &informerCache, &v1alpha1.SingleSignOnConfig{}, buildSSOConfigResMessage)
@@ -260,6 +261,25 @@ func buildHttpRouteResMessage(c *Client, action string, objWatched interface{})
 	}, nil
 }
 
+func buildTcpRouteResMessage(c *Client, action string, objWatched interface{}) (*ResMessage, error) {
+	route, ok := objWatched.(*v1alpha1.TcpRoute)
+	if !ok {
+		return nil, errors.New("convert watch obj to TcpRoute failed")
+	}
+
+	res := resources.BuildTcpRouteFromResource(route)
+	if !c.clientManager.CanOperateTcpRoute(c.clientInfo, "view", res) {
+		log.Info("permission denied", zap.Any("route", route))
+		return nil, nil
+	}
+
+	return &ResMessage{
+		Kind:   "TcpRoute",
+		Action: action,
+		Data:   res,
+	}, nil
+}
+
 func buildNodeResMessage(c *Client, action string, objWatched interface{}) (*ResMessage, error) {
 	node, ok := objWatched.(*corev1.Node)
 
@@ -16,6 +16,14 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -48,9 +56,15 @@ type AccessTokenRule struct {
 type AccessTokenSpec struct {
 	Memo string `json:"memo,omitempty"`
 
-	// The token, the access token name should be sha256 of this token.
-	// +kubebuilder:validation:MinLength=64
-	Token string `json:"token"`
+	// Deprecated: the plaintext token of tokens created before tokens are hashed,
+	// the access token name should be sha256 of this token. It's replaced by TokenHash by the webhook.
+	Token string `json:"token,omitempty"`
+
+	// Salted hash of the token, see SetToken
+	TokenHash string `json:"tokenHash,omitempty"`
+
+	// The beginning of the token, to help users recognize the token
+	TokenPrefix string `json:"tokenPrefix,omitempty"`
 
 	// Rules of this key
 	// +kubebuilder:validation:MinItems=1
@@ -62,12 +76,31 @@ type AccessTokenSpec struct {
 
 	// Expire time of this key. Infinity if blank
 	ExpiredAt *metav1.Time `json:"expiredAt,omitempty"`
+
+	// IPs or CIDRs the key can be used from. Any IP if blank
+	AllowedIPs []string `json:"allowedIPs,omitempty"`
+
+	// Limit of requests made with this key. Unlimited if blank
+	Quota *AccessTokenQuota `json:"quota,omitempty"`
+
+	// Name of the access token this key is rotated from
+	RotatedFrom string `json:"rotatedFrom,omitempty"`
+}
+
+type AccessTokenQuota struct {
+	// Max requests in each period
+	// +kubebuilder:validation:Minimum=1
+	Requests int `json:"requests"`
+
+	// +kubebuilder:validation:Minimum=1
+	PeriodSeconds int `json:"periodSeconds"`
 }
 
 // AccessTokenStatus defines the observed state of AccessTokeny
 type AccessTokenStatus struct {
-	LastUsedAt int `json:"lastUsedAt"`
-	UsedCount  int `json:"usedCount"`
+	LastUsedAt int    `json:"lastUsedAt"`
+	UsedCount  int    `json:"usedCount"`
+	LastUsedIP string `json:"lastUsedIP,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -95,6 +128,148 @@ type AccessTokenList struct {
 	Items           []AccessToken `json:"items"`
 }
 
+const (
+	AccessTokenPrefix = "kalm_"
+
+	accessTokenNameLength   = 16
+	accessTokenSecretLength = 48
+
+	// prefix length of tokens created before the name is embedded in the token
+	legacyAccessTokenPrefixLength = 8
+
+	accessTokenHashAlgorithm = "sha256"
+)
+
+const accessTokenAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// GenerateAccessToken returns a new token and the name of the access token.
+// The name is embedded in the token, so the access token can be found without storing the token.
+func GenerateAccessToken() (token string, name string) {
+	name = randomAccessTokenString(accessTokenNameLength)
+	token = AccessTokenPrefix + name + "_" + randomAccessTokenString(accessTokenSecretLength)
+	return token, name
+}
+
+// GetAccessTokenNameFromToken returns the access token name of both generated tokens
+// and tokens created before, which are named with the sha256 of the token.
+func GetAccessTokenNameFromToken(token string) string {
+	if name, ok := parseAccessToken(token); ok {
+		return name
+	}
+
+	tokenHash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(tokenHash[:])
+}
+
+func parseAccessToken(token string) (string, bool) {
+	if !strings.HasPrefix(token, AccessTokenPrefix) {
+		return "", false
+	}
+
+	parts := strings.Split(strings.TrimPrefix(token, AccessTokenPrefix), "_")
+
+	if len(parts) != 2 || len(parts[0]) != accessTokenNameLength || len(parts[1]) != accessTokenSecretLength {
+		return "", false
+	}
+
+	return parts[0], true
+}
+
+// SetToken saves the salted hash and the prefix of the token instead of the token
+func (spec *AccessTokenSpec) SetToken(token string) {
+	salt := make([]byte, 16)
+	_, _ = rand.Read(salt)
+
+	spec.TokenHash = hashAccessToken(token, salt)
+	spec.Token = ""
+
+	if name, ok := parseAccessToken(token); ok {
+		spec.TokenPrefix = AccessTokenPrefix + name
+	} else if len(token) > legacyAccessTokenPrefixLength {
+		spec.TokenPrefix = token[:legacyAccessTokenPrefixLength]
+	} else {
+		spec.TokenPrefix = ""
+	}
+}
+
+// VerifyToken checks the token against the hash, or the plaintext token if it's not hashed yet
+func (spec *AccessTokenSpec) VerifyToken(token string) bool {
+	if spec.TokenHash == "" {
+		return spec.Token != "" && subtle.ConstantTimeCompare([]byte(spec.Token), []byte(token)) == 1
+	}
+
+	parts := strings.Split(spec.TokenHash, "$")
+
+	if len(parts) != 3 || parts[0] != accessTokenHashAlgorithm {
+		return false
+	}
+
+	salt, err := hex.DecodeString(parts[1])
+
+	if err != nil {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(hashAccessToken(token, salt)), []byte(spec.TokenHash)) == 1
+}
+
+// AllowsIP returns whether the key can be used from the ip
+func (spec *AccessTokenSpec) AllowsIP(ip string) bool {
+	if len(spec.AllowedIPs) == 0 {
+		return true
+	}
+
+	parsedIP := net.ParseIP(ip)
+
+	if parsedIP == nil {
+		return false
+	}
+
+	for _, allowed := range spec.AllowedIPs {
+		if _, ipNet, err := net.ParseCIDR(allowed); err == nil {
+			if ipNet.Contains(parsedIP) {
+				return true
+			}
+		} else if allowedIP := net.ParseIP(allowed); allowedIP != nil && allowedIP.Equal(parsedIP) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// the hash is in the format of sha256$<salt>$<hash>
+func hashAccessToken(token string, salt []byte) string {
+	hash := sha256.Sum256(append(append([]byte(nil), salt...), token...))
+	return fmt.Sprintf("%s$%s$%s", accessTokenHashAlgorithm, hex.EncodeToString(salt), hex.EncodeToString(hash[:]))
+}
+
+func randomAccessTokenString(length int) string {
+	res := make([]byte, 0, length)
+	bs := make([]byte, length)
+
+	for len(res) < length {
+		if _, err := rand.Read(bs); err != nil {
+			panic(err)
+		}
+
+		for _, b := range bs {
+			// skip the bytes which make the characters not uniformly distributed
+			if int(b) >= 256-256%len(accessTokenAlphabet) {
+				continue
+			}
+
+			res = append(res, accessTokenAlphabet[int(b)%len(accessTokenAlphabet)])
+
+			if len(res) == length {
+				break
+			}
+		}
+	}
+
+	return string(res)
+}
+
 func init() {
 	SchemeBuilder.Register(&AccessToken{}, &AccessTokenList{})
 }
@@ -16,9 +16,9 @@ limitations under the License.
 package v1alpha1
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"fmt"
+	"net"
+	"regexp"
 
 	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -27,6 +27,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
+var accessTokenHashRegex = regexp.MustCompile(`^sha256\$[0-9a-f]+\$[0-9a-f]{64}$`)
+
 // log is for logging in this package.
 var accesstokenlog = logf.Log.WithName("accesstoken-resource")
 
@@ -43,6 +45,11 @@ var _ webhook.Defaulter = &AccessToken{}
 // Default implements webhook.Defaulter so a webhook will be registered for the type
 func (r *AccessToken) Default() {
 	accesstokenlog.Info("default", "name", r.Name)
+
+	// plaintext tokens are replaced by the hash, unless the name doesn't match, which is reported by the validation
+	if r.Spec.Token != "" && GetAccessTokenNameFromToken(r.Spec.Token) == r.Name {
+		r.Spec.SetToken(r.Spec.Token)
+	}
 }
 
 // +kubebuilder:webhook:verbs=create;update;delete,path=/validate-core-kalm-dev-v1alpha1-accesstoken,mutating=false,failurePolicy=fail,groups=core.kalm.dev,resources=accesstokens,versions=v1alpha1,name=vaccesstoken.kb.io
@@ -75,7 +82,12 @@ func (r *AccessToken) ValidateUpdate(old runtime.Object) error {
 	if oldAccessToken, ok := old.(*AccessToken); !ok {
 		return fmt.Errorf("old object is not an access token")
 	} else {
-		if r.Spec.Token != oldAccessToken.Spec.Token {
+		// the plaintext token can only be replaced by its hash
+		if oldAccessToken.Spec.TokenHash == "" {
+			if r.Spec.Token != oldAccessToken.Spec.Token && !r.Spec.VerifyToken(oldAccessToken.Spec.Token) {
+				return fmt.Errorf("Can't modify token")
+			}
+		} else if r.Spec.Token != "" || r.Spec.TokenHash != oldAccessToken.Spec.TokenHash {
 			return fmt.Errorf("Can't modify token")
 		}
 	}
@@ -83,11 +95,6 @@ func (r *AccessToken) ValidateUpdate(old runtime.Object) error {
 	return r.validate()
 }
 
-func GetAccessTokenNameFromToken(token string) string {
-	tokenHash := sha256.Sum256([]byte(token))
-	return hex.EncodeToString(tokenHash[:])
-}
-
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
 func (r *AccessToken) ValidateDelete() error {
 	accesstokenlog.Info("validate delete", "name", r.Name)
@@ -98,15 +105,47 @@ func (r *AccessToken) ValidateDelete() error {
 func (r *AccessToken) validate() error {
 	var rst KalmValidateErrorList
 
-	expectedName := GetAccessTokenNameFromToken(r.Spec.Token)
+	if r.Spec.Token != "" {
+		expectedName := GetAccessTokenNameFromToken(r.Spec.Token)
 
-	if expectedName != r.Name {
+		if expectedName != r.Name {
+			rst = append(rst, KalmValidateError{
+				Err:  fmt.Sprintf("name and token hash are not matched. Expect name: %s, but got %s", expectedName, r.Name),
+				Path: "spec.token",
+			})
+		}
+	} else if !accessTokenHashRegex.MatchString(r.Spec.TokenHash) {
 		rst = append(rst, KalmValidateError{
-			Err:  fmt.Sprintf("name and token hash are not matched. Expect name: %s, but got %s", expectedName, r.Name),
-			Path: "spec.token",
+			Err:  "token hash is required and should be in the format of sha256$<salt>$<hash>",
+			Path: "spec.tokenHash",
 		})
 	}
 
+	for i, allowed := range r.Spec.AllowedIPs {
+		if _, _, err := net.ParseCIDR(allowed); err != nil && net.ParseIP(allowed) == nil {
+			rst = append(rst, KalmValidateError{
+				Err:  fmt.Sprintf("invalid ip or cidr: %s", allowed),
+				Path: fmt.Sprintf("spec.allowedIPs[%d]", i),
+			})
+		}
+	}
+
+	if r.Spec.Quota != nil {
+		if r.Spec.Quota.Requests < 1 {
+			rst = append(rst, KalmValidateError{
+				Err:  "requests of quota should be at least 1",
+				Path: "spec.quota.requests",
+			})
+		}
+
+		if r.Spec.Quota.PeriodSeconds < 1 {
+			rst = append(rst, KalmValidateError{
+				Err:  "period of quota should be at least 1 second",
+				Path: "spec.quota.periodSeconds",
+			})
+		}
+	}
+
 	for i, rule := range r.Spec.Rules {
 		if rule.Namespace != "*" {
 			errs := apimachineryvalidation.ValidateNamespaceName(rule.Namespace, false)
@@ -3,9 +3,11 @@ package v1alpha1
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"strings"
+	"testing"
+
 	"github.com/stretchr/testify/assert"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"testing"
 )
 
 func TestAccessTokenValidateNameAndToken(t *testing.T) {
@@ -63,3 +65,102 @@ func TestAccessTokenValidateRules(t *testing.T) {
 
 	assert.Nil(t, key.validate())
 }
+
+func TestGenerateAccessToken(t *testing.T) {
+	token, name := GenerateAccessToken()
+
+	assert.True(t, strings.HasPrefix(token, AccessTokenPrefix+name+"_"))
+	assert.Equal(t, name, GetAccessTokenNameFromToken(token))
+
+	legacyToken := "abcdefghabcdefghabcdefghabcdefghabcdefghabcdefghabcdefghabcdefgh"
+	tokenHash := sha256.Sum256([]byte(legacyToken))
+	assert.Equal(t, hex.EncodeToString(tokenHash[:]), GetAccessTokenNameFromToken(legacyToken))
+}
+
+func TestAccessTokenSetToken(t *testing.T) {
+	token, name := GenerateAccessToken()
+
+	key := AccessToken{
+		ObjectMeta: ctrl.ObjectMeta{
+			Name: name,
+		},
+	}
+
+	key.Spec.SetToken(token)
+
+	assert.Empty(t, key.Spec.Token)
+	assert.Equal(t, AccessTokenPrefix+name, key.Spec.TokenPrefix)
+	assert.True(t, key.Spec.VerifyToken(token))
+	assert.False(t, key.Spec.VerifyToken(token+"a"))
+	assert.Nil(t, key.validate())
+
+	// the same token is hashed with different salts
+	another := key.Spec.DeepCopy()
+	another.SetToken(token)
+	assert.NotEqual(t, key.Spec.TokenHash, another.TokenHash)
+}
+
+func TestAccessTokenDefaultHashesPlaintextToken(t *testing.T) {
+	token := "abcdefghabcdefghabcdefghabcdefghabcdefghabcdefghabcdefghabcdefgh"
+
+	old := AccessToken{
+		ObjectMeta: ctrl.ObjectMeta{
+			Name: GetAccessTokenNameFromToken(token),
+		},
+		Spec: AccessTokenSpec{
+			Token:   token,
+			Creator: "foo",
+		},
+	}
+
+	key := old.DeepCopy()
+	key.Default()
+
+	assert.Empty(t, key.Spec.Token)
+	assert.Equal(t, "abcdefgh", key.Spec.TokenPrefix)
+	assert.True(t, key.Spec.VerifyToken(token))
+	assert.Nil(t, key.ValidateUpdate(&old))
+
+	// the hash can't be replaced
+	updated := key.DeepCopy()
+	updated.Spec.SetToken("another")
+	assert.NotNil(t, updated.ValidateUpdate(key))
+
+	// tokens with unmatched name are left to the validation
+	key = old.DeepCopy()
+	key.Name = "fake-name"
+	key.Default()
+	assert.Equal(t, token, key.Spec.Token)
+	assert.Contains(t, key.validate().Error(), "name and token hash are not matched")
+}
+
+func TestAccessTokenAllowsIP(t *testing.T) {
+	spec := AccessTokenSpec{}
+	assert.True(t, spec.AllowsIP("1.2.3.4"))
+
+	spec.AllowedIPs = []string{"10.0.0.0/8", "1.2.3.4"}
+	assert.True(t, spec.AllowsIP("10.1.2.3"))
+	assert.True(t, spec.AllowsIP("1.2.3.4"))
+	assert.False(t, spec.AllowsIP("1.2.3.5"))
+	assert.False(t, spec.AllowsIP("invalid"))
+}
+
+func TestAccessTokenValidateAllowedIPsAndQuota(t *testing.T) {
+	token, name := GenerateAccessToken()
+
+	key := AccessToken{
+		ObjectMeta: ctrl.ObjectMeta{
+			Name: name,
+		},
+		Spec: AccessTokenSpec{
+			AllowedIPs: []string{"10.0.0.0/8", "1.2.3"},
+			Quota:      &AccessTokenQuota{Requests: 0, PeriodSeconds: 60},
+		},
+	}
+
+	key.Spec.SetToken(token)
+
+	err := key.validate()
+	assert.Contains(t, err.Error(), "invalid ip or cidr: 1.2.3")
+	assert.Contains(t, err.Error(), "requests of quota should be at least 1")
+}
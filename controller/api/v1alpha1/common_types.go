@@ -1,6 +1,8 @@
 package v1alpha1
 
 import (
+	"strings"
+
 	rbacV1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
@@ -16,6 +18,8 @@ const (
 	EnvVarTypeLinked   EnvVarType = "linked"
 	EnvVarTypeFieldRef EnvVarType = "fieldref"
 	EnvVarTypeBuiltin  EnvVarType = "builtin"
+	// value is a secret key in the application, in the format of <secretName>/<key>
+	EnvVarTypeSecret EnvVarType = "secret"
 
 	EnvVarBuiltinHost      string = "host"
 	EnvVarBuiltinPodName   string = "podName"
@@ -44,7 +48,7 @@ type EnvVar struct {
 
 	Value string `json:"value,omitempty"`
 
-	// +kubebuilder:validation:Enum=static;external;linked;fieldref;builtin
+	// +kubebuilder:validation:Enum=static;external;linked;fieldref;builtin;secret
 	Type EnvVarType `json:"type,omitempty"`
 
 	Prefix string `json:"prefix,omitempty"`
@@ -52,6 +56,17 @@ type EnvVar struct {
 	Suffix string `json:"suffix,omitempty"`
 }
 
+// ParseSecretKeyRef splits a reference in the format of <secretName>/<key>
+func ParseSecretKeyRef(ref string) (secretName, key string, ok bool) {
+	parts := strings.Split(ref, "/")
+
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+
+	return parts[0], parts[1], true
+}
+
 type Port struct {
 	// +kubebuilder:validation:Maximum=65535
 	// +kubebuilder:validation:Minimum=1
@@ -64,6 +79,61 @@ type Port struct {
 
 	// +kubebuilder:validation:Enum=http;https;http2;grpc;grpc-web;tcp;udp;unknown
 	Protocol PortProtocol `json:"protocol"`
+
+	// +optional
+	LoadBalancer *PortLoadBalancer `json:"loadBalancer,omitempty"`
+}
+
+func (p PortProtocol) IsHTTPFamily() bool {
+	switch p {
+	case PortProtocolHTTP, PortProtocolHTTPS, PortProtocolHTTP2, PortProtocolGRPC, PortProtocolGRPCWEB:
+		return true
+	}
+
+	return false
+}
+
+// +kubebuilder:validation:Enum=roundRobin;leastRequest;consistentHash
+type LoadBalancerPolicy string
+
+const (
+	LoadBalancerPolicyRoundRobin     LoadBalancerPolicy = "roundRobin"
+	LoadBalancerPolicyLeastRequest   LoadBalancerPolicy = "leastRequest"
+	LoadBalancerPolicyConsistentHash LoadBalancerPolicy = "consistentHash"
+)
+
+// +kubebuilder:validation:Enum=cookie;header;sourceIP
+type ConsistentHashKey string
+
+const (
+	ConsistentHashKeyCookie   ConsistentHashKey = "cookie"
+	ConsistentHashKeyHeader   ConsistentHashKey = "header"
+	ConsistentHashKeySourceIP ConsistentHashKey = "sourceIP"
+)
+
+// PortLoadBalancer is rendered into the port level traffic policy of the DestinationRule of the component.
+// Ports without a load balancer use leastRequest.
+type PortLoadBalancer struct {
+	Policy LoadBalancerPolicy `json:"policy"`
+
+	// required by the consistentHash policy, which is only available for http, https, http2, grpc and grpc-web ports
+	// +optional
+	HashKey ConsistentHashKey `json:"hashKey,omitempty"`
+
+	// required by the cookie hash key
+	// +optional
+	CookieName string `json:"cookieName,omitempty"`
+
+	// the cookie is generated by the proxy when it's missing in the request,
+	// so that the following requests of the client stick to the same pod.
+	// It's a session cookie if the ttl is not set.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	CookieTTLSeconds int64 `json:"cookieTTLSeconds,omitempty"`
+
+	// required by the header hash key
+	// +optional
+	HeaderName string `json:"headerName,omitempty"`
 }
 
 // +kubebuilder:validation:Enum=emptyDirMemory;emptyDir;pvc;pvcTemplate;hostpath
@@ -0,0 +1,190 @@
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// set along with a spec change by the api, moved into AnnoComponentChanges by the webhook
+	AnnoChangedBy            = "core.kalm.dev/changed-by"
+	AnnoChangedByAccessToken = "core.kalm.dev/changed-by-access-token"
+	AnnoChangeCause          = "core.kalm.dev/change-cause"
+
+	// json of the changes of the spec by generation, the controller records the change of a generation in its revision
+	AnnoComponentChanges = "core.kalm.dev/changes"
+
+	// changes of older generations are dropped
+	maxComponentChanges = 10
+)
+
+var componentChangeAnnotations = []string{AnnoChangedBy, AnnoChangedByAccessToken, AnnoChangeCause}
+
+// ComponentChange is who changed the spec of a component and why
+// +kubebuilder:object:generate=false
+type ComponentChange struct {
+	ChangedBy            string `json:"changedBy,omitempty"`
+	ChangedByAccessToken string `json:"changedByAccessToken,omitempty"`
+	ChangeCause          string `json:"changeCause,omitempty"`
+}
+
+// GetComponentChanges returns the changes recorded in the annotation by generation, an invalid annotation is ignored.
+func GetComponentChanges(component *Component) map[int64]ComponentChange {
+	changes := make(map[int64]ComponentChange)
+
+	if value := component.Annotations[AnnoComponentChanges]; value != "" {
+		if err := json.Unmarshal([]byte(value), &changes); err != nil {
+			componentlog.Error(err, "invalid changes annotation", "ns", component.Namespace, "name", component.Name)
+			return make(map[int64]ComponentChange)
+		}
+	}
+
+	return changes
+}
+
+// GetComponentChange returns nil if the change of the generation is not recorded.
+func GetComponentChange(component *Component, generation int64) *ComponentChange {
+	change, exist := GetComponentChanges(component)[generation]
+	if !exist {
+		return nil
+	}
+
+	return &change
+}
+
+// +kubebuilder:webhook:path=/mutate-core-kalm-dev-v1alpha1-component-change,mutating=true,failurePolicy=fail,groups=core.kalm.dev,resources=components,verbs=create;update,versions=v1alpha1,name=mcomponentchange.kb.io
+
+// componentChangeRecorder records the change annotations at admission by the generation of the spec change,
+// so changes made before the controller reconciles are still attributed correctly.
+// It's not a part of Default(), the generation of a change can't be known without the old object.
+// +kubebuilder:object:generate=false
+type componentChangeRecorder struct {
+	decoder *admission.Decoder
+}
+
+var _ admission.DecoderInjector = &componentChangeRecorder{}
+
+func (h *componentChangeRecorder) InjectDecoder(d *admission.Decoder) error {
+	h.decoder = d
+	return nil
+}
+
+func (h *componentChangeRecorder) Handle(ctx context.Context, req admission.Request) admission.Response {
+	var component Component
+
+	if err := h.decoder.Decode(req, &component); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	var old *Component
+
+	if req.Operation == admissionv1beta1.Update {
+		old = &Component{}
+
+		if err := h.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+
+	if !recordComponentChange(old, &component) {
+		return admission.Allowed("")
+	}
+
+	marshaled, err := json.Marshal(component)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+// recordComponentChange moves the change annotations into the changes, old is nil for a creation.
+// Changes are only kept by the webhook, the ones of the old object are restored if a request drops them.
+// Returns false if the component is not modified.
+func recordComponentChange(old, component *Component) bool {
+	changes := make(map[int64]ComponentChange)
+	if old != nil {
+		changes = GetComponentChanges(old)
+	}
+
+	var change ComponentChange
+	hasChange := false
+
+	for _, key := range componentChangeAnnotations {
+		value, exist := component.Annotations[key]
+		if !exist {
+			continue
+		}
+
+		hasChange = true
+		delete(component.Annotations, key)
+
+		switch key {
+		case AnnoChangedBy:
+			change.ChangedBy = value
+		case AnnoChangedByAccessToken:
+			change.ChangedByAccessToken = value
+		case AnnoChangeCause:
+			change.ChangeCause = value
+		}
+	}
+
+	// the generation is bumped after admission if the spec changes, the defaults are set by the other webhook.
+	if hasChange && (old == nil || !isSameDefaultedSpec(old, component)) {
+		generation := int64(1)
+		if old != nil {
+			generation = old.Generation + 1
+		}
+
+		changes[generation] = change
+	}
+
+	value := ""
+
+	if len(changes) > 0 {
+		generations := make([]int64, 0, len(changes))
+		for generation := range changes {
+			generations = append(generations, generation)
+		}
+
+		sort.Slice(generations, func(i, j int) bool { return generations[i] > generations[j] })
+
+		if len(generations) > maxComponentChanges {
+			for _, generation := range generations[maxComponentChanges:] {
+				delete(changes, generation)
+			}
+		}
+
+		bts, _ := json.Marshal(changes)
+		value = string(bts)
+	}
+
+	if value == component.Annotations[AnnoComponentChanges] {
+		return hasChange
+	}
+
+	if value == "" {
+		delete(component.Annotations, AnnoComponentChanges)
+	} else {
+		if component.Annotations == nil {
+			component.Annotations = make(map[string]string)
+		}
+
+		component.Annotations[AnnoComponentChanges] = value
+	}
+
+	return true
+}
+
+func isSameDefaultedSpec(old, component *Component) bool {
+	defaulted := component.DeepCopy()
+	defaulted.Default()
+
+	return equality.Semantic.DeepEqual(old.Spec, defaulted.Spec)
+}
@@ -27,12 +27,21 @@ const (
 	KalmLabelComponentKey        = "kalm-component"
 	KalmLabelKeyExceedingQuota   = "kalm-exceeding-quota"
 	KalmLabelKeyOriginalReplicas = "kalm-original-replicas"
+
+	// secrets of an application managed through the kalm api
+	KalmLabelApplicationSecretKey = "kalm-application-secret"
+
+	// name of the init container writing pre-injected files, reserved
+	PreInjectedFilesInitContainerName = "inject-files"
 )
 
 type PreInjectFile struct {
-	// the content of the file
-	// +kubebuilder:validation:MinLength=1
-	Content string `json:"content"`
+	// the content of the file, required unless SecretRef is set
+	Content string `json:"content,omitempty"`
+
+	// mount a secret key of the application as the file instead of the content,
+	// in the format of <secretName>/<key>
+	SecretRef string `json:"secretRef,omitempty"`
 
 	// To support binary content, it allows set base64 encoded data into `Content` field
 	// and set this flag to `true`. Binary data will be restored instead of plain string in `Content`.
@@ -44,6 +53,30 @@ type PreInjectFile struct {
 	Readonly bool `json:"readonly,omitempty"`
 
 	Runnable bool `json:"runnable"`
+
+	// By default, changing the content rolls the pods of the component. A hot reload file is updated
+	// in running pods instead, for apps which watch their config files. Its directory is mounted as
+	// a whole and read only, so it can't be shared with other files of the image or component.
+	HotReload bool `json:"hotReload,omitempty"`
+}
+
+// ComponentContainer is an init container or a sidecar of a component.
+// It shares envs, volumes and pre-injected files with the main container.
+type ComponentContainer struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:MinLength=1
+	Image string `json:"image"`
+
+	// same format as the command of the component
+	Command string `json:"command,omitempty"`
+
+	// envs only for this container, they override envs of the component with the same name
+	Env []EnvVar `json:"env,omitempty"`
+
+	// +optional
+	ResourceRequirements *v1.ResourceRequirements `json:"resourceRequirements,omitempty"`
 }
 
 // ComponentSpec defines the desired state of Component
@@ -62,18 +95,36 @@ type ComponentSpec struct {
 
 	Replicas *int32 `json:"replicas,omitempty"`
 
+	// If set, the replicas of the workload are managed by a HorizontalPodAutoscaler and Replicas is ignored.
+	// Only available for server and statefulset workloads.
+	// +optional
+	Autoscaling *AutoscalingSpec `json:"autoscaling,omitempty"`
+
 	NodeSelectorLabels map[string]string `json:"nodeSelectorLabels,omitempty"`
 	PreferNotCoLocated bool              `json:"preferNotCoLocated,omitempty"`
 
+	// names of components in the same application, the workload is held back until they are ready
 	StartAfterComponents []string `json:"startAfterComponents,omitempty"`
 
 	Command string `json:"command,omitempty"`
 
+	// run to completion in order before the main container starts
+	// +optional
+	InitContainers []ComponentContainer `json:"initContainers,omitempty"`
+
+	// run alongside the main container, e.g. log shippers and proxies
+	// +optional
+	Sidecars []ComponentContainer `json:"sidecars,omitempty"`
+
 	// +optional
 	EnableHeadlessService bool `json:"enableHeadlessService,omitempty"`
 
 	Ports []Port `json:"ports,omitempty"`
 
+	// limits the connections to the component and ejects the pods returning consecutive 5xx errors
+	// +optional
+	CircuitBreaker *CircuitBreaker `json:"circuitBreaker,omitempty"`
+
 	// +kubebuilder:validation:Enum=server;cronjob;statefulset;daemonset
 	WorkloadType WorkloadType `json:"workloadType,omitempty"`
 
@@ -103,6 +154,12 @@ type ComponentSpec struct {
 	// +kubebuilder:validation:Enum=Recreate;RollingUpdate
 	RestartStrategy apps1.DeploymentStrategyType `json:"restartStrategy,omitempty"`
 
+	// Progressive delivery of new revisions, only available for server workloads.
+	// If set, a new revision is deployed as a canary workload first and
+	// RestartStrategy only applies when the canary is promoted.
+	// +optional
+	RolloutStrategy *RolloutStrategy `json:"rolloutStrategy,omitempty"`
+
 	// +optional
 	Volumes []Volume `json:"volumes,omitempty"`
 
@@ -113,15 +170,289 @@ type ComponentSpec struct {
 	// This is only meaningful if this component is a cronjob workload.
 	// Controller should immediately trigger a job and set its value to false if it's true.
 	ImmediateTrigger bool `json:"immediateTrigger,omitempty"`
+
+	// How many revisions of the spec are kept for rollback, defaults to 10.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+}
+
+// the pods metric served by a custom metrics adapter from the istio_requests_total prometheus metric
+const IstioRequestsPerSecondMetricName = "istio_requests_per_second"
+
+type AutoscalingSpec struct {
+	// +kubebuilder:validation:Minimum=1
+	MinReplicas int32 `json:"minReplicas"`
+
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// Target average CPU usage of the pods, in percentage of the requested CPU.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+
+	// Target average memory usage of the pods, in percentage of the requested memory.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+
+	// Target average Istio requests per second of the pods.
+	// It requires a custom metrics adapter serving the istio_requests_per_second pods metric.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	TargetRequestsPerSecond *int32 `json:"targetRequestsPerSecond,omitempty"`
+}
+
+type ComponentAutoscalingStatus struct {
+	// replicas of the workload last seen by the autoscaler
+	CurrentReplicas int32 `json:"currentReplicas"`
+
+	// replicas wanted by the autoscaler
+	DesiredReplicas int32 `json:"desiredReplicas"`
+
+	// +optional
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+}
+
+// CircuitBreaker is rendered into the connection pool and outlier detection of the DestinationRule of the component.
+// It is enforced by each envoy proxy calling the component, e.g. the ingress gateway.
+type CircuitBreaker struct {
+	// max connections to all pods of the component from each proxy
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxConnections int32 `json:"maxConnections,omitempty"`
+
+	// max requests waiting for a connection from each proxy, exceeding requests fail with 503
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxPendingRequests int32 `json:"maxPendingRequests,omitempty"`
+
+	// pods are ejected from the load balancing pool after this many consecutive 5xx errors,
+	// outlier detection is disabled if not set
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Consecutive5xxErrors int32 `json:"consecutive5xxErrors,omitempty"`
+
+	// time between ejection analysis sweeps, defaults to 10
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	IntervalSeconds int32 `json:"intervalSeconds,omitempty"`
+
+	// minimum ejection duration, multiplied by the number of times the pod has been ejected, defaults to 30
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	BaseEjectionSeconds int32 `json:"baseEjectionSeconds,omitempty"`
+
+	// max percentage of pods that can be ejected, defaults to 10
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	MaxEjectionPercent int32 `json:"maxEjectionPercent,omitempty"`
+}
+
+func (c *CircuitBreaker) OutlierDetectionEnabled() bool {
+	return c != nil && c.Consecutive5xxErrors > 0
+}
+
+type ComponentOutlierDetectionStatus struct {
+	// pods of the component currently ejected by the ingress gateway
+	EjectedHosts int32 `json:"ejectedHosts"`
+
+	// +optional
+	LastEjectionTime *metav1.Time `json:"lastEjectionTime,omitempty"`
+}
+
+type RolloutStrategyType string
+
+const (
+	// traffic is shifted to the new revision step by step
+	RolloutStrategyCanary RolloutStrategyType = "canary"
+	// the new revision is deployed with full replicas and receives all traffic at once
+	RolloutStrategyBlueGreen RolloutStrategyType = "blueGreen"
+)
+
+const (
+	DefaultRolloutStepIntervalSeconds     = 60
+	DefaultRolloutSuccessRateThreshold    = 95
+	DefaultRolloutProgressDeadlineSeconds = 600
+)
+
+type RolloutStrategy struct {
+	// +kubebuilder:validation:Enum=canary;blueGreen
+	Type RolloutStrategyType `json:"type"`
+
+	// Percentages of traffic sent to the new revision at each step, ascending and ending with 100.
+	// Defaults to [10, 50, 100] for canary and [100] for blueGreen.
+	// +optional
+	Steps []int `json:"steps,omitempty"`
+
+	// How long each step lasts before the success rate of the new revision is checked.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	StepIntervalSeconds int `json:"stepIntervalSeconds,omitempty"`
+
+	// Minimal percentage of non-5xx responses of the new revision to move to the next step.
+	// The rollout is rolled back if the success rate is lower.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	SuccessRateThreshold int `json:"successRateThreshold,omitempty"`
+
+	// The rollout is rolled back if the new revision is not ready within this time.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ProgressDeadlineSeconds int `json:"progressDeadlineSeconds,omitempty"`
+}
+
+// WithDefaults returns a copy of the strategy with all optional fields filled.
+func (s RolloutStrategy) WithDefaults() RolloutStrategy {
+	if len(s.Steps) == 0 {
+		if s.Type == RolloutStrategyBlueGreen {
+			s.Steps = []int{100}
+		} else {
+			s.Steps = []int{10, 50, 100}
+		}
+	}
+
+	if s.StepIntervalSeconds == 0 {
+		s.StepIntervalSeconds = DefaultRolloutStepIntervalSeconds
+	}
+
+	if s.SuccessRateThreshold == 0 {
+		s.SuccessRateThreshold = DefaultRolloutSuccessRateThreshold
+	}
+
+	if s.ProgressDeadlineSeconds == 0 {
+		s.ProgressDeadlineSeconds = DefaultRolloutProgressDeadlineSeconds
+	}
+
+	return s
+}
+
+type RolloutPhase string
+
+const (
+	// the new revision is running as a canary and receives part of the traffic
+	RolloutPhaseProgressing RolloutPhase = "Progressing"
+	// the new revision passed all steps, the stable workload is being updated
+	RolloutPhasePromoting RolloutPhase = "Promoting"
+	// the stable workload runs the new revision
+	RolloutPhasePromoted RolloutPhase = "Promoted"
+	// the new revision failed, traffic is back on the stable workload
+	RolloutPhaseRolledBack RolloutPhase = "RolledBack"
+)
+
+type ComponentRolloutStatus struct {
+	Phase RolloutPhase `json:"phase"`
+
+	// hash of the pod template being rolled out
+	Revision string `json:"revision"`
+
+	// +optional
+	StableImage string `json:"stableImage,omitempty"`
+	// +optional
+	CanaryImage string `json:"canaryImage,omitempty"`
+
+	// index of the current step in RolloutStrategy.Steps
+	CurrentStep int `json:"currentStep"`
+
+	// percentage of traffic currently sent to the new revision
+	CanaryWeight int `json:"canaryWeight"`
+
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+	// +optional
+	StepStartedAt *metav1.Time `json:"stepStartedAt,omitempty"`
+
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// IsInProgress returns true if traffic may still be routed to the canary workload.
+func (s *ComponentRolloutStatus) IsInProgress() bool {
+	return s != nil && (s.Phase == RolloutPhaseProgressing || s.Phase == RolloutPhasePromoting)
+}
+
+type ComponentConditionType string
+
+const (
+	// all desired replicas are updated and ready
+	ComponentConditionReady ComponentConditionType = "Ready"
+	// the workload is rolling out a new revision
+	ComponentConditionProgressing ComponentConditionType = "Progressing"
+	// a plugin, probe or container keeps failing
+	ComponentConditionDegraded ComponentConditionType = "Degraded"
+)
+
+type ComponentCondition struct {
+	// Type of the condition, one of ('Ready', 'Progressing', 'Degraded').
+	Type ComponentConditionType `json:"type"`
+
+	// Status of the condition, one of ('True', 'False', 'Unknown').
+	Status v1.ConditionStatus `json:"status"`
+
+	// Reason is a brief machine readable explanation for the condition's last
+	// transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is a human readable description of the details of the last
+	// transition, complementing reason.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
 }
 
 // ComponentStatus defines the observed state of Component
 type ComponentStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// the generation of the component spec that was most recently reconciled
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// +optional
+	Conditions []ComponentCondition `json:"conditions,omitempty"`
+
+	// +optional
+	DesiredReplicas int32 `json:"desiredReplicas"`
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas"`
+	// +optional
+	UpdatedReplicas int32 `json:"updatedReplicas"`
+
+	// the image of the main container in the last successfully applied workload
+	// +optional
+	LastAppliedImage string `json:"lastAppliedImage,omitempty"`
+
+	// the last plugin error or failing container/probe reason, cleared once the component is ready
+	// +optional
+	LastFailureReason string `json:"lastFailureReason,omitempty"`
+
+	// state of the progressive rollout, only set if the component has a RolloutStrategy
+	// +optional
+	Rollout *ComponentRolloutStatus `json:"rollout,omitempty"`
+
+	// state of the HorizontalPodAutoscaler, only set if the component has autoscaling
+	// +optional
+	Autoscaling *ComponentAutoscalingStatus `json:"autoscaling,omitempty"`
+
+	// pods ejected by outlier detection, only set if the circuit breaker of the component has consecutive5xxErrors
+	// +optional
+	OutlierDetection *ComponentOutlierDetectionStatus `json:"outlierDetection,omitempty"`
+
+	// components in StartAfterComponents which are not ready yet, the workload is held back until they are
+	// +optional
+	WaitingForComponents []string `json:"waitingForComponents,omitempty"`
+
+	// the revision in the history of the component which records the current spec
+	// +optional
+	CurrentRevision int64 `json:"currentRevision,omitempty"`
 }
 
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Workload",type="string",JSONPath=".spec.workloadType"
 // +kubebuilder:printcolumn:name="Image",type="string",JSONPath=".spec.image"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
@@ -147,3 +478,24 @@ type ComponentList struct {
 func init() {
 	SchemeBuilder.Register(&Component{}, &ComponentList{})
 }
+
+func GetComponentCondition(component Component, condType ComponentConditionType) *ComponentCondition {
+	for i := range component.Status.Conditions {
+		if component.Status.Conditions[i].Type == condType {
+			return &component.Status.Conditions[i]
+		}
+	}
+
+	return nil
+}
+
+// IsComponentReady is true only if the status is observed from the latest spec.
+func IsComponentReady(component Component) bool {
+	if component.Status.ObservedGeneration != component.Generation {
+		return false
+	}
+
+	cond := GetComponentCondition(component, ComponentConditionReady)
+
+	return cond != nil && cond.Status == v1.ConditionTrue
+}
@@ -18,6 +18,7 @@ package v1alpha1
 import (
 	"fmt"
 	"math/rand"
+	"path"
 	"strings"
 	"time"
 
@@ -36,6 +37,11 @@ import (
 var componentlog = logf.Log.WithName("component-webhook")
 
 func (r *Component) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(
+		"/mutate-core-kalm-dev-v1alpha1-component-change",
+		&webhook.Admission{Handler: &componentChangeRecorder{}},
+	)
+
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
 		Complete()
@@ -71,6 +77,11 @@ func (r *Component) Default() {
 		}
 	}
 
+	if r.Spec.RolloutStrategy != nil {
+		strategy := r.Spec.RolloutStrategy.WithDefaults()
+		r.Spec.RolloutStrategy = &strategy
+	}
+
 	if r.Spec.TerminationGracePeriodSeconds == nil {
 		x := int64(30)
 		r.Spec.TerminationGracePeriodSeconds = &x
@@ -221,6 +232,12 @@ func (r *Component) validate() KalmValidateErrorList {
 	rst = append(rst, r.validateVolumesOfComponent()...)
 	rst = append(rst, r.validateRunnerPermission()...)
 	rst = append(rst, r.validatePreInjectedFiles()...)
+	rst = append(rst, r.validateRolloutStrategy()...)
+	rst = append(rst, r.validateAutoscaling()...)
+	rst = append(rst, r.validateCircuitBreaker()...)
+	rst = append(rst, r.validatePortLoadBalancers()...)
+	rst = append(rst, r.validateContainers()...)
+	rst = append(rst, r.validateStartAfterComponents()...)
 
 	if len(rst) == 0 {
 		return nil
@@ -398,11 +415,26 @@ func (r *Component) validateEnvVarList() (rst KalmValidateErrorList) {
 	}
 
 	for i, env := range r.Spec.Env {
-		errs := apimachineryval.IsCIdentifier(env.Name)
-		for _, err := range errs {
+		rst = append(rst, validateEnvVar(env, fmt.Sprintf(".spec.env[%d]", i))...)
+	}
+
+	return rst
+}
+
+func validateEnvVar(env EnvVar, path string) (rst KalmValidateErrorList) {
+	errs := apimachineryval.IsCIdentifier(env.Name)
+	for _, err := range errs {
+		rst = append(rst, KalmValidateError{
+			Err:  err,
+			Path: path,
+		})
+	}
+
+	if env.Type == EnvVarTypeSecret {
+		if _, _, ok := ParseSecretKeyRef(env.Value); !ok {
 			rst = append(rst, KalmValidateError{
-				Err:  err,
-				Path: fmt.Sprintf(".spec.env[%d]", i),
+				Err:  "value of secret env should be in the format of <secretName>/<key>",
+				Path: path + ".value",
 			})
 		}
 	}
@@ -411,7 +443,10 @@ func (r *Component) validateEnvVarList() (rst KalmValidateErrorList) {
 }
 
 func (r *Component) validateResRequirement() (rst KalmValidateErrorList) {
-	resRequirement := r.Spec.ResourceRequirements
+	return validateResourceRequirements(r.Spec.ResourceRequirements, "spec.resourceRequirements")
+}
+
+func validateResourceRequirements(resRequirement *v1.ResourceRequirements, fieldPath string) (rst KalmValidateErrorList) {
 	if resRequirement == nil {
 		return nil
 	}
@@ -422,13 +457,13 @@ func (r *Component) validateResRequirement() (rst KalmValidateErrorList) {
 
 		if limit, exist := resRequirement.Limits[resName]; exist {
 
-			fldPath := field.NewPath("spec.resourceRequirements.limits." + string(resName))
+			fldPath := field.NewPath(fieldPath + ".limits." + string(resName))
 			errList := ValidateResourceQuantityValue(limit, fldPath, isIntegerRes)
 			rst = append(rst, toKalmValidateErrors(errList)...)
 		}
 
 		if request, exist := resRequirement.Requests[resName]; exist {
-			fldPath := field.NewPath("spec.resourceRequirements.requests." + string(resName))
+			fldPath := field.NewPath(fieldPath + ".requests." + string(resName))
 			errList := ValidateResourceQuantityValue(request, fldPath, isIntegerRes)
 			rst = append(rst, toKalmValidateErrors(errList)...)
 		}
@@ -437,6 +472,102 @@ func (r *Component) validateResRequirement() (rst KalmValidateErrorList) {
 	return rst
 }
 
+// validateContainers checks init containers and sidecars, their names share
+// the namespace of containers in the pod with the main container.
+func (r *Component) validateContainers() (rst KalmValidateErrorList) {
+	names := map[string]bool{
+		r.Name:                            true,
+		PreInjectedFilesInitContainerName: true,
+	}
+
+	check := func(containers []ComponentContainer, fieldName string) {
+		for i, c := range containers {
+			path := fmt.Sprintf(".spec.%s[%d]", fieldName, i)
+
+			for _, err := range apimachineryval.IsDNS1123Label(c.Name) {
+				rst = append(rst, KalmValidateError{
+					Err:  err,
+					Path: path + ".name",
+				})
+			}
+
+			if names[c.Name] {
+				rst = append(rst, KalmValidateError{
+					Err:  fmt.Sprintf("container name %s is duplicated or reserved", c.Name),
+					Path: path + ".name",
+				})
+			}
+
+			names[c.Name] = true
+
+			for j, env := range c.Env {
+				rst = append(rst, validateEnvVar(env, fmt.Sprintf("%s.env[%d]", path, j))...)
+			}
+
+			rst = append(rst, validateResourceRequirements(c.ResourceRequirements, fmt.Sprintf("spec.%s[%d].resourceRequirements", fieldName, i))...)
+		}
+	}
+
+	check(r.Spec.InitContainers, "initContainers")
+	check(r.Spec.Sidecars, "sidecars")
+
+	return rst
+}
+
+// the directory of a hot reload file is mounted as a whole
+func (r *Component) validateHotReloadFile(idx int, file PreInjectFile) (rst KalmValidateErrorList) {
+	fieldPath := fmt.Sprintf(".spec.preInjectedFiles[%d].hotReload", idx)
+
+	if file.SecretRef != "" {
+		return append(rst, KalmValidateError{
+			Err:  "file from a secret can't be hot reloaded",
+			Path: fieldPath,
+		})
+	}
+
+	dir := path.Dir(file.MountPath)
+	if dir == "/" {
+		return append(rst, KalmValidateError{
+			Err:  "hot reload file can't be in the root directory",
+			Path: fieldPath,
+		})
+	}
+
+	for _, other := range r.Spec.PreInjectedFiles {
+		if !other.HotReload && path.Dir(other.MountPath) == dir {
+			rst = append(rst, KalmValidateError{
+				Err:  fmt.Sprintf("directory %s of hot reload file is shared with file %s", dir, other.MountPath),
+				Path: fieldPath,
+			})
+		}
+	}
+
+	for _, vol := range r.Spec.Volumes {
+		if vol.Path == dir {
+			rst = append(rst, KalmValidateError{
+				Err:  fmt.Sprintf("directory %s of hot reload file is shared with a volume", dir),
+				Path: fieldPath,
+			})
+		}
+	}
+
+	return rst
+}
+
+// loops across components are resolved by the controller, a component depending on itself never starts
+func (r *Component) validateStartAfterComponents() (rst KalmValidateErrorList) {
+	for i, name := range r.Spec.StartAfterComponents {
+		if name == r.Name {
+			rst = append(rst, KalmValidateError{
+				Err:  "component can't start after itself",
+				Path: fmt.Sprintf(".spec.startAfterComponents[%d]", i),
+			})
+		}
+	}
+
+	return rst
+}
+
 func (r *Component) validatePreInjectedFiles() (rst KalmValidateErrorList) {
 	for i, preInjectFile := range r.Spec.PreInjectedFiles {
 		isPrefixOK := strings.HasPrefix(preInjectFile.MountPath, "/")
@@ -446,11 +577,237 @@ func (r *Component) validatePreInjectedFiles() (rst KalmValidateErrorList) {
 				Path: fmt.Sprintf(".spec.preInjectedFiles[%d]", i),
 			})
 		}
+
+		if preInjectFile.HotReload {
+			rst = append(rst, r.validateHotReloadFile(i, preInjectFile)...)
+		}
+
+		if preInjectFile.SecretRef == "" {
+			if preInjectFile.Content == "" {
+				rst = append(rst, KalmValidateError{
+					Err:  "content is required unless secretRef is set",
+					Path: fmt.Sprintf(".spec.preInjectedFiles[%d].content", i),
+				})
+			}
+
+			continue
+		}
+
+		if preInjectFile.Content != "" || preInjectFile.Base64 {
+			rst = append(rst, KalmValidateError{
+				Err:  "content can't be set together with secretRef",
+				Path: fmt.Sprintf(".spec.preInjectedFiles[%d].content", i),
+			})
+		}
+
+		if _, _, ok := ParseSecretKeyRef(preInjectFile.SecretRef); !ok {
+			rst = append(rst, KalmValidateError{
+				Err:  "should be in the format of <secretName>/<key>",
+				Path: fmt.Sprintf(".spec.preInjectedFiles[%d].secretRef", i),
+			})
+		}
 	}
 
 	return rst
 }
 
+func (r *Component) validateRolloutStrategy() (rst KalmValidateErrorList) {
+	strategy := r.Spec.RolloutStrategy
+	if strategy == nil {
+		return nil
+	}
+
+	if r.Spec.WorkloadType != WorkloadTypeServer && r.Spec.WorkloadType != "" {
+		rst = append(rst, KalmValidateError{
+			Err:  "rollout strategy is only supported by server workload",
+			Path: ".spec.rolloutStrategy",
+		})
+	}
+
+	steps := strategy.WithDefaults().Steps
+
+	if strategy.Type == RolloutStrategyBlueGreen && len(steps) != 1 {
+		rst = append(rst, KalmValidateError{
+			Err:  "blueGreen rollout switches all traffic at once, steps should be [100]",
+			Path: ".spec.rolloutStrategy.steps",
+		})
+	}
+
+	for i, step := range steps {
+		if step <= 0 || step > 100 {
+			rst = append(rst, KalmValidateError{
+				Err:  "should be in range (0, 100]",
+				Path: fmt.Sprintf(".spec.rolloutStrategy.steps[%d]", i),
+			})
+		} else if i > 0 && step <= steps[i-1] {
+			rst = append(rst, KalmValidateError{
+				Err:  "should be greater than the previous step",
+				Path: fmt.Sprintf(".spec.rolloutStrategy.steps[%d]", i),
+			})
+		}
+	}
+
+	if steps[len(steps)-1] != 100 {
+		rst = append(rst, KalmValidateError{
+			Err:  "the last step should be 100",
+			Path: ".spec.rolloutStrategy.steps",
+		})
+	}
+
+	return rst
+}
+
+func (r *Component) validateCircuitBreaker() (rst KalmValidateErrorList) {
+	circuitBreaker := r.Spec.CircuitBreaker
+	if circuitBreaker == nil {
+		return nil
+	}
+
+	// the circuit breaker is part of the destination rule, which is only created for components with ports
+	if len(r.Spec.Ports) == 0 {
+		rst = append(rst, KalmValidateError{
+			Err:  "circuit breaker requires at least one port",
+			Path: ".spec.circuitBreaker",
+		})
+	}
+
+	if !circuitBreaker.OutlierDetectionEnabled() &&
+		(circuitBreaker.IntervalSeconds > 0 || circuitBreaker.BaseEjectionSeconds > 0 || circuitBreaker.MaxEjectionPercent > 0) {
+		rst = append(rst, KalmValidateError{
+			Err:  "outlier detection settings require consecutive5xxErrors",
+			Path: ".spec.circuitBreaker.consecutive5xxErrors",
+		})
+	}
+
+	return rst
+}
+
+func (r *Component) validatePortLoadBalancers() (rst KalmValidateErrorList) {
+	for i, port := range r.Spec.Ports {
+		lb := port.LoadBalancer
+		if lb == nil {
+			continue
+		}
+
+		path := fmt.Sprintf(".spec.ports[%d].loadBalancer", i)
+
+		if lb.Policy != LoadBalancerPolicyConsistentHash {
+			if lb.HashKey != "" {
+				rst = append(rst, KalmValidateError{
+					Err:  "hashKey is only available for consistentHash policy",
+					Path: path + ".hashKey",
+				})
+			}
+
+			continue
+		}
+
+		// hash keys are extracted from the http requests by the envoy http connection manager
+		if !port.Protocol.IsHTTPFamily() {
+			rst = append(rst, KalmValidateError{
+				Err:  "consistentHash policy is only available for http, https, http2, grpc and grpc-web ports",
+				Path: path + ".policy",
+			})
+		}
+
+		switch lb.HashKey {
+		case ConsistentHashKeyCookie:
+			if errs := apimachineryval.IsHTTPHeaderName(lb.CookieName); len(errs) > 0 {
+				rst = append(rst, KalmValidateError{
+					Err:  "valid cookieName is required for cookie hash key",
+					Path: path + ".cookieName",
+				})
+			}
+		case ConsistentHashKeyHeader:
+			if errs := apimachineryval.IsHTTPHeaderName(lb.HeaderName); len(errs) > 0 {
+				rst = append(rst, KalmValidateError{
+					Err:  "valid headerName is required for header hash key",
+					Path: path + ".headerName",
+				})
+			}
+		case ConsistentHashKeySourceIP:
+		default:
+			rst = append(rst, KalmValidateError{
+				Err:  "hashKey is required for consistentHash policy",
+				Path: path + ".hashKey",
+			})
+		}
+	}
+
+	return rst
+}
+
+func (r *Component) validateAutoscaling() (rst KalmValidateErrorList) {
+	autoscaling := r.Spec.Autoscaling
+	if autoscaling == nil {
+		return nil
+	}
+
+	switch r.Spec.WorkloadType {
+	case WorkloadTypeServer, WorkloadTypeStatefulSet, "":
+	default:
+		rst = append(rst, KalmValidateError{
+			Err:  "autoscaling is only supported by server and statefulset workload",
+			Path: ".spec.autoscaling",
+		})
+	}
+
+	if autoscaling.MinReplicas < 1 {
+		rst = append(rst, KalmValidateError{
+			Err:  "should be at least 1",
+			Path: ".spec.autoscaling.minReplicas",
+		})
+	}
+
+	if autoscaling.MaxReplicas < autoscaling.MinReplicas {
+		rst = append(rst, KalmValidateError{
+			Err:  "should not be less than minReplicas",
+			Path: ".spec.autoscaling.maxReplicas",
+		})
+	}
+
+	if autoscaling.TargetCPUUtilizationPercentage == nil &&
+		autoscaling.TargetMemoryUtilizationPercentage == nil &&
+		autoscaling.TargetRequestsPerSecond == nil {
+		rst = append(rst, KalmValidateError{
+			Err:  "at least one of targetCPUUtilizationPercentage, targetMemoryUtilizationPercentage and targetRequestsPerSecond is required",
+			Path: ".spec.autoscaling",
+		})
+	}
+
+	// utilization is relative to the resource requests, they must be set
+	if autoscaling.TargetCPUUtilizationPercentage != nil && !hasResourceRequest(r.Spec.ResourceRequirements, v1.ResourceCPU) {
+		rst = append(rst, KalmValidateError{
+			Err:  "cpu request is required to scale on cpu utilization",
+			Path: ".spec.autoscaling.targetCPUUtilizationPercentage",
+		})
+	}
+
+	if autoscaling.TargetMemoryUtilizationPercentage != nil && !hasResourceRequest(r.Spec.ResourceRequirements, v1.ResourceMemory) {
+		rst = append(rst, KalmValidateError{
+			Err:  "memory request is required to scale on memory utilization",
+			Path: ".spec.autoscaling.targetMemoryUtilizationPercentage",
+		})
+	}
+
+	return rst
+}
+
+func hasResourceRequest(requirements *v1.ResourceRequirements, name v1.ResourceName) bool {
+	if requirements == nil {
+		return false
+	}
+
+	if _, exist := requirements.Requests[name]; exist {
+		return true
+	}
+
+	// requests default to limits
+	_, exist := requirements.Limits[name]
+
+	return exist
+}
+
 func (r *Component) validateRunnerPermission() (rst KalmValidateErrorList) {
 	runnerPermission := r.Spec.RunnerPermission
 	if runnerPermission == nil {
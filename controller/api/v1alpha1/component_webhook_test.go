@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
@@ -218,3 +219,380 @@ func TestSTSForbiddenUpdatePersistVol(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Contains(t, err.Error(), "should not update volume of type: pvcTemplate")
 }
+
+func TestComponentRolloutStrategy(t *testing.T) {
+	component := Component{
+		ObjectMeta: ctrl.ObjectMeta{
+			Namespace: "kalm-system",
+			Name:      "kalm-comp-canary",
+		},
+		Spec: ComponentSpec{
+			Image: fmt.Sprintf("%s:%s", "foo", "bar"),
+			RolloutStrategy: &RolloutStrategy{
+				Type: RolloutStrategyCanary,
+			},
+		},
+	}
+
+	component.Default()
+	assert.Nil(t, component.validate())
+	assert.Equal(t, []int{10, 50, 100}, component.Spec.RolloutStrategy.Steps)
+	assert.Equal(t, DefaultRolloutSuccessRateThreshold, component.Spec.RolloutStrategy.SuccessRateThreshold)
+
+	component.Spec.RolloutStrategy.Steps = []int{20, 20, 80}
+	errs := component.validate()
+	assert.Len(t, errs, 2)
+	assert.Equal(t, ".spec.rolloutStrategy.steps[1]", errs[0].Path)
+	assert.Equal(t, ".spec.rolloutStrategy.steps", errs[1].Path)
+
+	component.Spec.RolloutStrategy = &RolloutStrategy{Type: RolloutStrategyBlueGreen}
+	component.Default()
+	assert.Nil(t, component.validate())
+	assert.Equal(t, []int{100}, component.Spec.RolloutStrategy.Steps)
+
+	component.Spec.WorkloadType = WorkloadTypeDaemonSet
+	errs = component.validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, ".spec.rolloutStrategy", errs[0].Path)
+}
+
+func TestComponentAutoscaling(t *testing.T) {
+	targetCPU := int32(80)
+
+	component := Component{
+		ObjectMeta: ctrl.ObjectMeta{
+			Namespace: "kalm-system",
+			Name:      "kalm-comp-hpa",
+		},
+		Spec: ComponentSpec{
+			Image: fmt.Sprintf("%s:%s", "foo", "bar"),
+			Autoscaling: &AutoscalingSpec{
+				MinReplicas:                    2,
+				MaxReplicas:                    5,
+				TargetCPUUtilizationPercentage: &targetCPU,
+			},
+			ResourceRequirements: &v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceCPU: resource.MustParse("100m"),
+				},
+			},
+		},
+	}
+
+	component.Default()
+	assert.Nil(t, component.validate())
+
+	component.Spec.Autoscaling.MaxReplicas = 1
+	errs := component.validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, ".spec.autoscaling.maxReplicas", errs[0].Path)
+
+	component.Spec.Autoscaling.MaxReplicas = 5
+	component.Spec.ResourceRequirements = nil
+	errs = component.validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, ".spec.autoscaling.targetCPUUtilizationPercentage", errs[0].Path)
+
+	component.Spec.Autoscaling.TargetCPUUtilizationPercentage = nil
+	errs = component.validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, ".spec.autoscaling", errs[0].Path)
+
+	component.Spec.WorkloadType = WorkloadTypeCronjob
+	component.Spec.Schedule = "*/5 * * * *"
+	assert.Len(t, component.validate(), 2)
+}
+
+func TestComponentCircuitBreaker(t *testing.T) {
+	component := Component{
+		ObjectMeta: ctrl.ObjectMeta{
+			Namespace: "kalm-system",
+			Name:      "kalm-comp-cb",
+		},
+		Spec: ComponentSpec{
+			Image: fmt.Sprintf("%s:%s", "foo", "bar"),
+			Ports: []Port{
+				{ContainerPort: 8080, Protocol: PortProtocolHTTP},
+			},
+			CircuitBreaker: &CircuitBreaker{
+				MaxConnections:       100,
+				Consecutive5xxErrors: 5,
+				BaseEjectionSeconds:  60,
+			},
+		},
+	}
+
+	component.Default()
+	assert.Nil(t, component.validate())
+
+	component.Spec.CircuitBreaker.Consecutive5xxErrors = 0
+	errs := component.validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, ".spec.circuitBreaker.consecutive5xxErrors", errs[0].Path)
+
+	component.Spec.CircuitBreaker.BaseEjectionSeconds = 0
+	component.Spec.Ports = nil
+	errs = component.validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, ".spec.circuitBreaker", errs[0].Path)
+}
+
+func TestComponentPortLoadBalancers(t *testing.T) {
+	component := Component{
+		ObjectMeta: ctrl.ObjectMeta{
+			Namespace: "kalm-system",
+			Name:      "kalm-comp-lb",
+		},
+		Spec: ComponentSpec{
+			Image: fmt.Sprintf("%s:%s", "foo", "bar"),
+			Ports: []Port{
+				{
+					ContainerPort: 8080,
+					Protocol:      PortProtocolHTTP,
+					LoadBalancer: &PortLoadBalancer{
+						Policy:           LoadBalancerPolicyConsistentHash,
+						HashKey:          ConsistentHashKeyCookie,
+						CookieName:       "session",
+						CookieTTLSeconds: 3600,
+					},
+				},
+				{
+					ContainerPort: 5432,
+					Protocol:      PortProtocolTCP,
+					LoadBalancer: &PortLoadBalancer{
+						Policy: LoadBalancerPolicyRoundRobin,
+					},
+				},
+			},
+		},
+	}
+
+	component.Default()
+	assert.Nil(t, component.validate())
+
+	component.Spec.Ports[0].LoadBalancer.CookieName = ""
+	component.Spec.Ports[1].LoadBalancer = &PortLoadBalancer{
+		Policy:  LoadBalancerPolicyConsistentHash,
+		HashKey: ConsistentHashKeySourceIP,
+	}
+	errs := component.validate()
+	assert.Len(t, errs, 2)
+	assert.Equal(t, ".spec.ports[0].loadBalancer.cookieName", errs[0].Path)
+	assert.Equal(t, ".spec.ports[1].loadBalancer.policy", errs[1].Path)
+
+	component.Spec.Ports[0].LoadBalancer = &PortLoadBalancer{
+		Policy:  LoadBalancerPolicyLeastRequest,
+		HashKey: ConsistentHashKeyHeader,
+	}
+	component.Spec.Ports[1].LoadBalancer = nil
+	errs = component.validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, ".spec.ports[0].loadBalancer.hashKey", errs[0].Path)
+}
+
+func TestComponentContainers(t *testing.T) {
+	component := Component{
+		ObjectMeta: ctrl.ObjectMeta{
+			Namespace: "kalm-system",
+			Name:      "kalm-comp-containers",
+		},
+		Spec: ComponentSpec{
+			Image: fmt.Sprintf("%s:%s", "foo", "bar"),
+			InitContainers: []ComponentContainer{
+				{Name: "migrate", Image: "foo:bar", Command: "./migrate"},
+			},
+			Sidecars: []ComponentContainer{
+				{
+					Name:  "log-shipper",
+					Image: "fluent-bit:1.5",
+					Env:   []EnvVar{{Name: "LOG_LEVEL", Value: "info"}},
+				},
+			},
+		},
+	}
+
+	component.Default()
+	assert.Nil(t, component.validate())
+
+	component.Spec.Sidecars[0].Name = "migrate"
+	errs := component.validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, ".spec.sidecars[0].name", errs[0].Path)
+
+	component.Spec.Sidecars[0].Name = PreInjectedFilesInitContainerName
+	errs = component.validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, ".spec.sidecars[0].name", errs[0].Path)
+
+	component.Spec.Sidecars[0].Name = "log-shipper"
+	component.Spec.Sidecars[0].Env[0].Name = "LOG-LEVEL"
+	errs = component.validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, ".spec.sidecars[0].env[0]", errs[0].Path)
+
+	component.Spec.Sidecars[0].Env[0].Name = "LOG_LEVEL"
+	component.Spec.InitContainers[0].Name = "Migrate"
+	errs = component.validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, ".spec.initContainers[0].name", errs[0].Path)
+}
+
+func TestComponentStartAfterItself(t *testing.T) {
+	component := Component{
+		ObjectMeta: ctrl.ObjectMeta{
+			Namespace: "kalm-system",
+			Name:      "kalm-comp-deps",
+		},
+		Spec: ComponentSpec{
+			Image:                fmt.Sprintf("%s:%s", "foo", "bar"),
+			StartAfterComponents: []string{"db"},
+		},
+	}
+
+	component.Default()
+	assert.Nil(t, component.validate())
+
+	component.Spec.StartAfterComponents = append(component.Spec.StartAfterComponents, "kalm-comp-deps")
+	errs := component.validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, ".spec.startAfterComponents[1]", errs[0].Path)
+}
+
+func TestComponentSecretReferences(t *testing.T) {
+	component := Component{
+		ObjectMeta: ctrl.ObjectMeta{
+			Namespace: "kalm-system",
+			Name:      "kalm-comp-secrets",
+		},
+		Spec: ComponentSpec{
+			Image: fmt.Sprintf("%s:%s", "foo", "bar"),
+			Env: []EnvVar{
+				{Name: "DB_PASSWORD", Type: EnvVarTypeSecret, Value: "db/password"},
+			},
+			PreInjectedFiles: []PreInjectFile{
+				{MountPath: "/etc/tls/tls.key", SecretRef: "tls/tls.key"},
+			},
+		},
+	}
+
+	component.Default()
+	assert.Nil(t, component.validate())
+
+	component.Spec.Env[0].Value = "db"
+	errs := component.validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, ".spec.env[0].value", errs[0].Path)
+
+	component.Spec.Env[0].Value = "db/password"
+	component.Spec.PreInjectedFiles[0].Content = "foo"
+	errs = component.validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, ".spec.preInjectedFiles[0].content", errs[0].Path)
+
+	component.Spec.PreInjectedFiles[0].SecretRef = ""
+	assert.Nil(t, component.validate())
+
+	component.Spec.PreInjectedFiles[0].Content = ""
+	errs = component.validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, ".spec.preInjectedFiles[0].content", errs[0].Path)
+}
+
+func TestComponentHotReloadFiles(t *testing.T) {
+	component := Component{
+		ObjectMeta: ctrl.ObjectMeta{
+			Namespace: "kalm-system",
+			Name:      "kalm-comp-hot-reload",
+		},
+		Spec: ComponentSpec{
+			Image: fmt.Sprintf("%s:%s", "foo", "bar"),
+			PreInjectedFiles: []PreInjectFile{
+				{MountPath: "/etc/nginx/conf.d/default.conf", Content: "server {}", HotReload: true},
+				{MountPath: "/etc/nginx/nginx.conf", Content: "http {}"},
+			},
+		},
+	}
+
+	component.Default()
+	assert.Nil(t, component.validate())
+
+	component.Spec.PreInjectedFiles[1].MountPath = "/etc/nginx/conf.d/upstream.conf"
+	errs := component.validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, ".spec.preInjectedFiles[0].hotReload", errs[0].Path)
+
+	component.Spec.PreInjectedFiles[1].HotReload = true
+	assert.Nil(t, component.validate())
+
+	component.Spec.PreInjectedFiles[1].MountPath = "/upstream.conf"
+	errs = component.validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, ".spec.preInjectedFiles[1].hotReload", errs[0].Path)
+}
+
+func TestRecordComponentChange(t *testing.T) {
+	old := &Component{
+		ObjectMeta: ctrl.ObjectMeta{Name: "web", Namespace: "default", Generation: 1},
+		Spec:       ComponentSpec{Image: "nginx:1.18"},
+	}
+	old.Default()
+
+	// a creation is the change of the first generation
+	created := old.DeepCopy()
+	created.Annotations = map[string]string{AnnoChangedBy: "foo@bar.com", AnnoChangeCause: "create"}
+	assert.True(t, recordComponentChange(nil, created))
+	assert.Equal(t, &ComponentChange{ChangedBy: "foo@bar.com", ChangeCause: "create"}, GetComponentChange(created, 1))
+	assert.NotContains(t, created.Annotations, AnnoChangedBy)
+	assert.NotContains(t, created.Annotations, AnnoChangeCause)
+
+	old.Annotations = created.Annotations
+
+	// two updates before the controller reconciles, both are kept by their generations
+	updated := old.DeepCopy()
+	updated.Spec.Image = "nginx:1.19"
+	updated.Annotations[AnnoChangedBy] = "a@bar.com"
+	assert.True(t, recordComponentChange(old, updated))
+	updated.Generation = 2
+
+	again := updated.DeepCopy()
+	again.Spec.Image = "nginx:1.20"
+	again.Annotations[AnnoChangedBy] = "b@bar.com"
+	assert.True(t, recordComponentChange(updated, again))
+
+	assert.Equal(t, "foo@bar.com", GetComponentChange(again, 1).ChangedBy)
+	assert.Equal(t, "a@bar.com", GetComponentChange(again, 2).ChangedBy)
+	assert.Equal(t, "b@bar.com", GetComponentChange(again, 3).ChangedBy)
+
+	// no spec change, the generation is not bumped and nothing is recorded
+	unchanged := updated.DeepCopy()
+	unchanged.Annotations[AnnoChangedBy] = "c@bar.com"
+	assert.True(t, recordComponentChange(updated, unchanged))
+	assert.NotContains(t, unchanged.Annotations, AnnoChangedBy)
+	assert.Equal(t, updated.Annotations[AnnoComponentChanges], unchanged.Annotations[AnnoComponentChanges])
+
+	// a spec change out of the api has no change, the recorded ones can't be dropped
+	edited := updated.DeepCopy()
+	edited.Spec.Image = "nginx:1.21"
+	delete(edited.Annotations, AnnoComponentChanges)
+	assert.True(t, recordComponentChange(updated, edited))
+	assert.Nil(t, GetComponentChange(edited, 3))
+	assert.Equal(t, updated.Annotations[AnnoComponentChanges], edited.Annotations[AnnoComponentChanges])
+
+	assert.False(t, recordComponentChange(updated, updated.DeepCopy()))
+
+	// only the changes of the latest generations are kept
+	for generation := int64(2); generation <= maxComponentChanges+2; generation++ {
+		next := updated.DeepCopy()
+		next.Spec.Image = fmt.Sprintf("nginx:%d", generation)
+		next.Annotations[AnnoChangedBy] = "foo@bar.com"
+		assert.True(t, recordComponentChange(updated, next))
+
+		updated = next
+		updated.Generation = generation + 1
+	}
+
+	changes := GetComponentChanges(updated)
+	assert.Len(t, changes, maxComponentChanges)
+	assert.NotContains(t, changes, int64(3))
+	assert.Contains(t, changes, int64(maxComponentChanges+3))
+}
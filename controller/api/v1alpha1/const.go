@@ -11,6 +11,17 @@ const (
 	ReasonReschedule     = "ReSchedule"
 
 	ACMEServerName = "acme-server"
+
+	// requests of routes to applications with the annotation are responded with 503 and the maintenance page
+	KalmMaintenanceAnnotationName  = "core.kalm.dev/maintenance"
+	KalmMaintenanceAnnotationValue = "true"
+
+	// path of the maintenance page in the kalm-files config map of the application
+	KalmMaintenancePageAnnotationName = "core.kalm.dev/maintenance-page"
+
+	// exec terminal sessions to pods of applications with the annotation are recorded by the api server
+	KalmRecordExecSessionsAnnotationName  = "core.kalm.dev/record-exec-sessions"
+	KalmRecordExecSessionsAnnotationValue = "true"
 )
 
 type KalmMode string
@@ -38,6 +49,9 @@ const (
 
 	ENV_KALM_PHYSICAL_CLUSTER_ID = "ENV_KALM_PHYSICAL_CLUSTER_ID"
 
+	// json of the group mappings of the sso config, read by the auth proxy
+	ENV_KALM_SSO_GROUP_MAPPINGS = "KALM_SSO_GROUP_MAPPINGS"
+
 	ENV_KALM_BASE_DNS_DOMAIN               = "KALM_BASE_DNS_DOMAIN"
 	ENV_KALM_BASE_APP_DOMAIN               = "KALM_BASE_APP_DOMAIN"
 	ENV_KALM_CLUSTER_IP                    = "KALM_CLUSTER_IP"
@@ -46,5 +60,17 @@ const (
 
 	ENV_EXTERNAL_DNS_SERVER_IP = "EXTERNAL_DNS_SERVER_IP"
 
+	// the resolver used to verify the ownership of domains, e.g. 8.8.8.8:53
+	ENV_DOMAIN_VERIFICATION_RESOLVER = "DOMAIN_VERIFICATION_RESOLVER"
+
 	ENV_KALM_CLUSTER_NAME = "KALM_CLUSTER_NAME"
+
+	// days before expiry to raise the ExpiringSoon condition of https certs, 14 by default
+	ENV_HTTPS_CERT_EXPIRING_SOON_DAYS = "HTTPS_CERT_EXPIRING_SOON_DAYS"
+	// days before expiry to raise the RenewalFailing condition of auto managed https certs if they are not renewed, 21 by default.
+	// cert-manager renews certs 30 days before expiry.
+	ENV_HTTPS_CERT_RENEWAL_FAILING_DAYS = "HTTPS_CERT_RENEWAL_FAILING_DAYS"
+
+	// number of trusted proxies in front of the ingress gateway, used to find the client ip of rate limits, 0 by default
+	ENV_KALM_XFF_NUM_TRUSTED_HOPS = "KALM_XFF_NUM_TRUSTED_HOPS"
 )
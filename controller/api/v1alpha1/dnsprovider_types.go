@@ -0,0 +1,119 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DNSProviderSpec defines the desired state of DNSProvider
+// Exactly one provider config should be set, secrets are read from the kalm-system namespace.
+type DNSProviderSpec struct {
+	// Zones managed by this provider, e.g. example.com.
+	// The records of a domain are managed by the provider with the longest matching zone.
+	// +kubebuilder:validation:MinItems=1
+	Zones []string `json:"zones"`
+
+	// +optional
+	Cloudflare *CloudflareDNSProviderConfig `json:"cloudflare,omitempty"`
+	// +optional
+	Route53 *Route53DNSProviderConfig `json:"route53,omitempty"`
+	// +optional
+	GoogleCloudDNS *GoogleCloudDNSProviderConfig `json:"googleCloudDNS,omitempty"`
+	// +optional
+	DigitalOcean *DigitalOceanDNSProviderConfig `json:"digitalOcean,omitempty"`
+	// +optional
+	RFC2136 *RFC2136DNSProviderConfig `json:"rfc2136,omitempty"`
+}
+
+type CloudflareDNSProviderConfig struct {
+	// secret with the api token in the key "token"
+	// +kubebuilder:validation:MinLength=1
+	APITokenSecretName string `json:"apiTokenSecretName"`
+}
+
+type Route53DNSProviderConfig struct {
+	// secret with the keys "accessKeyID" and "secretAccessKey"
+	// +kubebuilder:validation:MinLength=1
+	CredentialsSecretName string `json:"credentialsSecretName"`
+}
+
+type GoogleCloudDNSProviderConfig struct {
+	// +kubebuilder:validation:MinLength=1
+	Project string `json:"project"`
+
+	// secret with the json key of a service account in the key "credentials.json"
+	// +kubebuilder:validation:MinLength=1
+	CredentialsSecretName string `json:"credentialsSecretName"`
+}
+
+type DigitalOceanDNSProviderConfig struct {
+	// secret with the api token in the key "token"
+	// +kubebuilder:validation:MinLength=1
+	APITokenSecretName string `json:"apiTokenSecretName"`
+}
+
+// Dynamic updates of RFC 2136, e.g. to BIND, PowerDNS or CoreDNS
+type RFC2136DNSProviderConfig struct {
+	// host:port of the primary nameserver of the zones
+	// +kubebuilder:validation:MinLength=1
+	Nameserver string `json:"nameserver"`
+
+	// Name of the TSIG key, updates are not signed if empty.
+	// +optional
+	TSIGKeyName string `json:"tsigKeyName,omitempty"`
+
+	// Defaults to hmac-sha256.
+	// +optional
+	// +kubebuilder:validation:Enum=hmac-md5;hmac-sha1;hmac-sha256;hmac-sha512
+	TSIGAlgorithm string `json:"tsigAlgorithm,omitempty"`
+
+	// secret with the base64 encoded TSIG secret in the key "secret", required if TSIGKeyName is set
+	// +optional
+	TSIGSecretName string `json:"tsigSecretName,omitempty"`
+}
+
+// DNSProviderStatus defines the observed state of DNSProvider
+type DNSProviderStatus struct {
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Zones",type="string",JSONPath=".spec.zones"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// DNSProvider is the Schema for the dnsproviders API
+type DNSProvider struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DNSProviderSpec   `json:"spec,omitempty"`
+	Status DNSProviderStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DNSProviderList contains a list of DNSProvider
+type DNSProviderList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DNSProvider `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DNSProvider{}, &DNSProviderList{})
+}
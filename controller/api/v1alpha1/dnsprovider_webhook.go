@@ -0,0 +1,151 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/kalmhq/kalm/controller/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var dnsproviderlog = logf.Log.WithName("dnsprovider-resource")
+
+func (r *DNSProvider) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-core-kalm-dev-v1alpha1-dnsprovider,mutating=false,failurePolicy=fail,groups=core.kalm.dev,resources=dnsproviders,versions=v1alpha1,name=vdnsprovider.kb.io
+
+var _ webhook.Validator = &DNSProvider{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *DNSProvider) ValidateCreate() error {
+	dnsproviderlog.Info("validate create", "name", r.Name)
+	return r.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *DNSProvider) ValidateUpdate(old runtime.Object) error {
+	dnsproviderlog.Info("validate update", "name", r.Name)
+	return r.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *DNSProvider) ValidateDelete() error {
+	dnsproviderlog.Info("validate delete", "name", r.Name)
+	return nil
+}
+
+func (r *DNSProvider) validate() error {
+	var rst KalmValidateErrorList
+
+	setConfigCnt := 0
+	if r.Spec.Cloudflare != nil {
+		setConfigCnt += 1
+	}
+	if r.Spec.Route53 != nil {
+		setConfigCnt += 1
+	}
+	if r.Spec.GoogleCloudDNS != nil {
+		setConfigCnt += 1
+	}
+	if r.Spec.DigitalOcean != nil {
+		setConfigCnt += 1
+	}
+	if r.Spec.RFC2136 != nil {
+		setConfigCnt += 1
+	}
+
+	if setConfigCnt != 1 {
+		rst = append(rst, KalmValidateError{
+			Err:  "should provide exactly 1 among: cloudflare, route53, googleCloudDNS, digitalOcean and rfc2136",
+			Path: "spec",
+		})
+	}
+
+	zones := make(map[string]bool)
+	for i, zone := range r.Spec.Zones {
+		if validation.ValidateFQDN(zone) != nil {
+			rst = append(rst, KalmValidateError{
+				Err:  "invalid zone: " + zone,
+				Path: fmt.Sprintf("spec.zones[%d]", i),
+			})
+		}
+
+		if zones[zone] {
+			rst = append(rst, KalmValidateError{
+				Err:  "duplicate zone: " + zone,
+				Path: fmt.Sprintf("spec.zones[%d]", i),
+			})
+		}
+
+		zones[zone] = true
+	}
+
+	secretNames := map[string]string{}
+
+	if r.Spec.Cloudflare != nil {
+		secretNames["spec.cloudflare.apiTokenSecretName"] = r.Spec.Cloudflare.APITokenSecretName
+	}
+
+	if r.Spec.Route53 != nil {
+		secretNames["spec.route53.credentialsSecretName"] = r.Spec.Route53.CredentialsSecretName
+	}
+
+	if r.Spec.GoogleCloudDNS != nil {
+		secretNames["spec.googleCloudDNS.credentialsSecretName"] = r.Spec.GoogleCloudDNS.CredentialsSecretName
+	}
+
+	if r.Spec.DigitalOcean != nil {
+		secretNames["spec.digitalOcean.apiTokenSecretName"] = r.Spec.DigitalOcean.APITokenSecretName
+	}
+
+	if rfc2136 := r.Spec.RFC2136; rfc2136 != nil {
+		if _, _, err := net.SplitHostPort(rfc2136.Nameserver); err != nil {
+			rst = append(rst, KalmValidateError{
+				Err:  "nameserver should be in the format of host:port",
+				Path: "spec.rfc2136.nameserver",
+			})
+		}
+
+		if rfc2136.TSIGKeyName != "" {
+			secretNames["spec.rfc2136.tsigSecretName"] = rfc2136.TSIGSecretName
+		}
+	}
+
+	for path, name := range secretNames {
+		if !isValidResourceName(name) {
+			rst = append(rst, KalmValidateError{
+				Err:  "invalid secret name",
+				Path: path,
+			})
+		}
+	}
+
+	if len(rst) == 0 {
+		return nil
+	}
+
+	return rst
+}
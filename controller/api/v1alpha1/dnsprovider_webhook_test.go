@@ -0,0 +1,62 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestDNSProvider_Validate(t *testing.T) {
+	provider := DNSProvider{
+		ObjectMeta: ctrl.ObjectMeta{
+			Name: "test-name",
+		},
+		Spec: DNSProviderSpec{
+			Zones: []string{"example.com"},
+		},
+	}
+
+	// exactly 1 config
+	assert.NotNil(t, provider.validate())
+
+	provider.Spec.Cloudflare = &CloudflareDNSProviderConfig{APITokenSecretName: "cloudflare"}
+	provider.Spec.DigitalOcean = &DigitalOceanDNSProviderConfig{APITokenSecretName: "digitalocean"}
+	assert.NotNil(t, provider.validate())
+
+	provider.Spec.DigitalOcean = nil
+	assert.Nil(t, provider.validate())
+
+	// zones
+	provider.Spec.Zones = []string{"example.com", "example.com"}
+	assert.NotNil(t, provider.validate())
+
+	provider.Spec.Zones = []string{"example_com"}
+	assert.NotNil(t, provider.validate())
+}
+
+func TestDNSProvider_ValidateRFC2136(t *testing.T) {
+	provider := DNSProvider{
+		ObjectMeta: ctrl.ObjectMeta{
+			Name: "test-name",
+		},
+		Spec: DNSProviderSpec{
+			Zones: []string{"example.com"},
+			RFC2136: &RFC2136DNSProviderConfig{
+				Nameserver: "127.0.0.1:53",
+			},
+		},
+	}
+
+	// updates without TSIG are allowed
+	assert.Nil(t, provider.validate())
+
+	provider.Spec.RFC2136.TSIGKeyName = "kalm"
+	assert.NotNil(t, provider.validate())
+
+	provider.Spec.RFC2136.TSIGSecretName = "tsig"
+	assert.Nil(t, provider.validate())
+
+	provider.Spec.RFC2136.Nameserver = "127.0.0.1"
+	assert.NotNil(t, provider.validate())
+}
@@ -25,18 +25,80 @@ const (
 	DNSTypeCNAME = "CNAME"
 	DNSTypeA     = "A"
 	DNSTypeNS    = "NS"
+	DNSTypeTXT   = "TXT"
+	DNSTypeAAAA  = "AAAA"
+	DNSTypeMX    = "MX"
+	DNSTypeSRV   = "SRV"
 )
 
 // DNSRecordSpec defines the desired state of DNSRecord
 type DNSRecordSpec struct {
-	Domain    string  `json:"domain,omitempty"`
-	DNSType   DNSType `json:"dnsType,omitempty"`
-	DNSTarget string  `json:"dnsTarget,omitempty"`
+	Domain string `json:"domain,omitempty"`
+
+	// +kubebuilder:validation:Enum=A;AAAA;CNAME;NS;TXT;MX;SRV
+	DNSType DNSType `json:"dnsType,omitempty"`
+
+	// merged with DNSTargets, kept for records with a single target
+	DNSTarget string `json:"dnsTarget,omitempty"`
+
+	// all targets of the record set, e.g. multiple MX hosts or TXT values
+	// +optional
+	DNSTargets []string `json:"dnsTargets,omitempty"`
+
+	// in seconds, the default TTL of the dns provider is used if not set
+	// +optional
+	// +kubebuilder:validation:Minimum=60
+	// +kubebuilder:validation:Maximum=86400
+	TTL int `json:"ttl,omitempty"`
+
+	// priority of MX and SRV records, lower is preferred
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	Priority int `json:"priority,omitempty"`
+
+	// weight of SRV records
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	Weight int `json:"weight,omitempty"`
+
+	// port of SRV records
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	Port int `json:"port,omitempty"`
+}
+
+// GetDNSTargets returns DNSTarget and DNSTargets without duplicates
+func (spec *DNSRecordSpec) GetDNSTargets() []string {
+	var rst []string
+	seen := make(map[string]bool)
+
+	for _, target := range append([]string{spec.DNSTarget}, spec.DNSTargets...) {
+		if target == "" || seen[target] {
+			continue
+		}
+
+		seen[target] = true
+		rst = append(rst, target)
+	}
+
+	return rst
 }
 
 // DNSRecordStatus defines the observed state of DNSRecord
 type DNSRecordStatus struct {
+	// true if the records at the dns provider match the spec
 	IsConfigured bool `json:"isConfigured"`
+
+	// contents of the records at the dns provider when last checked
+	// +optional
+	ActualTargets []string `json:"actualTargets,omitempty"`
+
+	// last time the records at the dns provider were found changed outside of kalm
+	// +optional
+	LastDriftDetectedAt *metav1.Time `json:"lastDriftDetectedAt,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,181 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/kalmhq/kalm/controller/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var dnsrecordlog = logf.Log.WithName("dnsrecord-resource")
+
+// max length of a TXT value, longer values are split into strings of 255 characters by the dns managers
+const maxTXTRecordValueLength = 2048
+
+func (r *DNSRecord) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-core-kalm-dev-v1alpha1-dnsrecord,mutating=false,failurePolicy=fail,groups=core.kalm.dev,resources=dnsrecords,versions=v1alpha1,name=vdnsrecord.kb.io
+
+var _ webhook.Validator = &DNSRecord{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *DNSRecord) ValidateCreate() error {
+	dnsrecordlog.Info("validate create", "name", r.Name)
+	return r.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *DNSRecord) ValidateUpdate(old runtime.Object) error {
+	dnsrecordlog.Info("validate update", "name", r.Name)
+
+	oldRecord, ok := old.(*DNSRecord)
+	if !ok {
+		return fmt.Errorf("old is not *DNSRecord, %+v", old)
+	}
+
+	// the record set at the dns provider is identified by domain and type
+	var rst KalmValidateErrorList
+
+	if oldRecord.Spec.Domain != r.Spec.Domain {
+		rst = append(rst, KalmValidateError{
+			Err:  "domain is immutable",
+			Path: "spec.domain",
+		})
+	}
+
+	if oldRecord.Spec.DNSType != r.Spec.DNSType {
+		rst = append(rst, KalmValidateError{
+			Err:  "dnsType is immutable",
+			Path: "spec.dnsType",
+		})
+	}
+
+	if len(rst) > 0 {
+		return rst
+	}
+
+	return r.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *DNSRecord) ValidateDelete() error {
+	dnsrecordlog.Info("validate delete", "name", r.Name)
+	return nil
+}
+
+func (r *DNSRecord) validate() error {
+	var rst KalmValidateErrorList
+
+	if err := validation.ValidateDNSRecordName(r.Spec.Domain); err != nil {
+		rst = append(rst, KalmValidateError{
+			Err:  err.Error(),
+			Path: "spec.domain",
+		})
+	}
+
+	targets := r.Spec.GetDNSTargets()
+
+	if len(targets) == 0 {
+		rst = append(rst, KalmValidateError{
+			Err:  "should provide at least 1 target in dnsTarget or dnsTargets",
+			Path: "spec.dnsTargets",
+		})
+	}
+
+	if r.Spec.TTL != 0 && (r.Spec.TTL < 60 || r.Spec.TTL > 86400) {
+		rst = append(rst, KalmValidateError{
+			Err:  "ttl should be between 60 and 86400",
+			Path: "spec.ttl",
+		})
+	}
+
+	var isValidTarget func(target string) bool
+
+	switch r.Spec.DNSType {
+	case DNSTypeA:
+		isValidTarget = func(target string) bool {
+			ip := net.ParseIP(target)
+			return ip != nil && ip.To4() != nil
+		}
+	case DNSTypeAAAA:
+		isValidTarget = func(target string) bool {
+			ip := net.ParseIP(target)
+			return ip != nil && ip.To4() == nil
+		}
+	case DNSTypeCNAME, DNSTypeNS, DNSTypeMX, DNSTypeSRV:
+		isValidTarget = func(target string) bool {
+			return validation.ValidateFQDN(target) == nil
+		}
+	case DNSTypeTXT:
+		isValidTarget = func(target string) bool {
+			return len(target) <= maxTXTRecordValueLength
+		}
+	default:
+		rst = append(rst, KalmValidateError{
+			Err:  "dnsType should be one of: A, AAAA, CNAME, NS, TXT, MX and SRV",
+			Path: "spec.dnsType",
+		})
+	}
+
+	if r.Spec.DNSType == DNSTypeCNAME && len(targets) > 1 {
+		rst = append(rst, KalmValidateError{
+			Err:  "CNAME record should have exactly 1 target",
+			Path: "spec.dnsTargets",
+		})
+	}
+
+	if r.Spec.DNSType == DNSTypeSRV && (r.Spec.Port <= 0 || r.Spec.Port > 65535) {
+		rst = append(rst, KalmValidateError{
+			Err:  "port of SRV record should be between 1 and 65535",
+			Path: "spec.port",
+		})
+	}
+
+	if isValidTarget != nil {
+		if r.Spec.DNSTarget != "" && !isValidTarget(r.Spec.DNSTarget) {
+			rst = append(rst, KalmValidateError{
+				Err:  fmt.Sprintf("invalid target for %s record", r.Spec.DNSType),
+				Path: "spec.dnsTarget",
+			})
+		}
+
+		for i, target := range r.Spec.DNSTargets {
+			if !isValidTarget(target) {
+				rst = append(rst, KalmValidateError{
+					Err:  fmt.Sprintf("invalid target for %s record", r.Spec.DNSType),
+					Path: fmt.Sprintf("spec.dnsTargets[%d]", i),
+				})
+			}
+		}
+	}
+
+	if len(rst) == 0 {
+		return nil
+	}
+
+	return rst
+}
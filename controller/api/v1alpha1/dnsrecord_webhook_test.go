@@ -0,0 +1,114 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestDNSRecord_Validate(t *testing.T) {
+	record := DNSRecord{
+		ObjectMeta: ctrl.ObjectMeta{
+			Name: "test-name",
+		},
+		Spec: DNSRecordSpec{
+			Domain:    "example.com",
+			DNSType:   DNSTypeA,
+			DNSTarget: "1.1.1.1",
+		},
+	}
+
+	assert.Nil(t, record.validate())
+
+	record.Spec.DNSTargets = []string{"1.1.1.1", "2.2.2.2"}
+	assert.Nil(t, record.validate())
+	assert.Equal(t, []string{"1.1.1.1", "2.2.2.2"}, record.Spec.GetDNSTargets())
+
+	record.Spec.DNSTargets = []string{"2001:db8::1"}
+	assert.NotNil(t, record.validate())
+
+	record.Spec.DNSType = DNSTypeAAAA
+	record.Spec.DNSTarget = ""
+	assert.Nil(t, record.validate())
+
+	// no target
+	record.Spec.DNSTargets = nil
+	assert.NotNil(t, record.validate())
+
+	record.Spec.DNSType = DNSTypeCNAME
+	record.Spec.DNSTargets = []string{"a.example.com", "b.example.com"}
+	assert.NotNil(t, record.validate())
+
+	record.Spec.DNSType = "PTR"
+	record.Spec.DNSTargets = []string{"a.example.com"}
+	assert.NotNil(t, record.validate())
+
+	// ttl
+	record.Spec.DNSType = DNSTypeCNAME
+	record.Spec.TTL = 30
+	assert.NotNil(t, record.validate())
+
+	record.Spec.TTL = 3600
+	assert.Nil(t, record.validate())
+}
+
+func TestDNSRecord_ValidateTXTMXAndSRV(t *testing.T) {
+	record := DNSRecord{
+		ObjectMeta: ctrl.ObjectMeta{
+			Name: "test-name",
+		},
+		Spec: DNSRecordSpec{
+			Domain:     "_dmarc.example.com",
+			DNSType:    DNSTypeTXT,
+			DNSTargets: []string{"v=DMARC1; p=none", `quoted "value"`},
+		},
+	}
+
+	assert.Nil(t, record.validate())
+
+	record.Spec.Domain = "example.com"
+	record.Spec.DNSType = DNSTypeMX
+	record.Spec.DNSTargets = []string{"mx1.example.com", "mx2.example.com"}
+	record.Spec.Priority = 10
+	assert.Nil(t, record.validate())
+
+	record.Spec.DNSTargets = []string{"1.1.1.1"}
+	assert.NotNil(t, record.validate())
+
+	record.Spec.Domain = "_sip._tcp.example.com"
+	record.Spec.DNSType = DNSTypeSRV
+	record.Spec.DNSTargets = []string{"sip.example.com"}
+	assert.NotNil(t, record.validate())
+
+	record.Spec.Port = 5060
+	record.Spec.Weight = 5
+	assert.Nil(t, record.validate())
+
+	record.Spec.Domain = "invalid domain"
+	assert.NotNil(t, record.validate())
+}
+
+func TestDNSRecord_ValidateUpdate(t *testing.T) {
+	old := DNSRecord{
+		ObjectMeta: ctrl.ObjectMeta{
+			Name: "test-name",
+		},
+		Spec: DNSRecordSpec{
+			Domain:    "example.com",
+			DNSType:   DNSTypeA,
+			DNSTarget: "1.1.1.1",
+		},
+	}
+
+	record := old.DeepCopy()
+	record.Spec.DNSTargets = []string{"2.2.2.2"}
+	assert.Nil(t, record.ValidateUpdate(&old))
+
+	record.Spec.Domain = "www.example.com"
+	assert.NotNil(t, record.ValidateUpdate(&old))
+
+	record.Spec.Domain = old.Spec.Domain
+	record.Spec.DNSType = DNSTypeAAAA
+	assert.NotNil(t, record.ValidateUpdate(&old))
+}
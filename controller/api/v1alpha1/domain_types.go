@@ -16,6 +16,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"strings"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -25,8 +27,30 @@ type DomainSpec struct {
 	DNSTarget string  `json:"dnsTarget,omitempty"`
 }
 
+type DomainVerificationStatus string
+
+const (
+	DomainVerificationPending  DomainVerificationStatus = "Pending"
+	DomainVerificationVerified DomainVerificationStatus = "Verified"
+	DomainVerificationFailed   DomainVerificationStatus = "Failed"
+)
+
+// the TXT record holding the verification token is set at _kalm-verification.<domain>
+const DomainVerificationTXTRecordPrefix = "_kalm-verification."
+
+// set on Domains when they are created, Domains without it existed before
+// the ownership verification was introduced and are trusted as they are.
+const AnnoDomainVerificationRequired = "core.kalm.dev/verification-required"
+
 // DomainStatus defines the observed state of Domain
 type DomainStatus struct {
+	// value of the TXT record which proves the ownership of the domain
+	VerificationToken   string                   `json:"verificationToken,omitempty"`
+	VerificationStatus  DomainVerificationStatus `json:"verificationStatus,omitempty"`
+	VerificationMessage string                   `json:"verificationMessage,omitempty"`
+	LastCheckedAt       *metav1.Time             `json:"lastCheckedAt,omitempty"`
+	VerifiedAt          *metav1.Time             `json:"verifiedAt,omitempty"`
+	FailedAt            *metav1.Time             `json:"failedAt,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -35,6 +59,7 @@ type DomainStatus struct {
 // +kubebuilder:printcolumn:name="Domain",type="string",JSONPath=".spec.domain"
 // +kubebuilder:printcolumn:name="DNSType",type="string",JSONPath=".spec.dnsType"
 // +kubebuilder:printcolumn:name="DNSTarget",type="string",JSONPath=".spec.dnsTarget"
+// +kubebuilder:printcolumn:name="Verification",type="string",JSONPath=".status.verificationStatus"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // Domain is the Schema for the domains API
@@ -46,6 +71,19 @@ type Domain struct {
 	Status DomainStatus `json:"status,omitempty"`
 }
 
+func (d *Domain) IsVerified() bool {
+	return d.Status.VerificationStatus == DomainVerificationVerified
+}
+
+func (d *Domain) IsVerificationRequired() bool {
+	return d.Annotations[AnnoDomainVerificationRequired] == "true"
+}
+
+// the TXT record of *.example.com is set at _kalm-verification.example.com
+func (d *Domain) VerificationTXTRecordName() string {
+	return DomainVerificationTXTRecordPrefix + strings.TrimPrefix(d.Spec.Domain, "*.")
+}
+
 // +kubebuilder:object:root=true
 
 // DomainList contains a list of Domain
@@ -18,7 +18,9 @@ package v1alpha1
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/kalmhq/kalm/controller/validation"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -45,6 +47,15 @@ var _ webhook.Defaulter = &Domain{}
 func (r *Domain) Default() {
 	domainlog.Info("default", "name", r.Name)
 
+	// only new Domains need to be verified, see AnnoDomainVerificationRequired
+	if r.CreationTimestamp.IsZero() {
+		if r.Annotations == nil {
+			r.Annotations = make(map[string]string)
+		}
+
+		r.Annotations[AnnoDomainVerificationRequired] = "true"
+	}
+
 	clusterIP, hostname, err := GetClusterIPOrHostname()
 	if err != nil {
 		return
@@ -124,6 +135,10 @@ func (r *Domain) ValidateUpdate(old runtime.Object) error {
 		return fmt.Errorf("domain is immutable, should not change it, old: %+v, new: %+v", oldDomain, r)
 	}
 
+	if oldDomain.IsVerificationRequired() && !r.IsVerificationRequired() {
+		return fmt.Errorf("annotation %s is immutable, should not remove it", AnnoDomainVerificationRequired)
+	}
+
 	return nil
 }
 
@@ -157,3 +172,96 @@ func GetClusterIPOrHostname() (string, string, error) {
 
 	return "", "", nil
 }
+
+// validateHostsOwnership refuses the hosts which are not covered by any verified Domain.
+// Hosts in existingHosts are already in use before the change, they are kept even if not verified,
+// so that the resources created before the ownership verification can still be updated.
+func validateHostsOwnership(hosts, existingHosts []string, path string) KalmValidateErrorList {
+	if webhookClient == nil || len(hosts) == 0 {
+		return nil
+	}
+
+	var domainList DomainList
+	if err := webhookClient.List(context.Background(), &domainList); err != nil {
+		return KalmValidateErrorList{{
+			Err:  "fail to check the ownership of hosts: " + err.Error(),
+			Path: path,
+		}}
+	}
+
+	return checkHostsOwnership(hosts, existingHosts, domainList.Items, path)
+}
+
+func hostsOwnershipError(hosts, existingHosts []string, path string) error {
+	if rst := validateHostsOwnership(hosts, existingHosts, path); len(rst) > 0 {
+		return rst
+	}
+
+	return nil
+}
+
+func checkHostsOwnership(hosts, existingHosts []string, domains []Domain, path string) KalmValidateErrorList {
+	var rst KalmValidateErrorList
+
+	existing := make(map[string]bool, len(existingHosts))
+	for _, host := range existingHosts {
+		existing[strings.ToLower(stripIfHasPort(host))] = true
+	}
+
+	for i, host := range hosts {
+		host = strings.ToLower(stripIfHasPort(host))
+
+		if host == "*" || validation.ValidateIPAddress(host) == nil || isKalmManagedHost(host) || existing[host] {
+			continue
+		}
+
+		var unverified []string
+		verified := false
+
+		for _, domain := range domains {
+			if !isHostCoveredByDomain(host, domain.Spec.Domain) {
+				continue
+			}
+
+			if domain.IsVerified() {
+				verified = true
+				break
+			}
+
+			unverified = append(unverified, domain.Spec.Domain)
+		}
+
+		if verified {
+			continue
+		}
+
+		var msg string
+		if len(unverified) == 0 {
+			msg = fmt.Sprintf("host %s is not verified, add and verify the domain of it first", host)
+		} else {
+			msg = fmt.Sprintf("host %s is not verified, verify the ownership of domain %s first", host, strings.Join(unverified, ", "))
+		}
+
+		rst = append(rst, KalmValidateError{
+			Err:  msg,
+			Path: fmt.Sprintf("%s[%d]", path, i),
+		})
+	}
+
+	return rst
+}
+
+// hosts under the base app domain are assigned by kalm
+func isKalmManagedHost(host string) bool {
+	baseAppDomain := strings.ToLower(GetEnvKalmBaseAppDomain())
+	return baseAppDomain != "" && isHostCoveredByDomain(host, baseAppDomain)
+}
+
+// the verification of a domain proves the control of its zone, so subdomains are covered too.
+// *.example.com is verified at example.com, and covers example.com and all of its subdomains.
+func isHostCoveredByDomain(host, domain string) bool {
+	base := strings.ToLower(strings.TrimPrefix(domain, "*."))
+	host = strings.TrimPrefix(host, "*.")
+
+	return host == base || strings.HasSuffix(host, "."+base)
+}
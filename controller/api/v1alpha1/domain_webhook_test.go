@@ -0,0 +1,84 @@
+package v1alpha1
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckHostsOwnership(t *testing.T) {
+	domains := []Domain{
+		{
+			Spec:   DomainSpec{Domain: "example.com"},
+			Status: DomainStatus{VerificationStatus: DomainVerificationVerified},
+		},
+		{
+			Spec:   DomainSpec{Domain: "*.unverified.io"},
+			Status: DomainStatus{VerificationStatus: DomainVerificationPending},
+		},
+		{
+			Spec:   DomainSpec{Domain: "failed.org"},
+			Status: DomainStatus{VerificationStatus: DomainVerificationFailed},
+		},
+		{
+			Spec:   DomainSpec{Domain: "www.failed.org"},
+			Status: DomainStatus{VerificationStatus: DomainVerificationVerified},
+		},
+	}
+
+	assert.Nil(t, checkHostsOwnership([]string{
+		"example.com",
+		"www.example.com",
+		"*.example.com",
+		"www.failed.org",
+		"*",
+		"1.1.1.1",
+	}, nil, domains, "spec.hosts"))
+
+	errs := checkHostsOwnership([]string{"a.unverified.io", "unverified.io:443", "failed.org", "api.failed.org", "not-claimed.net"}, nil, domains, "spec.hosts")
+	assert.Len(t, errs, 5)
+	assert.Equal(t, "spec.hosts[1]", errs[1].Path)
+	assert.Equal(t, "host not-claimed.net is not verified, add and verify the domain of it first", errs[4].Err)
+
+	// hosts already in use are kept
+	errs = checkHostsOwnership([]string{"not-claimed.net", "failed.org", "new.net"}, []string{"Not-Claimed.net", "failed.org:443"}, domains, "spec.hosts")
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "spec.hosts[2]", errs[0].Path)
+
+	os.Setenv(ENV_KALM_BASE_APP_DOMAIN, "unverified.io")
+	defer os.Unsetenv(ENV_KALM_BASE_APP_DOMAIN)
+	assert.Nil(t, checkHostsOwnership([]string{"a.unverified.io"}, nil, domains, "spec.hosts"))
+}
+
+func TestDomain_IsVerificationRequired(t *testing.T) {
+	os.Setenv(ENV_KALM_CLUSTER_IP, "1.1.1.1")
+	defer os.Unsetenv(ENV_KALM_CLUSTER_IP)
+
+	domain := Domain{}
+	domain.Default()
+	assert.True(t, domain.IsVerificationRequired())
+
+	// existing domains are not changed
+	legacy := Domain{}
+	legacy.CreationTimestamp = metav1.Now()
+	legacy.Default()
+	assert.False(t, legacy.IsVerificationRequired())
+
+	legacy.Spec.Domain = "example.com"
+	updated := legacy.DeepCopy()
+	assert.Nil(t, updated.ValidateUpdate(&legacy))
+
+	required := updated.DeepCopy()
+	required.Annotations = map[string]string{AnnoDomainVerificationRequired: "true"}
+	assert.NotNil(t, updated.ValidateUpdate(required))
+}
+
+func TestDomain_VerificationTXTRecordName(t *testing.T) {
+	domain := Domain{Spec: DomainSpec{Domain: "*.example.com"}}
+	assert.Equal(t, "_kalm-verification.example.com", domain.VerificationTXTRecordName())
+
+	domain.Spec.Domain = "www.example.com"
+	assert.Equal(t, "_kalm-verification.www.example.com", domain.VerificationTXTRecordName())
+}
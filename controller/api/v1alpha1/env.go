@@ -33,3 +33,7 @@ func GetEnvCloudflareDomainToZoneIDConfig() string {
 func GetEnvExternalDNSServerIP() string {
 	return os.Getenv(ENV_EXTERNAL_DNS_SERVER_IP)
 }
+
+func GetEnvDomainVerificationResolver() string {
+	return os.Getenv(ENV_DOMAIN_VERIFICATION_RESOLVER)
+}
@@ -0,0 +1,97 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// GroupMapper maps the groups from the identity provider with the group mappings of the sso config.
+// The groups are kept, and the mapped groups are appended, so role bindings of the original groups still work.
+type GroupMapper struct {
+	mappings []GroupMapping
+	regexes  []*regexp.Regexp
+}
+
+func NewGroupMapper(mappings []GroupMapping) (*GroupMapper, error) {
+	mapper := &GroupMapper{
+		mappings: mappings,
+		regexes:  make([]*regexp.Regexp, len(mappings)),
+	}
+
+	for i, mapping := range mappings {
+		switch mapping.Type {
+		case GroupMappingTypeExact, GroupMappingTypePrefix:
+		case GroupMappingTypeRegex:
+			re, err := compileGroupMappingRegex(mapping.Match)
+
+			if err != nil {
+				return nil, err
+			}
+
+			mapper.regexes[i] = re
+		default:
+			return nil, fmt.Errorf("unknown group mapping type: %s", mapping.Type)
+		}
+	}
+
+	return mapper, nil
+}
+
+func (m *GroupMapper) Map(groups []string) []string {
+	if m == nil || len(m.mappings) == 0 {
+		return groups
+	}
+
+	res := make([]string, 0, len(groups))
+	seen := make(map[string]struct{}, len(groups))
+
+	add := func(group string) {
+		if _, ok := seen[group]; ok || group == "" {
+			return
+		}
+
+		seen[group] = struct{}{}
+		res = append(res, group)
+	}
+
+	for _, group := range groups {
+		add(group)
+	}
+
+	for _, group := range groups {
+		for i, mapping := range m.mappings {
+			switch mapping.Type {
+			case GroupMappingTypeExact:
+				if group == mapping.Match {
+					for _, g := range mapping.Groups {
+						add(g)
+					}
+				}
+			case GroupMappingTypePrefix:
+				if strings.HasPrefix(group, mapping.Match) {
+					for _, g := range mapping.Groups {
+						add(g)
+					}
+				}
+			case GroupMappingTypeRegex:
+				match := m.regexes[i].FindStringSubmatchIndex(group)
+
+				if match == nil {
+					continue
+				}
+
+				for _, g := range mapping.Groups {
+					add(string(m.regexes[i].ExpandString(nil, g, group, match)))
+				}
+			}
+		}
+	}
+
+	return res
+}
+
+// the regex should match the whole group, so "admins" doesn't match "not-admins"
+func compileGroupMappingRegex(match string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + match + ")$")
+}
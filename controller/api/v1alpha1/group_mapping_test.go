@@ -0,0 +1,42 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupMapper(t *testing.T) {
+	mapper, err := NewGroupMapper([]GroupMapping{
+		{
+			Type:   GroupMappingTypeExact,
+			Match:  "kalmhq:admins",
+			Groups: []string{"kalm-admins"},
+		},
+		{
+			Type:   GroupMappingTypePrefix,
+			Match:  "kalmhq:",
+			Groups: []string{"kalm-members", "kalm-viewers"},
+		},
+		{
+			Type:   GroupMappingTypeRegex,
+			Match:  `cn=(?P<team>[a-z]+),ou=teams,dc=kalm,dc=dev`,
+			Groups: []string{"${team}-developers"},
+		},
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"kalmhq:admins", "kalm-admins", "kalm-members", "kalm-viewers"}, mapper.Map([]string{"kalmhq:admins"}))
+	assert.Equal(t, []string{"kalmhq:devs", "kalm-members", "kalm-viewers"}, mapper.Map([]string{"kalmhq:devs"}))
+	assert.Equal(t, []string{"cn=web,ou=teams,dc=kalm,dc=dev", "web-developers"}, mapper.Map([]string{"cn=web,ou=teams,dc=kalm,dc=dev"}))
+
+	// regex should match the whole group
+	assert.Equal(t, []string{"cn=web,ou=teams,dc=kalm,dc=dev,o=other"}, mapper.Map([]string{"cn=web,ou=teams,dc=kalm,dc=dev,o=other"}))
+	assert.Equal(t, []string{"other"}, mapper.Map([]string{"other"}))
+
+	var nilMapper *GroupMapper
+	assert.Equal(t, []string{"other"}, nilMapper.Map([]string{"other"}))
+
+	_, err = NewGroupMapper([]GroupMapping{{Type: GroupMappingTypeRegex, Match: "(", Groups: []string{"foo"}}})
+	assert.NotNil(t, err)
+}
@@ -97,6 +97,79 @@ type HttpRouteCORS struct {
 	MaxAgeSeconds    *int     `json:"maxAgeSeconds,omitempty"`
 }
 
+type HttpRouteHeaderOperations struct {
+	Set    map[string]string `json:"set,omitempty"`
+	Add    map[string]string `json:"add,omitempty"`
+	Remove []string          `json:"remove,omitempty"`
+}
+
+type HttpRouteHeaders struct {
+	Request  *HttpRouteHeaderOperations `json:"request,omitempty"`
+	Response *HttpRouteHeaderOperations `json:"response,omitempty"`
+}
+
+// HttpRouteRewrite rewrites the path and the host of requests before forwarding them to the destinations.
+// The prefix rewrite and the regex rewrite can't be used together, neither with stripPath.
+type HttpRouteRewrite struct {
+	// replaces the matched path prefix
+	Prefix string `json:"prefix,omitempty"`
+	// replaces the parts of the path matching the regex with the substitution,
+	// capture groups can be referenced in the substitution as \1
+	Regex        string `json:"regex,omitempty"`
+	Substitution string `json:"substitution,omitempty"`
+	// replaces the host header
+	Host string `json:"host,omitempty"`
+}
+
+// HttpRouteRedirect responds with a redirect instead of forwarding the requests.
+// The host and the path of the request are kept if not specified.
+type HttpRouteRedirect struct {
+	Host string `json:"host,omitempty"`
+	Path string `json:"path,omitempty"`
+	// defaults to 301
+	// +kubebuilder:validation:Enum=301;302
+	Code int `json:"code,omitempty"`
+}
+
+// HttpRouteErrorPage is responded with 503 by the ingress gateway if no destination of the route has ready endpoints,
+// e.g. the components are scaled to zero. The page is a file in the kalm-files config map of an application.
+type HttpRouteErrorPage struct {
+	// the application of the kalm-files config map
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+	// path of the file, e.g. /errors/503.html
+	// +kubebuilder:validation:MinLength=1
+	Path string `json:"path"`
+}
+
+// +kubebuilder:validation:Enum=route;clientIP;header;subject
+type HttpRouteRateLimitKey string
+
+const (
+	// all requests of the route share one counter
+	HttpRouteRateLimitKeyRoute HttpRouteRateLimitKey = "route"
+	// requests are counted by the client ip address seen by the ingress gateway
+	HttpRouteRateLimitKeyClientIP HttpRouteRateLimitKey = "clientIP"
+	// requests are counted by the value of the header specified by headerName
+	HttpRouteRateLimitKeyHeader HttpRouteRateLimitKey = "header"
+	// requests are counted by the subject verified by the sso of the protected endpoint, counted by its sidecar.
+	// Requests without a verified subject, or to destinations which are not protected, fall back to the client ip address
+	HttpRouteRateLimitKeySubject HttpRouteRateLimitKey = "subject"
+)
+
+// HttpRouteRateLimit limits the requests of a route in fixed windows of intervalSeconds.
+// Requests exceeding the limit are rejected with 429 by the ingress gateway.
+// Counters are local to each envoy worker of each ingress gateway replica (or protected endpoint sidecar for the subject key),
+// so a client may make up to requests x workers x replicas in a window.
+type HttpRouteRateLimit struct {
+	// +kubebuilder:validation:Minimum=1
+	Requests int `json:"requests"`
+	// +kubebuilder:validation:Minimum=1
+	IntervalSeconds int                   `json:"intervalSeconds"`
+	Key             HttpRouteRateLimitKey `json:"key"`
+	HeaderName      string                `json:"headerName,omitempty"`
+}
+
 // +kubebuilder:validation:Enum=GET;HEAD;POST;PUT;PATCH;DELETE;OPTIONS;TRACE;CONNECT
 type AllowMethod string
 
@@ -126,8 +199,8 @@ type HttpRouteSpec struct {
 
 	Conditions []HttpRouteCondition `json:"conditions,omitempty"`
 
-	// +kubebuilder:validation:MinItems=1
-	Destinations []HttpRouteDestination `json:"destinations"`
+	// required unless the route is a redirect
+	Destinations []HttpRouteDestination `json:"destinations,omitempty"`
 
 	HttpRedirectToHttps bool `json:"httpRedirectToHttps,omitempty"`
 
@@ -138,6 +211,14 @@ type HttpRouteSpec struct {
 	Fault  *HttpRouteFault  `json:"fault,omitempty"`
 	Delay  *HttpRouteDelay  `json:"delay,omitempty"`
 	CORS   *HttpRouteCORS   `json:"cors,omitempty"`
+
+	RateLimit *HttpRouteRateLimit `json:"rateLimit,omitempty"`
+
+	Headers  *HttpRouteHeaders  `json:"headers,omitempty"`
+	Rewrite  *HttpRouteRewrite  `json:"rewrite,omitempty"`
+	Redirect *HttpRouteRedirect `json:"redirect,omitempty"`
+
+	ErrorPage *HttpRouteErrorPage `json:"errorPage,omitempty"`
 }
 
 type HttpRouteDestinationStatus struct {
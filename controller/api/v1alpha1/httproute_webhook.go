@@ -17,11 +17,14 @@ package v1alpha1
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/kalmhq/kalm/controller/validation"
 	"k8s.io/apimachinery/pkg/runtime"
+	apimachineryval "k8s.io/apimachinery/pkg/util/validation"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -57,14 +60,23 @@ func (r *HttpRoute) ValidateCreate() error {
 		return err
 	}
 
-	return nil
+	return hostsOwnershipError(r.Spec.Hosts, nil, "spec.hosts")
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (r *HttpRoute) ValidateUpdate(old runtime.Object) error {
 	httproutelog.Info("validate update", "name", r.Name)
 
-	return r.validate()
+	oldRoute, ok := old.(*HttpRoute)
+	if !ok {
+		return fmt.Errorf("old is not *HttpRoute, %+v", old)
+	}
+
+	if err := r.validate(); err != nil {
+		return err
+	}
+
+	return hostsOwnershipError(r.Spec.Hosts, oldRoute.Spec.Hosts, "spec.hosts")
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -94,6 +106,13 @@ func (r *HttpRoute) validate() error {
 		}
 	}
 
+	if len(r.Spec.Destinations) == 0 && r.Spec.Redirect == nil {
+		rst = append(rst, KalmValidateError{
+			Err:  "destinations are required unless the route is a redirect",
+			Path: "spec.destinations",
+		})
+	}
+
 	for i, dest := range r.Spec.Destinations {
 		if !isValidDestinationHost(dest.Host) {
 			rst = append(rst, KalmValidateError{
@@ -124,6 +143,40 @@ func (r *HttpRoute) validate() error {
 		}
 	}
 
+	rateLimit := r.Spec.RateLimit
+	if rateLimit != nil && rateLimit.Key == HttpRouteRateLimitKeyHeader {
+		if errs := apimachineryval.IsHTTPHeaderName(rateLimit.HeaderName); len(errs) > 0 {
+			rst = append(rst, KalmValidateError{
+				Err:  "invalid rate limit header name, " + strings.Join(errs, ", "),
+				Path: "spec.rateLimit.headerName",
+			})
+		}
+	}
+
+	if r.Spec.Headers != nil {
+		rst = append(rst, validateHeaderOperations(r.Spec.Headers.Request, true, "spec.headers.request")...)
+		rst = append(rst, validateHeaderOperations(r.Spec.Headers.Response, false, "spec.headers.response")...)
+	}
+
+	rst = append(rst, r.validateRewrite()...)
+	rst = append(rst, r.validateRedirect()...)
+
+	if errorPage := r.Spec.ErrorPage; errorPage != nil {
+		if errs := apimachineryval.IsDNS1123Label(errorPage.Namespace); len(errs) > 0 {
+			rst = append(rst, KalmValidateError{
+				Err:  "invalid namespace, " + strings.Join(errs, ", "),
+				Path: "spec.errorPage.namespace",
+			})
+		}
+
+		if !isValidPath(errorPage.Path) {
+			rst = append(rst, KalmValidateError{
+				Err:  "invalid path, should start with: /",
+				Path: "spec.errorPage.path",
+			})
+		}
+	}
+
 	if len(rst) == 0 {
 		return nil
 	}
@@ -131,6 +184,204 @@ func (r *HttpRoute) validate() error {
 	return rst
 }
 
+// headers used by kalm sso, they are removed from requests at the ingress gateway to prevent spoofing.
+// Keep in sync with DANGEROUS_HEADERS of the http route controller.
+var reservedRouteRequestHeaders = []string{
+	"kalm-sso-userinfo",
+	"allow-to-pass-if-has-bearer-token",
+	"kalm-route",
+	"kalm-set-cookie",
+	"kalm-auth-email",
+	"kalm-sso-granted-groups",
+	"kalm-sso-granted-emails",
+	"kalm-sso-granted-cert-subjects",
+	"kalm-client-cert-subject",
+	"kalm-rate-limit",
+	"kalm-rate-limit-subject",
+}
+
+func validateHeaderOperations(ops *HttpRouteHeaderOperations, isRequest bool, path string) KalmValidateErrorList {
+	var rst KalmValidateErrorList
+
+	if ops == nil {
+		return rst
+	}
+
+	// header names are case insensitive
+	operated := make(map[string]string)
+
+	check := func(name, op, fieldPath string) {
+		if errs := apimachineryval.IsHTTPHeaderName(name); len(errs) > 0 {
+			rst = append(rst, KalmValidateError{
+				Err:  "invalid header name, " + strings.Join(errs, ", "),
+				Path: fieldPath,
+			})
+			return
+		}
+
+		lowerName := strings.ToLower(name)
+
+		if isRequest {
+			for _, reserved := range reservedRouteRequestHeaders {
+				if lowerName == reserved {
+					rst = append(rst, KalmValidateError{
+						Err:  "header " + name + " is reserved by kalm",
+						Path: fieldPath,
+					})
+					return
+				}
+			}
+		}
+
+		if prevOp, exist := operated[lowerName]; exist {
+			rst = append(rst, KalmValidateError{
+				Err:  fmt.Sprintf("header %s can't be both %s and %s", name, prevOp, op),
+				Path: fieldPath,
+			})
+			return
+		}
+
+		operated[lowerName] = op
+	}
+
+	for _, name := range sortedKeys(ops.Set) {
+		check(name, "set", fmt.Sprintf("%s.set.%s", path, name))
+	}
+
+	for _, name := range sortedKeys(ops.Add) {
+		check(name, "added", fmt.Sprintf("%s.add.%s", path, name))
+	}
+
+	for i, name := range ops.Remove {
+		check(name, "removed", fmt.Sprintf("%s.remove[%d]", path, i))
+	}
+
+	return rst
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (r *HttpRoute) validateRewrite() KalmValidateErrorList {
+	var rst KalmValidateErrorList
+
+	rewrite := r.Spec.Rewrite
+	if rewrite == nil {
+		return rst
+	}
+
+	if rewrite.Prefix != "" {
+		if !isValidPath(rewrite.Prefix) {
+			rst = append(rst, KalmValidateError{
+				Err:  "invalid path, should start with: /",
+				Path: "spec.rewrite.prefix",
+			})
+		}
+
+		if r.Spec.StripPath {
+			rst = append(rst, KalmValidateError{
+				Err:  "prefix rewrite can't be used with stripPath",
+				Path: "spec.rewrite.prefix",
+			})
+		}
+	}
+
+	if rewrite.Regex != "" {
+		if _, err := regexp.Compile(rewrite.Regex); err != nil {
+			rst = append(rst, KalmValidateError{
+				Err:  "invalid regex, " + err.Error(),
+				Path: "spec.rewrite.regex",
+			})
+		}
+
+		if rewrite.Prefix != "" || r.Spec.StripPath {
+			rst = append(rst, KalmValidateError{
+				Err:  "regex rewrite can't be used with prefix rewrite or stripPath",
+				Path: "spec.rewrite.regex",
+			})
+		}
+	} else if rewrite.Substitution != "" {
+		rst = append(rst, KalmValidateError{
+			Err:  "substitution requires regex",
+			Path: "spec.rewrite.substitution",
+		})
+	}
+
+	if rewrite.Host != "" && !isValidDestinationHost(rewrite.Host) {
+		rst = append(rst, KalmValidateError{
+			Err:  "invalid rewrite host:" + rewrite.Host,
+			Path: "spec.rewrite.host",
+		})
+	}
+
+	return rst
+}
+
+func (r *HttpRoute) validateRedirect() KalmValidateErrorList {
+	var rst KalmValidateErrorList
+
+	redirect := r.Spec.Redirect
+	if redirect == nil {
+		return rst
+	}
+
+	if redirect.Host == "" && redirect.Path == "" {
+		rst = append(rst, KalmValidateError{
+			Err:  "at least one of host and path is required",
+			Path: "spec.redirect",
+		})
+	}
+
+	if redirect.Host != "" && !isValidDestinationHost(redirect.Host) {
+		rst = append(rst, KalmValidateError{
+			Err:  "invalid redirect host:" + redirect.Host,
+			Path: "spec.redirect.host",
+		})
+	}
+
+	if redirect.Path != "" && !isValidPath(redirect.Path) {
+		rst = append(rst, KalmValidateError{
+			Err:  "invalid path, should start with: /",
+			Path: "spec.redirect.path",
+		})
+	}
+
+	// the requests are not forwarded, so the options about forwarding make no sense
+	conflicts := []struct {
+		path string
+		set  bool
+	}{
+		{"spec.destinations", len(r.Spec.Destinations) > 0},
+		{"spec.stripPath", r.Spec.StripPath},
+		{"spec.rewrite", r.Spec.Rewrite != nil},
+		{"spec.mirror", r.Spec.Mirror != nil},
+		{"spec.fault", r.Spec.Fault != nil},
+		{"spec.delay", r.Spec.Delay != nil},
+		{"spec.retries", r.Spec.Retries != nil},
+		{"spec.errorPage", r.Spec.ErrorPage != nil},
+	}
+
+	for _, conflict := range conflicts {
+		if conflict.set {
+			rst = append(rst, KalmValidateError{
+				Err:  "can't be used with redirect",
+				Path: conflict.path,
+			})
+		}
+	}
+
+	return rst
+}
+
 func isValidDestinationHost(host string) bool {
 	host = stripIfHasPort(host)
 	return validation.ValidateFQDN(host) == nil
@@ -36,6 +36,158 @@ func TestHttpRoute_Validate(t *testing.T) {
 	assert.Nil(t, route.validate())
 }
 
+func TestHttpRoute_ValidateRateLimit(t *testing.T) {
+	route := HttpRoute{
+		ObjectMeta: ctrl.ObjectMeta{
+			Name: "test-name",
+		},
+		Spec: HttpRouteSpec{
+			Hosts:   []string{"xip.io"},
+			Methods: []HttpRouteMethod{"GET"},
+			Schemes: []HttpRouteScheme{"http"},
+			Paths:   []string{"/"},
+			Destinations: []HttpRouteDestination{
+				{Host: "server-v1", Weight: 1},
+			},
+			RateLimit: &HttpRouteRateLimit{
+				Requests:        100,
+				IntervalSeconds: 60,
+				Key:             HttpRouteRateLimitKeyClientIP,
+			},
+		},
+	}
+
+	assert.Nil(t, route.validate())
+
+	route.Spec.RateLimit.Key = HttpRouteRateLimitKeyHeader
+	err := route.validate()
+	assert.NotNil(t, err)
+	assert.Equal(t, "spec.rateLimit.headerName", err.(KalmValidateErrorList)[0].Path)
+
+	route.Spec.RateLimit.HeaderName = "x-api-key"
+	assert.Nil(t, route.validate())
+}
+
+func newTestHttpRoute() HttpRoute {
+	return HttpRoute{
+		ObjectMeta: ctrl.ObjectMeta{
+			Name: "test-name",
+		},
+		Spec: HttpRouteSpec{
+			Hosts:   []string{"xip.io"},
+			Methods: []HttpRouteMethod{"GET"},
+			Schemes: []HttpRouteScheme{"http"},
+			Paths:   []string{"/"},
+			Destinations: []HttpRouteDestination{
+				{Host: "server-v1", Weight: 1},
+			},
+		},
+	}
+}
+
+func errorPaths(err error) []string {
+	var paths []string
+
+	if err == nil {
+		return paths
+	}
+
+	for _, e := range err.(KalmValidateErrorList) {
+		paths = append(paths, e.Path)
+	}
+
+	return paths
+}
+
+func TestHttpRoute_ValidateHeaders(t *testing.T) {
+	route := newTestHttpRoute()
+	route.Spec.Headers = &HttpRouteHeaders{
+		Request: &HttpRouteHeaderOperations{
+			Set:    map[string]string{"x-forwarded-prefix": "/api"},
+			Remove: []string{"x-debug"},
+		},
+		Response: &HttpRouteHeaderOperations{
+			Add: map[string]string{"x-frame-options": "DENY"},
+		},
+	}
+	assert.Nil(t, route.validate())
+
+	route.Spec.Headers.Request.Add = map[string]string{"X-Debug": "true", "kalm-auth-email": "a@b.c"}
+	route.Spec.Headers.Response.Remove = []string{"bad header"}
+
+	assert.ElementsMatch(t, []string{
+		"spec.headers.request.remove[0]",
+		"spec.headers.request.add.kalm-auth-email",
+		"spec.headers.response.remove[0]",
+	}, errorPaths(route.validate()))
+}
+
+func TestHttpRoute_ValidateRewrite(t *testing.T) {
+	route := newTestHttpRoute()
+	route.Spec.Rewrite = &HttpRouteRewrite{
+		Prefix: "/v2",
+		Host:   "api.example.com",
+	}
+	assert.Nil(t, route.validate())
+
+	route.Spec.StripPath = true
+	assert.Equal(t, []string{"spec.rewrite.prefix"}, errorPaths(route.validate()))
+
+	route.Spec.StripPath = false
+	route.Spec.Rewrite = &HttpRouteRewrite{
+		Regex:        "^/users/([0-9]+)$",
+		Substitution: "/profile/\\1",
+	}
+	assert.Nil(t, route.validate())
+
+	route.Spec.Rewrite.Regex = "^/users/([0-9]+$"
+	route.Spec.Rewrite.Prefix = "/v2"
+	assert.Equal(t, []string{"spec.rewrite.regex", "spec.rewrite.regex"}, errorPaths(route.validate()))
+
+	route.Spec.Rewrite = &HttpRouteRewrite{Substitution: "/profile"}
+	assert.Equal(t, []string{"spec.rewrite.substitution"}, errorPaths(route.validate()))
+}
+
+func TestHttpRoute_ValidateRedirect(t *testing.T) {
+	route := newTestHttpRoute()
+	route.Spec.Destinations = nil
+	assert.Equal(t, []string{"spec.destinations"}, errorPaths(route.validate()))
+
+	route.Spec.Redirect = &HttpRouteRedirect{
+		Host: "new.example.com",
+		Code: 302,
+	}
+	assert.Nil(t, route.validate())
+
+	route.Spec.Redirect = &HttpRouteRedirect{}
+	route.Spec.Destinations = []HttpRouteDestination{{Host: "server-v1", Weight: 1}}
+	route.Spec.StripPath = true
+	assert.Equal(t, []string{"spec.redirect", "spec.destinations", "spec.stripPath"}, errorPaths(route.validate()))
+}
+
+func TestHttpRoute_ValidateErrorPage(t *testing.T) {
+	route := newTestHttpRoute()
+	route.Spec.ErrorPage = &HttpRouteErrorPage{
+		Namespace: "shop",
+		Path:      "/errors/503.html",
+	}
+	assert.Nil(t, route.validate())
+
+	route.Spec.ErrorPage = &HttpRouteErrorPage{
+		Namespace: "Shop_1",
+		Path:      "errors/503.html",
+	}
+	assert.Equal(t, []string{"spec.errorPage.namespace", "spec.errorPage.path"}, errorPaths(route.validate()))
+
+	route.Spec.ErrorPage = &HttpRouteErrorPage{
+		Namespace: "shop",
+		Path:      "/errors/503.html",
+	}
+	route.Spec.Destinations = nil
+	route.Spec.Redirect = &HttpRouteRedirect{Host: "new.example.com"}
+	assert.Equal(t, []string{"spec.errorPage"}, errorPaths(route.validate()))
+}
+
 func TestHttpRoute_isValidRouteHost(t *testing.T) {
 	validRouteHosts := []string{
 		"*.xip.io",
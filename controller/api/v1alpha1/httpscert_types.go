@@ -32,6 +32,15 @@ type HttpsCertSpec struct {
 
 	// +kubebuilder:validation:MinItems=1
 	Domains []string `json:"domains"`
+
+	// PEM encoded CA bundle to verify client certificates.
+	// If it's set, the ingress gateway runs in mutual TLS mode for the domains,
+	// clients without a certificate signed by the bundle are refused.
+	ClientCACert string `json:"clientCACert,omitempty"`
+}
+
+func (spec *HttpsCertSpec) IsMutualTLS() bool {
+	return spec.ClientCACert != ""
 }
 
 // HttpsCertStatus defines the observed state of HttpsCert
@@ -53,10 +62,14 @@ type HttpsCertConditionType string
 
 const (
 	HttpsCertConditionReady HttpsCertConditionType = "Ready"
+	// the cert expires within the expiring soon threshold
+	HttpsCertConditionExpiringSoon HttpsCertConditionType = "ExpiringSoon"
+	// the auto managed cert failed to renew, or is not renewed within the renewal failing threshold
+	HttpsCertConditionRenewalFailing HttpsCertConditionType = "RenewalFailing"
 )
 
 type HttpsCertCondition struct {
-	// Type of the condition, currently ('Ready').
+	// Type of the condition, one of ('Ready', 'ExpiringSoon', 'RenewalFailing').
 	Type HttpsCertConditionType `json:"type"`
 
 	// Status of the condition, one of ('True', 'False', 'Unknown').
@@ -16,11 +16,15 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
@@ -74,14 +78,23 @@ func (r *HttpsCert) ValidateCreate() error {
 		return err
 	}
 
-	return nil
+	return hostsOwnershipError(r.Spec.Domains, nil, "spec.domains")
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (r *HttpsCert) ValidateUpdate(old runtime.Object) error {
 	httpscertlog.Info("validate update", "name", r.Name)
 
-	return r.validate()
+	oldCert, ok := old.(*HttpsCert)
+	if !ok {
+		return fmt.Errorf("old is not *HttpsCert, %+v", old)
+	}
+
+	if err := r.validate(); err != nil {
+		return err
+	}
+
+	return hostsOwnershipError(r.Spec.Domains, oldCert.Spec.Domains, "spec.domains")
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -119,37 +132,21 @@ func (r *HttpsCert) validate() error {
 	} else {
 		switch r.Spec.HttpsCertIssuer {
 		case DefaultHTTP01IssuerName:
-			if len(r.Spec.Domains) <= 0 {
-				rst = append(rst, KalmValidateError{
-					Err:  "http01 cert should have at lease 1 domain",
-					Path: "spec.domains",
-				})
-			}
-
-			for _, d := range r.Spec.Domains {
-				if strings.Contains(d, "*") {
-					rst = append(rst, KalmValidateError{
-						Err:  fmt.Sprintf("http01 cert should not have '*' in domain: %s", d),
-						Path: "spec.domains",
-					})
-				}
-			}
+			rst = append(rst, validateHTTP01CertDomains(r.Spec.Domains)...)
 		case DefaultDNS01IssuerName:
 			//nothing
 		case DefaultCAIssuerName:
 			//nothing
 		default:
+			rst = append(rst, validateACMEIssuerOfCert(r)...)
+		}
+	}
 
-			validIssuers := []string{
-				DefaultDNS01IssuerName,
-				DefaultHTTP01IssuerName,
-				DefaultCAIssuerName,
-			}
-
+	if r.Spec.IsMutualTLS() {
+		if err := validateCACertBundle(r.Spec.ClientCACert); err != nil {
 			rst = append(rst, KalmValidateError{
-				Err: fmt.Sprintf("for auto managed cert, httpsCertIssuer should be one of: %s, but: %s",
-					validIssuers, r.Spec.HttpsCertIssuer),
-				Path: "spec.httpsCertIssuer",
+				Err:  "invalid client CA cert: " + err.Error(),
+				Path: "spec.clientCACert",
 			})
 		}
 	}
@@ -160,3 +157,90 @@ func (r *HttpsCert) validate() error {
 
 	return rst
 }
+
+// the bundle should only contain PEM encoded certificates
+func validateHTTP01CertDomains(domains []string) KalmValidateErrorList {
+	var rst KalmValidateErrorList
+
+	if len(domains) <= 0 {
+		rst = append(rst, KalmValidateError{
+			Err:  "http01 cert should have at lease 1 domain",
+			Path: "spec.domains",
+		})
+	}
+
+	for _, d := range domains {
+		if strings.Contains(d, "*") {
+			rst = append(rst, KalmValidateError{
+				Err:  fmt.Sprintf("http01 cert should not have '*' in domain: %s", d),
+				Path: "spec.domains",
+			})
+		}
+	}
+
+	return rst
+}
+
+// besides the default issuers, certs can be issued by HttpsCertIssuers with a generic ACME issuer
+func validateACMEIssuerOfCert(cert *HttpsCert) KalmValidateErrorList {
+	var issuer HttpsCertIssuer
+
+	if webhookClient == nil ||
+		webhookClient.Get(context.Background(), client.ObjectKey{Name: cert.Spec.HttpsCertIssuer}, &issuer) != nil ||
+		issuer.Spec.ACME == nil {
+
+		validIssuers := []string{
+			DefaultDNS01IssuerName,
+			DefaultHTTP01IssuerName,
+			DefaultCAIssuerName,
+		}
+
+		return KalmValidateErrorList{
+			KalmValidateError{
+				Err: fmt.Sprintf("for auto managed cert, httpsCertIssuer should be one of: %s or an ACME issuer, but: %s",
+					validIssuers, cert.Spec.HttpsCertIssuer),
+				Path: "spec.httpsCertIssuer",
+			},
+		}
+	}
+
+	if issuer.Spec.ACME.HTTP01 != nil {
+		return validateHTTP01CertDomains(cert.Spec.Domains)
+	}
+
+	return nil
+}
+
+func validateCACertBundle(bundle string) error {
+	rest := []byte(bundle)
+	count := 0
+
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			return fmt.Errorf("unexpected PEM block type %s", block.Type)
+		}
+
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return err
+		}
+
+		count++
+	}
+
+	if count == 0 {
+		return fmt.Errorf("no PEM encoded certificate found")
+	}
+
+	if len(strings.TrimSpace(string(rest))) > 0 {
+		return fmt.Errorf("unexpected content after certificates")
+	}
+
+	return nil
+}
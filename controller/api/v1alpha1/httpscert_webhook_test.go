@@ -1,9 +1,18 @@
 package v1alpha1
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
 	"github.com/stretchr/testify/assert"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"testing"
 )
 
 func TestHttpsCertValidateSelfCACert(t *testing.T) {
@@ -103,3 +112,56 @@ func TestHttpsCertIssuerNameMustExist(t *testing.T) {
 	err := cert.validate()
 	assert.NotNil(t, err)
 }
+
+func TestHttpsCertValidateClientCACert(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "client-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+
+	cert := HttpsCert{
+		ObjectMeta: ctrl.ObjectMeta{
+			Name: "kalm-cert",
+		},
+		Spec: HttpsCertSpec{
+			IsSelfManaged:             true,
+			SelfManagedCertSecretName: "fake-sec-name",
+			Domains:                   []string{"example.com"},
+			ClientCACert:              caPEM,
+		},
+	}
+
+	assert.Nil(t, cert.validate())
+
+	// bundle of multiple CAs
+	cert.Spec.ClientCACert = caPEM + caPEM
+	assert.Nil(t, cert.validate())
+
+	invalidBundles := []string{
+		"not a cert",
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("bad")})),
+		caPEM + "trailing",
+	}
+
+	for _, bundle := range invalidBundles {
+		cert.Spec.ClientCACert = bundle
+		err := cert.validate()
+
+		if assert.NotNil(t, err) {
+			assert.Equal(t, "spec.clientCACert", err.(KalmValidateErrorList)[0].Path)
+		}
+	}
+}
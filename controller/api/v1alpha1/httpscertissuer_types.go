@@ -29,6 +29,8 @@ type HttpsCertIssuerSpec struct {
 	HTTP01 *HTTP01Issuer `json:"http01,omitempty"`
 	// +optional
 	DNS01 *DNS01Issuer `json:"dns01,omitempty"`
+	// +optional
+	ACME *ACMEIssuer `json:"acme,omitempty"`
 }
 
 type CAForTestIssuer struct{}
@@ -59,6 +61,51 @@ type DNS01IssuerConfig struct {
 	AllowFrom []string `json:"allowfrom,omitempty"`
 }
 
+// ACMEIssuer issues certs from any ACME server, e.g. ZeroSSL, Google Public CA or an internal step-ca.
+// Exactly one of http01 and dns01 solvers should be provided.
+type ACMEIssuer struct {
+	// the directory url of the ACME server
+	// +kubebuilder:validation:MinLength=1
+	Server string `json:"server"`
+	// +optional
+	Email string `json:"email,omitempty"`
+	// skip verifying the tls cert of the ACME server, e.g. a local Pebble server for testing
+	// +optional
+	SkipTLSVerify bool `json:"skipTLSVerify,omitempty"`
+	// required by ACME servers binding the account to an existing account of the CA
+	// +optional
+	ExternalAccountBinding *ACMEExternalAccountBinding `json:"externalAccountBinding,omitempty"`
+	// +optional
+	HTTP01 *ACMEHTTP01Solver `json:"http01,omitempty"`
+	// +optional
+	DNS01 *ACMEDNS01Solver `json:"dns01,omitempty"`
+}
+
+type ACMEExternalAccountBinding struct {
+	// +kubebuilder:validation:MinLength=1
+	KeyID string `json:"keyID"`
+	// the secret in the cert-manager namespace holding the base64 url encoded HMAC key in the hmacKey field
+	// +kubebuilder:validation:MinLength=1
+	KeySecretName string `json:"keySecretName"`
+	// HS256 is used if it's empty
+	// +kubebuilder:validation:Enum=HS256;HS384;HS512
+	// +optional
+	KeyAlgorithm string `json:"keyAlgorithm,omitempty"`
+}
+
+// challenges are solved by the ingress gateway
+type ACMEHTTP01Solver struct{}
+
+// challenges are solved with the records created by the api of a DNSProvider
+type ACMEDNS01Solver struct {
+	// name of the DNSProvider managing the zones of the cert domains, its credentials are copied into
+	// the cert-manager namespace for the solver
+	// +kubebuilder:validation:MinLength=1
+	DNSProvider string `json:"dnsProvider"`
+}
+
+const ACMEExternalAccountBindingSecretKey = "hmacKey"
+
 // HttpsCertIssuerStatus defines the observed state of HttpsCertIssuer
 type HttpsCertIssuerStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -16,6 +16,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"net/url"
+
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -69,17 +71,20 @@ func (r *HttpsCertIssuer) validate() error {
 	if r.Spec.DNS01 != nil {
 		setConfigCnt += 1
 	}
+	if r.Spec.ACME != nil {
+		setConfigCnt += 1
+	}
 
 	if setConfigCnt == 0 {
 		rst = append(rst, KalmValidateError{
-			Err:  "should provide at least 1 among: acmeCloudFlare, caForTest, http01 and dns01",
+			Err:  "should provide at least 1 among: acmeCloudFlare, caForTest, http01, dns01 and acme",
 			Path: "spec",
 		})
 	}
 
 	if setConfigCnt > 1 {
 		rst = append(rst, KalmValidateError{
-			Err:  "should provide at most 1 among: acmeCloudFlare, caForTest, http01 and dns01",
+			Err:  "should provide at most 1 among: acmeCloudFlare, caForTest, http01, dns01 and acme",
 			Path: "spec",
 		})
 	}
@@ -111,9 +116,64 @@ func (r *HttpsCertIssuer) validate() error {
 		}
 	}
 
+	if r.Spec.ACME != nil {
+		rst = append(rst, validateACMEIssuer(r.Spec.ACME)...)
+	}
+
 	if len(rst) == 0 {
 		return nil
 	}
 
 	return rst
 }
+
+func validateACMEIssuer(acme *ACMEIssuer) KalmValidateErrorList {
+	var rst KalmValidateErrorList
+
+	// RFC 8555 requires https for the directory url
+	if u, err := url.Parse(acme.Server); err != nil || u.Scheme != "https" || u.Host == "" {
+		rst = append(rst, KalmValidateError{
+			Err:  "invalid ACME server, should be an https url: " + acme.Server,
+			Path: "spec.acme.server",
+		})
+	}
+
+	if acme.Email != "" && !isValidEmail(acme.Email) {
+		rst = append(rst, KalmValidateError{
+			Err:  "invalid email:" + acme.Email,
+			Path: "spec.acme.email",
+		})
+	}
+
+	if eab := acme.ExternalAccountBinding; eab != nil {
+		if eab.KeyID == "" {
+			rst = append(rst, KalmValidateError{
+				Err:  "keyID should not be empty",
+				Path: "spec.acme.externalAccountBinding.keyID",
+			})
+		}
+
+		if !isValidResourceName(eab.KeySecretName) {
+			rst = append(rst, KalmValidateError{
+				Err:  "invalid secret name",
+				Path: "spec.acme.externalAccountBinding.keySecretName",
+			})
+		}
+	}
+
+	if (acme.HTTP01 == nil) == (acme.DNS01 == nil) {
+		rst = append(rst, KalmValidateError{
+			Err:  "should provide exactly 1 among: http01 and dns01",
+			Path: "spec.acme",
+		})
+	}
+
+	if acme.DNS01 != nil && !isValidResourceName(acme.DNS01.DNSProvider) {
+		rst = append(rst, KalmValidateError{
+			Err:  "invalid dns provider name",
+			Path: "spec.acme.dns01.dnsProvider",
+		})
+	}
+
+	return rst
+}
@@ -40,3 +40,46 @@ func TestHttpsCertIssuer_Validate2(t *testing.T) {
 
 	assert.Nil(t, issuer.validate())
 }
+
+func TestHttpsCertIssuer_ValidateACME(t *testing.T) {
+	issuer := HttpsCertIssuer{
+		ObjectMeta: ctrl.ObjectMeta{
+			Name: "zerossl",
+		},
+		Spec: HttpsCertIssuerSpec{
+			ACME: &ACMEIssuer{
+				Server: "https://acme.zerossl.com/v2/DV90",
+				Email:  "admin@example.com",
+				ExternalAccountBinding: &ACMEExternalAccountBinding{
+					KeyID:         "kid",
+					KeySecretName: "zerossl-eab",
+				},
+				HTTP01: &ACMEHTTP01Solver{},
+			},
+		},
+	}
+
+	assert.Nil(t, issuer.validate())
+
+	// acme servers are only served over https
+	issuer.Spec.ACME.Server = "http://localhost:14000/dir"
+	assert.NotNil(t, issuer.validate())
+	issuer.Spec.ACME.Server = "https://localhost:14000/dir"
+	assert.Nil(t, issuer.validate())
+
+	// exactly 1 solver
+	issuer.Spec.ACME.DNS01 = &ACMEDNS01Solver{DNSProvider: "cloudflare"}
+	assert.NotNil(t, issuer.validate())
+	issuer.Spec.ACME.HTTP01 = nil
+	assert.Nil(t, issuer.validate())
+	issuer.Spec.ACME.DNS01 = nil
+	assert.NotNil(t, issuer.validate())
+	issuer.Spec.ACME.DNS01 = &ACMEDNS01Solver{DNSProvider: "Invalid_Name"}
+	assert.NotNil(t, issuer.validate())
+	issuer.Spec.ACME.DNS01.DNSProvider = "cloudflare"
+
+	issuer.Spec.ACME.ExternalAccountBinding.KeySecretName = ""
+	errs := issuer.validate().(KalmValidateErrorList)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "spec.acme.externalAccountBinding.keySecretName", errs[0].Path)
+}
@@ -46,6 +46,12 @@ type ProtectedEndpointSpec struct {
 	// This flag should be set carefully. Please make sure that the upstream can handle the token correctly.
 	// Otherwise, client can bypass kalm sso by sending a not empty bearer token.
 	AllowToPassIfHasBearerToken bool `json:"allowToPassIfHasBearerToken,omitempty"`
+
+	// Subjects of client certificates allowed to access the endpoint without sso, in RFC 2253 format, e.g. "CN=client,O=Example".
+	// The subject is verified by the ingress gateway, which requires an HttpsCert with clientCACert for the host.
+	// It's only trusted if the request is sent by the ingress gateway with istio mutual TLS,
+	// requests from other workloads or without mutual TLS must pass the sso.
+	AllowedCertSubjects []string `json:"allowedCertSubjects,omitempty"`
 }
 
 // ProtectedEndpointStatus defines the observed state of ProtectedEndpoint
@@ -17,6 +17,7 @@ package v1alpha1
 
 import (
 	"fmt"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -84,6 +85,16 @@ func (r *ProtectedEndpoint) validate() error {
 		}
 	}
 
+	for i, subject := range r.Spec.AllowedCertSubjects {
+		// subjects are passed to the auth proxy joined with "|"
+		if strings.TrimSpace(subject) == "" || strings.Contains(subject, "|") {
+			rst = append(rst, KalmValidateError{
+				Err:  "invalid cert subject",
+				Path: fmt.Sprintf("spec.allowedCertSubjects[%d]", i),
+			})
+		}
+	}
+
 	if len(rst) == 0 {
 		return nil
 	}
@@ -30,4 +30,15 @@ func TestProtectedEndpoint_Validate(t *testing.T) {
 	protectedEndpoint.Spec.EndpointName = "valid-ep-name"
 	protectedEndpoint.Spec.Ports = []uint32{0}
 	assert.NotNil(t, protectedEndpoint.validate())
+
+	// cert subjects
+	protectedEndpoint.Spec.Ports = []uint32{8080}
+	protectedEndpoint.Spec.AllowedCertSubjects = []string{"CN=client,O=Example"}
+	assert.Nil(t, protectedEndpoint.validate())
+
+	protectedEndpoint.Spec.AllowedCertSubjects = []string{"CN=a|CN=b"}
+	assert.NotNil(t, protectedEndpoint.validate())
+
+	protectedEndpoint.Spec.AllowedCertSubjects = []string{" "}
+	assert.NotNil(t, protectedEndpoint.validate())
 }
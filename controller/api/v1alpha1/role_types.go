@@ -0,0 +1,73 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RoleRule grants an action on the resources matching the pattern
+type RoleRule struct {
+	// The action granted, the actions it implies are granted as well: manage implies edit, edit implies view.
+	// +kubebuilder:validation:Enum=view;edit;manage
+	Action string `json:"action"`
+
+	// Pattern of resources in the form of <kind>/<name>, e.g. components/web, pods/* or *.
+	// A trailing * matches any name with the prefix.
+	// +kubebuilder:validation:MinLength=1
+	Resource string `json:"resource"`
+}
+
+// RoleSpec defines the desired state of Role
+type RoleSpec struct {
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// +kubebuilder:validation:MinItems=1
+	Rules []RoleRule `json:"rules"`
+}
+
+// RoleStatus defines the observed state of Role
+type RoleStatus struct{}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Description",type="string",JSONPath=".spec.description"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// Role is a custom role defined by cluster owners, referenced by the role field of RoleBindings.
+// The rules are granted in the namespace of the binding, or in all namespaces if the binding is in kalm-system.
+type Role struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RoleSpec   `json:"spec,omitempty"`
+	Status RoleStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RoleList contains a list of Role
+type RoleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Role `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Role{}, &RoleList{})
+}
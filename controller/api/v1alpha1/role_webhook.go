@@ -0,0 +1,108 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var rolelog = logf.Log.WithName("role-resource")
+
+// *, <kind>/*, <kind>/<name> or <kind>/<name prefix>*
+// the rules are compiled into casbin policy lines, so separators like "," are not allowed
+var roleRuleResourceRegex = regexp.MustCompile(`^\*$|^[a-zA-Z]+/([a-zA-Z0-9._-]+\*?|\*)$`)
+
+func (r *Role) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-core-kalm-dev-v1alpha1-role,mutating=false,failurePolicy=fail,groups=core.kalm.dev,resources=roles,versions=v1alpha1,name=vrole.kb.io
+
+var _ webhook.Validator = &Role{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *Role) ValidateCreate() error {
+	rolelog.Info("validate create", "name", r.Name)
+	return r.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *Role) ValidateUpdate(old runtime.Object) error {
+	rolelog.Info("validate update", "name", r.Name)
+	return r.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *Role) ValidateDelete() error {
+	rolelog.Info("validate delete", "name", r.Name)
+	return nil
+}
+
+func (r *Role) validate() error {
+	var rst KalmValidateErrorList
+
+	if !isValidResourceName(r.Name) {
+		rst = append(rst, KalmValidateError{
+			Err:  "invalid name: " + r.Name,
+			Path: "metadata.name",
+		})
+	} else if IsBuiltinRole(r.Name) {
+		rst = append(rst, KalmValidateError{
+			Err:  fmt.Sprintf("%s is a builtin role", r.Name),
+			Path: "metadata.name",
+		})
+	}
+
+	if len(r.Spec.Rules) == 0 {
+		rst = append(rst, KalmValidateError{
+			Err:  "should have at least 1 rule",
+			Path: "spec.rules",
+		})
+	}
+
+	for i, rule := range r.Spec.Rules {
+		switch rule.Action {
+		case "view", "edit", "manage":
+		default:
+			rst = append(rst, KalmValidateError{
+				Err:  "action should be one of: view, edit, manage",
+				Path: fmt.Sprintf("spec.rules[%d].action", i),
+			})
+		}
+
+		if !roleRuleResourceRegex.MatchString(rule.Resource) {
+			rst = append(rst, KalmValidateError{
+				Err:  "invalid resource, should be *, <kind>/* or <kind>/<name>, " + rule.Resource,
+				Path: fmt.Sprintf("spec.rules[%d].resource", i),
+			})
+		}
+	}
+
+	if len(rst) == 0 {
+		return nil
+	}
+
+	return rst
+}
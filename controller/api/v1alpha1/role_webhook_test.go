@@ -0,0 +1,49 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestRoleValidate(t *testing.T) {
+	role := Role{
+		ObjectMeta: ctrl.ObjectMeta{
+			Name: "job-operator",
+		},
+		Spec: RoleSpec{
+			Rules: []RoleRule{
+				{Action: "view", Resource: "*"},
+				{Action: "edit", Resource: "components/*"},
+				{Action: "edit", Resource: "pods/web-*"},
+				{Action: "manage", Resource: "protectedEndpoints/web"},
+			},
+		},
+	}
+
+	assert.Nil(t, role.validate())
+
+	role.Spec.Rules = []RoleRule{
+		{Action: "exec", Resource: "pods/*"},
+		{Action: "view", Resource: "pods"},
+		{Action: "view", Resource: "pods/*, ns2, *"},
+		{Action: "view", Resource: "*/web"},
+	}
+
+	errList, ok := role.validate().(KalmValidateErrorList)
+	assert.True(t, ok)
+	assert.Len(t, errList, 4)
+	assert.Equal(t, "spec.rules[0].action", errList[0].Path)
+	assert.Equal(t, "spec.rules[1].resource", errList[1].Path)
+	assert.Equal(t, "spec.rules[2].resource", errList[2].Path)
+	assert.Equal(t, "spec.rules[3].resource", errList[3].Path)
+
+	role.Name = RoleOwner
+	role.Spec.Rules = []RoleRule{{Action: "view", Resource: "*"}}
+
+	errList, ok = role.validate().(KalmValidateErrorList)
+	assert.True(t, ok)
+	assert.Len(t, errList, 1)
+	assert.Equal(t, "metadata.name", errList[0].Path)
+}
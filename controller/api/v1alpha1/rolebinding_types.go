@@ -40,6 +40,15 @@ const (
 	SubjectTypeGroup = "group"
 )
 
+func IsBuiltinRole(role string) bool {
+	switch role {
+	case RoleViewer, RoleEditor, RoleOwner, ClusterRoleViewer, ClusterRoleEditor, ClusterRoleOwner, RoleSuspended, RolePlaceholder:
+		return true
+	}
+
+	return false
+}
+
 type RoleBindingSpec struct {
 	// +kubebuilder:validation:MinLength=1
 	Subject string `json:"subject"`
@@ -47,7 +56,9 @@ type RoleBindingSpec struct {
 	// +kubebuilder:validation:Enum=user;group
 	SubjectType string `json:"subjectType"`
 
-	// +kubebuilder:validation:Enum=viewer;editor;owner;clusterViewer;clusterEditor;clusterOwner;suspended;placeholder
+	// One of viewer, editor, owner, clusterViewer, clusterEditor, clusterOwner, suspended, placeholder,
+	// or the name of a custom Role
+	// +kubebuilder:validation:MinLength=1
 	Role string `json:"role"`
 
 	// Creator of this binding
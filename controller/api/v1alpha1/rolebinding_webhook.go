@@ -16,11 +16,13 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"context"
 	"crypto/md5"
 	"fmt"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
@@ -47,6 +49,9 @@ func (r *RoleBinding) Default() {
 
 var _ webhook.Validator = &RoleBinding{}
 
+// custom roles referenced by role bindings are checked by the validating webhook
+// +kubebuilder:rbac:groups=core.kalm.dev,resources=roles,verbs=get;list;watch
+
 func (r *RoleBinding) GetNameBaseOnRoleAndSubject() string {
 	switch r.Spec.Role {
 	case ClusterRoleViewer, ClusterRoleEditor, ClusterRoleOwner, RoleSuspended, RolePlaceholder:
@@ -124,6 +129,22 @@ func (r *RoleBinding) validate() error {
 		}
 	}
 
+	if !IsBuiltinRole(r.Spec.Role) {
+		var role Role
+
+		if !isValidResourceName(r.Spec.Role) {
+			rst = append(rst, KalmValidateError{
+				Err:  "invalid role: " + r.Spec.Role,
+				Path: ".spec.role",
+			})
+		} else if webhookClient != nil && webhookClient.Get(context.Background(), client.ObjectKey{Name: r.Spec.Role}, &role) != nil {
+			rst = append(rst, KalmValidateError{
+				Err:  fmt.Sprintf("role %s not exist", r.Spec.Role),
+				Path: ".spec.role",
+			})
+		}
+	}
+
 	if len(rst) == 0 {
 		return nil
 	}
@@ -20,3 +20,22 @@ func TestRoleBindingValidate(t *testing.T) {
 
 	assert.Nil(t, key.validate())
 }
+
+func TestRoleBindingValidateCustomRole(t *testing.T) {
+	binding := RoleBinding{
+		ObjectMeta: ctrl.ObjectMeta{
+			Name:      "test",
+			Namespace: "ns1",
+		},
+		Spec: RoleBindingSpec{
+			Subject: "abc",
+			Role:    "job-operator",
+			Creator: "test",
+		},
+	}
+
+	assert.Nil(t, binding.validate())
+
+	binding.Spec.Role = "Job_Operator"
+	assert.NotNil(t, binding.validate())
+}
@@ -69,6 +69,32 @@ type SingleSignOnConfigSpec struct {
 	ExternalEnvoyExtAuthz *ExtAuthzEndpoint `json:"externalEnvoyExtAuthz,omitempty"`
 
 	IDTokenExpirySeconds *uint32 `json:"idTokenExpirySeconds,omitempty"`
+
+	// Map the groups from the identity provider, such as github org:team, gitlab group paths and ldap DNs,
+	// to the groups used as subjects of role bindings.
+	GroupMappings []GroupMapping `json:"groupMappings,omitempty"`
+}
+
+type GroupMappingType string
+
+const (
+	GroupMappingTypeExact  GroupMappingType = "exact"
+	GroupMappingTypePrefix GroupMappingType = "prefix"
+	GroupMappingTypeRegex  GroupMappingType = "regex"
+)
+
+type GroupMapping struct {
+	// +kubebuilder:validation:Enum=exact;prefix;regex
+	Type GroupMappingType `json:"type"`
+
+	// The group, the prefix of the group, or the regex which should match the whole group
+	// +kubebuilder:validation:MinLength=1
+	Match string `json:"match"`
+
+	// Groups granted if the group from the identity provider is matched.
+	// For regex mappings, $1 or ${name} are replaced with the submatches.
+	// +kubebuilder:validation:MinItems=1
+	Groups []string `json:"groups"`
 }
 
 // SingleSignOnConfigStatus defines the observed state of SingleSignOnConfig
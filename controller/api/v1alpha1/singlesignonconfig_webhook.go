@@ -208,6 +208,32 @@ func (r *SingleSignOnConfig) commonValidate() error {
 		}
 	}
 
+	for i, mapping := range r.Spec.GroupMappings {
+		basePath := field.NewPath("spec", "groupMappings", strconv.Itoa(i))
+
+		if mapping.Match == "" {
+			allErrs = append(allErrs, field.Invalid(basePath.Child("match"), mapping.Match, "Can't be blank"))
+		}
+
+		if mapping.Type == GroupMappingTypeRegex {
+			if _, err := compileGroupMappingRegex(mapping.Match); err != nil {
+				allErrs = append(allErrs, field.Invalid(basePath.Child("match"), mapping.Match, fmt.Sprintf("Invalid regex: %s", err.Error())))
+			}
+		} else if mapping.Type != GroupMappingTypeExact && mapping.Type != GroupMappingTypePrefix {
+			allErrs = append(allErrs, field.Invalid(basePath.Child("type"), mapping.Type, fmt.Sprintf("Unsupport group mapping type: %s", mapping.Type)))
+		}
+
+		if len(mapping.Groups) == 0 {
+			allErrs = append(allErrs, field.Invalid(basePath.Child("groups"), mapping.Groups, "Can't be blank"))
+		}
+
+		for j, group := range mapping.Groups {
+			if group == "" {
+				allErrs = append(allErrs, field.Invalid(basePath.Child("groups", strconv.Itoa(j)), group, "Can't be blank"))
+			}
+		}
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
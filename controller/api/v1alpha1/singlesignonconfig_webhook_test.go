@@ -29,3 +29,29 @@ func TestSingleSignOnConfig_Webhook(t *testing.T) {
 	ssoConfig.Default()
 	assert.Nil(t, ssoConfig.commonValidate())
 }
+
+func TestSingleSignOnConfig_WebhookGroupMappings(t *testing.T) {
+	ssoConfig := SingleSignOnConfig{
+		ObjectMeta: ctrl.ObjectMeta{
+			Namespace: "test-ns",
+			Name:      "test-name",
+		},
+		Spec: SingleSignOnConfigSpec{
+			Domain:        "sso.kapp.live",
+			TemporaryUser: &TemporaryDexUser{},
+			GroupMappings: []GroupMapping{
+				{Type: GroupMappingTypeExact, Match: "kalmhq:admins", Groups: []string{"kalm-admins"}},
+				{Type: GroupMappingTypeRegex, Match: "(", Groups: []string{"foo"}},
+				{Type: "glob", Match: "kalmhq:*", Groups: []string{"foo"}},
+				{Type: GroupMappingTypePrefix, Match: "kalmhq:", Groups: []string{}},
+			},
+		},
+	}
+
+	err := ssoConfig.commonValidate()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "spec.groupMappings.1.match")
+	assert.Contains(t, err.Error(), "spec.groupMappings.2.type")
+	assert.Contains(t, err.Error(), "spec.groupMappings.3.groups")
+	assert.NotContains(t, err.Error(), "spec.groupMappings.0")
+}
@@ -0,0 +1,92 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:validation:Enum=tcp;tls
+type TcpRouteProtocol string
+
+const (
+	// raw tcp, the port can only be used by one route
+	TcpRouteProtocolTCP TcpRouteProtocol = "tcp"
+	// tls passthrough, routes sharing a port are matched by SNI, the tls is terminated by the destinations
+	TcpRouteProtocolTLS TcpRouteProtocol = "tls"
+)
+
+// TcpRouteSpec defines the desired state of TcpRoute
+//
+// The port is opened on the istio ingress gateway, it also needs to be exposed by the
+// istio-ingressgateway service, which is managed by the istio operator. New ports are
+// refused until they are added to the ingress gateway ports of the IstioOperator.
+type TcpRouteSpec struct {
+	// +kubebuilder:validation:Enum=tcp;tls
+	Protocol TcpRouteProtocol `json:"protocol"`
+
+	// port of the ingress gateway, 80 and 443 are reserved for http routes
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int `json:"port"`
+
+	// SNI hosts of tls routes, should be empty for tcp routes
+	Hosts []string `json:"hosts,omitempty"`
+
+	// host of the destination should contain the port, e.g. postgres.default.svc.cluster.local:5432
+	// +kubebuilder:validation:MinItems=1
+	Destinations []HttpRouteDestination `json:"destinations"`
+}
+
+// TcpRouteStatus defines the observed state of TcpRoute
+type TcpRouteStatus struct {
+	DestinationsStatus []HttpRouteDestinationStatus `json:"destinationsStatus,omitempty"`
+
+	// whether the port is exposed by the istio-ingressgateway service, the route is not reachable if not
+	PortExposed bool   `json:"portExposed,omitempty"`
+	PortMessage string `json:"portMessage,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Protocol",type="string",JSONPath=".spec.protocol"
+// +kubebuilder:printcolumn:name="Port",type="integer",JSONPath=".spec.port"
+// +kubebuilder:printcolumn:name="Hosts",type="string",JSONPath=".spec.hosts"
+// +kubebuilder:printcolumn:name="Exposed",type="boolean",JSONPath=".status.portExposed"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// TcpRoute is the Schema for the tcproutes API
+type TcpRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TcpRouteSpec   `json:"spec,omitempty"`
+	Status TcpRouteStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TcpRouteList contains a list of TcpRoute
+type TcpRouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TcpRoute `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TcpRoute{}, &TcpRouteList{})
+}
@@ -0,0 +1,269 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kalmhq/kalm/controller/validation"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var tcproutelog = logf.Log.WithName("tcproute-resource")
+
+// ports used by the http gateways and istio itself
+var reservedTcpRoutePorts = map[int]bool{
+	80:    true,
+	443:   true,
+	15021: true,
+	15090: true,
+}
+
+func (r *TcpRoute) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-core-kalm-dev-v1alpha1-tcproute,mutating=false,failurePolicy=fail,groups=core.kalm.dev,resources=tcproutes,versions=v1alpha1,name=vtcproute.kb.io
+
+var _ webhook.Validator = &TcpRoute{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *TcpRoute) ValidateCreate() error {
+	tcproutelog.Info("validate create", "name", r.Name)
+
+	if err := r.validate(); err != nil {
+		return err
+	}
+
+	if err := r.validatePortExposed(); err != nil {
+		return err
+	}
+
+	return hostsOwnershipError(r.Spec.Hosts, nil, "spec.hosts")
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *TcpRoute) ValidateUpdate(old runtime.Object) error {
+	tcproutelog.Info("validate update", "name", r.Name)
+
+	oldRoute, ok := old.(*TcpRoute)
+	if !ok {
+		return fmt.Errorf("old is not *TcpRoute, %+v", old)
+	}
+
+	if err := r.validate(); err != nil {
+		return err
+	}
+
+	// routes created before the check are reported in the status
+	if oldRoute.Spec.Port != r.Spec.Port {
+		if err := r.validatePortExposed(); err != nil {
+			return err
+		}
+	}
+
+	return hostsOwnershipError(r.Spec.Hosts, oldRoute.Spec.Hosts, "spec.hosts")
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *TcpRoute) ValidateDelete() error {
+	tcproutelog.Info("validate delete", "name", r.Name)
+	return nil
+}
+
+func (r *TcpRoute) validate() error {
+	var rst KalmValidateErrorList
+
+	switch r.Spec.Protocol {
+	case TcpRouteProtocolTCP:
+		if len(r.Spec.Hosts) > 0 {
+			rst = append(rst, KalmValidateError{
+				Err:  "tcp route can't match hosts, use tls protocol to route by SNI",
+				Path: "spec.hosts",
+			})
+		}
+	case TcpRouteProtocolTLS:
+		if len(r.Spec.Hosts) == 0 {
+			rst = append(rst, KalmValidateError{
+				Err:  "tls route should have at least 1 SNI host",
+				Path: "spec.hosts",
+			})
+		}
+
+		for i, host := range r.Spec.Hosts {
+			if !isValidRouteHost(host) || validation.ValidateIPAddress(host) == nil {
+				rst = append(rst, KalmValidateError{
+					Err:  "invalid SNI host:" + host,
+					Path: fmt.Sprintf("spec.hosts[%d]", i),
+				})
+			}
+		}
+	case "udp":
+		rst = append(rst, KalmValidateError{
+			Err:  "udp is not supported by the istio ingress gateway",
+			Path: "spec.protocol",
+		})
+	default:
+		rst = append(rst, KalmValidateError{
+			Err:  "protocol should be one of: tcp, tls",
+			Path: "spec.protocol",
+		})
+	}
+
+	if r.Spec.Port <= 0 || r.Spec.Port > 65535 {
+		rst = append(rst, KalmValidateError{
+			Err:  "port should be between 1 and 65535",
+			Path: "spec.port",
+		})
+	} else if reservedTcpRoutePorts[r.Spec.Port] {
+		rst = append(rst, KalmValidateError{
+			Err:  fmt.Sprintf("port %d is reserved", r.Spec.Port),
+			Path: "spec.port",
+		})
+	}
+
+	if len(r.Spec.Destinations) == 0 {
+		rst = append(rst, KalmValidateError{
+			Err:  "should have at least 1 destination",
+			Path: "spec.destinations",
+		})
+	}
+
+	for i, dest := range r.Spec.Destinations {
+		if !isValidTcpRouteDestinationHost(dest.Host) {
+			rst = append(rst, KalmValidateError{
+				Err:  "invalid destination host, should be in the format of host:port, " + dest.Host,
+				Path: fmt.Sprintf("spec.destinations[%d].host", i),
+			})
+		}
+	}
+
+	if len(rst) == 0 && webhookClient != nil {
+		var routeList TcpRouteList
+		if err := webhookClient.List(context.Background(), &routeList); err != nil {
+			return err
+		}
+
+		rst = append(rst, r.checkPortConflicts(routeList.Items)...)
+	}
+
+	if len(rst) == 0 {
+		return nil
+	}
+
+	return rst
+}
+
+// the ingress gateway is not checked if it is not installed yet
+func (r *TcpRoute) validatePortExposed() error {
+	if webhookClient == nil {
+		return nil
+	}
+
+	var svc corev1.Service
+	if err := webhookClient.Get(context.Background(), IngressGatewayServiceName, &svc); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	if err := CheckIngressGatewayPort(&svc, r.Spec.Port); err != nil {
+		return KalmValidateErrorList{{
+			Err:  err.Error(),
+			Path: "spec.port",
+		}}
+	}
+
+	return nil
+}
+
+var IngressGatewayServiceName = types.NamespacedName{Namespace: "istio-system", Name: "istio-ingressgateway"}
+
+// the ports of the istio-ingressgateway service are managed by the istio operator,
+// changes to the service are reverted, so the ports are not added by kalm.
+func CheckIngressGatewayPort(svc *corev1.Service, port int) error {
+	for _, servicePort := range svc.Spec.Ports {
+		if int(servicePort.Port) == port && (servicePort.Protocol == "" || servicePort.Protocol == corev1.ProtocolTCP) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf(
+		"port %d is not exposed by service %s, add it to the ports of the ingress gateway in the IstioOperator first",
+		port, IngressGatewayServiceName,
+	)
+}
+
+// a tcp port can only be used by one route, a tls port can be shared by routes with different SNI hosts
+func (r *TcpRoute) checkPortConflicts(routes []TcpRoute) KalmValidateErrorList {
+	var rst KalmValidateErrorList
+
+	for _, route := range routes {
+		if route.Name == r.Name || route.Spec.Port != r.Spec.Port {
+			continue
+		}
+
+		if route.Spec.Protocol != TcpRouteProtocolTLS || r.Spec.Protocol != TcpRouteProtocolTLS {
+			rst = append(rst, KalmValidateError{
+				Err:  fmt.Sprintf("port %d is already used by route %s", r.Spec.Port, route.Name),
+				Path: "spec.port",
+			})
+
+			continue
+		}
+
+		for i, host := range r.Spec.Hosts {
+			for _, usedHost := range route.Spec.Hosts {
+				if strings.EqualFold(host, usedHost) {
+					rst = append(rst, KalmValidateError{
+						Err:  fmt.Sprintf("host %s on port %d is already used by route %s", host, r.Spec.Port, route.Name),
+						Path: fmt.Sprintf("spec.hosts[%d]", i),
+					})
+				}
+			}
+		}
+	}
+
+	return rst
+}
+
+func isValidTcpRouteDestinationHost(host string) bool {
+	colon := strings.LastIndexByte(host, ':')
+	if colon == -1 {
+		return false
+	}
+
+	port, err := strconv.Atoi(host[colon+1:])
+	if err != nil || port <= 0 || port > 65535 {
+		return false
+	}
+
+	return isValidDestinationHost(host)
+}
@@ -0,0 +1,115 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestTcpRoute_Validate(t *testing.T) {
+	route := TcpRoute{
+		ObjectMeta: ctrl.ObjectMeta{
+			Name: "postgres",
+		},
+		Spec: TcpRouteSpec{
+			Protocol: TcpRouteProtocolTCP,
+			Port:     5432,
+			Destinations: []HttpRouteDestination{
+				{Host: "postgres.default.svc.cluster.local:5432", Weight: 1},
+			},
+		},
+	}
+
+	assert.Nil(t, route.validate())
+
+	// tcp routes can't match hosts
+	route.Spec.Hosts = []string{"db.example.com"}
+	assert.NotNil(t, route.validate())
+
+	route.Spec.Protocol = TcpRouteProtocolTLS
+	assert.Nil(t, route.validate())
+
+	route.Spec.Hosts = nil
+	assert.NotNil(t, route.validate())
+
+	route.Spec.Hosts = []string{"1.1.1.1"}
+	assert.NotNil(t, route.validate())
+
+	route.Spec.Hosts = []string{"*.example.com"}
+	assert.Nil(t, route.validate())
+
+	route.Spec.Protocol = "udp"
+	assert.NotNil(t, route.validate())
+
+	route.Spec.Protocol = TcpRouteProtocolTLS
+	route.Spec.Port = 443
+	assert.NotNil(t, route.validate())
+
+	route.Spec.Port = 70000
+	assert.NotNil(t, route.validate())
+
+	// destination without port
+	route.Spec.Port = 8883
+	route.Spec.Destinations = []HttpRouteDestination{{Host: "mqtt.default.svc.cluster.local", Weight: 1}}
+	assert.NotNil(t, route.validate())
+
+	route.Spec.Destinations = nil
+	assert.NotNil(t, route.validate())
+}
+
+func TestTcpRoute_CheckPortConflicts(t *testing.T) {
+	routes := []TcpRoute{
+		{
+			ObjectMeta: ctrl.ObjectMeta{Name: "postgres"},
+			Spec:       TcpRouteSpec{Protocol: TcpRouteProtocolTCP, Port: 5432},
+		},
+		{
+			ObjectMeta: ctrl.ObjectMeta{Name: "mqtt"},
+			Spec:       TcpRouteSpec{Protocol: TcpRouteProtocolTLS, Port: 8883, Hosts: []string{"mqtt.example.com"}},
+		},
+	}
+
+	route := TcpRoute{
+		ObjectMeta: ctrl.ObjectMeta{Name: "postgres"},
+		Spec:       TcpRouteSpec{Protocol: TcpRouteProtocolTCP, Port: 5432},
+	}
+
+	// the route itself
+	assert.Len(t, route.checkPortConflicts(routes), 0)
+
+	route.Name = "another-postgres"
+	assert.Len(t, route.checkPortConflicts(routes), 1)
+
+	route.Spec = TcpRouteSpec{Protocol: TcpRouteProtocolTLS, Port: 8883, Hosts: []string{"mqtt2.example.com"}}
+	assert.Len(t, route.checkPortConflicts(routes), 0)
+
+	route.Spec.Hosts = append(route.Spec.Hosts, "MQTT.example.com")
+	errs := route.checkPortConflicts(routes)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "spec.hosts[1]", errs[0].Path)
+
+	route.Spec = TcpRouteSpec{Protocol: TcpRouteProtocolTCP, Port: 8883}
+	assert.Len(t, route.checkPortConflicts(routes), 1)
+}
+
+func TestCheckIngressGatewayPort(t *testing.T) {
+	svc := corev1.Service{
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "http2", Port: 80, Protocol: corev1.ProtocolTCP},
+				{Name: "tcp-postgres", Port: 5432, Protocol: corev1.ProtocolTCP},
+				{Name: "udp-dns", Port: 53, Protocol: corev1.ProtocolUDP},
+			},
+		},
+	}
+
+	assert.Nil(t, CheckIngressGatewayPort(&svc, 5432))
+	assert.EqualError(
+		t,
+		CheckIngressGatewayPort(&svc, 8883),
+		"port 8883 is not exposed by service istio-system/istio-ingressgateway, add it to the ports of the ingress gateway in the IstioOperator first",
+	)
+	assert.NotNil(t, CheckIngressGatewayPort(&svc, 53))
+}
@@ -3,6 +3,7 @@ package v1alpha1
 import (
 	"container/list"
 	"fmt"
+	"sort"
 )
 
 // 1. check if there is any loop in dependency graph
@@ -152,3 +153,89 @@ type node struct {
 	Name  string
 	Refed []*node
 }
+
+// ResolveComponentStartupOrder groups the components of an application into stages.
+// Components in a stage start in parallel once all components they start after are ready.
+// Components which are, or depend on, a dependency loop never start and are returned in inLoop.
+// Components which start after a component that doesn't exist, directly or through other components,
+// never start either and are returned in blocked.
+func ResolveComponentStartupOrder(components []Component) (stages [][]string, inLoop []string, blocked []string) {
+	deps := make(map[string][]string, len(components))
+
+	for _, component := range components {
+		deps[component.Name] = component.Spec.StartAfterComponents
+	}
+
+	started := make(map[string]bool, len(components))
+
+	for len(started) < len(deps) {
+		var stage []string
+
+		for name, nameDeps := range deps {
+			if started[name] {
+				continue
+			}
+
+			ready := true
+			for _, dep := range nameDeps {
+				if !started[dep] {
+					ready = false
+					break
+				}
+			}
+
+			if ready {
+				stage = append(stage, name)
+			}
+		}
+
+		if len(stage) == 0 {
+			break
+		}
+
+		sort.Strings(stage)
+
+		for _, name := range stage {
+			started[name] = true
+		}
+
+		stages = append(stages, stage)
+	}
+
+	isBlocked := make(map[string]bool)
+
+	for changed := true; changed; {
+		changed = false
+
+		for name, nameDeps := range deps {
+			if started[name] || isBlocked[name] {
+				continue
+			}
+
+			for _, dep := range nameDeps {
+				if _, exist := deps[dep]; !exist || isBlocked[dep] {
+					isBlocked[name] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	for name := range deps {
+		if started[name] {
+			continue
+		}
+
+		if isBlocked[name] {
+			blocked = append(blocked, name)
+		} else {
+			inLoop = append(inLoop, name)
+		}
+	}
+
+	sort.Strings(inLoop)
+	sort.Strings(blocked)
+
+	return stages, inLoop, blocked
+}
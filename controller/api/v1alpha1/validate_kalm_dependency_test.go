@@ -1,5 +1,56 @@
 package v1alpha1
 
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func componentStartAfter(name string, deps ...string) Component {
+	return Component{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       ComponentSpec{StartAfterComponents: deps},
+	}
+}
+
+func TestResolveComponentStartupOrder(t *testing.T) {
+	stages, inLoop, blocked := ResolveComponentStartupOrder([]Component{
+		componentStartAfter("web", "api", "cache"),
+		componentStartAfter("api", "db"),
+		componentStartAfter("db"),
+		componentStartAfter("cache"),
+	})
+
+	assert.Equal(t, [][]string{{"cache", "db"}, {"api"}, {"web"}}, stages)
+	assert.Nil(t, inLoop)
+	assert.Nil(t, blocked)
+
+	stages, inLoop, blocked = ResolveComponentStartupOrder([]Component{
+		componentStartAfter("a", "b"),
+		componentStartAfter("b", "a"),
+		componentStartAfter("c", "a"),
+		componentStartAfter("d"),
+	})
+
+	assert.Equal(t, [][]string{{"d"}}, stages)
+	assert.Equal(t, []string{"a", "b", "c"}, inLoop)
+	assert.Nil(t, blocked)
+
+	// a typo in startAfterComponents blocks the component and its dependents
+	stages, inLoop, blocked = ResolveComponentStartupOrder([]Component{
+		componentStartAfter("web", "api"),
+		componentStartAfter("api", "dbb"),
+		componentStartAfter("db"),
+		componentStartAfter("x", "y"),
+		componentStartAfter("y", "x", "not-exist"),
+	})
+
+	assert.Equal(t, [][]string{{"db"}}, stages)
+	assert.Nil(t, inLoop)
+	assert.Equal(t, []string{"api", "web", "x", "y"}, blocked)
+}
+
 //
 //func TestIsValidateDependency(t *testing.T) {
 //	appSpec := ApplicationSpec{
@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	autoscalingV2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+
+func (r *ComponentReconcilerTask) isAutoscaled() bool {
+	return r.component.Spec.Autoscaling != nil && !isComponentLabeledAsExceedingQuota(r.component)
+}
+
+// getScaleTargetRef returns the workload scaled by the autoscaler, nil if the workload is not scalable.
+func (r *ComponentReconcilerTask) getScaleTargetRef() *autoscalingV2beta2.CrossVersionObjectReference {
+	switch r.component.Spec.WorkloadType {
+	case v1alpha1.WorkloadTypeServer, "":
+		return &autoscalingV2beta2.CrossVersionObjectReference{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Name:       r.component.Name,
+		}
+	case v1alpha1.WorkloadTypeStatefulSet:
+		return &autoscalingV2beta2.CrossVersionObjectReference{
+			APIVersion: "apps/v1",
+			Kind:       "StatefulSet",
+			Name:       r.component.Name,
+		}
+	default:
+		return nil
+	}
+}
+
+func buildAutoscalingMetrics(autoscaling *v1alpha1.AutoscalingSpec) []autoscalingV2beta2.MetricSpec {
+	var metrics []autoscalingV2beta2.MetricSpec
+
+	if autoscaling.TargetCPUUtilizationPercentage != nil {
+		metrics = append(metrics, autoscalingV2beta2.MetricSpec{
+			Type: autoscalingV2beta2.ResourceMetricSourceType,
+			Resource: &autoscalingV2beta2.ResourceMetricSource{
+				Name: corev1.ResourceCPU,
+				Target: autoscalingV2beta2.MetricTarget{
+					Type:               autoscalingV2beta2.UtilizationMetricType,
+					AverageUtilization: autoscaling.TargetCPUUtilizationPercentage,
+				},
+			},
+		})
+	}
+
+	if autoscaling.TargetMemoryUtilizationPercentage != nil {
+		metrics = append(metrics, autoscalingV2beta2.MetricSpec{
+			Type: autoscalingV2beta2.ResourceMetricSourceType,
+			Resource: &autoscalingV2beta2.ResourceMetricSource{
+				Name: corev1.ResourceMemory,
+				Target: autoscalingV2beta2.MetricTarget{
+					Type:               autoscalingV2beta2.UtilizationMetricType,
+					AverageUtilization: autoscaling.TargetMemoryUtilizationPercentage,
+				},
+			},
+		})
+	}
+
+	if autoscaling.TargetRequestsPerSecond != nil {
+		averageValue := resource.NewQuantity(int64(*autoscaling.TargetRequestsPerSecond), resource.DecimalSI)
+
+		metrics = append(metrics, autoscalingV2beta2.MetricSpec{
+			Type: autoscalingV2beta2.PodsMetricSourceType,
+			Pods: &autoscalingV2beta2.PodsMetricSource{
+				Metric: autoscalingV2beta2.MetricIdentifier{
+					Name: v1alpha1.IstioRequestsPerSecondMetricName,
+				},
+				Target: autoscalingV2beta2.MetricTarget{
+					Type:         autoscalingV2beta2.AverageValueMetricType,
+					AverageValue: averageValue,
+				},
+			},
+		})
+	}
+
+	return metrics
+}
+
+// ReconcileAutoscaling keeps a HorizontalPodAutoscaler for the workload of the component.
+// It's removed if the component has no autoscaling, its namespace is not kalm-enabled,
+// it's forced to scale down because of exceeding quota, or it's waiting for its dependencies.
+func (r *ComponentReconcilerTask) ReconcileAutoscaling() error {
+	targetRef := r.getScaleTargetRef()
+
+	if !IsNamespaceKalmEnabled(r.namespace) || !r.isAutoscaled() || targetRef == nil || r.isWaitingForDependencies() {
+		if r.hpa != nil {
+			if err := r.Delete(r.ctx, r.hpa); client.IgnoreNotFound(err) != nil {
+				r.WarningEvent(err, "unable to delete HorizontalPodAutoscaler for Component")
+				return err
+			}
+
+			r.hpa = nil
+		}
+
+		return nil
+	}
+
+	autoscaling := r.component.Spec.Autoscaling
+	minReplicas := autoscaling.MinReplicas
+
+	spec := autoscalingV2beta2.HorizontalPodAutoscalerSpec{
+		ScaleTargetRef: *targetRef,
+		MinReplicas:    &minReplicas,
+		MaxReplicas:    autoscaling.MaxReplicas,
+		Metrics:        buildAutoscalingMetrics(autoscaling),
+	}
+
+	if r.hpa == nil {
+		hpa := &autoscalingV2beta2.HorizontalPodAutoscaler{
+			ObjectMeta: metaV1.ObjectMeta{
+				Name:      r.component.Name,
+				Namespace: r.component.Namespace,
+				Labels:    r.GetLabels(),
+			},
+			Spec: spec,
+		}
+
+		if err := ctrl.SetControllerReference(r.component, hpa, r.Scheme); err != nil {
+			r.WarningEvent(err, "unable to set owner for HorizontalPodAutoscaler")
+			return err
+		}
+
+		if err := r.Create(r.ctx, hpa); err != nil {
+			r.WarningEvent(err, "unable to create HorizontalPodAutoscaler for Component")
+			return err
+		}
+
+		r.hpa = hpa
+
+		return nil
+	}
+
+	copied := r.hpa.DeepCopy()
+	copied.Spec = spec
+
+	if err := r.Patch(r.ctx, copied, client.MergeFrom(r.hpa)); err != nil {
+		r.WarningEvent(err, "unable to patch HorizontalPodAutoscaler for Component")
+		return err
+	}
+
+	r.hpa = copied
+
+	return nil
+}
+
+// getAutoscaledReplicas returns the replicas of an existing workload, which are owned by the autoscaler.
+// A new workload starts with the min replicas.
+func (r *ComponentReconcilerTask) getAutoscaledReplicas(current *int32) *int32 {
+	if current != nil {
+		replicas := *current
+		return &replicas
+	}
+
+	replicas := r.component.Spec.Autoscaling.MinReplicas
+
+	return &replicas
+}
+
+func (r *ComponentReconcilerTask) getAutoscalingStatus() *v1alpha1.ComponentAutoscalingStatus {
+	if r.hpa == nil {
+		return nil
+	}
+
+	return &v1alpha1.ComponentAutoscalingStatus{
+		CurrentReplicas: r.hpa.Status.CurrentReplicas,
+		DesiredReplicas: r.hpa.Status.DesiredReplicas,
+		LastScaleTime:   r.hpa.Status.LastScaleTime,
+	}
+}
+
+func (r *ComponentReconcilerTask) LoadHorizontalPodAutoscaler() error {
+	var hpa autoscalingV2beta2.HorizontalPodAutoscaler
+
+	if err := r.Reader.Get(
+		r.ctx,
+		types.NamespacedName{
+			Namespace: r.component.Namespace,
+			Name:      r.component.Name,
+		},
+		&hpa,
+	); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	r.hpa = &hpa
+
+	return nil
+}
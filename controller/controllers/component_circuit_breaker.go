@@ -0,0 +1,279 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	protoTypes "github.com/gogo/protobuf/types"
+	v1alpha32 "istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+)
+
+// the prometheus endpoint of the istio proxy,
+// the ingress gateway includes the outlier detection stats with the sidecar.istio.io/statsInclusionSuffixes annotation
+const istioProxyPrometheusPort = 15090
+
+const outlierDetectionCheckInterval = 30 * time.Second
+
+func buildCircuitBreakerPolicy(circuitBreaker *v1alpha1.CircuitBreaker) (*v1alpha32.ConnectionPoolSettings, *v1alpha32.OutlierDetection) {
+	if circuitBreaker == nil {
+		return nil, nil
+	}
+
+	var connectionPool *v1alpha32.ConnectionPoolSettings
+
+	if circuitBreaker.MaxConnections > 0 || circuitBreaker.MaxPendingRequests > 0 {
+		connectionPool = &v1alpha32.ConnectionPoolSettings{}
+
+		if circuitBreaker.MaxConnections > 0 {
+			connectionPool.Tcp = &v1alpha32.ConnectionPoolSettings_TCPSettings{
+				MaxConnections: circuitBreaker.MaxConnections,
+			}
+		}
+
+		if circuitBreaker.MaxPendingRequests > 0 {
+			connectionPool.Http = &v1alpha32.ConnectionPoolSettings_HTTPSettings{
+				Http1MaxPendingRequests: circuitBreaker.MaxPendingRequests,
+			}
+		}
+	}
+
+	if !circuitBreaker.OutlierDetectionEnabled() {
+		return connectionPool, nil
+	}
+
+	outlierDetection := &v1alpha32.OutlierDetection{
+		Consecutive_5XxErrors: &protoTypes.UInt32Value{Value: uint32(circuitBreaker.Consecutive5xxErrors)},
+		MaxEjectionPercent:    circuitBreaker.MaxEjectionPercent,
+	}
+
+	if circuitBreaker.IntervalSeconds > 0 {
+		outlierDetection.Interval = &protoTypes.Duration{Seconds: int64(circuitBreaker.IntervalSeconds)}
+	}
+
+	if circuitBreaker.BaseEjectionSeconds > 0 {
+		outlierDetection.BaseEjectionTime = &protoTypes.Duration{Seconds: int64(circuitBreaker.BaseEjectionSeconds)}
+	}
+
+	return connectionPool, outlierDetection
+}
+
+var ejectionsActiveMetricRegexp = regexp.MustCompile(`^envoy_cluster_outlier_detection_ejections_active\{.*cluster_name="([^"]+)".*\}\s+(\S+)$`)
+
+// parseEjectionsActive sums the active ejections of the clusters of each host
+// from the prometheus stats of an envoy proxy. Cluster names are in the form of direction|port|subset|host.
+func parseEjectionsActive(r io.Reader) (map[string]int32, error) {
+	rst := make(map[string]int32)
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		matches := ejectionsActiveMetricRegexp.FindStringSubmatch(scanner.Text())
+		if matches == nil {
+			continue
+		}
+
+		parts := strings.Split(matches[1], "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(matches[2], 64)
+		if err != nil {
+			continue
+		}
+
+		rst[parts[3]] += int32(value)
+	}
+
+	return rst, scanner.Err()
+}
+
+// ejectionsCache holds the active ejections of all hosts of the ingress gateway.
+// It's shared by the reconciles of all components, so that the gateway pods are scraped
+// at most once per interval no matter how many components there are.
+type ejectionsCache struct {
+	mut       sync.Mutex
+	interval  time.Duration
+	scrape    func() (map[string]int32, error)
+	scrapedAt time.Time
+	hosts     map[string]int32
+}
+
+func (c *ejectionsCache) get() (map[string]int32, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.hosts != nil && time.Since(c.scrapedAt) < c.interval {
+		return c.hosts, nil
+	}
+
+	hosts, err := c.scrape()
+	if err != nil {
+		return nil, err
+	}
+
+	c.hosts = hosts
+	c.scrapedAt = time.Now()
+
+	return hosts, nil
+}
+
+// ComponentOutlierDetectionReconciler reports the pods of components ejected by the ingress gateway
+type ComponentOutlierDetectionReconciler struct {
+	*BaseReconciler
+	ctx        context.Context
+	httpClient *http.Client
+	ejections  *ejectionsCache
+}
+
+func NewComponentOutlierDetectionReconciler(mgr ctrl.Manager) *ComponentOutlierDetectionReconciler {
+	r := &ComponentOutlierDetectionReconciler{
+		BaseReconciler: NewBaseReconciler(mgr, "ComponentOutlierDetection"),
+		ctx:            context.Background(),
+		httpClient:     &http.Client{Timeout: 5 * time.Second},
+	}
+
+	r.ejections = &ejectionsCache{
+		interval: outlierDetectionCheckInterval,
+		scrape:   r.scrapeIngressGateway,
+	}
+
+	return r
+}
+
+// +kubebuilder:rbac:groups=core.kalm.dev,resources=components,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core.kalm.dev,resources=components/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+
+func (r *ComponentOutlierDetectionReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	var component v1alpha1.Component
+	if err := r.Get(r.ctx, req.NamespacedName, &component); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if component.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	copied := component.DeepCopy()
+
+	if !component.Spec.CircuitBreaker.OutlierDetectionEnabled() || len(component.Spec.Ports) == 0 {
+		copied.Status.OutlierDetection = nil
+		return ctrl.Result{}, r.patchStatus(&component, copied)
+	}
+
+	ejections, err := r.ejections.get()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	ejectedHosts := ejections[fmt.Sprintf("%s.%s.svc.cluster.local", component.Name, component.Namespace)]
+
+	status := &v1alpha1.ComponentOutlierDetectionStatus{EjectedHosts: ejectedHosts}
+
+	if component.Status.OutlierDetection != nil {
+		status.LastEjectionTime = component.Status.OutlierDetection.LastEjectionTime
+	}
+
+	if ejectedHosts > 0 && (component.Status.OutlierDetection == nil || component.Status.OutlierDetection.EjectedHosts == 0) {
+		now := metaV1.Now()
+		status.LastEjectionTime = &now
+		r.Recorder.Eventf(&component, corev1.EventTypeWarning, "HostsEjected",
+			"%d pods are ejected by the outlier detection of the ingress gateway", ejectedHosts)
+	}
+
+	copied.Status.OutlierDetection = status
+
+	if err := r.patchStatus(&component, copied); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{RequeueAfter: outlierDetectionCheckInterval}, nil
+}
+
+// Only status.outlierDetection is changed, the merge patch doesn't touch the fields set by the component controller,
+// which patches the status in the same way.
+func (r *ComponentOutlierDetectionReconciler) patchStatus(component, copied *v1alpha1.Component) error {
+	if equality.Semantic.DeepEqual(component.Status, copied.Status) {
+		return nil
+	}
+
+	err := r.Status().Patch(r.ctx, copied, client.MergeFrom(component))
+
+	if errors.IsNotFound(err) {
+		return nil
+	}
+
+	return err
+}
+
+// Each ingress gateway pod ejects the hosts independently, the max of them is reported for each host.
+// Unreachable gateway pods are skipped.
+func (r *ComponentOutlierDetectionReconciler) scrapeIngressGateway() (map[string]int32, error) {
+	var pods corev1.PodList
+	if err := r.Reader.List(r.ctx, &pods, client.InNamespace(istioNamespace), client.MatchingLabels{"app": "istio-ingressgateway"}); err != nil {
+		return nil, err
+	}
+
+	rst := make(map[string]int32)
+
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
+			continue
+		}
+
+		ejections, err := r.scrapeEjectionsActive(pod.Status.PodIP)
+		if err != nil {
+			r.Log.Error(err, "fail to get outlier detection stats", "pod", pod.Name)
+			continue
+		}
+
+		for host, count := range ejections {
+			if count > rst[host] {
+				rst[host] = count
+			}
+		}
+	}
+
+	return rst, nil
+}
+
+func (r *ComponentOutlierDetectionReconciler) scrapeEjectionsActive(podIP string) (map[string]int32, error) {
+	resp, err := r.httpClient.Get(fmt.Sprintf("http://%s:%d/stats/prometheus", podIP, istioProxyPrometheusPort))
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return parseEjectionsActive(resp.Body)
+}
+
+func (r *ComponentOutlierDetectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// status patches of this reconciler don't change the generation, the stats are polled with RequeueAfter
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("component-outlier-detection").
+		For(&v1alpha1.Component{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Complete(r)
+}
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildCircuitBreakerPolicy(t *testing.T) {
+	connectionPool, outlierDetection := buildCircuitBreakerPolicy(nil)
+	assert.Nil(t, connectionPool)
+	assert.Nil(t, outlierDetection)
+
+	connectionPool, outlierDetection = buildCircuitBreakerPolicy(&v1alpha1.CircuitBreaker{
+		MaxConnections: 100,
+	})
+	assert.Equal(t, int32(100), connectionPool.Tcp.MaxConnections)
+	assert.Nil(t, connectionPool.Http)
+	assert.Nil(t, outlierDetection)
+
+	connectionPool, outlierDetection = buildCircuitBreakerPolicy(&v1alpha1.CircuitBreaker{
+		MaxPendingRequests:   10,
+		Consecutive5xxErrors: 5,
+		BaseEjectionSeconds:  60,
+		MaxEjectionPercent:   50,
+	})
+	assert.Nil(t, connectionPool.Tcp)
+	assert.Equal(t, int32(10), connectionPool.Http.Http1MaxPendingRequests)
+	assert.Equal(t, uint32(5), outlierDetection.Consecutive_5XxErrors.Value)
+	assert.Nil(t, outlierDetection.Interval)
+	assert.Equal(t, int64(60), outlierDetection.BaseEjectionTime.Seconds)
+	assert.Equal(t, int32(50), outlierDetection.MaxEjectionPercent)
+}
+
+func TestParseEjectionsActive(t *testing.T) {
+	stats := `# TYPE envoy_cluster_outlier_detection_ejections_active gauge
+envoy_cluster_outlier_detection_ejections_active{cluster_name="outbound|80||web.default.svc.cluster.local"} 1
+envoy_cluster_outlier_detection_ejections_active{cluster_name="outbound|80|canary|web.default.svc.cluster.local"} 1
+envoy_cluster_outlier_detection_ejections_active{cluster_name="outbound|8080||api.default.svc.cluster.local"} 0
+envoy_cluster_outlier_detection_ejections_active{cluster_name="xds-grpc"} 2
+envoy_cluster_upstream_cx_active{cluster_name="outbound|80||web.default.svc.cluster.local"} 7
+`
+
+	rst, err := parseEjectionsActive(strings.NewReader(stats))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int32{
+		"web.default.svc.cluster.local": 2,
+		"api.default.svc.cluster.local": 0,
+	}, rst)
+}
+
+func TestEjectionsCache(t *testing.T) {
+	scrapes := 0
+
+	cache := &ejectionsCache{
+		interval: time.Minute,
+		scrape: func() (map[string]int32, error) {
+			scrapes++
+			return map[string]int32{"web.default.svc.cluster.local": int32(scrapes)}, nil
+		},
+	}
+
+	for i := 0; i < 3; i++ {
+		hosts, err := cache.get()
+		assert.Nil(t, err)
+		assert.Equal(t, int32(1), hosts["web.default.svc.cluster.local"])
+	}
+
+	assert.Equal(t, 1, scrapes)
+
+	cache.scrapedAt = time.Now().Add(-time.Minute)
+	hosts, err := cache.get()
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), hosts["web.default.svc.cluster.local"])
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"path"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+
+func getHotReloadFilesConfigMapName(componentName string) string {
+	return fmt.Sprintf("%s-hot-reload-files", componentName)
+}
+
+// the configMap key of a hot reload file, mount paths are not valid keys
+func getHotReloadFileKey(mountPath string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(mountPath)))[:16]
+}
+
+// ReconcileHotReloadFiles keeps the configMap holding the hot reload files of the component.
+// Changes of the configMap are synced into running pods by the kubelet.
+func (r *ComponentReconcilerTask) ReconcileHotReloadFiles() error {
+	data := make(map[string]string)
+	binaryData := make(map[string][]byte)
+
+	for _, file := range r.component.Spec.PreInjectedFiles {
+		if !file.HotReload {
+			continue
+		}
+
+		key := getHotReloadFileKey(file.MountPath)
+
+		if file.Base64 {
+			content, err := base64.StdEncoding.DecodeString(file.Content)
+			if err != nil {
+				return fmt.Errorf("invalid base64 content of pre-injected file %s: %s", file.MountPath, err)
+			}
+
+			binaryData[key] = content
+		} else {
+			data[key] = file.Content
+		}
+	}
+
+	var configMap corev1.ConfigMap
+
+	err := r.Get(r.ctx, types.NamespacedName{
+		Namespace: r.component.Namespace,
+		Name:      getHotReloadFilesConfigMapName(r.component.Name),
+	}, &configMap)
+
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	exists := err == nil
+
+	if len(data) == 0 && len(binaryData) == 0 {
+		if exists {
+			return client.IgnoreNotFound(r.Delete(r.ctx, &configMap))
+		}
+
+		return nil
+	}
+
+	if !exists {
+		configMap = corev1.ConfigMap{
+			ObjectMeta: metaV1.ObjectMeta{
+				Namespace: r.component.Namespace,
+				Name:      getHotReloadFilesConfigMapName(r.component.Name),
+				Labels:    r.GetLabels(),
+			},
+			Data:       data,
+			BinaryData: binaryData,
+		}
+
+		if err := ctrl.SetControllerReference(r.component, &configMap, r.Scheme); err != nil {
+			r.WarningEvent(err, "unable to set owner for hot reload files configMap")
+			return err
+		}
+
+		return r.Create(r.ctx, &configMap)
+	}
+
+	if equality.Semantic.DeepEqual(configMap.Data, data) && equality.Semantic.DeepEqual(configMap.BinaryData, binaryData) {
+		return nil
+	}
+
+	configMap.Data = data
+	configMap.BinaryData = binaryData
+
+	return r.Update(r.ctx, &configMap)
+}
+
+// prepareHotReloadFiles mounts the directories of hot reload files from the configMap.
+// A subPath mount is never updated, so the whole directory is mounted.
+func (r *ComponentReconcilerTask) prepareHotReloadFiles(volumes *[]corev1.Volume, volumeMounts *[]corev1.VolumeMount) {
+	itemsOfDir := make(map[string][]corev1.KeyToPath)
+
+	for _, file := range r.component.Spec.PreInjectedFiles {
+		if !file.HotReload {
+			continue
+		}
+
+		mode := int32(0644)
+		if file.Runnable {
+			mode = 0755
+		}
+
+		dir := path.Dir(file.MountPath)
+		itemsOfDir[dir] = append(itemsOfDir[dir], corev1.KeyToPath{
+			Key:  getHotReloadFileKey(file.MountPath),
+			Path: path.Base(file.MountPath),
+			Mode: &mode,
+		})
+	}
+
+	dirs := make([]string, 0, len(itemsOfDir))
+	for dir := range itemsOfDir {
+		dirs = append(dirs, dir)
+	}
+
+	sort.Strings(dirs)
+
+	for i, dir := range dirs {
+		volName := fmt.Sprintf("hot-reload-files-%d", i)
+
+		*volumes = append(*volumes, corev1.Volume{
+			Name: volName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: getHotReloadFilesConfigMapName(r.component.Name),
+					},
+					Items: itemsOfDir[dir],
+				},
+			},
+		})
+
+		*volumeMounts = append(*volumeMounts, corev1.VolumeMount{
+			Name:      volName,
+			MountPath: dir,
+			ReadOnly:  true,
+		})
+	}
+}
@@ -26,13 +26,16 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	js "github.com/dop251/goja"
+	protoTypes "github.com/gogo/protobuf/types"
 	"github.com/kalmhq/kalm/controller/vm"
 	"github.com/xeipuuv/gojsonschema"
 	v1alpha32 "istio.io/api/networking/v1alpha3"
 	"istio.io/client-go/pkg/apis/networking/v1alpha3"
 	appsV1 "k8s.io/api/apps/v1"
+	autoscalingV2beta2 "k8s.io/api/autoscaling/v2beta2"
 	batchV1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/batch/v1"
 	batchV1Beta1 "k8s.io/api/batch/v1beta1"
@@ -61,6 +64,8 @@ const (
 // ComponentReconciler reconciles a Component object
 type ComponentReconciler struct {
 	*BaseReconciler
+
+	RolloutMetric RolloutMetricProvider
 }
 
 type ComponentReconcilerTask struct {
@@ -80,6 +85,22 @@ type ComponentReconcilerTask struct {
 	daemonSet       *appsV1.DaemonSet
 	statefulSet     *appsV1.StatefulSet
 	pluginBindings  *v1alpha1.ComponentPluginBindingList
+	hpa             *autoscalingV2beta2.HorizontalPodAutoscaler
+
+	// canary deployment and rollout state of a component with a rollout strategy
+	canaryDeployment *appsV1.Deployment
+	rolloutStatus    *v1alpha1.ComponentRolloutStatus
+
+	// components in StartAfterComponents which are not ready yet, the workload is held back until they are
+	waitingForComponents []string
+	// components in StartAfterComponents which don't exist, the workload never starts until they are created
+	missingComponents []string
+
+	// number of the revision recording the current spec
+	currentRevision int64
+
+	// set if the component needs to be checked again even if nothing changes, e.g. during a rollout
+	requeueAfter time.Duration
 }
 
 // +kubebuilder:rbac:groups=core.kalm.dev,resources=components,verbs=get;list;watch;create;update;patch;delete
@@ -105,7 +126,9 @@ func (r *ComponentReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		ctx:                 context.Background(),
 	}
 
-	return ctrl.Result{}, task.Run(req)
+	err := task.Run(req)
+
+	return ctrl.Result{RequeueAfter: task.requeueAfter}, err
 }
 
 func (r *ComponentReconcilerTask) WarningEvent(err error, msg string, args ...interface{}) {
@@ -118,7 +141,8 @@ func (r *ComponentReconcilerTask) NormalEvent(reason, msg string, args ...interf
 
 func NewComponentReconciler(mgr ctrl.Manager) *ComponentReconciler {
 	return &ComponentReconciler{
-		NewBaseReconciler(mgr, "Component"),
+		BaseReconciler: NewBaseReconciler(mgr, "Component"),
+		RolloutMetric:  NewPrometheusRolloutMetricProvider(),
 	}
 }
 
@@ -216,11 +240,18 @@ func (r *ComponentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Watches(&source.Kind{Type: &v1alpha1.ComponentPluginBinding{}}, &handler.EnqueueRequestsFromMapFunc{
 			ToRequests: &ComponentPluginBindingsMapper{r.BaseReconciler},
 		}).
+		Watches(&source.Kind{Type: &v1alpha1.Component{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: &ComponentDependentsMapper{r.BaseReconciler},
+		}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: &ComponentSecretsMapper{r.BaseReconciler},
+		}).
 		Owns(&appsV1.Deployment{}).
 		Owns(&batchV1Beta1.CronJob{}).
 		Owns(&appsV1.DaemonSet{}).
 		Owns(&appsV1.StatefulSet{}).
 		Owns(&corev1.Service{}).
+		Owns(&autoscalingV2beta2.HorizontalPodAutoscaler{}).
 		Complete(r)
 }
 func (r *ComponentReconcilerTask) Run(req ctrl.Request) error {
@@ -240,6 +271,24 @@ func (r *ComponentReconcilerTask) Run(req ctrl.Request) error {
 		return nil
 	}
 
+	err := r.ReconcileResources()
+
+	if statusErr := r.ReconcileStatus(err); statusErr != nil {
+		r.Log.Error(statusErr, "unable to update component status", "component", r.component.Name)
+
+		if err == nil {
+			return statusErr
+		}
+	}
+
+	return err
+}
+
+func (r *ComponentReconcilerTask) ReconcileResources() error {
+	if err := r.ReconcileRevisionHistory(); err != nil {
+		return err
+	}
+
 	if err := r.ReconcileService(); err != nil {
 		return err
 	}
@@ -252,6 +301,10 @@ func (r *ComponentReconcilerTask) Run(req ctrl.Request) error {
 		return err
 	}
 
+	if err := r.ReconcileAutoscaling(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -512,27 +565,12 @@ func (r *ComponentReconcilerTask) ReconcileService() (err error) {
 				Port: &v1alpha32.PortSelector{
 					Number: servicePort,
 				},
-				LoadBalancer: &v1alpha32.LoadBalancerSettings{
-					LbPolicy: &v1alpha32.LoadBalancerSettings_Simple{
-						Simple: v1alpha32.LoadBalancerSettings_LEAST_CONN,
-					},
-
-					// TODO; ConsistentHash will be used for sticky session
-					//LbPolicy: &v1alpha32.LoadBalancerSettings_ConsistentHash{
-					//	ConsistentHash: &v1alpha32.LoadBalancerSettings_ConsistentHashLB{
-					//		HashKey: &v1alpha32.LoadBalancerSettings_ConsistentHashLB_HttpCookie{
-					//			HttpCookie: &v1alpha32.LoadBalancerSettings_ConsistentHashLB_HTTPCookie{
-					//				Name: "kalm-sticky-session",
-					//				Ttl: &protoTypes.Duration{
-					//					Seconds: 10,
-					//				},
-					//			},
-					//		},
-					//	},
-					//},
-				},
+				LoadBalancer: buildPortLoadBalancerSettings(port.LoadBalancer),
 			}
 
+			// port level settings replace the top level settings, so the circuit breaker is set on each port
+			policy.ConnectionPool, policy.OutlierDetection = buildCircuitBreakerPolicy(r.component.Spec.CircuitBreaker)
+
 			// TODO, should we support to use https in a upstream server?
 			if port.Protocol == v1alpha1.PortProtocolHTTPS {
 				policy.Tls = &v1alpha32.ClientTLSSettings{
@@ -543,6 +581,21 @@ func (r *ComponentReconcilerTask) ReconcileService() (err error) {
 			destinationRule.Spec.TrafficPolicy.PortLevelSettings[i] = policy
 		}
 
+		destinationRule.Spec.TrafficPolicy.ConnectionPool, destinationRule.Spec.TrafficPolicy.OutlierDetection = buildCircuitBreakerPolicy(r.component.Spec.CircuitBreaker)
+
+		if r.component.Spec.RolloutStrategy != nil {
+			destinationRule.Spec.Subsets = []*v1alpha32.Subset{
+				{
+					Name:   RolloutTrackStable,
+					Labels: map[string]string{KalmLabelRolloutTrack: RolloutTrackStable},
+				},
+				{
+					Name:   RolloutTrackCanary,
+					Labels: map[string]string{KalmLabelRolloutTrack: RolloutTrackCanary},
+				},
+			}
+		}
+
 		if r.destinationRule == nil {
 			if err := ctrl.SetControllerReference(r.component, destinationRule, r.Scheme); err != nil {
 				r.WarningEvent(err, "unable to set owner for DestinationRule")
@@ -626,6 +679,10 @@ func (r *ComponentReconcilerTask) ReconcileWorkload() (err error) {
 				return err
 			}
 		}
+		if err := r.deleteCanaryDeployment(); err != nil {
+			return err
+		}
+		r.rolloutStatus = nil
 		if r.cronJob != nil {
 			if err := r.Delete(r.ctx, r.cronJob); err != nil {
 				return err
@@ -666,6 +723,14 @@ func (r *ComponentReconcilerTask) ReconcileWorkload() (err error) {
 		return err
 	}
 
+	if err := r.ResolveDependencies(); err != nil {
+		return err
+	}
+
+	if err := r.ReconcileHotReloadFiles(); err != nil {
+		return err
+	}
+
 	template, err := r.GetPodTemplateWithoutVols()
 	if err != nil {
 		return err
@@ -677,6 +742,15 @@ func (r *ComponentReconcilerTask) ReconcileWorkload() (err error) {
 			return err
 		}
 
+		if r.component.Spec.RolloutStrategy != nil {
+			return r.ReconcileRollout(template)
+		}
+
+		if err := r.deleteCanaryDeployment(); err != nil {
+			return err
+		}
+		r.rolloutStatus = nil
+
 		return r.ReconcileDeployment(template)
 	case v1alpha1.WorkloadTypeCronjob:
 		if err := r.prepareVolsForSimpleWorkload(template); err != nil {
@@ -740,9 +814,29 @@ func (r *ComponentReconcilerTask) ReconcileDeployment(podTemplateSpec *corev1.Po
 	ctx := r.ctx
 	deployment := r.deployment
 	isNewDeployment := false
-	labelMap := podTemplateSpec.Labels
+	labelMap := make(map[string]string, len(podTemplateSpec.Labels))
 	annotations := r.GetAnnotations()
 
+	for k, v := range podTemplateSpec.Labels {
+		if k != KalmLabelRolloutTrack {
+			labelMap[k] = v
+		}
+	}
+
+	// Pods of the deployment are always on the stable track, so they are never selected by a canary.
+	// Deployments created before the track was added keep their selectors of all pod labels, as selectors are immutable.
+	if (deployment == nil || deployment.Spec.Selector.MatchLabels[KalmLabelRolloutTrack] != "") &&
+		podTemplateSpec.Labels[KalmLabelRolloutTrack] == "" {
+
+		podTemplateSpec = podTemplateSpec.DeepCopy()
+
+		if podTemplateSpec.Labels == nil {
+			podTemplateSpec.Labels = make(map[string]string)
+		}
+
+		podTemplateSpec.Labels[KalmLabelRolloutTrack] = RolloutTrackStable
+	}
+
 	if deployment == nil {
 		isNewDeployment = true
 
@@ -756,7 +850,7 @@ func (r *ComponentReconcilerTask) ReconcileDeployment(podTemplateSpec *corev1.Po
 			Spec: appsV1.DeploymentSpec{
 				Template: *podTemplateSpec,
 				Selector: &metaV1.LabelSelector{
-					MatchLabels: labelMap,
+					MatchLabels: getDeploymentSelectorLabels(component, RolloutTrackStable),
 				},
 			},
 		}
@@ -783,12 +877,25 @@ func (r *ComponentReconcilerTask) ReconcileDeployment(podTemplateSpec *corev1.Po
 	}
 
 	// TODO consider to move to plugin
-	if component.Spec.Replicas != nil {
+	if r.isWaitingForDependencies() {
+		zero := int32(0)
+		deployment.Spec.Replicas = &zero
+	} else if r.isAutoscaled() {
+		// replicas are owned by the HorizontalPodAutoscaler, a released deployment starts with the min replicas
+		current := deployment.Spec.Replicas
+		if isHeldBackByDependencies(deployment.ObjectMeta) {
+			current = nil
+		}
+
+		deployment.Spec.Replicas = r.getAutoscaledReplicas(current)
+	} else if component.Spec.Replicas != nil {
 		deployment.Spec.Replicas = component.Spec.Replicas
 	} else {
 		deployment.Spec.Replicas = nil
 	}
 
+	r.setWaitingForComponentsAnnotation(&deployment.ObjectMeta)
+
 	if err := ctrl.SetControllerReference(component, deployment, r.Scheme); err != nil {
 		r.WarningEvent(err, "unable to set owner for deployment")
 		return err
@@ -815,6 +922,8 @@ func (r *ComponentReconcilerTask) ReconcileDeployment(podTemplateSpec *corev1.Po
 		r.NormalEvent("DeploymentUpdated", deployment.Name+" is updated.")
 	}
 
+	r.deployment = deployment
+
 	return nil
 }
 
@@ -825,6 +934,11 @@ func (r *ComponentReconcilerTask) ReconcileDaemonSet(podTemplateSpec *corev1.Pod
 	daemonSet := r.daemonSet
 	isNewDs := false
 
+	// a daemonSet can't be scaled down, so it's not created until its dependencies are ready
+	if daemonSet == nil && r.isWaitingForDependencies() {
+		return nil
+	}
+
 	if daemonSet == nil {
 		isNewDs = true
 
@@ -867,6 +981,8 @@ func (r *ComponentReconcilerTask) ReconcileDaemonSet(podTemplateSpec *corev1.Pod
 		r.NormalEvent("DaemonSetUpdated", daemonSet.Name+" is updated.")
 	}
 
+	r.daemonSet = daemonSet
+
 	return nil
 }
 
@@ -966,6 +1082,11 @@ func (r *ComponentReconcilerTask) ReconcileCronJob(podTemplateSpec *corev1.PodTe
 		FailedJobsHistoryLimit:     &failJobHistoryLimit,
 	}
 
+	if r.isWaitingForDependencies() {
+		suspend := true
+		desiredCJSpec.Suspend = &suspend
+	}
+
 	var isNewCJ bool
 	if cj == nil {
 		isNewCJ = true
@@ -983,6 +1104,8 @@ func (r *ComponentReconcilerTask) ReconcileCronJob(podTemplateSpec *corev1.PodTe
 		cj.Spec = desiredCJSpec
 	}
 
+	r.setWaitingForComponentsAnnotation(&cj.ObjectMeta)
+
 	if isNewCJ {
 		if err := ctrl.SetControllerReference(component, cj, r.Scheme); err != nil {
 			r.WarningEvent(err, "unable to set owner for cronJob")
@@ -1004,7 +1127,10 @@ func (r *ComponentReconcilerTask) ReconcileCronJob(podTemplateSpec *corev1.PodTe
 		r.NormalEvent("CronJobUpdated", cj.Name+" is updated.")
 	}
 
-	if r.component.Spec.ImmediateTrigger {
+	r.cronJob = cj
+
+	// the trigger is kept until the cronjob is released
+	if r.component.Spec.ImmediateTrigger && !r.isWaitingForDependencies() {
 		return r.ReconcileImmediateJob(template)
 	}
 
@@ -1052,10 +1178,27 @@ func (r *ComponentReconcilerTask) ReconcileStatefulSet(
 		sts.Spec.Template = *spec
 	}
 
-	if r.component.Spec.Replicas != nil {
+	heldBack := isHeldBackByDependencies(sts.ObjectMeta)
+
+	if r.isWaitingForDependencies() {
+		zero := int32(0)
+		sts.Spec.Replicas = &zero
+	} else if r.isAutoscaled() {
+		// replicas are owned by the HorizontalPodAutoscaler, a released sts starts with the min replicas
+		current := sts.Spec.Replicas
+		if heldBack {
+			current = nil
+		}
+
+		sts.Spec.Replicas = r.getAutoscaledReplicas(current)
+	} else if r.component.Spec.Replicas != nil {
 		sts.Spec.Replicas = r.component.Spec.Replicas
+	} else if heldBack {
+		sts.Spec.Replicas = nil
 	}
 
+	r.setWaitingForComponentsAnnotation(&sts.ObjectMeta)
+
 	if isNewSts {
 		if err := ctrl.SetControllerReference(r.component, sts, r.Scheme); err != nil {
 			log.Error(err, "unable to set owner for sts")
@@ -1077,6 +1220,8 @@ func (r *ComponentReconcilerTask) ReconcileStatefulSet(
 		r.NormalEvent("StatefulSetUpdated", sts.Name+" is updated.")
 	}
 
+	r.statefulSet = sts
+
 	return nil
 }
 
@@ -1150,24 +1295,23 @@ func (r *ComponentReconcilerTask) GetPodTemplateWithoutVols() (template *corev1.
 		template.ObjectMeta.Annotations[AnnoLastUpdatedByWebhook] = v
 	}
 
+	// pods are rolled once referenced secrets change
+	secretsHash, err := r.getSecretsHash()
+	if err != nil {
+		return nil, err
+	}
+
+	if secretsHash != "" {
+		template.ObjectMeta.Annotations[AnnoSecretsHash] = secretsHash
+	}
+
 	mainContainer := &template.Spec.Containers[0]
 
 	if component.Spec.TerminationGracePeriodSeconds != nil {
 		template.Spec.TerminationGracePeriodSeconds = component.Spec.TerminationGracePeriodSeconds
 	}
 
-	if component.Spec.Command != "" {
-		if strings.HasPrefix(component.Spec.Command, "-") {
-			space := regexp.MustCompile(`\s+`)
-			parts := space.Split(component.Spec.Command, -1)
-			mainContainer.Args = parts
-		} else if strings.Contains(component.Spec.Command, " ") {
-			mainContainer.Command = []string{"sh"}
-			mainContainer.Args = []string{"-c", component.Spec.Command}
-		} else {
-			mainContainer.Command = []string{component.Spec.Command}
-		}
-	}
+	setContainerCommand(mainContainer, component.Spec.Command)
 
 	var pullImageSecrets corev1.SecretList
 	if err := r.Client.List(
@@ -1215,8 +1359,127 @@ func (r *ComponentReconcilerTask) GetPodTemplateWithoutVols() (template *corev1.
 	}
 
 	// apply envs
-	var envs []corev1.EnvVar
-	for _, env := range component.Spec.Env {
+	envs, err := r.buildContainerEnvs(component.Spec.Env)
+	if err != nil {
+		return nil, err
+	}
+	mainContainer.Env = envs
+
+	envFromCommonCM := corev1.EnvFromSource{
+		ConfigMapRef: &corev1.ConfigMapEnvSource{
+			LocalObjectReference: corev1.LocalObjectReference{
+				Name: NSScopeSharedConfigMapName,
+			},
+		},
+	}
+
+	// envFromCommonSec := corev1.EnvFromSource{
+	// 	SecretRef: &corev1.SecretEnvSource{
+	// 		LocalObjectReference: corev1.LocalObjectReference{
+	// 			Name: NSScopeSharedConfigMapName,
+	// 		},
+	// 	},
+	// }
+
+	mainContainer.EnvFrom = append(mainContainer.EnvFrom, envFromCommonCM)
+
+	// init containers and sidecars share envs of the component
+	for _, c := range component.Spec.InitContainers {
+		container, err := r.buildExtraContainer(c, envs, envFromCommonCM)
+		if err != nil {
+			return nil, err
+		}
+
+		template.Spec.InitContainers = append(template.Spec.InitContainers, container)
+	}
+
+	for _, c := range component.Spec.Sidecars {
+		container, err := r.buildExtraContainer(c, envs, envFromCommonCM)
+		if err != nil {
+			return nil, err
+		}
+
+		template.Spec.Containers = append(template.Spec.Containers, container)
+	}
+
+	err = r.runPlugins(ComponentPluginMethodAfterPodTemplateGeneration, component, template, template)
+	if err != nil {
+		r.WarningEvent(err, "run "+ComponentPluginMethodAfterPodTemplateGeneration+" save plugin error")
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func setContainerCommand(container *corev1.Container, command string) {
+	if command == "" {
+		return
+	}
+
+	if strings.HasPrefix(command, "-") {
+		space := regexp.MustCompile(`\s+`)
+		parts := space.Split(command, -1)
+		container.Args = parts
+	} else if strings.Contains(command, " ") {
+		container.Command = []string{"sh"}
+		container.Args = []string{"-c", command}
+	} else {
+		container.Command = []string{command}
+	}
+}
+
+// buildExtraContainer builds an init container or a sidecar, its own envs override the envs
+// of the component with the same name.
+func (r *ComponentReconcilerTask) buildExtraContainer(
+	c v1alpha1.ComponentContainer,
+	componentEnvs []corev1.EnvVar,
+	envFrom ...corev1.EnvFromSource,
+) (corev1.Container, error) {
+	envs, err := r.buildContainerEnvs(c.Env)
+	if err != nil {
+		return corev1.Container{}, err
+	}
+
+	container := corev1.Container{
+		Name:    c.Name,
+		Image:   c.Image,
+		Env:     mergeEnvs(componentEnvs, envs),
+		EnvFrom: envFrom,
+	}
+
+	setContainerCommand(&container, c.Command)
+
+	if c.ResourceRequirements != nil {
+		container.Resources = *c.ResourceRequirements
+	}
+
+	return container, nil
+}
+
+// mergeEnvs replaces the envs of the same name in place, the others are appended in order.
+func mergeEnvs(envs []corev1.EnvVar, overrides []corev1.EnvVar) []corev1.EnvVar {
+	rst := append([]corev1.EnvVar{}, envs...)
+
+	for _, override := range overrides {
+		replaced := false
+
+		for i := range rst {
+			if rst[i].Name == override.Name {
+				rst[i] = override
+				replaced = true
+			}
+		}
+
+		if !replaced {
+			rst = append(rst, override)
+		}
+	}
+
+	return rst
+}
+
+func (r *ComponentReconcilerTask) buildContainerEnvs(envVars []v1alpha1.EnvVar) (envs []corev1.EnvVar, err error) {
+	for _, env := range envVars {
 		var value string
 		var valueFrom *corev1.EnvVarSource
 
@@ -1241,6 +1504,18 @@ func (r *ComponentReconcilerTask) GetPodTemplateWithoutVols() (template *corev1.
 					FieldPath: env.Value,
 				},
 			}
+		case v1alpha1.EnvVarTypeSecret:
+			secretName, key, ok := v1alpha1.ParseSecretKeyRef(env.Value)
+			if !ok {
+				return nil, fmt.Errorf("invalid secret env %s: %s", env.Name, env.Value)
+			}
+
+			valueFrom = &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+					Key:                  key,
+				},
+			}
 		case v1alpha1.EnvVarTypeBuiltin:
 			switch env.Value {
 			case v1alpha1.EnvVarBuiltinHost:
@@ -1273,33 +1548,8 @@ func (r *ComponentReconcilerTask) GetPodTemplateWithoutVols() (template *corev1.
 			ValueFrom: valueFrom,
 		})
 	}
-	mainContainer.Env = envs
 
-	envFromCommonCM := corev1.EnvFromSource{
-		ConfigMapRef: &corev1.ConfigMapEnvSource{
-			LocalObjectReference: corev1.LocalObjectReference{
-				Name: NSScopeSharedConfigMapName,
-			},
-		},
-	}
-
-	// envFromCommonSec := corev1.EnvFromSource{
-	// 	SecretRef: &corev1.SecretEnvSource{
-	// 		LocalObjectReference: corev1.LocalObjectReference{
-	// 			Name: NSScopeSharedConfigMapName,
-	// 		},
-	// 	},
-	// }
-
-	mainContainer.EnvFrom = append(mainContainer.EnvFrom, envFromCommonCM)
-
-	err = r.runPlugins(ComponentPluginMethodAfterPodTemplateGeneration, component, template, template)
-	if err != nil {
-		r.WarningEvent(err, "run "+ComponentPluginMethodAfterPodTemplateGeneration+" save plugin error")
-		return nil, err
-	}
-
-	return template, nil
+	return envs, nil
 }
 
 func getVolName(componentName, diskPath string) string {
@@ -1561,6 +1811,7 @@ func (r *ComponentReconcilerTask) SetupAttributes(req ctrl.Request) (err error)
 		return err
 	}
 	r.component = &component
+	r.rolloutStatus = component.Status.Rollout.DeepCopy()
 
 	var ns corev1.Namespace
 	err = r.Reader.Get(r.ctx, types.NamespacedName{Name: component.Namespace}, &ns)
@@ -1635,6 +1886,59 @@ func (r *ComponentReconcilerTask) DeleteItem(obj runtime.Object) (err error) {
 	return nil
 }
 
+func buildPortLoadBalancerSettings(lb *v1alpha1.PortLoadBalancer) *v1alpha32.LoadBalancerSettings {
+	if lb == nil {
+		lb = &v1alpha1.PortLoadBalancer{Policy: v1alpha1.LoadBalancerPolicyLeastRequest}
+	}
+
+	switch lb.Policy {
+	case v1alpha1.LoadBalancerPolicyRoundRobin:
+		return &v1alpha32.LoadBalancerSettings{
+			LbPolicy: &v1alpha32.LoadBalancerSettings_Simple{
+				Simple: v1alpha32.LoadBalancerSettings_ROUND_ROBIN,
+			},
+		}
+	case v1alpha1.LoadBalancerPolicyConsistentHash:
+		consistentHash := &v1alpha32.LoadBalancerSettings_ConsistentHashLB{}
+
+		switch lb.HashKey {
+		case v1alpha1.ConsistentHashKeyCookie:
+			// the ttl is required by istio, a zero ttl makes envoy generate a session cookie
+			cookie := &v1alpha32.LoadBalancerSettings_ConsistentHashLB_HTTPCookie{
+				Name: lb.CookieName,
+				Path: "/",
+				Ttl: &protoTypes.Duration{
+					Seconds: lb.CookieTTLSeconds,
+				},
+			}
+
+			consistentHash.HashKey = &v1alpha32.LoadBalancerSettings_ConsistentHashLB_HttpCookie{
+				HttpCookie: cookie,
+			}
+		case v1alpha1.ConsistentHashKeyHeader:
+			consistentHash.HashKey = &v1alpha32.LoadBalancerSettings_ConsistentHashLB_HttpHeaderName{
+				HttpHeaderName: lb.HeaderName,
+			}
+		default:
+			consistentHash.HashKey = &v1alpha32.LoadBalancerSettings_ConsistentHashLB_UseSourceIp{
+				UseSourceIp: true,
+			}
+		}
+
+		return &v1alpha32.LoadBalancerSettings{
+			LbPolicy: &v1alpha32.LoadBalancerSettings_ConsistentHash{
+				ConsistentHash: consistentHash,
+			},
+		}
+	default:
+		return &v1alpha32.LoadBalancerSettings{
+			LbPolicy: &v1alpha32.LoadBalancerSettings_Simple{
+				Simple: v1alpha32.LoadBalancerSettings_LEAST_CONN,
+			},
+		}
+	}
+}
+
 func (r *ComponentReconcilerTask) LoadResources() (err error) {
 	if err := r.LoadService(); err != nil {
 		return err
@@ -1648,8 +1952,16 @@ func (r *ComponentReconcilerTask) LoadResources() (err error) {
 		return err
 	}
 
+	if err := r.LoadHorizontalPodAutoscaler(); err != nil {
+		return err
+	}
+
 	switch r.component.Spec.WorkloadType {
 	case v1alpha1.WorkloadTypeServer, "":
+		if err := r.LoadCanaryDeployment(); err != nil {
+			return err
+		}
+
 		return r.LoadDeployment()
 	case v1alpha1.WorkloadTypeCronjob:
 		return r.LoadCronJob()
@@ -1741,6 +2053,23 @@ func (r *ComponentReconcilerTask) LoadDeployment() error {
 	return nil
 }
 
+func (r *ComponentReconcilerTask) LoadCanaryDeployment() error {
+	var deploy appsV1.Deployment
+	if err := r.Reader.Get(
+		r.ctx,
+		types.NamespacedName{
+			Namespace: r.component.Namespace,
+			Name:      getNameForCanaryDeployment(r.component.Name),
+		},
+		&deploy,
+	); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	r.canaryDeployment = &deploy
+	return nil
+}
+
 func (r *ComponentReconcilerTask) LoadCronJob() error {
 	var cornJob batchV1Beta1.CronJob
 	err := r.LoadItem(&cornJob)
@@ -1805,19 +2134,22 @@ func (r *ComponentReconcilerTask) preparePreInjectedFiles(
 		return nil
 	}
 
-	*volumes = append(*volumes, corev1.Volume{
-		Name: "pre-injected-files-volume",
-		VolumeSource: corev1.VolumeSource{
-			EmptyDir: &corev1.EmptyDirVolumeSource{},
-		},
-	})
-
-	if len(template.Spec.InitContainers) == 0 {
-		template.Spec.InitContainers = []corev1.Container{}
-	}
+	r.prepareHotReloadFiles(volumes, volumeMounts)
 
 	var injectCommands []string
-	for _, file := range component.Spec.PreInjectedFiles {
+	for i, file := range component.Spec.PreInjectedFiles {
+		if file.SecretRef != "" {
+			if err := prepareSecretFile(i, file, volumes, volumeMounts); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if file.HotReload {
+			continue
+		}
+
 		content := file.Content
 
 		if !file.Base64 {
@@ -1842,11 +2174,65 @@ func (r *ComponentReconcilerTask) preparePreInjectedFiles(
 		})
 	}
 
-	template.Spec.InitContainers = append(template.Spec.InitContainers, corev1.Container{
-		Name:         "inject-files",
+	// all files are mounted from secrets or the hot reload configMap
+	if len(injectCommands) == 0 {
+		return nil
+	}
+
+	*volumes = append(*volumes, corev1.Volume{
+		Name: "pre-injected-files-volume",
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	})
+
+	// files are injected before any init container of the component runs
+	template.Spec.InitContainers = append([]corev1.Container{{
+		Name:         v1alpha1.PreInjectedFilesInitContainerName,
 		Image:        "busybox",
 		Command:      []string{"sh", "-c", fmt.Sprintf("%s", strings.Join(injectCommands, " && "))},
 		VolumeMounts: []corev1.VolumeMount{{MountPath: "/files", Name: "pre-injected-files-volume"}},
+	}}, template.Spec.InitContainers...)
+
+	return nil
+}
+
+// prepareSecretFile mounts a secret key as a pre-injected file, secret volumes are always read only
+func prepareSecretFile(
+	idx int,
+	file v1alpha1.PreInjectFile,
+	volumes *[]corev1.Volume,
+	volumeMounts *[]corev1.VolumeMount,
+) error {
+	secretName, key, ok := v1alpha1.ParseSecretKeyRef(file.SecretRef)
+	if !ok {
+		return fmt.Errorf("invalid secretRef of pre-injected file %s: %s", file.MountPath, file.SecretRef)
+	}
+
+	mode := int32(0644)
+	if file.Runnable {
+		mode = 0755
+	}
+
+	volName := fmt.Sprintf("pre-injected-secret-%d", idx)
+
+	*volumes = append(*volumes, corev1.Volume{
+		Name: volName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: secretName,
+				Items: []corev1.KeyToPath{
+					{Key: key, Path: path.Base(file.MountPath), Mode: &mode},
+				},
+			},
+		},
+	})
+
+	*volumeMounts = append(*volumeMounts, corev1.VolumeMount{
+		Name:      volName,
+		MountPath: file.MountPath,
+		SubPath:   path.Base(file.MountPath),
+		ReadOnly:  true,
 	})
 
 	return nil
@@ -1936,8 +2322,7 @@ func (r *ComponentReconcilerTask) prepareVolsForSTS(podTemplate *corev1.PodTempl
 	podTemplate.Spec.Volumes = volumes
 
 	// mount vols into container
-	mainContainer := &podTemplate.Spec.Containers[0]
-	mainContainer.VolumeMounts = volumeMounts
+	mountVolumesToContainers(podTemplate, volumeMounts)
 
 	// for STS, pvc is not in podTemplate but in volumeClaimTemplate
 	return volClaimTemplates, nil
@@ -2073,12 +2458,27 @@ func (r *ComponentReconcilerTask) prepareVolsForSimpleWorkload(template *corev1.
 
 	template.Spec.Volumes = volumes
 
-	mainContainer := &template.Spec.Containers[0]
-	mainContainer.VolumeMounts = volumeMounts
+	mountVolumesToContainers(template, volumeMounts)
 
 	return nil
 }
 
+// volumes and pre-injected files of the component are shared by all containers except the file injector
+// Each container gets its own copy of the mounts, so appending to one container's mounts won't affect the others.
+func mountVolumesToContainers(template *corev1.PodTemplateSpec, volumeMounts []corev1.VolumeMount) {
+	for i := range template.Spec.Containers {
+		template.Spec.Containers[i].VolumeMounts = append([]corev1.VolumeMount(nil), volumeMounts...)
+	}
+
+	for i := range template.Spec.InitContainers {
+		if template.Spec.InitContainers[i].Name == v1alpha1.PreInjectedFilesInitContainerName {
+			continue
+		}
+
+		template.Spec.InitContainers[i].VolumeMounts = append([]corev1.VolumeMount(nil), volumeMounts...)
+	}
+}
+
 // 2. diff ns pv reuse, remove old pvc, clean ref in pv
 func (r *ComponentReconcilerTask) reconcilePVForReUse(
 	pvc corev1.PersistentVolumeClaim,
@@ -4,11 +4,16 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	istioNetworkingV1alpha3 "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
 	appsV1 "k8s.io/api/apps/v1"
+	autoscalingV2beta2 "k8s.io/api/autoscaling/v2beta2"
 	coreV1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -352,6 +357,485 @@ func (suite *ComponentControllerSuite) TestPorts() {
 	}, "service should be deleted")
 }
 
+func (suite *ComponentControllerSuite) TestComponentStatus() {
+	component := generateEmptyComponent(suite.ns.Name)
+	suite.createComponent(component)
+
+	// there is no kubelet in test env, so the deployment is never ready
+	suite.Eventually(func() bool {
+		suite.reloadComponent(component)
+
+		ready := v1alpha1.GetComponentCondition(*component, v1alpha1.ComponentConditionReady)
+
+		return component.Status.ObservedGeneration == component.Generation &&
+			component.Status.DesiredReplicas == 1 &&
+			component.Status.LastAppliedImage == "nginx:latest" &&
+			ready != nil && ready.Status == coreV1.ConditionFalse &&
+			!v1alpha1.IsComponentReady(*component)
+	}, "component status should be reported")
+
+	// a new spec generation should be observed
+	suite.reloadComponent(component)
+	component.Spec.Image = "nginx:alpine"
+	suite.updateComponent(component)
+
+	suite.Eventually(func() bool {
+		suite.reloadComponent(component)
+
+		return component.Status.ObservedGeneration == component.Generation &&
+			component.Status.LastAppliedImage == "nginx:alpine"
+	}, "component status should follow the latest spec")
+}
+
+func (suite *ComponentControllerSuite) TestComponentCanaryRollout() {
+	component := generateEmptyComponent(suite.ns.Name)
+	component.Spec.Ports = []v1alpha1.Port{
+		{
+			Protocol:      v1alpha1.PortProtocolHTTP,
+			ContainerPort: 80,
+			ServicePort:   80,
+		},
+	}
+	component.Spec.RolloutStrategy = &v1alpha1.RolloutStrategy{
+		Type: v1alpha1.RolloutStrategyCanary,
+	}
+	component.Spec.Labels = map[string]string{"team": "a"}
+	suite.createComponent(component)
+
+	var stable appsV1.Deployment
+	suite.Eventually(func() bool {
+		err := suite.K8sClient.Get(context.Background(), types.NamespacedName{
+			Name:      component.Name,
+			Namespace: component.Namespace,
+		}, &stable)
+
+		return err == nil && stable.Spec.Template.Labels[KalmLabelRolloutTrack] == RolloutTrackStable
+	}, "the first revision should be deployed as stable")
+
+	// user labels are kept out of the immutable selector
+	suite.Equal(getDeploymentSelectorLabels(component, RolloutTrackStable), stable.Spec.Selector.MatchLabels)
+	suite.Equal("a", stable.Spec.Template.Labels["team"])
+
+	var destinationRule v1alpha3.DestinationRule
+	suite.Nil(suite.K8sClient.Get(context.Background(), types.NamespacedName{
+		Name:      component.Name,
+		Namespace: component.Namespace,
+	}, &destinationRule))
+	suite.Len(destinationRule.Spec.Subsets, 2)
+
+	suite.reloadComponent(component)
+	component.Spec.Image = "nginx:alpine"
+	suite.updateComponent(component)
+
+	var canary appsV1.Deployment
+	suite.Eventually(func() bool {
+		err := suite.K8sClient.Get(context.Background(), types.NamespacedName{
+			Name:      getNameForCanaryDeployment(component.Name),
+			Namespace: component.Namespace,
+		}, &canary)
+
+		return err == nil &&
+			canary.Spec.Template.Labels[KalmLabelRolloutTrack] == RolloutTrackCanary &&
+			canary.Spec.Template.Spec.Containers[0].Image == "nginx:alpine"
+	}, "new revision should be deployed as canary")
+
+	suite.Equal(getDeploymentSelectorLabels(component, RolloutTrackCanary), canary.Spec.Selector.MatchLabels)
+
+	// labels can be changed during a rollout
+	suite.reloadComponent(component)
+	component.Spec.Labels = map[string]string{"team": "b"}
+	suite.updateComponent(component)
+
+	suite.Eventually(func() bool {
+		err := suite.K8sClient.Get(context.Background(), types.NamespacedName{
+			Name:      getNameForCanaryDeployment(component.Name),
+			Namespace: component.Namespace,
+		}, &canary)
+
+		return err == nil && canary.Spec.Template.Labels["team"] == "b"
+	}, "canary should be updated with the new labels")
+
+	// there is no kubelet in test env, the canary never gets traffic
+	suite.Eventually(func() bool {
+		suite.reloadComponent(component)
+
+		return component.Status.Rollout != nil &&
+			component.Status.Rollout.Phase == v1alpha1.RolloutPhaseProgressing &&
+			component.Status.Rollout.StableImage == "nginx:latest" &&
+			component.Status.Rollout.CanaryImage == "nginx:alpine" &&
+			component.Status.Rollout.CanaryWeight == 0
+	}, "rollout status should be reported")
+
+	suite.Nil(suite.K8sClient.Get(context.Background(), types.NamespacedName{
+		Name:      component.Name,
+		Namespace: component.Namespace,
+	}, &stable))
+	suite.Equal("nginx:latest", stable.Spec.Template.Spec.Containers[0].Image)
+
+	// removing the strategy updates the stable deployment directly
+	suite.reloadComponent(component)
+	component.Spec.RolloutStrategy = nil
+	suite.updateComponent(component)
+
+	suite.Eventually(func() bool {
+		err := suite.K8sClient.Get(context.Background(), types.NamespacedName{
+			Name:      getNameForCanaryDeployment(component.Name),
+			Namespace: component.Namespace,
+		}, &canary)
+
+		return errors.IsNotFound(err)
+	}, "canary should be deleted")
+}
+
+func (suite *ComponentControllerSuite) TestComponentAutoscaling() {
+	targetCPU := int32(70)
+
+	component := generateEmptyComponent(suite.ns.Name)
+	component.Spec.Autoscaling = &v1alpha1.AutoscalingSpec{
+		MinReplicas:                    2,
+		MaxReplicas:                    4,
+		TargetCPUUtilizationPercentage: &targetCPU,
+	}
+	suite.createComponent(component)
+
+	key := types.NamespacedName{
+		Namespace: component.Namespace,
+		Name:      component.Name,
+	}
+
+	var hpa autoscalingV2beta2.HorizontalPodAutoscaler
+	suite.Eventually(func() bool {
+		err := suite.K8sClient.Get(context.Background(), key, &hpa)
+
+		return err == nil &&
+			hpa.Spec.MaxReplicas == 4 &&
+			*hpa.Spec.MinReplicas == 2 &&
+			hpa.Spec.ScaleTargetRef.Kind == "Deployment" &&
+			len(hpa.Spec.Metrics) == 1
+	}, "autoscaler should be created")
+
+	var deployment appsV1.Deployment
+	suite.Nil(suite.K8sClient.Get(context.Background(), key, &deployment))
+	suite.Equal(int32(2), *deployment.Spec.Replicas)
+
+	// replicas set by the autoscaler should be kept
+	deployment.Spec.Replicas = &component.Spec.Autoscaling.MaxReplicas
+	suite.Nil(suite.K8sClient.Update(context.Background(), &deployment))
+
+	suite.reloadComponent(component)
+	component.Spec.Image = "nginx:alpine"
+	suite.updateComponent(component)
+
+	suite.Eventually(func() bool {
+		err := suite.K8sClient.Get(context.Background(), key, &deployment)
+
+		return err == nil &&
+			deployment.Spec.Template.Spec.Containers[0].Image == "nginx:alpine" &&
+			*deployment.Spec.Replicas == 4
+	}, "replicas of autoscaled deployment should not be reset")
+
+	suite.reloadComponent(component)
+	component.Spec.Autoscaling = nil
+	suite.updateComponent(component)
+
+	suite.Eventually(func() bool {
+		err := suite.K8sClient.Get(context.Background(), key, &hpa)
+
+		return errors.IsNotFound(err)
+	}, "autoscaler should be deleted")
+}
+
+func (suite *ComponentControllerSuite) TestComponentSidecars() {
+	component := generateEmptyComponent(suite.ns.Name)
+	component.Spec.Env = []v1alpha1.EnvVar{
+		{Name: "APP_ENV", Value: "production"},
+	}
+	component.Spec.Volumes = []v1alpha1.Volume{
+		{
+			Type: v1alpha1.VolumeTypeTemporaryDisk,
+			Path: "/data",
+			Size: resource.MustParse("10m"),
+		},
+	}
+	component.Spec.InitContainers = []v1alpha1.ComponentContainer{
+		{Name: "migrate", Image: "busybox", Command: "echo migrate"},
+	}
+	component.Spec.Sidecars = []v1alpha1.ComponentContainer{
+		{
+			Name:  "log-shipper",
+			Image: "busybox",
+			Env:   []v1alpha1.EnvVar{{Name: "APP_ENV", Value: "shipper"}},
+		},
+	}
+	suite.createComponent(component)
+
+	key := types.NamespacedName{
+		Namespace: component.Namespace,
+		Name:      component.Name,
+	}
+
+	var deployment appsV1.Deployment
+	suite.Eventually(func() bool {
+		return suite.K8sClient.Get(context.Background(), key, &deployment) == nil
+	}, "can't get deployment")
+
+	podSpec := deployment.Spec.Template.Spec
+	suite.Len(podSpec.InitContainers, 1)
+	suite.Len(podSpec.Containers, 2)
+
+	initContainer := podSpec.InitContainers[0]
+	suite.Equal("migrate", initContainer.Name)
+	suite.Equal([]string{"sh", "-c", "echo migrate"}, initContainer.Command)
+	suite.Equal("/data", initContainer.VolumeMounts[0].MountPath)
+
+	sidecar := podSpec.Containers[1]
+	suite.Equal("log-shipper", sidecar.Name)
+	suite.Equal("/data", sidecar.VolumeMounts[0].MountPath)
+
+	// envs of the sidecar itself replace envs of the component with the same name
+	var appEnvs []string
+	for _, env := range sidecar.Env {
+		if env.Name == "APP_ENV" {
+			appEnvs = append(appEnvs, env.Value)
+		}
+	}
+	suite.Equal([]string{"shipper"}, appEnvs)
+}
+
+func (suite *ComponentControllerSuite) TestComponentStartAfterComponents() {
+	dependency := generateEmptyComponent(suite.ns.Name, v1alpha1.WorkloadTypeCronjob)
+	dependency.Spec.Schedule = "*/5 * * * *"
+
+	component := generateEmptyComponent(suite.ns.Name)
+	component.Spec.StartAfterComponents = []string{dependency.Name}
+	suite.createComponent(component)
+
+	key := types.NamespacedName{
+		Namespace: component.Namespace,
+		Name:      component.Name,
+	}
+
+	var deployment appsV1.Deployment
+	suite.Eventually(func() bool {
+		err := suite.K8sClient.Get(context.Background(), key, &deployment)
+
+		return err == nil &&
+			*deployment.Spec.Replicas == 0 &&
+			deployment.Annotations[AnnoWaitingForComponents] == dependency.Name
+	}, "deployment should be held back by the missing dependency")
+
+	suite.Eventually(func() bool {
+		suite.reloadComponent(component)
+		ready := v1alpha1.GetComponentCondition(*component, v1alpha1.ComponentConditionReady)
+
+		degraded := v1alpha1.GetComponentCondition(*component, v1alpha1.ComponentConditionDegraded)
+
+		return len(component.Status.WaitingForComponents) == 1 &&
+			ready != nil && ready.Reason == ComponentReasonDependencyNotFound &&
+			degraded != nil && degraded.Status == coreV1.ConditionTrue
+	}, "component status should tell the dependency doesn't exist")
+
+	// a cronjob is ready as soon as it's scheduled
+	suite.createComponent(dependency)
+
+	suite.Eventually(func() bool {
+		err := suite.K8sClient.Get(context.Background(), key, &deployment)
+
+		return err == nil &&
+			*deployment.Spec.Replicas == 1 &&
+			!isHeldBackByDependencies(deployment.ObjectMeta)
+	}, "deployment should be released once the dependency is ready")
+}
+
+func (suite *ComponentControllerSuite) TestComponentSecrets() {
+	secret := coreV1.Secret{
+		ObjectMeta: metaV1.ObjectMeta{
+			Namespace: suite.ns.Name,
+			Name:      "db",
+		},
+		StringData: map[string]string{
+			"password": "foo",
+			"ca.crt":   "bar",
+		},
+	}
+	suite.createObject(&secret)
+
+	component := generateEmptyComponent(suite.ns.Name)
+	component.Spec.Env = append(component.Spec.Env, v1alpha1.EnvVar{
+		Name:  "DB_PASSWORD",
+		Type:  v1alpha1.EnvVarTypeSecret,
+		Value: "db/password",
+	})
+	component.Spec.PreInjectedFiles = []v1alpha1.PreInjectFile{
+		{MountPath: "/etc/db/ca.crt", SecretRef: "db/ca.crt"},
+	}
+	suite.createComponent(component)
+
+	key := types.NamespacedName{
+		Namespace: component.Namespace,
+		Name:      component.Name,
+	}
+
+	var deployment appsV1.Deployment
+	suite.Eventually(func() bool {
+		return suite.K8sClient.Get(context.Background(), key, &deployment) == nil
+	}, "can't get deployment")
+
+	podSpec := deployment.Spec.Template.Spec
+	suite.Len(podSpec.InitContainers, 0)
+	suite.Equal("db", podSpec.Volumes[0].Secret.SecretName)
+	suite.Equal("/etc/db/ca.crt", podSpec.Containers[0].VolumeMounts[0].MountPath)
+
+	var passwordEnv *coreV1.EnvVar
+	for i := range podSpec.Containers[0].Env {
+		if podSpec.Containers[0].Env[i].Name == "DB_PASSWORD" {
+			passwordEnv = &podSpec.Containers[0].Env[i]
+		}
+	}
+	suite.NotNil(passwordEnv)
+	suite.Equal("password", passwordEnv.ValueFrom.SecretKeyRef.Key)
+
+	hash := deployment.Spec.Template.Annotations[AnnoSecretsHash]
+	suite.NotEmpty(hash)
+
+	// rotating the secret restarts pods
+	suite.Nil(suite.K8sClient.Get(context.Background(), types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, &secret))
+	secret.Data["password"] = []byte("rotated")
+	suite.updateObject(&secret)
+
+	suite.Eventually(func() bool {
+		err := suite.K8sClient.Get(context.Background(), key, &deployment)
+
+		return err == nil && deployment.Spec.Template.Annotations[AnnoSecretsHash] != hash
+	}, "secrets hash should change after rotation")
+}
+
+func (suite *ComponentControllerSuite) TestComponentRevisionHistory() {
+	component := generateEmptyComponent(suite.ns.Name)
+	limit := int32(2)
+	component.Spec.RevisionHistoryLimit = &limit
+	component.Annotations = map[string]string{v1alpha1.AnnoChangedBy: "foo@bar.com"}
+	suite.createComponent(component)
+
+	listRevisions := func() []appsV1.ControllerRevision {
+		var revisionList appsV1.ControllerRevisionList
+		suite.Nil(suite.K8sClient.List(
+			context.Background(),
+			&revisionList,
+			client.InNamespace(component.Namespace),
+			client.MatchingLabels{KalmLabelComponentRevisionKey: component.Name},
+		))
+
+		SortComponentRevisions(revisionList.Items)
+
+		return revisionList.Items
+	}
+
+	suite.Eventually(func() bool {
+		suite.reloadComponent(component)
+		return component.Status.CurrentRevision == 1 && component.Annotations[v1alpha1.AnnoChangedBy] == ""
+	}, "first revision should be recorded")
+
+	revisions := listRevisions()
+	suite.Len(revisions, 1)
+	suite.Equal("foo@bar.com", revisions[0].Annotations[v1alpha1.AnnoChangedBy])
+
+	for _, image := range []string{"nginx:1.18", "nginx:1.19"} {
+		suite.reloadComponent(component)
+		component.Spec.Image = image
+		suite.updateComponent(component)
+	}
+
+	suite.Eventually(func() bool {
+		suite.reloadComponent(component)
+		return component.Status.CurrentRevision == 3
+	}, "a revision should be recorded for each change")
+
+	suite.Eventually(func() bool {
+		return len(listRevisions()) == 2
+	}, "revisions exceeding the limit should be pruned")
+
+	revisions = listRevisions()
+	suite.Equal(GetComponentRevisionName(component.Name, 2), revisions[0].Name)
+
+	spec, err := DecodeComponentRevision(&revisions[1])
+	suite.Nil(err)
+	suite.Equal("nginx:1.19", spec.Image)
+	suite.Empty(revisions[1].Annotations[v1alpha1.AnnoChangedBy])
+}
+
+func (suite *ComponentControllerSuite) TestComponentPreInjectedFilesRollout() {
+	component := generateEmptyComponent(suite.ns.Name)
+	component.Spec.PreInjectedFiles = []v1alpha1.PreInjectFile{
+		{MountPath: "/etc/app/app.conf", Content: "foo"},
+		{MountPath: "/etc/app/reload/reload.conf", Content: "foo", HotReload: true},
+	}
+	suite.createComponent(component)
+
+	key := types.NamespacedName{
+		Namespace: component.Namespace,
+		Name:      component.Name,
+	}
+
+	var deployment appsV1.Deployment
+	suite.Eventually(func() bool {
+		return suite.K8sClient.Get(context.Background(), key, &deployment) == nil
+	}, "can't get deployment")
+
+	getInjectCommand := func() string {
+		for _, c := range deployment.Spec.Template.Spec.InitContainers {
+			if c.Name == v1alpha1.PreInjectedFilesInitContainerName {
+				return strings.Join(c.Command, " ")
+			}
+		}
+
+		return ""
+	}
+
+	injectCommand := getInjectCommand()
+	suite.NotEmpty(injectCommand)
+	suite.Empty(deployment.Spec.Template.Annotations[AnnoSecretsHash])
+
+	var hotReloadMount *coreV1.VolumeMount
+	for i := range deployment.Spec.Template.Spec.Containers[0].VolumeMounts {
+		if deployment.Spec.Template.Spec.Containers[0].VolumeMounts[i].MountPath == "/etc/app/reload" {
+			hotReloadMount = &deployment.Spec.Template.Spec.Containers[0].VolumeMounts[i]
+		}
+	}
+	suite.NotNil(hotReloadMount)
+	suite.Empty(hotReloadMount.SubPath)
+
+	configMapKey := types.NamespacedName{
+		Namespace: component.Namespace,
+		Name:      getHotReloadFilesConfigMapName(component.Name),
+	}
+
+	// a hot reload file is updated without rolling the pods
+	suite.reloadComponent(component)
+	component.Spec.PreInjectedFiles[1].Content = "bar"
+	suite.updateComponent(component)
+
+	var configMap coreV1.ConfigMap
+	suite.Eventually(func() bool {
+		err := suite.K8sClient.Get(context.Background(), configMapKey, &configMap)
+
+		return err == nil && configMap.Data[getHotReloadFileKey("/etc/app/reload/reload.conf")] == "bar"
+	}, "hot reload file should be updated")
+
+	suite.Nil(suite.K8sClient.Get(context.Background(), key, &deployment))
+	suite.Equal(injectCommand, getInjectCommand())
+
+	suite.reloadComponent(component)
+	component.Spec.PreInjectedFiles[0].Content = "bar"
+	suite.updateComponent(component)
+
+	suite.Eventually(func() bool {
+		err := suite.K8sClient.Get(context.Background(), key, &deployment)
+
+		return err == nil && getInjectCommand() != injectCommand
+	}, "pod template should change with the file content")
+}
+
 func (suite *ComponentControllerSuite) getComponentPVCs(component *v1alpha1.Component) []coreV1.PersistentVolumeClaim {
 	var pvcList coreV1.PersistentVolumeClaimList
 	_ = suite.K8sClient.List(context.Background(), &pvcList, client.MatchingLabels{"kalm-component": component.Name})
@@ -698,3 +1182,76 @@ func TestRegexSplit(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestBuildPortLoadBalancerSettings(t *testing.T) {
+	settings := buildPortLoadBalancerSettings(nil)
+	assert.Equal(t, istioNetworkingV1alpha3.LoadBalancerSettings_LEAST_CONN, settings.GetSimple())
+
+	settings = buildPortLoadBalancerSettings(&v1alpha1.PortLoadBalancer{Policy: v1alpha1.LoadBalancerPolicyRoundRobin})
+	assert.Equal(t, istioNetworkingV1alpha3.LoadBalancerSettings_ROUND_ROBIN, settings.GetSimple())
+
+	settings = buildPortLoadBalancerSettings(&v1alpha1.PortLoadBalancer{
+		Policy:           v1alpha1.LoadBalancerPolicyConsistentHash,
+		HashKey:          v1alpha1.ConsistentHashKeyCookie,
+		CookieName:       "session",
+		CookieTTLSeconds: 3600,
+	})
+	cookie := settings.GetConsistentHash().GetHttpCookie()
+	assert.Equal(t, "session", cookie.Name)
+	assert.Equal(t, int64(3600), cookie.Ttl.Seconds)
+
+	// a session cookie
+	settings = buildPortLoadBalancerSettings(&v1alpha1.PortLoadBalancer{
+		Policy:     v1alpha1.LoadBalancerPolicyConsistentHash,
+		HashKey:    v1alpha1.ConsistentHashKeyCookie,
+		CookieName: "session",
+	})
+	assert.Equal(t, int64(0), settings.GetConsistentHash().GetHttpCookie().Ttl.Seconds)
+
+	settings = buildPortLoadBalancerSettings(&v1alpha1.PortLoadBalancer{
+		Policy:     v1alpha1.LoadBalancerPolicyConsistentHash,
+		HashKey:    v1alpha1.ConsistentHashKeyHeader,
+		HeaderName: "x-user-id",
+	})
+	assert.Equal(t, "x-user-id", settings.GetConsistentHash().GetHttpHeaderName())
+
+	settings = buildPortLoadBalancerSettings(&v1alpha1.PortLoadBalancer{
+		Policy:  v1alpha1.LoadBalancerPolicyConsistentHash,
+		HashKey: v1alpha1.ConsistentHashKeySourceIP,
+	})
+	assert.True(t, settings.GetConsistentHash().GetUseSourceIp())
+}
+
+func TestMountVolumesToContainers(t *testing.T) {
+	template := &coreV1.PodTemplateSpec{
+		Spec: coreV1.PodSpec{
+			Containers:     []coreV1.Container{{Name: "main"}, {Name: "sidecar"}},
+			InitContainers: []coreV1.Container{{Name: "init"}},
+		},
+	}
+
+	mounts := make([]coreV1.VolumeMount, 1, 2)
+	mounts[0] = coreV1.VolumeMount{Name: "data", MountPath: "/data"}
+
+	mountVolumesToContainers(template, mounts)
+
+	template.Spec.Containers[0].VolumeMounts = append(template.Spec.Containers[0].VolumeMounts, coreV1.VolumeMount{Name: "main-only"})
+	template.Spec.Containers[1].VolumeMounts = append(template.Spec.Containers[1].VolumeMounts, coreV1.VolumeMount{Name: "sidecar-only"})
+
+	assert.Equal(t, "main-only", template.Spec.Containers[0].VolumeMounts[1].Name)
+	assert.Equal(t, "sidecar-only", template.Spec.Containers[1].VolumeMounts[1].Name)
+	assert.Len(t, template.Spec.InitContainers[0].VolumeMounts, 1)
+}
+
+func TestMergeEnvs(t *testing.T) {
+	envs := []coreV1.EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}}
+
+	merged := mergeEnvs(envs, []coreV1.EnvVar{{Name: "B", Value: "3"}, {Name: "C", Value: "4"}})
+
+	assert.Equal(t, []coreV1.EnvVar{
+		{Name: "A", Value: "1"},
+		{Name: "B", Value: "3"},
+		{Name: "C", Value: "4"},
+	}, merged)
+	assert.Equal(t, "2", envs[1].Value)
+}
@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// set on a workload which is held back until the listed components are ready
+	AnnoWaitingForComponents = "core.kalm.dev/waiting-for-components"
+
+	ComponentReasonWaitingForDependencies = "WaitingForDependencies"
+	ComponentReasonDependencyNotFound     = "DependencyNotFound"
+)
+
+// ComponentDependentsMapper enqueues the components which start after the changed component,
+// so a held back component starts as soon as its dependencies become ready.
+type ComponentDependentsMapper struct {
+	*BaseReconciler
+}
+
+func (m *ComponentDependentsMapper) Map(object handler.MapObject) []reconcile.Request {
+	component, ok := object.Object.(*v1alpha1.Component)
+	if !ok {
+		return nil
+	}
+
+	var componentList v1alpha1.ComponentList
+	if err := m.Reader.List(context.Background(), &componentList, client.InNamespace(component.Namespace)); err != nil {
+		m.Log.Error(err, "Can't list components in mapper.")
+		return nil
+	}
+
+	var res []reconcile.Request
+
+	for _, item := range componentList.Items {
+		for _, dep := range item.Spec.StartAfterComponents {
+			if dep != component.Name {
+				continue
+			}
+
+			res = append(res, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: item.Namespace, Name: item.Name},
+			})
+
+			break
+		}
+	}
+
+	return res
+}
+
+func isHeldBackByDependencies(meta metaV1.ObjectMeta) bool {
+	_, exist := meta.Annotations[AnnoWaitingForComponents]
+	return exist
+}
+
+func (r *ComponentReconcilerTask) isWaitingForDependencies() bool {
+	return len(r.waitingForComponents) > 0
+}
+
+func (r *ComponentReconcilerTask) hasMissingDependencies() bool {
+	return len(r.missingComponents) > 0
+}
+
+// setWaitingForComponentsAnnotation marks the workload as held back, or removes the mark once it's released.
+func (r *ComponentReconcilerTask) setWaitingForComponentsAnnotation(meta *metaV1.ObjectMeta) {
+	if !r.isWaitingForDependencies() {
+		delete(meta.Annotations, AnnoWaitingForComponents)
+		return
+	}
+
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string)
+	}
+
+	meta.Annotations[AnnoWaitingForComponents] = strings.Join(r.waitingForComponents, ",")
+}
+
+// hasStarted returns true if the workload exists and is not held back.
+// Dependencies only hold back the first start of a component, a started component
+// is not stopped when a component it starts after becomes unavailable.
+func (r *ComponentReconcilerTask) hasStarted() bool {
+	var meta *metaV1.ObjectMeta
+
+	switch r.component.Spec.WorkloadType {
+	case v1alpha1.WorkloadTypeServer, "":
+		if r.deployment != nil {
+			meta = &r.deployment.ObjectMeta
+		}
+	case v1alpha1.WorkloadTypeStatefulSet:
+		if r.statefulSet != nil {
+			meta = &r.statefulSet.ObjectMeta
+		}
+	case v1alpha1.WorkloadTypeDaemonSet:
+		if r.daemonSet != nil {
+			meta = &r.daemonSet.ObjectMeta
+		}
+	case v1alpha1.WorkloadTypeCronjob:
+		if r.cronJob != nil {
+			meta = &r.cronJob.ObjectMeta
+		}
+	}
+
+	return meta != nil && !isHeldBackByDependencies(*meta)
+}
+
+// ResolveDependencies finds the components in StartAfterComponents which are not ready yet.
+// A missing component is never ready, it's also recorded in missingComponents so the status tells why the component doesn't start.
+func (r *ComponentReconcilerTask) ResolveDependencies() error {
+	r.waitingForComponents = nil
+	r.missingComponents = nil
+
+	if len(r.component.Spec.StartAfterComponents) == 0 || r.hasStarted() {
+		return nil
+	}
+
+	for _, name := range r.component.Spec.StartAfterComponents {
+		var dep v1alpha1.Component
+
+		err := r.Get(r.ctx, types.NamespacedName{Namespace: r.component.Namespace, Name: name}, &dep)
+
+		if errors.IsNotFound(err) {
+			r.waitingForComponents = append(r.waitingForComponents, name)
+			r.missingComponents = append(r.missingComponents, name)
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if !v1alpha1.IsComponentReady(dep) {
+			r.waitingForComponents = append(r.waitingForComponents, name)
+		}
+	}
+
+	// events are only emitted when the state of the dependencies changes, not on every reconcile
+	if !r.isDependenciesStateChanged() {
+		return nil
+	}
+
+	if r.hasMissingDependencies() {
+		r.WarningEvent(
+			fmt.Errorf("components not found: %s", strings.Join(r.missingComponents, ", ")),
+			"unable to start, components to start after don't exist",
+		)
+	} else if r.isWaitingForDependencies() {
+		r.NormalEvent(
+			ComponentReasonWaitingForDependencies,
+			"waiting for components to be ready: %s",
+			strings.Join(r.waitingForComponents, ", "),
+		)
+	}
+
+	return nil
+}
+
+// isDependenciesStateChanged compares the components waited for, and whether some of them are missing,
+// with the ones recorded in the status by the last reconcile.
+func (r *ComponentReconcilerTask) isDependenciesStateChanged() bool {
+	if strings.Join(r.waitingForComponents, ",") != strings.Join(r.component.Status.WaitingForComponents, ",") {
+		return true
+	}
+
+	if !r.isWaitingForDependencies() {
+		return false
+	}
+
+	reason := ComponentReasonWaitingForDependencies
+	if r.hasMissingDependencies() {
+		reason = ComponentReasonDependencyNotFound
+	}
+
+	cond := v1alpha1.GetComponentCondition(*r.component, v1alpha1.ComponentConditionReady)
+
+	return cond == nil || cond.Reason != reason
+}
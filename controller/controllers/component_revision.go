@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	appsV1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=apps,resources=controllerrevisions,verbs=get;list;watch;create;update;patch;delete
+
+const (
+	// ControllerRevisions holding the spec history of a component, the value is the component name.
+	// Statefulsets create ControllerRevisions with the labels of their pods too, so kalm-component can't be used.
+	KalmLabelComponentRevisionKey = "kalm-component-revision"
+
+	DefaultRevisionHistoryLimit = 10
+)
+
+func GetComponentRevisionName(componentName string, revision int64) string {
+	return fmt.Sprintf("%s-revision-%d", componentName, revision)
+}
+
+// normalizeRevisionSpec drops fields which are one-off actions rather than a part of a revision.
+func normalizeRevisionSpec(spec v1alpha1.ComponentSpec) v1alpha1.ComponentSpec {
+	spec.ImmediateTrigger = false
+	return spec
+}
+
+// DecodeComponentRevision returns the component spec saved in a revision.
+func DecodeComponentRevision(revision *appsV1.ControllerRevision) (*v1alpha1.ComponentSpec, error) {
+	var spec v1alpha1.ComponentSpec
+
+	if err := json.Unmarshal(revision.Data.Raw, &spec); err != nil {
+		return nil, fmt.Errorf("invalid data of revision %s: %s", revision.Name, err.Error())
+	}
+
+	return &spec, nil
+}
+
+// SortComponentRevisions sorts revisions from the oldest to the newest.
+func SortComponentRevisions(revisions []appsV1.ControllerRevision) {
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+}
+
+func (r *ComponentReconcilerTask) getRevisionHistoryLimit() int {
+	if r.component.Spec.RevisionHistoryLimit != nil {
+		return int(*r.component.Spec.RevisionHistoryLimit)
+	}
+
+	return DefaultRevisionHistoryLimit
+}
+
+// ReconcileRevisionHistory records the spec of the component as a new revision if it differs from the latest one,
+// and prunes revisions exceeding the history limit.
+func (r *ComponentReconcilerTask) ReconcileRevisionHistory() error {
+	var revisionList appsV1.ControllerRevisionList
+
+	// read from the api server, a stale cache would record the same spec twice
+	if err := r.Reader.List(
+		r.ctx,
+		&revisionList,
+		client.InNamespace(r.component.Namespace),
+		client.MatchingLabels{KalmLabelComponentRevisionKey: r.component.Name},
+	); err != nil {
+		return err
+	}
+
+	revisions := revisionList.Items
+	SortComponentRevisions(revisions)
+
+	spec := normalizeRevisionSpec(r.component.Spec)
+	changed := true
+
+	if len(revisions) > 0 {
+		latest := &revisions[len(revisions)-1]
+		latestSpec, err := DecodeComponentRevision(latest)
+
+		if err != nil {
+			return err
+		}
+
+		if equality.Semantic.DeepEqual(*latestSpec, spec) {
+			changed = false
+			r.currentRevision = latest.Revision
+		}
+	}
+
+	if changed {
+		revision, err := r.createRevision(spec, revisions)
+		if err != nil {
+			return err
+		}
+
+		revisions = append(revisions, *revision)
+		r.currentRevision = revision.Revision
+		r.NormalEvent("RevisionRecorded", "Recorded revision %d of the component", revision.Revision)
+	}
+
+	for i := 0; i < len(revisions)-r.getRevisionHistoryLimit(); i++ {
+		if err := r.Delete(r.ctx, &revisions[i]); client.IgnoreNotFound(err) != nil {
+			r.WarningEvent(err, "failed to prune revision %s", revisions[i].Name)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *ComponentReconcilerTask) createRevision(spec v1alpha1.ComponentSpec, revisions []appsV1.ControllerRevision) (*appsV1.ControllerRevision, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	number := int64(1)
+	if len(revisions) > 0 {
+		number = revisions[len(revisions)-1].Revision + 1
+	}
+
+	// who made the change of the generation is recorded by the webhook at admission
+	annotations := make(map[string]string)
+	if change := v1alpha1.GetComponentChange(r.component, r.component.Generation); change != nil {
+		for key, value := range map[string]string{
+			v1alpha1.AnnoChangedBy:            change.ChangedBy,
+			v1alpha1.AnnoChangedByAccessToken: change.ChangedByAccessToken,
+			v1alpha1.AnnoChangeCause:          change.ChangeCause,
+		} {
+			if value != "" {
+				annotations[key] = value
+			}
+		}
+	}
+
+	revision := &appsV1.ControllerRevision{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:        GetComponentRevisionName(r.component.Name, number),
+			Namespace:   r.component.Namespace,
+			Labels:      map[string]string{KalmLabelComponentRevisionKey: r.component.Name},
+			Annotations: annotations,
+		},
+		Data:     runtime.RawExtension{Raw: data},
+		Revision: number,
+	}
+
+	if err := ctrl.SetControllerReference(r.component, revision, r.Scheme); err != nil {
+		r.WarningEvent(err, "unable to set owner for revision")
+		return nil, err
+	}
+
+	if err := r.Create(r.ctx, revision); err != nil {
+		r.WarningEvent(err, "failed to create revision %s", revision.Name)
+		return nil, err
+	}
+
+	return revision, nil
+}
@@ -0,0 +1,418 @@
+package controllers
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	appsV1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// pods of a component with a rollout strategy are labeled with the track they belong to,
+	// the DestinationRule of the component has a subset for each track.
+	KalmLabelRolloutTrack = "kalm-rollout-track"
+	RolloutTrackStable    = "stable"
+	RolloutTrackCanary    = "canary"
+
+	AnnoRolloutRevision = "core.kalm.dev/rollout-revision"
+
+	// how soon to check a rollout which is waiting for a deployment
+	rolloutRequeueInterval = 5 * time.Second
+)
+
+func getNameForCanaryDeployment(componentName string) string {
+	return fmt.Sprintf("%s-canary", componentName)
+}
+
+// getRolloutRevision returns a hash of the pod template, it's computed before any rollout label is added.
+func getRolloutRevision(template *corev1.PodTemplateSpec) (string, error) {
+	bts, err := json.Marshal(template)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", md5.Sum(bts))[:10], nil
+}
+
+// getDeploymentSelectorLabels returns the selector of the stable or canary deployment of the component.
+// The tracks never match pods of each other, user labels are excluded as the selector is immutable.
+func getDeploymentSelectorLabels(component *v1alpha1.Component, track string) map[string]string {
+	return map[string]string{
+		v1alpha1.KalmLabelNamespaceKey: component.Namespace,
+		v1alpha1.KalmLabelComponentKey: component.Name,
+		KalmLabelRolloutTrack:          track,
+	}
+}
+
+func withRolloutTrack(template *corev1.PodTemplateSpec, revision, track string) *corev1.PodTemplateSpec {
+	copied := template.DeepCopy()
+
+	if copied.Labels == nil {
+		copied.Labels = make(map[string]string)
+	}
+
+	if copied.Annotations == nil {
+		copied.Annotations = make(map[string]string)
+	}
+
+	copied.Labels[KalmLabelRolloutTrack] = track
+	copied.Annotations[AnnoRolloutRevision] = revision
+
+	return copied
+}
+
+func (r *ComponentReconcilerTask) getDesiredReplicas() int32 {
+	if r.isAutoscaled() && r.deployment != nil && r.deployment.Spec.Replicas != nil {
+		return *r.deployment.Spec.Replicas
+	}
+
+	if r.component.Spec.Replicas != nil {
+		return *r.component.Spec.Replicas
+	}
+
+	return 1
+}
+
+// ReconcileRollout deploys a new revision of a server component as a canary deployment
+// next to the stable one. The HttpRoute controller splits the traffic of the component
+// between the two DestinationRule subsets according to the canary weight in the status.
+// Traffic which doesn't go through a HttpRoute is balanced across both deployments.
+func (r *ComponentReconcilerTask) ReconcileRollout(template *corev1.PodTemplateSpec) error {
+	revision, err := getRolloutRevision(template)
+	if err != nil {
+		return err
+	}
+
+	stable := r.deployment
+
+	// Nothing to roll out if this is the first revision, the stable deployment is held back by dependencies,
+	// it's created before the strategy was set, or it's already running the desired revision.
+	if stable == nil ||
+		isHeldBackByDependencies(stable.ObjectMeta) ||
+		stable.Spec.Template.Annotations[AnnoRolloutRevision] == "" ||
+		stable.Spec.Template.Annotations[AnnoRolloutRevision] == revision {
+
+		if err := r.ReconcileDeployment(withRolloutTrack(template, revision, RolloutTrackStable)); err != nil {
+			return err
+		}
+
+		return r.finishRollout(revision)
+	}
+
+	strategy := r.component.Spec.RolloutStrategy.WithDefaults()
+	rollout := r.rolloutStatus
+
+	if rollout != nil && rollout.Revision == revision && rollout.Phase == v1alpha1.RolloutPhaseRolledBack {
+		// a failed revision will not be retried until the spec is changed
+		if err := r.syncStableReplicas(); err != nil {
+			return err
+		}
+
+		return r.deleteCanaryDeployment()
+	}
+
+	if rollout == nil || rollout.Revision != revision {
+		now := metaV1.Now()
+		rollout = &v1alpha1.ComponentRolloutStatus{
+			Phase:         v1alpha1.RolloutPhaseProgressing,
+			Revision:      revision,
+			StableImage:   mainContainerImage(stable.Spec.Template),
+			CanaryImage:   mainContainerImage(*template),
+			StartedAt:     &now,
+			StepStartedAt: &now,
+			Message:       "waiting for the canary to be ready",
+		}
+		r.rolloutStatus = rollout
+
+		r.NormalEvent("RolloutStarted", fmt.Sprintf("rolling out revision %s with image %s", revision, rollout.CanaryImage))
+	}
+
+	// The revision only covers the pod template, steps may be removed while the rollout is in progress.
+	if rollout.CurrentStep >= len(strategy.Steps) {
+		rollout.CurrentStep = len(strategy.Steps) - 1
+	}
+
+	if rollout.Phase == v1alpha1.RolloutPhasePromoting {
+		// the stable deployment has been updated to this revision, wait for it in finishRollout
+		return r.ReconcileDeployment(withRolloutTrack(template, revision, RolloutTrackStable))
+	}
+
+	if err := r.syncStableReplicas(); err != nil {
+		return err
+	}
+
+	canaryReplicas := r.getDesiredReplicas()
+	if strategy.Type == v1alpha1.RolloutStrategyCanary {
+		weight := strategy.Steps[rollout.CurrentStep]
+		canaryReplicas = int32(math.Ceil(float64(canaryReplicas) * float64(weight) / 100))
+	}
+
+	if canaryReplicas < 1 {
+		canaryReplicas = 1
+	}
+
+	if err := r.reconcileCanaryDeployment(withRolloutTrack(template, revision, RolloutTrackCanary), canaryReplicas); err != nil {
+		return err
+	}
+
+	// the canary is being recreated
+	if r.canaryDeployment == nil {
+		return nil
+	}
+
+	return r.progressRollout(strategy, withRolloutTrack(template, revision, RolloutTrackStable))
+}
+
+// progressRollout moves the rollout to the next step if the canary is healthy during the current step.
+// stableTemplate is applied to the stable deployment once all steps are passed.
+func (r *ComponentReconcilerTask) progressRollout(strategy v1alpha1.RolloutStrategy, stableTemplate *corev1.PodTemplateSpec) error {
+	rollout := r.rolloutStatus
+	canary := r.canaryDeployment
+	now := time.Now()
+
+	if rollout.StepStartedAt == nil {
+		// started by an older version of the controller or a lost status update
+		startedAt := metaV1.NewTime(now)
+		rollout.StepStartedAt = &startedAt
+	}
+
+	elapsed := now.Sub(rollout.StepStartedAt.Time)
+
+	// The weight of a step is only applied once the canary is scaled for it.
+	if rollout.CanaryWeight != strategy.Steps[rollout.CurrentStep] {
+		isCanaryReady := canary.Status.ObservedGeneration >= canary.Generation &&
+			canary.Spec.Replicas != nil &&
+			canary.Status.ReadyReplicas >= *canary.Spec.Replicas
+
+		if !isCanaryReady {
+			deadline := time.Duration(strategy.ProgressDeadlineSeconds) * time.Second
+
+			if elapsed > deadline {
+				return r.rollback(fmt.Sprintf("canary is not ready after %s", deadline))
+			}
+
+			// the canary deployment is owned by the component, its status changes will trigger reconciliation
+			r.requeueAfter = deadline - elapsed
+			return nil
+		}
+
+		r.startRolloutStep(strategy)
+		r.NormalEvent("RolloutStepped", rollout.Message)
+		return nil
+	}
+
+	interval := time.Duration(strategy.StepIntervalSeconds) * time.Second
+
+	if elapsed < interval {
+		r.requeueAfter = interval - elapsed
+		return nil
+	}
+
+	rate, requests, err := r.RolloutMetric.GetSuccessRate(r.component.Namespace, canary.Name, interval)
+	if err != nil {
+		// don't make decisions without metrics, try again later
+		r.WarningEvent(err, "unable to get success rate of canary")
+		r.requeueAfter = interval
+		return nil
+	}
+
+	// no traffic means no evidence of failure, the rollout goes on
+	if requests > 0 && rate*100 < float64(strategy.SuccessRateThreshold) {
+		return r.rollback(fmt.Sprintf(
+			"success rate %.2f%% of canary is lower than %d%% at step %d",
+			rate*100,
+			strategy.SuccessRateThreshold,
+			rollout.CurrentStep,
+		))
+	}
+
+	if rollout.CurrentStep+1 < len(strategy.Steps) {
+		// scale the canary for the next step in the next round
+		startedAt := metaV1.NewTime(now)
+		rollout.CurrentStep++
+		rollout.StepStartedAt = &startedAt
+		rollout.Message = fmt.Sprintf("scaling the canary for step %d/%d", rollout.CurrentStep+1, len(strategy.Steps))
+		r.requeueAfter = rolloutRequeueInterval
+		return nil
+	}
+
+	return r.promote(stableTemplate)
+}
+
+func (r *ComponentReconcilerTask) startRolloutStep(strategy v1alpha1.RolloutStrategy) {
+	now := metaV1.Now()
+	step := r.rolloutStatus.CurrentStep
+
+	r.rolloutStatus.CanaryWeight = strategy.Steps[step]
+	r.rolloutStatus.StepStartedAt = &now
+	r.rolloutStatus.Message = fmt.Sprintf("step %d/%d, %d%% traffic to canary", step+1, len(strategy.Steps), strategy.Steps[step])
+
+	r.requeueAfter = time.Duration(strategy.StepIntervalSeconds) * time.Second
+}
+
+// promote updates the stable deployment to the new revision. The canary keeps serving
+// all traffic until the stable deployment is rolled out.
+func (r *ComponentReconcilerTask) promote(template *corev1.PodTemplateSpec) error {
+	rollout := r.rolloutStatus
+
+	rollout.Phase = v1alpha1.RolloutPhasePromoting
+	rollout.CanaryWeight = 100
+	rollout.StepStartedAt = nil
+	rollout.Message = "all steps passed, updating stable workload"
+
+	r.NormalEvent("RolloutPromoting", fmt.Sprintf("revision %s passed all steps, updating stable workload", rollout.Revision))
+	r.requeueAfter = rolloutRequeueInterval
+
+	return r.ReconcileDeployment(template)
+}
+
+func (r *ComponentReconcilerTask) rollback(reason string) error {
+	rollout := r.rolloutStatus
+
+	rollout.Phase = v1alpha1.RolloutPhaseRolledBack
+	rollout.CanaryWeight = 0
+	rollout.StepStartedAt = nil
+	rollout.Message = reason
+
+	r.WarningEvent(errors.New(reason), fmt.Sprintf("rollout of revision %s is rolled back", rollout.Revision))
+
+	return r.deleteCanaryDeployment()
+}
+
+// finishRollout is called once the stable deployment runs the desired revision.
+func (r *ComponentReconcilerTask) finishRollout(revision string) error {
+	rollout := r.rolloutStatus
+
+	if rollout != nil && rollout.Revision == revision && rollout.Phase == v1alpha1.RolloutPhasePromoting {
+		if !r.getWorkloadState().isRolledOut() {
+			r.requeueAfter = rolloutRequeueInterval
+			return nil
+		}
+
+		// switch traffic back to the stable subset first, the canary is removed in the next round
+		rollout.Phase = v1alpha1.RolloutPhasePromoted
+		rollout.CanaryWeight = 0
+		rollout.Message = fmt.Sprintf("image %s is promoted", rollout.CanaryImage)
+
+		r.NormalEvent("RolloutPromoted", rollout.Message)
+		r.requeueAfter = rolloutRequeueInterval
+
+		return nil
+	}
+
+	if rollout.IsInProgress() {
+		// the spec is reverted to the stable revision during a rollout
+		r.NormalEvent("RolloutAborted", fmt.Sprintf("rollout of revision %s is aborted", rollout.Revision))
+		r.rolloutStatus = nil
+	}
+
+	return r.deleteCanaryDeployment()
+}
+
+// syncStableReplicas applies replicas changes to the stable deployment, which is not updated during a rollout
+func (r *ComponentReconcilerTask) syncStableReplicas() error {
+	replicas := r.getDesiredReplicas()
+
+	if r.deployment.Spec.Replicas != nil && *r.deployment.Spec.Replicas == replicas {
+		return nil
+	}
+
+	copied := r.deployment.DeepCopy()
+	copied.Spec.Replicas = &replicas
+
+	if err := r.Patch(r.ctx, copied, client.MergeFrom(r.deployment)); err != nil {
+		r.WarningEvent(err, "unable to scale stable Deployment")
+		return err
+	}
+
+	r.deployment = copied
+
+	return nil
+}
+
+func (r *ComponentReconcilerTask) reconcileCanaryDeployment(template *corev1.PodTemplateSpec, replicas int32) error {
+	canary := r.canaryDeployment
+	isNew := canary == nil
+
+	selector := getDeploymentSelectorLabels(r.component, RolloutTrackCanary)
+
+	// A canary created with the pod labels as its selector can't be updated once the labels change,
+	// it's recreated with the track selector in the next round.
+	if !isNew && !equality.Semantic.DeepEqual(canary.Spec.Selector.MatchLabels, selector) {
+		r.requeueAfter = rolloutRequeueInterval
+		return r.deleteCanaryDeployment()
+	}
+
+	if isNew {
+		canary = &appsV1.Deployment{
+			ObjectMeta: metaV1.ObjectMeta{
+				Name:        getNameForCanaryDeployment(r.component.Name),
+				Namespace:   r.component.Namespace,
+				Annotations: r.GetAnnotations(),
+			},
+			Spec: appsV1.DeploymentSpec{
+				Selector: &metaV1.LabelSelector{
+					MatchLabels: selector,
+				},
+			},
+		}
+	}
+
+	canary.Labels = template.Labels
+
+	canary.Spec.Template = *template
+	canary.Spec.Replicas = &replicas
+
+	if err := ctrl.SetControllerReference(r.component, canary, r.Scheme); err != nil {
+		r.WarningEvent(err, "unable to set owner for canary deployment")
+		return err
+	}
+
+	if err := r.runPlugins(ComponentPluginMethodBeforeDeploymentSave, r.component, canary, canary); err != nil {
+		r.WarningEvent(err, "run before deployment save error.")
+		return err
+	}
+
+	if isNew {
+		if err := r.Create(r.ctx, canary); err != nil {
+			r.WarningEvent(err, "unable to create canary Deployment")
+			return err
+		}
+
+		r.NormalEvent("DeploymentCreated", canary.Name+" is created.")
+	} else {
+		if err := r.Update(r.ctx, canary); err != nil {
+			r.WarningEvent(err, "unable to update canary Deployment")
+			return err
+		}
+	}
+
+	r.canaryDeployment = canary
+
+	return nil
+}
+
+func (r *ComponentReconcilerTask) deleteCanaryDeployment() error {
+	if r.canaryDeployment == nil {
+		return nil
+	}
+
+	if err := r.Delete(r.ctx, r.canaryDeployment); client.IgnoreNotFound(err) != nil {
+		r.WarningEvent(err, "unable to delete canary Deployment")
+		return err
+	}
+
+	r.canaryDeployment = nil
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// RolloutMetricProvider tells how well a workload is serving its traffic.
+// It's an interface so tests can decide the result of a rollout step.
+type RolloutMetricProvider interface {
+	// GetSuccessRate returns the ratio of non-5xx responses and the total number of
+	// requests received by the workload during the last window.
+	GetSuccessRate(namespace, workload string, window time.Duration) (rate float64, requests float64, err error)
+}
+
+type PrometheusRolloutMetricProvider struct {
+	address string
+	client  *http.Client
+}
+
+func NewPrometheusRolloutMetricProvider() *PrometheusRolloutMetricProvider {
+	address := os.Getenv("KALM_ISTIO_PROMETHEUS_API_ADDRESS")
+
+	if address == "" {
+		address = "http://prometheus.istio-system:9090"
+	}
+
+	return &PrometheusRolloutMetricProvider{
+		address: address,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *PrometheusRolloutMetricProvider) GetSuccessRate(namespace, workload string, window time.Duration) (float64, float64, error) {
+	selector := fmt.Sprintf(
+		`reporter="destination",destination_workload_namespace="%s",destination_workload="%s"`,
+		namespace,
+		workload,
+	)
+
+	total, err := p.queryScalar(fmt.Sprintf(`sum(increase(istio_requests_total{%s}[%ds]))`, selector, int(window.Seconds())))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if total == 0 {
+		return 1, 0, nil
+	}
+
+	failed, err := p.queryScalar(fmt.Sprintf(`sum(increase(istio_requests_total{%s,response_code=~"5.."}[%ds]))`, selector, int(window.Seconds())))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return (total - failed) / total, total, nil
+}
+
+type promInstantQueryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		Result []struct {
+			Value []interface{} `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// queryScalar runs an instant query whose result is a single sample, an empty result is 0
+func (p *PrometheusRolloutMetricProvider) queryScalar(query string) (float64, error) {
+	resp, err := p.client.Get(fmt.Sprintf("%s/api/v1/query?query=%s", p.address, url.QueryEscape(query)))
+	if err != nil {
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	var promResp promInstantQueryResponse
+	if err := json.Unmarshal(body, &promResp); err != nil {
+		return 0, err
+	}
+
+	if promResp.Status != "success" {
+		return 0, fmt.Errorf("prometheus query failed, status: %s, error: %s", promResp.Status, promResp.Error)
+	}
+
+	if len(promResp.Data.Result) == 0 || len(promResp.Data.Result[0].Value) != 2 {
+		return 0, nil
+	}
+
+	value, ok := promResp.Data.Result[0].Value[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("unexpected prometheus sample value: %v", promResp.Data.Result[0].Value[1])
+	}
+
+	return strconv.ParseFloat(value, 64)
+}
@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// hash of the data of secrets referenced by the component, set on the pod template so pods are
+// restarted on secret rotation. Anything else affecting the pods, such as injected files and linked
+// envs, is already part of the pod template.
+const AnnoSecretsHash = "core.kalm.dev/secrets-hash"
+
+// getReferencedSecretNames returns the sorted names of secrets used by secret envs and files of the component.
+func getReferencedSecretNames(component *v1alpha1.Component) []string {
+	names := make(map[string]bool)
+
+	addEnvs := func(envs []v1alpha1.EnvVar) {
+		for _, env := range envs {
+			if env.Type != v1alpha1.EnvVarTypeSecret {
+				continue
+			}
+
+			if name, _, ok := v1alpha1.ParseSecretKeyRef(env.Value); ok {
+				names[name] = true
+			}
+		}
+	}
+
+	addEnvs(component.Spec.Env)
+
+	for _, c := range component.Spec.InitContainers {
+		addEnvs(c.Env)
+	}
+
+	for _, c := range component.Spec.Sidecars {
+		addEnvs(c.Env)
+	}
+
+	for _, file := range component.Spec.PreInjectedFiles {
+		if name, _, ok := v1alpha1.ParseSecretKeyRef(file.SecretRef); ok {
+			names[name] = true
+		}
+	}
+
+	res := make([]string, 0, len(names))
+	for name := range names {
+		res = append(res, name)
+	}
+
+	sort.Strings(res)
+
+	return res
+}
+
+// getSecretsHash returns a hash of the data of all referenced secrets, empty if there is none.
+// A missing secret is part of the hash as well, pods are restarted once it's created.
+func (r *ComponentReconcilerTask) getSecretsHash() (string, error) {
+	names := getReferencedSecretNames(r.component)
+
+	if len(names) == 0 {
+		return "", nil
+	}
+
+	hash := sha256.New()
+
+	for _, name := range names {
+		var secret corev1.Secret
+
+		err := r.Get(r.ctx, types.NamespacedName{Namespace: r.component.Namespace, Name: name}, &secret)
+
+		if errors.IsNotFound(err) {
+			fmt.Fprintf(hash, "secret %s:missing;", name)
+			continue
+		} else if err != nil {
+			return "", err
+		}
+
+		keys := make([]string, 0, len(secret.Data))
+		for key := range secret.Data {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		fmt.Fprintf(hash, "secret %s:", name)
+
+		for _, key := range keys {
+			fmt.Fprintf(hash, "%s=%x,", key, secret.Data[key])
+		}
+
+		fmt.Fprint(hash, ";")
+	}
+
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// ComponentSecretsMapper enqueues the components which reference the changed secret.
+type ComponentSecretsMapper struct {
+	*BaseReconciler
+}
+
+func (m *ComponentSecretsMapper) Map(object handler.MapObject) []reconcile.Request {
+	secret, ok := object.Object.(*corev1.Secret)
+	if !ok {
+		return nil
+	}
+
+	var componentList v1alpha1.ComponentList
+	if err := m.Reader.List(context.Background(), &componentList, client.InNamespace(secret.Namespace)); err != nil {
+		m.Log.Error(err, "Can't list components in mapper.")
+		return nil
+	}
+
+	var res []reconcile.Request
+
+	for i := range componentList.Items {
+		component := &componentList.Items[i]
+
+		for _, name := range getReferencedSecretNames(component) {
+			if name != secret.Name {
+				continue
+			}
+
+			res = append(res, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: component.Namespace, Name: component.Name},
+			})
+
+			break
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,345 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	ComponentReasonReplicasReady       = "ReplicasReady"
+	ComponentReasonReplicasNotReady    = "ReplicasNotReady"
+	ComponentReasonRollingOut          = "RollingOut"
+	ComponentReasonRolloutComplete     = "RolloutComplete"
+	ComponentReasonReconcileFailed     = "ReconcileFailed"
+	ComponentReasonNoFailure           = "NoFailure"
+	ComponentReasonNotKalmEnabled      = "NamespaceNotKalmEnabled"
+	ComponentReasonReadinessProbeFails = "ReadinessProbeFailing"
+)
+
+// container waiting reasons which will not recover without user intervention
+var componentFailingWaitingReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	"RunContainerError":          true,
+}
+
+type componentWorkloadState struct {
+	exists          bool
+	upToDate        bool
+	desiredReplicas int32
+	readyReplicas   int32
+	updatedReplicas int32
+	// replicas of old revisions which are still running
+	staleReplicas int32
+	image         string
+}
+
+func (s componentWorkloadState) isRolledOut() bool {
+	return s.exists &&
+		s.upToDate &&
+		s.staleReplicas == 0 &&
+		s.updatedReplicas >= s.desiredReplicas &&
+		s.readyReplicas >= s.desiredReplicas
+}
+
+func mainContainerImage(template corev1.PodTemplateSpec) string {
+	if len(template.Spec.Containers) == 0 {
+		return ""
+	}
+
+	return template.Spec.Containers[0].Image
+}
+
+func (r *ComponentReconcilerTask) getWorkloadState() componentWorkloadState {
+	var state componentWorkloadState
+
+	switch r.component.Spec.WorkloadType {
+	case v1alpha1.WorkloadTypeServer, "":
+		if r.deployment == nil {
+			return state
+		}
+
+		dp := r.deployment
+		state.exists = true
+		state.upToDate = dp.Status.ObservedGeneration >= dp.Generation
+		state.desiredReplicas = 1
+		if dp.Spec.Replicas != nil {
+			state.desiredReplicas = *dp.Spec.Replicas
+		}
+		state.readyReplicas = dp.Status.ReadyReplicas
+		state.updatedReplicas = dp.Status.UpdatedReplicas
+		state.staleReplicas = dp.Status.Replicas - dp.Status.UpdatedReplicas
+		state.image = mainContainerImage(dp.Spec.Template)
+	case v1alpha1.WorkloadTypeStatefulSet:
+		if r.statefulSet == nil {
+			return state
+		}
+
+		sts := r.statefulSet
+		state.exists = true
+		state.upToDate = sts.Status.ObservedGeneration >= sts.Generation
+		state.desiredReplicas = 1
+		if sts.Spec.Replicas != nil {
+			state.desiredReplicas = *sts.Spec.Replicas
+		}
+		state.readyReplicas = sts.Status.ReadyReplicas
+		state.updatedReplicas = sts.Status.UpdatedReplicas
+		if sts.Status.UpdateRevision != "" && sts.Status.CurrentRevision != sts.Status.UpdateRevision {
+			state.staleReplicas = sts.Status.Replicas - sts.Status.UpdatedReplicas
+		}
+		state.image = mainContainerImage(sts.Spec.Template)
+	case v1alpha1.WorkloadTypeDaemonSet:
+		if r.daemonSet == nil {
+			return state
+		}
+
+		ds := r.daemonSet
+		state.exists = true
+		state.upToDate = ds.Status.ObservedGeneration >= ds.Generation
+		state.desiredReplicas = ds.Status.DesiredNumberScheduled
+		state.readyReplicas = ds.Status.NumberReady
+		state.updatedReplicas = ds.Status.UpdatedNumberScheduled
+		state.staleReplicas = ds.Status.CurrentNumberScheduled - ds.Status.UpdatedNumberScheduled
+		state.image = mainContainerImage(ds.Spec.Template)
+	case v1alpha1.WorkloadTypeCronjob:
+		if r.cronJob == nil {
+			return state
+		}
+
+		// a cronjob has no long running replicas, it's ready as soon as it's scheduled
+		state.exists = true
+		state.upToDate = true
+		state.image = mainContainerImage(r.cronJob.Spec.JobTemplate.Spec.Template)
+	}
+
+	if state.staleReplicas < 0 {
+		state.staleReplicas = 0
+	}
+
+	return state
+}
+
+// findPodFailureReason returns the first reason why a pod of this component can't become ready.
+func (r *ComponentReconcilerTask) findPodFailureReason() (string, error) {
+	var podList corev1.PodList
+
+	if err := r.List(
+		r.ctx,
+		&podList,
+		client.InNamespace(r.component.Namespace),
+		client.MatchingLabels{v1alpha1.KalmLabelComponentKey: r.component.Name},
+	); err != nil {
+		return "", err
+	}
+
+	for _, pod := range podList.Items {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		statuses := make([]corev1.ContainerStatus, 0, len(pod.Status.InitContainerStatuses)+len(pod.Status.ContainerStatuses))
+		statuses = append(statuses, pod.Status.InitContainerStatuses...)
+		statuses = append(statuses, pod.Status.ContainerStatuses...)
+
+		for _, status := range statuses {
+			if status.State.Waiting != nil && componentFailingWaitingReasons[status.State.Waiting.Reason] {
+				return fmt.Sprintf("%s: container %s of pod %s, %s", status.State.Waiting.Reason, status.Name, pod.Name, status.State.Waiting.Message), nil
+			}
+
+			if status.State.Running != nil && !status.Ready && r.isReadinessProbeOverdue(status.State.Running.StartedAt.Time) {
+				return fmt.Sprintf("%s: container %s of pod %s", ComponentReasonReadinessProbeFails, status.Name, pod.Name), nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// A container is considered failing its readiness probe only if it hasn't become ready
+// after the initial delay plus enough periods to reach the failure threshold.
+func (r *ComponentReconcilerTask) isReadinessProbeOverdue(startedAt time.Time) bool {
+	probe := r.component.Spec.ReadinessProbe
+
+	if probe == nil {
+		return false
+	}
+
+	periodSeconds := probe.PeriodSeconds
+	if periodSeconds == 0 {
+		periodSeconds = 10
+	}
+
+	failureThreshold := probe.FailureThreshold
+	if failureThreshold == 0 {
+		failureThreshold = 3
+	}
+
+	grace := time.Duration(probe.InitialDelaySeconds+periodSeconds*failureThreshold) * time.Second
+
+	return time.Since(startedAt) > grace
+}
+
+func setComponentCondition(status *v1alpha1.ComponentStatus, condType v1alpha1.ComponentConditionType, condStatus corev1.ConditionStatus, reason, message string) {
+	cond := v1alpha1.ComponentCondition{
+		Type:               condType,
+		Status:             condStatus,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metaV1.Now(),
+	}
+
+	for i := range status.Conditions {
+		if status.Conditions[i].Type != condType {
+			continue
+		}
+
+		if status.Conditions[i].Status == condStatus {
+			cond.LastTransitionTime = status.Conditions[i].LastTransitionTime
+		}
+
+		status.Conditions[i] = cond
+		return
+	}
+
+	status.Conditions = append(status.Conditions, cond)
+}
+
+func boolToConditionStatus(b bool) corev1.ConditionStatus {
+	if b {
+		return corev1.ConditionTrue
+	}
+
+	return corev1.ConditionFalse
+}
+
+// ReconcileStatus writes the rollout state of the component's workload into the status subresource.
+// reconcileErr is the error returned by the previous reconcile steps, if any.
+func (r *ComponentReconcilerTask) ReconcileStatus(reconcileErr error) error {
+	status := r.component.Status.DeepCopy()
+	status.ObservedGeneration = r.component.Generation
+
+	if !IsNamespaceKalmEnabled(r.namespace) {
+		status.DesiredReplicas = 0
+		status.ReadyReplicas = 0
+		status.UpdatedReplicas = 0
+
+		msg := fmt.Sprintf("namespace %s is not kalm-enabled", r.namespace.Name)
+		setComponentCondition(status, v1alpha1.ComponentConditionReady, corev1.ConditionFalse, ComponentReasonNotKalmEnabled, msg)
+		setComponentCondition(status, v1alpha1.ComponentConditionProgressing, corev1.ConditionFalse, ComponentReasonNotKalmEnabled, msg)
+		setComponentCondition(status, v1alpha1.ComponentConditionDegraded, corev1.ConditionFalse, ComponentReasonNotKalmEnabled, msg)
+		status.Rollout = r.rolloutStatus
+		status.Autoscaling = nil
+		status.WaitingForComponents = nil
+
+		return r.patchStatus(status)
+	}
+
+	state := r.getWorkloadState()
+
+	status.DesiredReplicas = state.desiredReplicas
+	status.ReadyReplicas = state.readyReplicas
+	status.UpdatedReplicas = state.updatedReplicas
+
+	if reconcileErr == nil && state.image != "" {
+		status.LastAppliedImage = state.image
+	}
+
+	failureReason := ""
+	degradedReason := ComponentReasonNoFailure
+
+	if reconcileErr != nil {
+		failureReason = reconcileErr.Error()
+		degradedReason = ComponentReasonReconcileFailed
+	} else if r.hasMissingDependencies() {
+		failureReason = "components to start after don't exist: " + strings.Join(r.missingComponents, ", ")
+		degradedReason = ComponentReasonDependencyNotFound
+	} else if !state.isRolledOut() {
+		reason, err := r.findPodFailureReason()
+		if err != nil {
+			return err
+		}
+
+		if reason != "" {
+			failureReason = reason
+			degradedReason = ComponentReasonReplicasNotReady
+		}
+	}
+
+	isReady := reconcileErr == nil && state.isRolledOut() && !r.isWaitingForDependencies()
+	replicasMsg := fmt.Sprintf("%d/%d replicas ready, %d updated", state.readyReplicas, state.desiredReplicas, state.updatedReplicas)
+
+	if isReady {
+		setComponentCondition(status, v1alpha1.ComponentConditionReady, corev1.ConditionTrue, ComponentReasonReplicasReady, replicasMsg)
+		setComponentCondition(status, v1alpha1.ComponentConditionProgressing, corev1.ConditionFalse, ComponentReasonRolloutComplete, replicasMsg)
+	} else {
+		readyReason := ComponentReasonReplicasNotReady
+		if reconcileErr != nil {
+			readyReason = ComponentReasonReconcileFailed
+		}
+
+		setComponentCondition(status, v1alpha1.ComponentConditionReady, corev1.ConditionFalse, readyReason, replicasMsg)
+		setComponentCondition(status, v1alpha1.ComponentConditionProgressing, boolToConditionStatus(reconcileErr == nil && state.exists), ComponentReasonRollingOut, replicasMsg)
+	}
+
+	if reconcileErr == nil && r.hasMissingDependencies() {
+		setComponentCondition(status, v1alpha1.ComponentConditionReady, corev1.ConditionFalse, ComponentReasonDependencyNotFound, failureReason)
+		setComponentCondition(status, v1alpha1.ComponentConditionProgressing, corev1.ConditionFalse, ComponentReasonDependencyNotFound, failureReason)
+	} else if reconcileErr == nil && r.isWaitingForDependencies() {
+		msg := "waiting for components to be ready: " + strings.Join(r.waitingForComponents, ", ")
+		setComponentCondition(status, v1alpha1.ComponentConditionReady, corev1.ConditionFalse, ComponentReasonWaitingForDependencies, msg)
+		setComponentCondition(status, v1alpha1.ComponentConditionProgressing, corev1.ConditionFalse, ComponentReasonWaitingForDependencies, msg)
+	}
+
+	status.WaitingForComponents = r.waitingForComponents
+
+	if r.currentRevision > 0 {
+		status.CurrentRevision = r.currentRevision
+	}
+
+	if r.rolloutStatus.IsInProgress() {
+		setComponentCondition(status, v1alpha1.ComponentConditionProgressing, corev1.ConditionTrue, ComponentReasonRollingOut, r.rolloutStatus.Message)
+	}
+
+	status.Rollout = r.rolloutStatus
+	status.Autoscaling = r.getAutoscalingStatus()
+
+	setComponentCondition(status, v1alpha1.ComponentConditionDegraded, boolToConditionStatus(failureReason != ""), degradedReason, failureReason)
+
+	if failureReason != "" {
+		status.LastFailureReason = failureReason
+	} else if isReady {
+		status.LastFailureReason = ""
+	}
+
+	return r.patchStatus(status)
+}
+
+// status.outlierDetection is owned by the ComponentOutlierDetectionReconciler, it's kept as is in the status,
+// so it's not in the merge patch.
+func (r *ComponentReconcilerTask) patchStatus(status *v1alpha1.ComponentStatus) error {
+	if equality.Semantic.DeepEqual(r.component.Status, *status) {
+		return nil
+	}
+
+	copied := r.component.DeepCopy()
+	copied.Status = *status
+
+	if err := r.Status().Patch(r.ctx, copied, client.MergeFrom(r.component)); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	r.component = copied
+
+	return nil
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/cloudflare/cloudflare-go"
@@ -15,17 +16,236 @@ type DNSRecord struct {
 	ID      string
 	DNSType v1alpha1.DNSType
 	Name    string
+	// rdata without trailing dots, e.g. "10 mx.example.com" for MX and "10 5 5060 sip.example.com" for SRV records.
+	// TXT values are not quoted.
 	Content string
+	TTL     int
 }
 
 type DNSManager interface {
 	CreateDNSRecord(dnsType v1alpha1.DNSType, name, content string) error
 	DeleteDNSRecord(dnsType v1alpha1.DNSType, name string) error
-	UpsertDNSRecord(dnsType v1alpha1.DNSType, name, content string) error
+	// replaces all records of the type and name with the contents, ttl 0 means the default of the provider
+	UpsertDNSRecord(dnsType v1alpha1.DNSType, name string, contents []string, ttl int) error
 	Exist(dnsType v1alpha1.DNSType, name, content string) (bool, error)
+	// returns the records in the zone of the domain, some providers only return the records of the domain itself
 	GetDNSRecords(domain string) ([]DNSRecord, error)
 }
 
+// record types which can be managed by a DNSManager
+var supportedDNSTypes = []v1alpha1.DNSType{
+	v1alpha1.DNSTypeA,
+	v1alpha1.DNSTypeAAAA,
+	v1alpha1.DNSTypeCNAME,
+	v1alpha1.DNSTypeNS,
+	v1alpha1.DNSTypeTXT,
+	v1alpha1.DNSTypeMX,
+	v1alpha1.DNSTypeSRV,
+}
+
+const defaultDNSRecordTTL = 300
+
+// a character-string in a TXT record is at most 255 bytes
+const maxTXTStringLength = 255
+
+var NoDNSZoneForDomainError = fmt.Errorf("no dns zone for domain error")
+
+// getZoneOfDomain returns the longest zone containing the domain, empty if there is none.
+func getZoneOfDomain(zones []string, domain string) string {
+	domain = normalizeDNSName(domain)
+	var rst string
+
+	for _, zone := range zones {
+		zone = normalizeDNSName(zone)
+
+		if (domain == zone || strings.HasSuffix(domain, "."+zone)) && len(zone) > len(rst) {
+			rst = zone
+		}
+	}
+
+	return rst
+}
+
+// names are compared case insensitively and without the trailing dot
+func normalizeDNSName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+// the last field in the content of these types is an absolute domain name
+func isDomainNameContent(dnsType v1alpha1.DNSType) bool {
+	switch dnsType {
+	case v1alpha1.DNSTypeCNAME, v1alpha1.DNSTypeNS, v1alpha1.DNSTypeMX, v1alpha1.DNSTypeSRV:
+		return true
+	}
+
+	return false
+}
+
+// toRecordContent returns the content in the format of zone files, e.g. with trailing dots of absolute names.
+func toRecordContent(dnsType v1alpha1.DNSType, content string) string {
+	if dnsType == v1alpha1.DNSTypeTXT {
+		return quoteTXTContent(content)
+	}
+
+	if isDomainNameContent(dnsType) && !strings.HasSuffix(content, ".") {
+		return content + "."
+	}
+
+	return content
+}
+
+func fromRecordContent(dnsType v1alpha1.DNSType, content string) string {
+	if dnsType == v1alpha1.DNSTypeTXT {
+		return unquoteTXTContent(content)
+	}
+
+	if !isDomainNameContent(dnsType) {
+		return content
+	}
+
+	fields := strings.Fields(content)
+	if len(fields) == 0 {
+		return content
+	}
+
+	fields[len(fields)-1] = normalizeDNSName(fields[len(fields)-1])
+
+	return strings.Join(fields, " ")
+}
+
+// "a long value" -> "a long" " value", split into strings of 255 characters
+func quoteTXTContent(content string) string {
+	var parts []string
+
+	for {
+		part := content
+		if len(part) > maxTXTStringLength {
+			part = part[:maxTXTStringLength]
+		}
+
+		part = strings.ReplaceAll(part, `\`, `\\`)
+		part = strings.ReplaceAll(part, `"`, `\"`)
+		parts = append(parts, `"`+part+`"`)
+
+		if len(content) <= maxTXTStringLength {
+			return strings.Join(parts, " ")
+		}
+
+		content = content[maxTXTStringLength:]
+	}
+}
+
+// unquoteTXTContent joins the quoted strings of a TXT record, content not starting with a quote is returned as it is.
+func unquoteTXTContent(content string) string {
+	if !strings.HasPrefix(content, `"`) {
+		return content
+	}
+
+	var sb strings.Builder
+	inQuote := false
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+
+		switch {
+		case c == '"':
+			inQuote = !inQuote
+		case !inQuote:
+			// spaces between strings
+		case c == '\\' && i+3 < len(content) && isDigits(content[i+1:i+4]):
+			// \DDD
+			n, _ := strconv.Atoi(content[i+1 : i+4])
+			sb.WriteByte(byte(n))
+			i += 3
+		case c == '\\' && i+1 < len(content):
+			sb.WriteByte(content[i+1])
+			i++
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String()
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// "10 mx.example.com" -> 10, "mx.example.com"
+func splitMXContent(content string) (priority int, target string, err error) {
+	fields := strings.Fields(content)
+	if len(fields) != 2 {
+		return 0, "", fmt.Errorf("invalid MX content: %s", content)
+	}
+
+	if priority, err = strconv.Atoi(fields[0]); err != nil {
+		return 0, "", fmt.Errorf("invalid MX content: %s", content)
+	}
+
+	return priority, fields[1], nil
+}
+
+// "10 5 5060 sip.example.com" -> 10, 5, 5060, "sip.example.com"
+func splitSRVContent(content string) (priority, weight, port int, target string, err error) {
+	fields := strings.Fields(content)
+	if len(fields) != 4 {
+		return 0, 0, 0, "", fmt.Errorf("invalid SRV content: %s", content)
+	}
+
+	var numbers [3]int
+	for i := range numbers {
+		if numbers[i], err = strconv.Atoi(fields[i]); err != nil {
+			return 0, 0, 0, "", fmt.Errorf("invalid SRV content: %s", content)
+		}
+	}
+
+	return numbers[0], numbers[1], numbers[2], fields[3], nil
+}
+
+func isSameDNSRecord(record DNSRecord, dnsType v1alpha1.DNSType, name string) bool {
+	return record.DNSType == dnsType && normalizeDNSName(record.Name) == normalizeDNSName(name)
+}
+
+// dnsRecordExist is Exist() for managers which implement GetDNSRecords()
+func dnsRecordExist(m DNSManager, dnsType v1alpha1.DNSType, name, content string) (bool, error) {
+	records, err := getDNSRecordSet(m, dnsType, name)
+	if err != nil {
+		return false, err
+	}
+
+	for _, r := range records {
+		if r.Content == fromRecordContent(dnsType, content) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// getDNSRecordSet returns the records of the type and name
+func getDNSRecordSet(m DNSManager, dnsType v1alpha1.DNSType, name string) ([]DNSRecord, error) {
+	records, err := m.GetDNSRecords(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var rst []DNSRecord
+	for _, r := range records {
+		if isSameDNSRecord(r, dnsType, name) {
+			rst = append(rst, r)
+		}
+	}
+
+	return rst, nil
+}
+
 var _ DNSManager = CloudflareDNSManager{}
 
 type CloudflareDNSManager struct {
@@ -47,28 +267,71 @@ func NewCloudflareDNSManager(token string, domain2ZoneIDMap map[string]string) (
 	}, nil
 }
 
-var NoCloudflareZoneIDForDomainError = fmt.Errorf("no cloudflare zone id for domain error")
+// NewCloudflareDNSManagerForZones looks up the zone ids of the zones by their names.
+func NewCloudflareDNSManagerForZones(token string, zones []string) (*CloudflareDNSManager, error) {
+	mgr, err := NewCloudflareDNSManager(token, make(map[string]string))
+	if err != nil {
+		return nil, err
+	}
 
-func (m CloudflareDNSManager) CreateDNSRecord(dnsType v1alpha1.DNSType, name, content string) error {
-	rootDomain := getRootDomain(name)
+	if err := mgr.lookupZoneIDs(zones); err != nil {
+		return nil, err
+	}
+
+	return mgr, nil
+}
+
+func (m CloudflareDNSManager) lookupZoneIDs(zones []string) error {
+	for _, zone := range zones {
+		zone = normalizeDNSName(zone)
+
+		zoneID, err := m.API.ZoneIDByName(zone)
+		if err != nil {
+			return err
+		}
+
+		m.Domain2ZoneIDMap[zone] = zoneID
+	}
+
+	return nil
+}
+
+func (m CloudflareDNSManager) getZoneID(domain string) (string, error) {
+	zones := make([]string, 0, len(m.Domain2ZoneIDMap))
+	for zone := range m.Domain2ZoneIDMap {
+		zones = append(zones, zone)
+	}
 
-	zoneID, exist := m.Domain2ZoneIDMap[rootDomain]
+	zoneID, exist := m.Domain2ZoneIDMap[getZoneOfDomain(zones, domain)]
 	if !exist {
+		return "", NoDNSZoneForDomainError
+	}
+
+	return zoneID, nil
+}
+
+func (m CloudflareDNSManager) CreateDNSRecord(dnsType v1alpha1.DNSType, name, content string) error {
+	zoneID, err := m.getZoneID(name)
+	if err != nil {
 		mLog.Info("domain not exist in Domain2ZoneIDMap when CreateDNSRecord()",
 			"dnsType", dnsType,
 			"name", name,
 			"content", content,
-			"rootDomain", rootDomain,
 		)
 
-		return NoCloudflareZoneIDForDomainError
+		return err
 	}
 
-	resp, err := m.API.CreateDNSRecord(zoneID, cloudflare.DNSRecord{
-		Type:    string(dnsType),
-		Name:    name,
-		Content: content,
-	})
+	return m.createDNSRecord(zoneID, dnsType, name, content, 0)
+}
+
+func (m CloudflareDNSManager) createDNSRecord(zoneID string, dnsType v1alpha1.DNSType, name, content string, ttl int) error {
+	record, err := toCloudflareDNSRecord(dnsType, name, content, ttl)
+	if err != nil {
+		return err
+	}
+
+	resp, err := m.API.CreateDNSRecord(zoneID, record)
 
 	if err != nil {
 		return err
@@ -80,50 +343,77 @@ func (m CloudflareDNSManager) CreateDNSRecord(dnsType v1alpha1.DNSType, name, co
 }
 
 func (m CloudflareDNSManager) DeleteDNSRecord(dnsType v1alpha1.DNSType, domain string) error {
-	rootDomain := getRootDomain(domain)
-
-	zoneID, exist := m.Domain2ZoneIDMap[rootDomain]
-	if !exist {
-		return NoCloudflareZoneIDForDomainError
+	zoneID, err := m.getZoneID(domain)
+	if err != nil {
+		return err
 	}
 
-	records, err := m.GetDNSRecords(domain)
+	records, err := getDNSRecordSet(m, dnsType, domain)
 	if err != nil {
 		return err
 	}
 
+	// for not exist record, return without error
 	for _, r := range records {
-		if r.DNSType != dnsType || r.Name != domain {
-			continue
+		if err := m.API.DeleteDNSRecord(zoneID, r.ID); err != nil {
+			return err
 		}
-
-		return m.API.DeleteDNSRecord(zoneID, r.ID)
 	}
 
-	// for not exist record, return without error
 	return nil
 }
 
-func (m CloudflareDNSManager) UpsertDNSRecord(dnsType v1alpha1.DNSType, name, content string) error {
-	// skip if DNSRecord already exist
-	if exist, _ := m.Exist(dnsType, name, content); exist {
-		return nil
+func (m CloudflareDNSManager) UpsertDNSRecord(dnsType v1alpha1.DNSType, name string, contents []string, ttl int) error {
+	zoneID, err := m.getZoneID(name)
+	if err != nil {
+		return err
+	}
+
+	records, err := getDNSRecordSet(m, dnsType, name)
+	if err != nil {
+		return err
+	}
+
+	desired := make(map[string]bool)
+	for _, content := range contents {
+		desired[fromRecordContent(dnsType, content)] = true
+	}
+
+	// skip records which already exist, delete the others
+	existing := make(map[string]bool)
+	for _, r := range records {
+		if desired[r.Content] && !existing[r.Content] && (ttl == 0 || r.TTL == ttl) {
+			existing[r.Content] = true
+			continue
+		}
+
+		if err := m.API.DeleteDNSRecord(zoneID, r.ID); err != nil {
+			return err
+		}
 	}
 
-	_ = m.DeleteDNSRecord(dnsType, name)
+	for _, content := range contents {
+		if existing[fromRecordContent(dnsType, content)] {
+			continue
+		}
+
+		if err := m.createDNSRecord(zoneID, dnsType, name, content, ttl); err != nil {
+			return err
+		}
+	}
 
-	return m.CreateDNSRecord(dnsType, name, content)
+	return nil
 }
 
 func (m CloudflareDNSManager) Exist(dnsType v1alpha1.DNSType, name, content string) (bool, error) {
 
-	records, err := m.GetDNSRecords(name)
+	records, err := getDNSRecordSet(m, dnsType, name)
 	if err != nil {
 		return false, err
 	}
 
 	for _, r := range records {
-		if r.DNSType != dnsType || r.Name != name || r.Content != content {
+		if r.Content != fromRecordContent(dnsType, content) {
 			continue
 		}
 
@@ -137,11 +427,9 @@ func (m CloudflareDNSManager) Exist(dnsType v1alpha1.DNSType, name, content stri
 }
 
 func (m CloudflareDNSManager) GetDNSRecords(domain string) ([]DNSRecord, error) {
-	rootDomain := getRootDomain(domain)
-
-	zoneID, exist := m.Domain2ZoneIDMap[rootDomain]
-	if !exist {
-		return nil, NoCloudflareZoneIDForDomainError
+	zoneID, err := m.getZoneID(domain)
+	if err != nil {
+		return nil, err
 	}
 
 	resp, err := m.API.DNSRecords(zoneID, cloudflare.DNSRecord{})
@@ -151,27 +439,73 @@ func (m CloudflareDNSManager) GetDNSRecords(domain string) ([]DNSRecord, error)
 
 	var rst []DNSRecord
 	for _, record := range resp {
+		dnsType := v1alpha1.DNSType(record.Type)
+		content := record.Content
+
+		// the priority is not in the content, the content of SRV records is "weight port target"
+		if dnsType == v1alpha1.DNSTypeMX || dnsType == v1alpha1.DNSTypeSRV {
+			content = fmt.Sprintf("%d %s", record.Priority, content)
+		}
+
 		rst = append(rst, DNSRecord{
 			ID:      record.ID,
-			DNSType: v1alpha1.DNSType(record.Type),
+			DNSType: dnsType,
 			Name:    record.Name,
-			Content: record.Content,
+			Content: fromRecordContent(dnsType, content),
+			TTL:     record.TTL,
 		})
 	}
 
 	return rst, nil
 }
 
-// a.b.com -> b.com
-func getRootDomain(domain string) string {
-	parts := strings.Split(domain, ".")
+func toCloudflareDNSRecord(dnsType v1alpha1.DNSType, name, content string, ttl int) (cloudflare.DNSRecord, error) {
+	record := cloudflare.DNSRecord{
+		Type:    string(dnsType),
+		Name:    name,
+		Content: content,
+		TTL:     ttl,
+	}
+
+	// 1 is automatic
+	if ttl == 0 {
+		record.TTL = 1
+	}
+
+	switch dnsType {
+	case v1alpha1.DNSTypeMX:
+		priority, target, err := splitMXContent(content)
+		if err != nil {
+			return record, err
+		}
 
-	size := len(parts)
-	if size <= 1 {
-		return ""
+		record.Priority = priority
+		record.Content = target
+	case v1alpha1.DNSTypeSRV:
+		priority, weight, port, target, err := splitSRVContent(content)
+		if err != nil {
+			return record, err
+		}
+
+		// _service._proto.name
+		parts := strings.SplitN(name, ".", 3)
+		if len(parts) != 3 {
+			return record, fmt.Errorf("invalid SRV record name: %s", name)
+		}
+
+		record.Content = ""
+		record.Data = map[string]interface{}{
+			"service":  parts[0],
+			"proto":    parts[1],
+			"name":     parts[2],
+			"priority": priority,
+			"weight":   weight,
+			"port":     port,
+			"target":   target,
+		}
 	}
 
-	return parts[size-2] + "." + parts[size-1]
+	return record, nil
 }
 
 func initCloudflareDNSManagerFromEnv() (*CloudflareDNSManager, error) {
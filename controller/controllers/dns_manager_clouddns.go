@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	clouddns "google.golang.org/api/dns/v1"
+	"google.golang.org/api/option"
+)
+
+var _ DNSManager = &GoogleCloudDNSManager{}
+
+// GoogleCloudDNSManager manages the record sets of Cloud DNS with the credentials of a service account.
+type GoogleCloudDNSManager struct {
+	Project string
+	Zones   []string
+	*clouddns.Service
+
+	// zone name -> managed zone name
+	managedZones *dnsZoneIDCache
+}
+
+func NewGoogleCloudDNSManager(project string, credentialsJSON []byte, zones []string) (*GoogleCloudDNSManager, error) {
+	service, err := clouddns.NewService(context.Background(), option.WithCredentialsJSON(credentialsJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	return &GoogleCloudDNSManager{
+		Project:      project,
+		Zones:        zones,
+		Service:      service,
+		managedZones: newDNSZoneIDCache(),
+	}, nil
+}
+
+func (m *GoogleCloudDNSManager) CreateDNSRecord(dnsType v1alpha1.DNSType, name, content string) error {
+	managedZone, err := m.getManagedZone(name)
+	if err != nil {
+		return err
+	}
+
+	return m.createChange(managedZone, &clouddns.Change{
+		Additions: []*clouddns.ResourceRecordSet{newGoogleCloudDNSRecordSet(dnsType, name, []string{content}, 0)},
+	})
+}
+
+func (m *GoogleCloudDNSManager) UpsertDNSRecord(dnsType v1alpha1.DNSType, name string, contents []string, ttl int) error {
+	managedZone, err := m.getManagedZone(name)
+	if err != nil {
+		return err
+	}
+
+	current, err := m.getRecordSet(managedZone, dnsType, name)
+	if err != nil {
+		return err
+	}
+
+	// deletions and additions of a change are applied atomically
+	change := &clouddns.Change{
+		Additions: []*clouddns.ResourceRecordSet{newGoogleCloudDNSRecordSet(dnsType, name, contents, ttl)},
+	}
+
+	if current != nil {
+		change.Deletions = []*clouddns.ResourceRecordSet{current}
+	}
+
+	return m.createChange(managedZone, change)
+}
+
+func (m *GoogleCloudDNSManager) DeleteDNSRecord(dnsType v1alpha1.DNSType, name string) error {
+	managedZone, err := m.getManagedZone(name)
+	if err != nil {
+		return err
+	}
+
+	current, err := m.getRecordSet(managedZone, dnsType, name)
+	if err != nil {
+		return err
+	}
+
+	// for not exist record, return without error
+	if current == nil {
+		return nil
+	}
+
+	return m.createChange(managedZone, &clouddns.Change{
+		Deletions: []*clouddns.ResourceRecordSet{current},
+	})
+}
+
+func (m *GoogleCloudDNSManager) Exist(dnsType v1alpha1.DNSType, name, content string) (bool, error) {
+	return dnsRecordExist(m, dnsType, name, content)
+}
+
+func (m *GoogleCloudDNSManager) GetDNSRecords(domain string) ([]DNSRecord, error) {
+	managedZone, err := m.getManagedZone(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	var rst []DNSRecord
+
+	err = m.ResourceRecordSets.List(m.Project, managedZone).Pages(context.Background(), func(resp *clouddns.ResourceRecordSetsListResponse) error {
+		for _, recordSet := range resp.Rrsets {
+			dnsType := v1alpha1.DNSType(recordSet.Type)
+
+			for _, rrdata := range recordSet.Rrdatas {
+				rst = append(rst, DNSRecord{
+					DNSType: dnsType,
+					Name:    normalizeDNSName(recordSet.Name),
+					Content: fromRecordContent(dnsType, rrdata),
+					TTL:     int(recordSet.Ttl),
+				})
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return rst, nil
+}
+
+func newGoogleCloudDNSRecordSet(dnsType v1alpha1.DNSType, name string, contents []string, ttl int) *clouddns.ResourceRecordSet {
+	if ttl == 0 {
+		ttl = defaultDNSRecordTTL
+	}
+
+	rrdatas := make([]string, 0, len(contents))
+	for _, content := range contents {
+		rrdatas = append(rrdatas, toRecordContent(dnsType, content))
+	}
+
+	return &clouddns.ResourceRecordSet{
+		Name:    normalizeDNSName(name) + ".",
+		Type:    string(dnsType),
+		Ttl:     int64(ttl),
+		Rrdatas: rrdatas,
+	}
+}
+
+// getRecordSet returns nil if the record set doesn't exist
+func (m *GoogleCloudDNSManager) getRecordSet(managedZone string, dnsType v1alpha1.DNSType, name string) (*clouddns.ResourceRecordSet, error) {
+	resp, err := m.ResourceRecordSets.List(m.Project, managedZone).
+		Name(normalizeDNSName(name) + ".").
+		Type(string(dnsType)).
+		Do()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Rrsets) == 0 {
+		return nil, nil
+	}
+
+	return resp.Rrsets[0], nil
+}
+
+func (m *GoogleCloudDNSManager) createChange(managedZone string, change *clouddns.Change) error {
+	_, err := m.Changes.Create(m.Project, managedZone, change).Do()
+	return err
+}
+
+func (m *GoogleCloudDNSManager) getManagedZone(domain string) (string, error) {
+	zone := getZoneOfDomain(m.Zones, domain)
+	if zone == "" {
+		return "", NoDNSZoneForDomainError
+	}
+
+	if managedZone, exist := m.managedZones.get(zone); exist {
+		return managedZone, nil
+	}
+
+	resp, err := m.ManagedZones.List(m.Project).DnsName(zone + ".").Do()
+	if err != nil {
+		return "", err
+	}
+
+	// private zones may have the same dns name, the first one wins
+	if len(resp.ManagedZones) == 0 {
+		return "", fmt.Errorf("google cloud dns managed zone %s not found in project %s", zone, m.Project)
+	}
+
+	managedZone := resp.ManagedZones[0].Name
+	m.managedZones.set(zone, managedZone)
+
+	return managedZone, nil
+}
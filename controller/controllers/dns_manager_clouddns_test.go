@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	clouddns "google.golang.org/api/dns/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+// an in-memory cloud dns api, record sets are listed two per page
+type testGoogleCloudDNSServer struct {
+	sync.Mutex
+	recordSets []clouddns.ResourceRecordSet
+
+	zoneLookups int
+	changes     []clouddns.Change
+}
+
+func (s *testGoogleCloudDNSServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	query := r.URL.Query()
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/projects/p1/managedZones":
+		s.zoneLookups++
+
+		var resp clouddns.ManagedZonesListResponse
+		if query.Get("dnsName") == "example.com." {
+			resp.ManagedZones = append(resp.ManagedZones, &clouddns.ManagedZone{Name: "example-zone", DnsName: "example.com."})
+		}
+
+		s.writeJSON(w, http.StatusOK, resp)
+	case r.Method == http.MethodGet && r.URL.Path == "/projects/p1/managedZones/example-zone/rrsets":
+		var matched []*clouddns.ResourceRecordSet
+		for i, recordSet := range s.recordSets {
+			if (query.Get("name") == "" || recordSet.Name == query.Get("name")) && (query.Get("type") == "" || recordSet.Type == query.Get("type")) {
+				matched = append(matched, &s.recordSets[i])
+			}
+		}
+
+		start, _ := strconv.Atoi(query.Get("pageToken"))
+		end := start + 2
+
+		var resp clouddns.ResourceRecordSetsListResponse
+		if end < len(matched) {
+			resp.NextPageToken = strconv.Itoa(end)
+		} else {
+			end = len(matched)
+		}
+
+		resp.Rrsets = matched[start:end]
+		s.writeJSON(w, http.StatusOK, resp)
+	case r.Method == http.MethodPost && r.URL.Path == "/projects/p1/managedZones/example-zone/changes":
+		var change clouddns.Change
+		if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		s.changes = append(s.changes, change)
+
+		// deletions must match the existing record sets exactly
+		for _, deletion := range change.Deletions {
+			index := s.indexOf(deletion.Name, deletion.Type)
+
+			if index < 0 || !reflect.DeepEqual(s.recordSets[index], *deletion) {
+				s.writeJSON(w, http.StatusPreconditionFailed, map[string]interface{}{"error": map[string]interface{}{"code": 412, "message": "conditionNotMet"}})
+				return
+			}
+
+			s.recordSets = append(s.recordSets[:index], s.recordSets[index+1:]...)
+		}
+
+		for _, addition := range change.Additions {
+			if s.indexOf(addition.Name, addition.Type) >= 0 {
+				s.writeJSON(w, http.StatusConflict, map[string]interface{}{"error": map[string]interface{}{"code": 409, "message": "alreadyExists"}})
+				return
+			}
+
+			s.recordSets = append(s.recordSets, *addition)
+		}
+
+		s.writeJSON(w, http.StatusOK, change)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *testGoogleCloudDNSServer) indexOf(name, dnsType string) int {
+	for i, recordSet := range s.recordSets {
+		if recordSet.Name == name && recordSet.Type == dnsType {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (s *testGoogleCloudDNSServer) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func newTestGoogleCloudDNSManager(t *testing.T, server *httptest.Server) *GoogleCloudDNSManager {
+	service, err := clouddns.NewService(context.Background(), option.WithEndpoint(server.URL+"/projects/"), option.WithHTTPClient(server.Client()))
+	assert.Nil(t, err)
+
+	return &GoogleCloudDNSManager{
+		Project:      "p1",
+		Zones:        []string{"example.com"},
+		Service:      service,
+		managedZones: newDNSZoneIDCache(),
+	}
+}
+
+func TestGoogleCloudDNSManager(t *testing.T) {
+	fake := &testGoogleCloudDNSServer{
+		recordSets: []clouddns.ResourceRecordSet{
+			{Name: "example.com.", Type: "NS", Ttl: 21600, Rrdatas: []string{"ns-cloud-a1.googledomains.com."}},
+			{Name: "example.com.", Type: "MX", Ttl: 300, Rrdatas: []string{"10 mx1.example.com.", "20 mx2.example.com."}},
+			{Name: "txt.example.com.", Type: "TXT", Ttl: 300, Rrdatas: []string{`"hello"`}},
+			{Name: "www.example.com.", Type: "A", Ttl: 300, Rrdatas: []string{"1.1.1.1"}},
+		},
+	}
+
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	mgr := newTestGoogleCloudDNSManager(t, server)
+
+	// records of all pages are listed
+	records, err := mgr.GetDNSRecords("example.com")
+	assert.Nil(t, err)
+	assert.Len(t, records, 5)
+	assert.Contains(t, records, DNSRecord{DNSType: v1alpha1.DNSTypeMX, Name: "example.com", Content: "20 mx2.example.com", TTL: 300})
+	assert.Contains(t, records, DNSRecord{DNSType: v1alpha1.DNSTypeTXT, Name: "txt.example.com", Content: "hello", TTL: 300})
+
+	assert.Nil(t, mgr.CreateDNSRecord(v1alpha1.DNSTypeCNAME, "blog.example.com", "example.com"))
+	assert.Equal(t, []*clouddns.ResourceRecordSet{
+		{Name: "blog.example.com.", Type: "CNAME", Ttl: defaultDNSRecordTTL, Rrdatas: []string{"example.com."}},
+	}, fake.changes[0].Additions)
+
+	// an upsert replaces the current record set atomically
+	assert.Nil(t, mgr.UpsertDNSRecord(v1alpha1.DNSTypeA, "www.example.com", []string{"2.2.2.2", "3.3.3.3"}, 60))
+	assert.Equal(t, []*clouddns.ResourceRecordSet{
+		{Name: "www.example.com.", Type: "A", Ttl: 300, Rrdatas: []string{"1.1.1.1"}},
+	}, fake.changes[1].Deletions)
+	assert.Equal(t, []*clouddns.ResourceRecordSet{
+		{Name: "www.example.com.", Type: "A", Ttl: 60, Rrdatas: []string{"2.2.2.2", "3.3.3.3"}},
+	}, fake.changes[1].Additions)
+
+	// an upsert of a not exist record set only adds it
+	assert.Nil(t, mgr.UpsertDNSRecord(v1alpha1.DNSTypeAAAA, "www.example.com", []string{"::1"}, 0))
+	assert.Empty(t, fake.changes[2].Deletions)
+
+	assert.Nil(t, mgr.DeleteDNSRecord(v1alpha1.DNSTypeTXT, "txt.example.com"))
+	assert.Equal(t, "txt.example.com.", fake.changes[3].Deletions[0].Name)
+
+	exist, err := mgr.Exist(v1alpha1.DNSTypeTXT, "txt.example.com", "hello")
+	assert.Nil(t, err)
+	assert.False(t, exist)
+
+	// deleting a not exist record is not an error
+	assert.Nil(t, mgr.DeleteDNSRecord(v1alpha1.DNSTypeTXT, "txt.example.com"))
+	assert.Len(t, fake.changes, 4)
+
+	assert.Equal(t, 1, fake.zoneLookups)
+
+	// errors of the api are returned
+	err = mgr.CreateDNSRecord(v1alpha1.DNSTypeCNAME, "blog.example.com", "example.com")
+	if assert.IsType(t, &googleapi.Error{}, err) {
+		assert.Equal(t, http.StatusConflict, err.(*googleapi.Error).Code)
+		assert.Equal(t, "alreadyExists", err.(*googleapi.Error).Message)
+	}
+
+	assert.Equal(t, NoDNSZoneForDomainError, mgr.DeleteDNSRecord(v1alpha1.DNSTypeA, "example.org"))
+}
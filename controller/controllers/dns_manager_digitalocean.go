@@ -0,0 +1,231 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/digitalocean/godo"
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"golang.org/x/oauth2"
+)
+
+var _ DNSManager = &DigitalOceanDNSManager{}
+
+// DigitalOceanDNSManager manages the domain records of DigitalOcean, the zones are the domains of the account.
+type DigitalOceanDNSManager struct {
+	Zones []string
+	*godo.Client
+}
+
+func NewDigitalOceanDNSManager(token string, zones []string) *DigitalOceanDNSManager {
+	httpClient := oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))
+
+	return &DigitalOceanDNSManager{
+		Zones:  zones,
+		Client: godo.NewClient(httpClient),
+	}
+}
+
+func (m *DigitalOceanDNSManager) CreateDNSRecord(dnsType v1alpha1.DNSType, name, content string) error {
+	zone := getZoneOfDomain(m.Zones, name)
+	if zone == "" {
+		return NoDNSZoneForDomainError
+	}
+
+	return m.createDNSRecord(zone, dnsType, name, content, 0)
+}
+
+func (m *DigitalOceanDNSManager) createDNSRecord(zone string, dnsType v1alpha1.DNSType, name, content string, ttl int) error {
+	record, err := toDigitalOceanDNSRecord(zone, dnsType, name, content, ttl)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = m.Domains.CreateRecord(context.Background(), zone, record)
+
+	return err
+}
+
+func (m *DigitalOceanDNSManager) UpsertDNSRecord(dnsType v1alpha1.DNSType, name string, contents []string, ttl int) error {
+	zone := getZoneOfDomain(m.Zones, name)
+	if zone == "" {
+		return NoDNSZoneForDomainError
+	}
+
+	records, err := getDNSRecordSet(m, dnsType, name)
+	if err != nil {
+		return err
+	}
+
+	desired := make(map[string]bool)
+	for _, content := range contents {
+		desired[fromRecordContent(dnsType, content)] = true
+	}
+
+	// keep records which already exist, delete the others
+	existing := make(map[string]bool)
+	for _, record := range records {
+		if desired[record.Content] && !existing[record.Content] && (ttl == 0 || record.TTL == ttl) {
+			existing[record.Content] = true
+			continue
+		}
+
+		if err := m.deleteDNSRecord(zone, record.ID); err != nil {
+			return err
+		}
+	}
+
+	for _, content := range contents {
+		if existing[fromRecordContent(dnsType, content)] {
+			continue
+		}
+
+		if err := m.createDNSRecord(zone, dnsType, name, content, ttl); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *DigitalOceanDNSManager) DeleteDNSRecord(dnsType v1alpha1.DNSType, name string) error {
+	zone := getZoneOfDomain(m.Zones, name)
+	if zone == "" {
+		return NoDNSZoneForDomainError
+	}
+
+	records, err := getDNSRecordSet(m, dnsType, name)
+	if err != nil {
+		return err
+	}
+
+	// for not exist record, return without error
+	for _, record := range records {
+		if err := m.deleteDNSRecord(zone, record.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *DigitalOceanDNSManager) deleteDNSRecord(zone, id string) error {
+	recordID, err := strconv.Atoi(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.Domains.DeleteRecord(context.Background(), zone, recordID)
+
+	return err
+}
+
+func (m *DigitalOceanDNSManager) Exist(dnsType v1alpha1.DNSType, name, content string) (bool, error) {
+	return dnsRecordExist(m, dnsType, name, content)
+}
+
+func (m *DigitalOceanDNSManager) GetDNSRecords(domain string) ([]DNSRecord, error) {
+	zone := getZoneOfDomain(m.Zones, domain)
+	if zone == "" {
+		return nil, NoDNSZoneForDomainError
+	}
+
+	var rst []DNSRecord
+	opt := &godo.ListOptions{Page: 1, PerPage: 200}
+
+	for {
+		records, resp, err := m.Domains.Records(context.Background(), zone, opt)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range records {
+			dnsType := v1alpha1.DNSType(record.Type)
+			content := record.Data
+
+			// the priority, weight and port are not in the data
+			switch dnsType {
+			case v1alpha1.DNSTypeMX:
+				content = fmt.Sprintf("%d %s", record.Priority, content)
+			case v1alpha1.DNSTypeSRV:
+				content = fmt.Sprintf("%d %d %d %s", record.Priority, record.Weight, record.Port, content)
+			}
+
+			rst = append(rst, DNSRecord{
+				ID:      strconv.Itoa(record.ID),
+				DNSType: dnsType,
+				Name:    fromDigitalOceanRecordName(zone, record.Name),
+				Content: fromRecordContent(dnsType, content),
+				TTL:     record.TTL,
+			})
+		}
+
+		if resp.Links == nil || resp.Links.IsLastPage() {
+			return rst, nil
+		}
+
+		opt.Page++
+	}
+}
+
+func toDigitalOceanDNSRecord(zone string, dnsType v1alpha1.DNSType, name, content string, ttl int) (*godo.DomainRecordEditRequest, error) {
+	if ttl == 0 {
+		ttl = defaultDNSRecordTTL
+	}
+
+	record := &godo.DomainRecordEditRequest{
+		Type: string(dnsType),
+		Name: toDigitalOceanRecordName(zone, name),
+		Data: content,
+		TTL:  ttl,
+	}
+
+	switch dnsType {
+	case v1alpha1.DNSTypeMX:
+		priority, target, err := splitMXContent(content)
+		if err != nil {
+			return nil, err
+		}
+
+		record.Priority = priority
+		record.Data = target
+	case v1alpha1.DNSTypeSRV:
+		priority, weight, port, target, err := splitSRVContent(content)
+		if err != nil {
+			return nil, err
+		}
+
+		record.Priority = priority
+		record.Weight = weight
+		record.Port = port
+		record.Data = target
+	}
+
+	// TXT values are not quoted
+	if dnsType != v1alpha1.DNSTypeTXT {
+		record.Data = toRecordContent(dnsType, record.Data)
+	}
+
+	return record, nil
+}
+
+// a.example.com -> a, example.com -> @
+func toDigitalOceanRecordName(zone, name string) string {
+	name = normalizeDNSName(name)
+
+	if name == zone {
+		return "@"
+	}
+
+	return strings.TrimSuffix(name, "."+zone)
+}
+
+func fromDigitalOceanRecordName(zone, name string) string {
+	if name == "@" {
+		return zone
+	}
+
+	return normalizeDNSName(name) + "." + zone
+}
@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/digitalocean/godo"
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+// an in-memory digitalocean domain records api, records are listed two per page
+type testDigitalOceanServer struct {
+	sync.Mutex
+	records []godo.DomainRecord
+	nextID  int
+
+	created []godo.DomainRecord
+	deleted []int
+}
+
+type testDigitalOceanErrorResponse struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+type testDigitalOceanDomainRecordList struct {
+	DomainRecords []godo.DomainRecord `json:"domain_records"`
+	Links         godo.Links          `json:"links"`
+}
+
+func (s *testDigitalOceanServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer token" {
+		s.writeJSON(w, http.StatusUnauthorized, testDigitalOceanErrorResponse{ID: "unauthorized", Message: "Unable to authenticate you"})
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v2/domains/example.com/records":
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		start := (page - 1) * 2
+		end := start + 2
+
+		var resp testDigitalOceanDomainRecordList
+		if end < len(s.records) {
+			resp.Links.Pages = &godo.Pages{Next: fmt.Sprintf("http://%s%s?page=%d", r.Host, r.URL.Path, page+1)}
+		} else {
+			end = len(s.records)
+		}
+
+		resp.DomainRecords = s.records[start:end]
+		s.writeJSON(w, http.StatusOK, resp)
+	case r.Method == http.MethodPost && r.URL.Path == "/v2/domains/example.com/records":
+		var record godo.DomainRecord
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		s.nextID++
+		record.ID = s.nextID
+		s.records = append(s.records, record)
+		s.created = append(s.created, record)
+
+		s.writeJSON(w, http.StatusCreated, map[string]interface{}{"domain_record": record})
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/v2/domains/example.com/records/"):
+		id, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/v2/domains/example.com/records/"))
+
+		for i, record := range s.records {
+			if record.ID == id {
+				s.records = append(s.records[:i], s.records[i+1:]...)
+				s.deleted = append(s.deleted, id)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+
+		s.writeJSON(w, http.StatusNotFound, testDigitalOceanErrorResponse{ID: "not_found", Message: "The resource you were accessing could not be found."})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *testDigitalOceanServer) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func newTestDigitalOceanDNSManager(server *httptest.Server, token string) *DigitalOceanDNSManager {
+	mgr := NewDigitalOceanDNSManager(token, []string{"example.com"})
+	mgr.BaseURL, _ = url.Parse(server.URL + "/")
+
+	return mgr
+}
+
+func TestDigitalOceanDNSManager(t *testing.T) {
+	fake := &testDigitalOceanServer{
+		records: []godo.DomainRecord{
+			{ID: 1, Type: "NS", Name: "@", Data: "ns1.digitalocean.com", TTL: 1800},
+			{ID: 2, Type: "MX", Name: "@", Data: "mx1.example.com", TTL: 300, Priority: 10},
+			{ID: 3, Type: "A", Name: "www", Data: "1.1.1.1", TTL: 300},
+			{ID: 4, Type: "A", Name: "www", Data: "2.2.2.2", TTL: 300},
+			{ID: 5, Type: "TXT", Name: "txt", Data: "hello world", TTL: 300},
+		},
+		nextID: 5,
+	}
+
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	mgr := newTestDigitalOceanDNSManager(server, "token")
+
+	// records of all pages are listed
+	records, err := mgr.GetDNSRecords("example.com")
+	assert.Nil(t, err)
+	assert.Len(t, records, 5)
+	assert.Contains(t, records, DNSRecord{ID: "2", DNSType: v1alpha1.DNSTypeMX, Name: "example.com", Content: "10 mx1.example.com", TTL: 300})
+	assert.Contains(t, records, DNSRecord{ID: "5", DNSType: v1alpha1.DNSTypeTXT, Name: "txt.example.com", Content: "hello world", TTL: 300})
+
+	assert.Nil(t, mgr.CreateDNSRecord(v1alpha1.DNSTypeSRV, "_sip._tcp.example.com", "10 5 5060 sip.example.com"))
+	srv := fake.created[0]
+	assert.Equal(t, "_sip._tcp", srv.Name)
+	assert.Equal(t, "sip.example.com.", srv.Data)
+	assert.Equal(t, 10, srv.Priority)
+	assert.Equal(t, 5, srv.Weight)
+	assert.Equal(t, 5060, srv.Port)
+
+	// existing records with the same content are kept, the others are replaced
+	assert.Nil(t, mgr.UpsertDNSRecord(v1alpha1.DNSTypeA, "www.example.com", []string{"2.2.2.2", "3.3.3.3"}, 0))
+	assert.Equal(t, []int{3}, fake.deleted)
+	assert.Equal(t, "3.3.3.3", fake.created[1].Data)
+
+	records, err = getDNSRecordSet(mgr, v1alpha1.DNSTypeA, "www.example.com")
+	assert.Nil(t, err)
+	assert.Len(t, records, 2)
+
+	// a ttl change replaces all records
+	assert.Nil(t, mgr.UpsertDNSRecord(v1alpha1.DNSTypeTXT, "txt.example.com", []string{"hello world"}, 60))
+	assert.Equal(t, []int{3, 5}, fake.deleted)
+	assert.Equal(t, "hello world", fake.created[2].Data)
+	assert.Equal(t, 60, fake.created[2].TTL)
+
+	assert.Nil(t, mgr.DeleteDNSRecord(v1alpha1.DNSTypeA, "www.example.com"))
+	assert.Len(t, fake.deleted, 4)
+
+	exist, err := mgr.Exist(v1alpha1.DNSTypeA, "www.example.com", "2.2.2.2")
+	assert.Nil(t, err)
+	assert.False(t, exist)
+
+	// deleting a not exist record is not an error
+	assert.Nil(t, mgr.DeleteDNSRecord(v1alpha1.DNSTypeA, "www.example.com"))
+	assert.Len(t, fake.deleted, 4)
+
+	assert.Equal(t, NoDNSZoneForDomainError, mgr.CreateDNSRecord(v1alpha1.DNSTypeA, "example.org", "1.1.1.1"))
+
+	unauthorized := newTestDigitalOceanDNSManager(server, "wrong")
+	_, err = unauthorized.GetDNSRecords("example.com")
+	if assert.IsType(t, &godo.ErrorResponse{}, err) {
+		assert.Equal(t, http.StatusUnauthorized, err.(*godo.ErrorResponse).Response.StatusCode)
+		assert.Equal(t, "Unable to authenticate you", err.(*godo.ErrorResponse).Message)
+	}
+}
+
+func TestDigitalOceanRecordName(t *testing.T) {
+	assert.Equal(t, "@", toDigitalOceanRecordName("example.com", "example.com."))
+	assert.Equal(t, "a.b", toDigitalOceanRecordName("example.com", "A.b.example.com"))
+	assert.Equal(t, "example.com", fromDigitalOceanRecordName("example.com", "@"))
+	assert.Equal(t, "a.b.example.com", fromDigitalOceanRecordName("example.com", "a.b"))
+}
@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/miekg/dns"
+)
+
+var _ DNSManager = &RFC2136DNSManager{}
+
+// RFC2136DNSManager updates the records with dynamic updates of RFC 2136, signed with TSIG if a key is set.
+type RFC2136DNSManager struct {
+	Nameserver    string
+	TSIGKeyName   string
+	TSIGAlgorithm string
+	// base64 encoded
+	TSIGSecret string
+	Zones      []string
+}
+
+func NewRFC2136DNSManager(nameserver, tsigKeyName, tsigAlgorithm, tsigSecret string, zones []string) *RFC2136DNSManager {
+	return &RFC2136DNSManager{
+		Nameserver:    nameserver,
+		TSIGKeyName:   tsigKeyName,
+		TSIGAlgorithm: tsigAlgorithm,
+		TSIGSecret:    tsigSecret,
+		Zones:         zones,
+	}
+}
+
+var rfc2136TSIGAlgorithms = map[string]string{
+	"hmac-md5":    dns.HmacMD5,
+	"hmac-sha1":   dns.HmacSHA1,
+	"hmac-sha256": dns.HmacSHA256,
+	"hmac-sha512": dns.HmacSHA512,
+}
+
+func (m *RFC2136DNSManager) CreateDNSRecord(dnsType v1alpha1.DNSType, name, content string) error {
+	rr, err := newRFC2136RR(dnsType, name, content, 0)
+	if err != nil {
+		return err
+	}
+
+	return m.update(name, func(msg *dns.Msg) {
+		msg.Insert([]dns.RR{rr})
+	})
+}
+
+func (m *RFC2136DNSManager) UpsertDNSRecord(dnsType v1alpha1.DNSType, name string, contents []string, ttl int) error {
+	rrs := make([]dns.RR, 0, len(contents))
+
+	for _, content := range contents {
+		rr, err := newRFC2136RR(dnsType, name, content, ttl)
+		if err != nil {
+			return err
+		}
+
+		rrs = append(rrs, rr)
+	}
+
+	if len(rrs) == 0 {
+		return m.DeleteDNSRecord(dnsType, name)
+	}
+
+	// the removal and the insertion are applied in one update
+	return m.update(name, func(msg *dns.Msg) {
+		msg.RemoveRRset(rrs[:1])
+		msg.Insert(rrs)
+	})
+}
+
+func (m *RFC2136DNSManager) DeleteDNSRecord(dnsType v1alpha1.DNSType, name string) error {
+	rrType, exist := dns.StringToType[string(dnsType)]
+	if !exist {
+		return fmt.Errorf("unsupported dns type %s", dnsType)
+	}
+
+	// for not exist record, the update is a no-op
+	return m.update(name, func(msg *dns.Msg) {
+		msg.RemoveRRset([]dns.RR{&dns.ANY{Hdr: dns.RR_Header{Name: dns.Fqdn(name), Rrtype: rrType}}})
+	})
+}
+
+func (m *RFC2136DNSManager) Exist(dnsType v1alpha1.DNSType, name, content string) (bool, error) {
+	return dnsRecordExist(m, dnsType, name, content)
+}
+
+// GetDNSRecords only returns the records of the domain itself, as zone transfers are usually not allowed.
+func (m *RFC2136DNSManager) GetDNSRecords(domain string) ([]DNSRecord, error) {
+	if getZoneOfDomain(m.Zones, domain) == "" {
+		return nil, NoDNSZoneForDomainError
+	}
+
+	var rst []DNSRecord
+
+	for _, dnsType := range supportedDNSTypes {
+		msg := new(dns.Msg)
+		msg.SetQuestion(dns.Fqdn(domain), dns.StringToType[string(dnsType)])
+		msg.RecursionDesired = false
+
+		resp, err := m.exchange(msg)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.Rcode == dns.RcodeNameError {
+			return rst, nil
+		}
+
+		if resp.Rcode != dns.RcodeSuccess {
+			return nil, fmt.Errorf("query %s %s failed, rcode: %s", dnsType, domain, dns.RcodeToString[resp.Rcode])
+		}
+
+		for _, rr := range resp.Answer {
+			if rr.Header().Rrtype != dns.StringToType[string(dnsType)] {
+				continue
+			}
+
+			rst = append(rst, DNSRecord{
+				DNSType: dnsType,
+				Name:    normalizeDNSName(rr.Header().Name),
+				Content: fromRecordContent(dnsType, rfc2136RRContent(rr)),
+				TTL:     int(rr.Header().Ttl),
+			})
+		}
+	}
+
+	return rst, nil
+}
+
+func (m *RFC2136DNSManager) update(name string, fn func(msg *dns.Msg)) error {
+	zone := getZoneOfDomain(m.Zones, name)
+	if zone == "" {
+		return NoDNSZoneForDomainError
+	}
+
+	msg := new(dns.Msg)
+	msg.SetUpdate(dns.Fqdn(zone))
+	fn(msg)
+
+	resp, err := m.exchange(msg)
+	if err != nil {
+		return err
+	}
+
+	if resp.Rcode != dns.RcodeSuccess {
+		return fmt.Errorf("dns update of %s failed, rcode: %s", name, dns.RcodeToString[resp.Rcode])
+	}
+
+	return nil
+}
+
+func (m *RFC2136DNSManager) exchange(msg *dns.Msg) (*dns.Msg, error) {
+	// tcp avoids truncated responses
+	c := &dns.Client{Net: "tcp", Timeout: 10 * time.Second}
+
+	if m.TSIGKeyName != "" {
+		algorithm, exist := rfc2136TSIGAlgorithms[m.TSIGAlgorithm]
+		if !exist {
+			algorithm = dns.HmacSHA256
+		}
+
+		keyName := dns.Fqdn(m.TSIGKeyName)
+		c.TsigSecret = map[string]string{keyName: m.TSIGSecret}
+		msg.SetTsig(keyName, algorithm, 300, time.Now().Unix())
+	}
+
+	resp, _, err := c.Exchange(msg, m.Nameserver)
+	return resp, err
+}
+
+func newRFC2136RR(dnsType v1alpha1.DNSType, name, content string, ttl int) (dns.RR, error) {
+	if ttl == 0 {
+		ttl = defaultDNSRecordTTL
+	}
+
+	return dns.NewRR(fmt.Sprintf("%s %d IN %s %s", dns.Fqdn(name), ttl, dnsType, toRecordContent(dnsType, content)))
+}
+
+// the rdata of the record in the zone file format
+func rfc2136RRContent(rr dns.RR) string {
+	return strings.TrimPrefix(rr.String(), rr.Header().String())
+}
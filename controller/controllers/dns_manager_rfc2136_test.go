@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+const testTSIGKeyName = "kalm."
+const testTSIGSecret = "a2FsbS10c2lnLXNlY3JldA=="
+
+// an in-memory nameserver which accepts signed dynamic updates
+type testRFC2136Server struct {
+	sync.Mutex
+	records []dns.RR
+}
+
+func (s *testRFC2136Server) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	s.Lock()
+	defer s.Unlock()
+
+	resp := new(dns.Msg)
+	resp.SetReply(req)
+
+	if req.Opcode == dns.OpcodeUpdate {
+		if req.IsTsig() == nil || w.TsigStatus() != nil {
+			resp.Rcode = dns.RcodeNotAuth
+		} else {
+			for _, rr := range req.Ns {
+				if rr.Header().Class == dns.ClassANY {
+					s.remove(rr.Header().Name, rr.Header().Rrtype)
+				} else {
+					s.records = append(s.records, dns.Copy(rr))
+				}
+			}
+		}
+	} else {
+		question := req.Question[0]
+		nameExist := false
+
+		for _, rr := range s.records {
+			if rr.Header().Name != question.Name {
+				continue
+			}
+
+			nameExist = true
+			if rr.Header().Rrtype == question.Qtype {
+				resp.Answer = append(resp.Answer, rr)
+			}
+		}
+
+		if !nameExist {
+			resp.Rcode = dns.RcodeNameError
+		}
+	}
+
+	if req.IsTsig() != nil {
+		resp.SetTsig(testTSIGKeyName, dns.HmacSHA256, 300, int64(req.IsTsig().TimeSigned))
+	}
+
+	_ = w.WriteMsg(resp)
+}
+
+func (s *testRFC2136Server) remove(name string, rrType uint16) {
+	var records []dns.RR
+
+	for _, rr := range s.records {
+		if rr.Header().Name != name || rr.Header().Rrtype != rrType {
+			records = append(records, rr)
+		}
+	}
+
+	s.records = records
+}
+
+func startTestRFC2136Server(t *testing.T) (*testRFC2136Server, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := &testRFC2136Server{}
+	started := make(chan struct{})
+
+	server := &dns.Server{
+		Listener:          listener,
+		Handler:           handler,
+		TsigSecret:        map[string]string{testTSIGKeyName: testTSIGSecret},
+		NotifyStartedFunc: func() { close(started) },
+		// the default accept func rejects dynamic updates
+		MsgAcceptFunc: func(dh dns.Header) dns.MsgAcceptAction { return dns.MsgAccept },
+	}
+
+	go func() { _ = server.ActivateAndServe() }()
+	t.Cleanup(func() { _ = server.Shutdown() })
+	<-started
+
+	return handler, listener.Addr().String()
+}
+
+func TestRFC2136DNSManager(t *testing.T) {
+	server, addr := startTestRFC2136Server(t)
+	mgr := NewRFC2136DNSManager(addr, testTSIGKeyName, "hmac-sha256", testTSIGSecret, []string{"example.com"})
+
+	assert.Nil(t, mgr.CreateDNSRecord(v1alpha1.DNSTypeA, "a.example.com", "1.1.1.1"))
+	assert.Nil(t, mgr.CreateDNSRecord(v1alpha1.DNSTypeCNAME, "www.example.com", "a.example.com"))
+
+	records, err := mgr.GetDNSRecords("a.example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, []DNSRecord{{DNSType: v1alpha1.DNSTypeA, Name: "a.example.com", Content: "1.1.1.1", TTL: defaultDNSRecordTTL}}, records)
+
+	exist, err := mgr.Exist(v1alpha1.DNSTypeCNAME, "www.example.com", "a.example.com")
+	assert.Nil(t, err)
+	assert.True(t, exist)
+
+	// upsert replaces the record set
+	assert.Nil(t, mgr.UpsertDNSRecord(v1alpha1.DNSTypeA, "a.example.com", []string{"2.2.2.2"}, 0))
+
+	exist, err = mgr.Exist(v1alpha1.DNSTypeA, "a.example.com", "1.1.1.1")
+	assert.Nil(t, err)
+	assert.False(t, exist)
+
+	exist, err = mgr.Exist(v1alpha1.DNSTypeA, "a.example.com", "2.2.2.2")
+	assert.Nil(t, err)
+	assert.True(t, exist)
+
+	assert.Nil(t, mgr.DeleteDNSRecord(v1alpha1.DNSTypeA, "a.example.com"))
+	assert.Len(t, server.records, 1)
+
+	records, err = mgr.GetDNSRecords("a.example.com")
+	assert.Nil(t, err)
+	assert.Empty(t, records)
+
+	// domains out of the zones
+	assert.Equal(t, NoDNSZoneForDomainError, mgr.CreateDNSRecord(v1alpha1.DNSTypeA, "a.example.org", "1.1.1.1"))
+
+	// unsigned updates are refused
+	unsigned := NewRFC2136DNSManager(addr, "", "", "", []string{"example.com"})
+	assert.NotNil(t, unsigned.CreateDNSRecord(v1alpha1.DNSTypeA, "b.example.com", "1.1.1.1"))
+}
+
+func TestRFC2136DNSManagerRecordSets(t *testing.T) {
+	_, addr := startTestRFC2136Server(t)
+	mgr := NewRFC2136DNSManager(addr, testTSIGKeyName, "hmac-sha256", testTSIGSecret, []string{"example.com"})
+
+	mx := []string{"10 mx1.example.com", "20 mx2.example.com"}
+	txt := []string{"v=spf1 include:_spf.example.com ~all", `with "quotes"`}
+	srv := []string{"10 5 5060 sip.example.com"}
+
+	assert.Nil(t, mgr.UpsertDNSRecord(v1alpha1.DNSTypeMX, "example.com", mx, 3600))
+	assert.Nil(t, mgr.UpsertDNSRecord(v1alpha1.DNSTypeTXT, "example.com", txt, 0))
+	assert.Nil(t, mgr.UpsertDNSRecord(v1alpha1.DNSTypeSRV, "_sip._tcp.example.com", srv, 0))
+	assert.Nil(t, mgr.UpsertDNSRecord(v1alpha1.DNSTypeAAAA, "example.com", []string{"2001:db8::1"}, 0))
+
+	records, err := getDNSRecordSet(mgr, v1alpha1.DNSTypeMX, "example.com")
+	assert.Nil(t, err)
+	assert.True(t, isDNSRecordSetSynced(records, mx, 3600))
+	assert.False(t, isDNSRecordSetSynced(records, mx, 60))
+
+	records, err = getDNSRecordSet(mgr, v1alpha1.DNSTypeTXT, "example.com")
+	assert.Nil(t, err)
+	assert.True(t, isDNSRecordSetSynced(records, txt, 0))
+
+	records, err = getDNSRecordSet(mgr, v1alpha1.DNSTypeSRV, "_sip._tcp.example.com")
+	assert.Nil(t, err)
+	assert.True(t, isDNSRecordSetSynced(records, srv, 0))
+
+	exist, err := mgr.Exist(v1alpha1.DNSTypeAAAA, "example.com", "2001:db8::1")
+	assert.Nil(t, err)
+	assert.True(t, exist)
+
+	// shrink the record set
+	assert.Nil(t, mgr.UpsertDNSRecord(v1alpha1.DNSTypeMX, "example.com", mx[:1], 0))
+
+	records, err = getDNSRecordSet(mgr, v1alpha1.DNSTypeMX, "example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, mx[:1], getDNSRecordSetContents(records))
+}
+
+func TestDNSRecordContent(t *testing.T) {
+	long := strings.Repeat("a", 300)
+
+	assert.Equal(t, `"a \"b\" \\c"`, toRecordContent(v1alpha1.DNSTypeTXT, `a "b" \c`))
+	assert.Equal(t, `a "b" \c`, fromRecordContent(v1alpha1.DNSTypeTXT, `"a \"b\" \\c"`))
+	assert.Equal(t, `"`+long[:255]+`" "`+long[255:]+`"`, toRecordContent(v1alpha1.DNSTypeTXT, long))
+	assert.Equal(t, long, fromRecordContent(v1alpha1.DNSTypeTXT, toRecordContent(v1alpha1.DNSTypeTXT, long)))
+	assert.Equal(t, "a;b", fromRecordContent(v1alpha1.DNSTypeTXT, `"a\059b"`))
+	assert.Equal(t, "unquoted", fromRecordContent(v1alpha1.DNSTypeTXT, "unquoted"))
+
+	assert.Equal(t, "10 mx.example.com.", toRecordContent(v1alpha1.DNSTypeMX, "10 mx.example.com"))
+	assert.Equal(t, "10 mx.example.com", fromRecordContent(v1alpha1.DNSTypeMX, "10 MX.example.com."))
+	assert.Equal(t, "10 5 5060 sip.example.com", fromRecordContent(v1alpha1.DNSTypeSRV, "10 5 5060 sip.example.com."))
+
+	assert.Equal(t, []string{"10 mx1.example.com", "10 mx2.example.com"}, getDNSRecordContents(v1alpha1.DNSRecordSpec{
+		DNSType:    v1alpha1.DNSTypeMX,
+		DNSTargets: []string{"mx1.example.com", "mx2.example.com."},
+		Priority:   10,
+	}))
+
+	assert.Equal(t, []string{"2001:db8::1"}, getDNSRecordContents(v1alpha1.DNSRecordSpec{
+		DNSType:   v1alpha1.DNSTypeAAAA,
+		DNSTarget: "2001:0db8:0000:0000:0000:0000:0000:0001",
+	}))
+
+	assert.Equal(t, []string{"10 5 5060 sip.example.com"}, getDNSRecordContents(v1alpha1.DNSRecordSpec{
+		DNSType:   v1alpha1.DNSTypeSRV,
+		DNSTarget: "sip.example.com",
+		Priority:  10,
+		Weight:    5,
+		Port:      5060,
+	}))
+}
+
+func TestGetZoneOfDomain(t *testing.T) {
+	zones := []string{"example.com", "sub.example.com.", "example.org"}
+
+	assert.Equal(t, "example.com", getZoneOfDomain(zones, "example.com"))
+	assert.Equal(t, "example.com", getZoneOfDomain(zones, "a.example.com"))
+	assert.Equal(t, "sub.example.com", getZoneOfDomain(zones, "a.sub.example.com."))
+	assert.Equal(t, "sub.example.com", getZoneOfDomain(zones, "A.Sub.Example.com"))
+	assert.Equal(t, "", getZoneOfDomain(zones, "badexample.com"))
+	assert.Equal(t, "", getZoneOfDomain(zones, "example.net"))
+}
@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+)
+
+var _ DNSManager = &Route53DNSManager{}
+
+// Route53DNSManager manages the record sets of the Route53 hosted zones with the zones as names.
+type Route53DNSManager struct {
+	Zones []string
+	*route53.Route53
+
+	// zone name -> hosted zone id
+	hostedZoneIDs *dnsZoneIDCache
+}
+
+func NewRoute53DNSManager(accessKeyID, secretAccessKey string, zones []string) (*Route53DNSManager, error) {
+	// route53 is a global service, requests are always signed for us-east-1
+	sess, err := session.NewSession(&aws.Config{
+		Credentials: credentials.NewStaticCredentials(accessKeyID, secretAccessKey, ""),
+		Region:      aws.String("us-east-1"),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Route53DNSManager{
+		Zones:         zones,
+		Route53:       route53.New(sess),
+		hostedZoneIDs: newDNSZoneIDCache(),
+	}, nil
+}
+
+func (m *Route53DNSManager) CreateDNSRecord(dnsType v1alpha1.DNSType, name, content string) error {
+	return m.changeRecordSet(route53.ChangeActionCreate, dnsType, name, []string{content}, 0)
+}
+
+func (m *Route53DNSManager) UpsertDNSRecord(dnsType v1alpha1.DNSType, name string, contents []string, ttl int) error {
+	return m.changeRecordSet(route53.ChangeActionUpsert, dnsType, name, contents, ttl)
+}
+
+func (m *Route53DNSManager) DeleteDNSRecord(dnsType v1alpha1.DNSType, name string) error {
+	zoneID, err := m.getHostedZoneID(name)
+	if err != nil {
+		return err
+	}
+
+	recordSets, err := m.listRecordSets(zoneID)
+	if err != nil {
+		return err
+	}
+
+	// a deletion must match the existing record set exactly
+	for _, recordSet := range recordSets {
+		if aws.StringValue(recordSet.Type) != string(dnsType) || normalizeRoute53Name(aws.StringValue(recordSet.Name)) != normalizeDNSName(name) {
+			continue
+		}
+
+		return m.changeRecordSets(zoneID, &route53.Change{
+			Action:            aws.String(route53.ChangeActionDelete),
+			ResourceRecordSet: recordSet,
+		})
+	}
+
+	// for not exist record, return without error
+	return nil
+}
+
+func (m *Route53DNSManager) Exist(dnsType v1alpha1.DNSType, name, content string) (bool, error) {
+	return dnsRecordExist(m, dnsType, name, content)
+}
+
+func (m *Route53DNSManager) GetDNSRecords(domain string) ([]DNSRecord, error) {
+	zoneID, err := m.getHostedZoneID(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	recordSets, err := m.listRecordSets(zoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	var rst []DNSRecord
+	for _, recordSet := range recordSets {
+		dnsType := v1alpha1.DNSType(aws.StringValue(recordSet.Type))
+
+		// alias records have no values
+		for _, record := range recordSet.ResourceRecords {
+			rst = append(rst, DNSRecord{
+				DNSType: dnsType,
+				Name:    normalizeRoute53Name(aws.StringValue(recordSet.Name)),
+				Content: fromRecordContent(dnsType, aws.StringValue(record.Value)),
+				TTL:     int(aws.Int64Value(recordSet.TTL)),
+			})
+		}
+	}
+
+	return rst, nil
+}
+
+func (m *Route53DNSManager) changeRecordSet(action string, dnsType v1alpha1.DNSType, name string, contents []string, ttl int) error {
+	zoneID, err := m.getHostedZoneID(name)
+	if err != nil {
+		return err
+	}
+
+	if ttl == 0 {
+		ttl = defaultDNSRecordTTL
+	}
+
+	records := make([]*route53.ResourceRecord, 0, len(contents))
+	for _, content := range contents {
+		records = append(records, &route53.ResourceRecord{Value: aws.String(toRecordContent(dnsType, content))})
+	}
+
+	return m.changeRecordSets(zoneID, &route53.Change{
+		Action: aws.String(action),
+		ResourceRecordSet: &route53.ResourceRecordSet{
+			Name:            aws.String(normalizeDNSName(name) + "."),
+			Type:            aws.String(string(dnsType)),
+			TTL:             aws.Int64(int64(ttl)),
+			ResourceRecords: records,
+		},
+	})
+}
+
+func (m *Route53DNSManager) changeRecordSets(zoneID string, changes ...*route53.Change) error {
+	_, err := m.ChangeResourceRecordSets(&route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(zoneID),
+		ChangeBatch:  &route53.ChangeBatch{Changes: changes},
+	})
+
+	return err
+}
+
+func (m *Route53DNSManager) listRecordSets(zoneID string) ([]*route53.ResourceRecordSet, error) {
+	var rst []*route53.ResourceRecordSet
+
+	err := m.ListResourceRecordSetsPages(
+		&route53.ListResourceRecordSetsInput{HostedZoneId: aws.String(zoneID)},
+		func(resp *route53.ListResourceRecordSetsOutput, lastPage bool) bool {
+			rst = append(rst, resp.ResourceRecordSets...)
+			return true
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return rst, nil
+}
+
+func (m *Route53DNSManager) getHostedZoneID(domain string) (string, error) {
+	zone := getZoneOfDomain(m.Zones, domain)
+	if zone == "" {
+		return "", NoDNSZoneForDomainError
+	}
+
+	if zoneID, exist := m.hostedZoneIDs.get(zone); exist {
+		return zoneID, nil
+	}
+
+	resp, err := m.ListHostedZonesByName(&route53.ListHostedZonesByNameInput{
+		DNSName:  aws.String(zone + "."),
+		MaxItems: aws.String("1"),
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(resp.HostedZones) == 0 || normalizeRoute53Name(aws.StringValue(resp.HostedZones[0].Name)) != zone {
+		return "", fmt.Errorf("route53 hosted zone %s not found", zone)
+	}
+
+	zoneID := strings.TrimPrefix(aws.StringValue(resp.HostedZones[0].Id), "/hostedzone/")
+	m.hostedZoneIDs.set(zone, zoneID)
+
+	return zoneID, nil
+}
+
+// route53 returns absolute names with special characters escaped, e.g. \052 for a wildcard
+func normalizeRoute53Name(name string) string {
+	return normalizeDNSName(strings.ReplaceAll(name, `\052`, "*"))
+}
@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+// the xml of the route53 api, only the fields used by the dns manager
+type testRoute53ResourceRecord struct {
+	Value string
+}
+
+type testRoute53ResourceRecordSet struct {
+	Name            string
+	Type            string
+	TTL             int64                       `xml:",omitempty"`
+	ResourceRecords []testRoute53ResourceRecord `xml:"ResourceRecords>ResourceRecord"`
+}
+
+type testRoute53Change struct {
+	Action            string
+	ResourceRecordSet testRoute53ResourceRecordSet
+}
+
+type testRoute53ChangeResourceRecordSetsRequest struct {
+	XMLName xml.Name            `xml:"https://route53.amazonaws.com/doc/2013-04-01/ ChangeResourceRecordSetsRequest"`
+	Changes []testRoute53Change `xml:"ChangeBatch>Changes>Change"`
+}
+
+type testRoute53ListResourceRecordSetsResponse struct {
+	XMLName            xml.Name                       `xml:"ListResourceRecordSetsResponse"`
+	ResourceRecordSets []testRoute53ResourceRecordSet `xml:"ResourceRecordSets>ResourceRecordSet"`
+	IsTruncated        bool
+	MaxItems           string
+	NextRecordName     string `xml:",omitempty"`
+	NextRecordType     string `xml:",omitempty"`
+}
+
+type testRoute53HostedZone struct {
+	Id   string
+	Name string
+}
+
+type testRoute53ListHostedZonesByNameResponse struct {
+	XMLName     xml.Name                `xml:"ListHostedZonesByNameResponse"`
+	HostedZones []testRoute53HostedZone `xml:"HostedZones>HostedZone"`
+}
+
+// an in-memory route53 api, record sets are listed two per page
+type testRoute53Server struct {
+	sync.Mutex
+	recordSets []testRoute53ResourceRecordSet
+
+	zoneLookups int
+	changes     []testRoute53Change
+}
+
+func (s *testRoute53Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") ||
+		!strings.Contains(r.Header.Get("Authorization"), "/us-east-1/route53/aws4_request") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/2013-04-01/hostedzonesbyname":
+		s.zoneLookups++
+
+		var resp testRoute53ListHostedZonesByNameResponse
+		if r.URL.Query().Get("dnsname") == "example.com." {
+			resp.HostedZones = append(resp.HostedZones, testRoute53HostedZone{Id: "/hostedzone/Z1", Name: "example.com."})
+		}
+
+		s.writeXML(w, resp)
+	case r.Method == http.MethodGet && r.URL.Path == "/2013-04-01/hostedzone/Z1/rrset":
+		start := 0
+		if name := r.URL.Query().Get("name"); name != "" {
+			for i, recordSet := range s.recordSets {
+				if recordSet.Name == name && recordSet.Type == r.URL.Query().Get("type") {
+					start = i
+				}
+			}
+		}
+
+		var resp testRoute53ListResourceRecordSetsResponse
+		resp.MaxItems = "2"
+		end := start + 2
+
+		if end < len(s.recordSets) {
+			resp.IsTruncated = true
+			resp.NextRecordName = s.recordSets[end].Name
+			resp.NextRecordType = s.recordSets[end].Type
+		} else {
+			end = len(s.recordSets)
+		}
+
+		resp.ResourceRecordSets = s.recordSets[start:end]
+		s.writeXML(w, resp)
+	case r.Method == http.MethodPost && r.URL.Path == "/2013-04-01/hostedzone/Z1/rrset/":
+		body, _ := ioutil.ReadAll(r.Body)
+
+		var req testRoute53ChangeResourceRecordSetsRequest
+		if err := xml.Unmarshal(body, &req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		for _, change := range req.Changes {
+			s.changes = append(s.changes, change)
+			s.applyChange(change)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *testRoute53Server) applyChange(change testRoute53Change) {
+	for i, recordSet := range s.recordSets {
+		if recordSet.Name != change.ResourceRecordSet.Name || recordSet.Type != change.ResourceRecordSet.Type {
+			continue
+		}
+
+		if change.Action == "UPSERT" {
+			s.recordSets[i] = change.ResourceRecordSet
+		} else if change.Action == "DELETE" {
+			s.recordSets = append(s.recordSets[:i], s.recordSets[i+1:]...)
+		}
+
+		return
+	}
+
+	if change.Action != "DELETE" {
+		s.recordSets = append(s.recordSets, change.ResourceRecordSet)
+	}
+}
+
+func (s *testRoute53Server) writeXML(w http.ResponseWriter, v interface{}) {
+	bts, _ := xml.Marshal(v)
+	w.Header().Set("Content-Type", "application/xml")
+	_, _ = w.Write(bts)
+}
+
+func newTestRoute53DNSManager(t *testing.T, server *httptest.Server, zones ...string) *Route53DNSManager {
+	mgr, err := NewRoute53DNSManager("AKID", "secret", zones)
+	assert.Nil(t, err)
+
+	mgr.Route53 = route53.New(session.Must(session.NewSession(mgr.Config.Copy(&aws.Config{
+		Endpoint:   aws.String(server.URL),
+		HTTPClient: server.Client(),
+	}))))
+
+	return mgr
+}
+
+func TestRoute53DNSManager(t *testing.T) {
+	fake := &testRoute53Server{
+		recordSets: []testRoute53ResourceRecordSet{
+			{Name: "example.com.", Type: "NS", TTL: 172800, ResourceRecords: []testRoute53ResourceRecord{{Value: "ns-1.awsdns-00.com."}}},
+			{Name: "example.com.", Type: "SOA", TTL: 900, ResourceRecords: []testRoute53ResourceRecord{{Value: "ns-1.awsdns-00.com. awsdns-hostmaster.amazon.com. 1 7200 900 1209600 86400"}}},
+			{Name: `\052.example.com.`, Type: "A", TTL: 300, ResourceRecords: []testRoute53ResourceRecord{{Value: "1.1.1.1"}}},
+			{Name: "txt.example.com.", Type: "TXT", TTL: 300, ResourceRecords: []testRoute53ResourceRecord{{Value: `"hello" " world"`}}},
+			{Name: "www.example.com.", Type: "CNAME", TTL: 60, ResourceRecords: []testRoute53ResourceRecord{{Value: "example.com."}}},
+		},
+	}
+
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	mgr := newTestRoute53DNSManager(t, server, "example.com")
+
+	// records of all pages are listed
+	records, err := mgr.GetDNSRecords("example.com")
+	assert.Nil(t, err)
+	assert.Len(t, records, 5)
+	assert.Contains(t, records, DNSRecord{DNSType: v1alpha1.DNSTypeA, Name: "*.example.com", Content: "1.1.1.1", TTL: 300})
+	assert.Contains(t, records, DNSRecord{DNSType: v1alpha1.DNSTypeTXT, Name: "txt.example.com", Content: "hello world", TTL: 300})
+	assert.Contains(t, records, DNSRecord{DNSType: v1alpha1.DNSTypeCNAME, Name: "www.example.com", Content: "example.com", TTL: 60})
+
+	exist, err := mgr.Exist(v1alpha1.DNSTypeCNAME, "www.example.com", "example.com")
+	assert.Nil(t, err)
+	assert.True(t, exist)
+
+	assert.Nil(t, mgr.CreateDNSRecord(v1alpha1.DNSTypeA, "new.example.com", "2.2.2.2"))
+	assert.Equal(t, "CREATE", fake.changes[0].Action)
+	assert.Equal(t, "new.example.com.", fake.changes[0].ResourceRecordSet.Name)
+	assert.Equal(t, int64(defaultDNSRecordTTL), fake.changes[0].ResourceRecordSet.TTL)
+
+	assert.Nil(t, mgr.UpsertDNSRecord(v1alpha1.DNSTypeMX, "example.com", []string{"10 mx1.example.com", "20 mx2.example.com"}, 600))
+	assert.Equal(t, "UPSERT", fake.changes[1].Action)
+	assert.Equal(t, []testRoute53ResourceRecord{{Value: "10 mx1.example.com."}, {Value: "20 mx2.example.com."}}, fake.changes[1].ResourceRecordSet.ResourceRecords)
+	assert.Equal(t, int64(600), fake.changes[1].ResourceRecordSet.TTL)
+
+	// the deleted record set is on the last page, it's sent exactly as it is
+	assert.Nil(t, mgr.DeleteDNSRecord(v1alpha1.DNSTypeCNAME, "www.example.com"))
+	assert.Equal(t, "DELETE", fake.changes[2].Action)
+	assert.Equal(t, int64(60), fake.changes[2].ResourceRecordSet.TTL)
+
+	exist, err = mgr.Exist(v1alpha1.DNSTypeCNAME, "www.example.com", "example.com")
+	assert.Nil(t, err)
+	assert.False(t, exist)
+
+	// deleting a not exist record is not an error
+	assert.Nil(t, mgr.DeleteDNSRecord(v1alpha1.DNSTypeCNAME, "www.example.com"))
+	assert.Len(t, fake.changes, 3)
+
+	// the hosted zone id is looked up once, and shared by managers with the same cache
+	other := newTestRoute53DNSManager(t, server, "example.com")
+	other.hostedZoneIDs = mgr.hostedZoneIDs
+	_, err = other.GetDNSRecords("example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, fake.zoneLookups)
+
+	assert.Equal(t, NoDNSZoneForDomainError, mgr.CreateDNSRecord(v1alpha1.DNSTypeA, "example.org", "1.1.1.1"))
+
+	wrong := newTestRoute53DNSManager(t, server, "example.net")
+	_, err = wrong.GetDNSRecords("example.net")
+	assert.EqualError(t, err, "route53 hosted zone example.net not found")
+}
@@ -0,0 +1,267 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// keys of the secrets referenced by DNSProviders
+const (
+	DNSProviderSecretKeyToken           = "token"
+	DNSProviderSecretKeyAccessKeyID     = "accessKeyID"
+	DNSProviderSecretKeySecretAccessKey = "secretAccessKey"
+	DNSProviderSecretKeyCredentials     = "credentials.json"
+	DNSProviderSecretKeyTSIGSecret      = "secret"
+)
+
+// GetDNSManagerForDomain returns the dns manager of the DNSProvider with the longest zone matching the domain,
+// nil if no DNSProvider manages the domain.
+func GetDNSManagerForDomain(ctx context.Context, c client.Reader, domain string) (DNSManager, error) {
+	var providerList v1alpha1.DNSProviderList
+
+	if err := c.List(ctx, &providerList); err != nil {
+		return nil, err
+	}
+
+	dnsProviderManagers.prune(providerList.Items)
+
+	var provider *v1alpha1.DNSProvider
+	var providerZone string
+
+	for i := range providerList.Items {
+		zone := getZoneOfDomain(providerList.Items[i].Spec.Zones, domain)
+
+		if len(zone) > len(providerZone) {
+			provider = &providerList.Items[i]
+			providerZone = zone
+		}
+	}
+
+	if provider == nil {
+		return nil, nil
+	}
+
+	return getDNSManagerForProvider(ctx, c, provider)
+}
+
+// getDNSManagerForProvider reuses the dns manager of the provider, unless the provider or its credentials changed.
+func getDNSManagerForProvider(ctx context.Context, c client.Reader, provider *v1alpha1.DNSProvider) (DNSManager, error) {
+	var secret *corev1.Secret
+
+	if secretName := getDNSProviderSecretName(provider.Spec); secretName != "" {
+		secret = &corev1.Secret{}
+
+		if err := c.Get(ctx, types.NamespacedName{Namespace: KalmSystemNamespace, Name: secretName}, secret); err != nil {
+			return nil, err
+		}
+	}
+
+	version := fmt.Sprintf("%d", provider.Generation)
+	if secret != nil {
+		version += "/" + secret.ResourceVersion
+	}
+
+	if mgr := dnsProviderManagers.get(provider.UID, version); mgr != nil {
+		return mgr, nil
+	}
+
+	mgr, err := NewDNSManagerFromProvider(provider, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	dnsProviderManagers.set(provider.UID, version, mgr)
+
+	return mgr, nil
+}
+
+// NewDNSManagerFromProvider builds the dns manager of the provider, secret holds its credentials, nil if it has none.
+func NewDNSManagerFromProvider(provider *v1alpha1.DNSProvider, secret *corev1.Secret) (DNSManager, error) {
+	spec := provider.Spec
+
+	switch {
+	case spec.Cloudflare != nil:
+		token, err := getDNSProviderSecretValue(secret, DNSProviderSecretKeyToken)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewCloudflareDNSManagerForZones(token, spec.Zones)
+	case spec.Route53 != nil:
+		accessKeyID, err := getDNSProviderSecretValue(secret, DNSProviderSecretKeyAccessKeyID)
+		if err != nil {
+			return nil, err
+		}
+
+		secretAccessKey, err := getDNSProviderSecretValue(secret, DNSProviderSecretKeySecretAccessKey)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewRoute53DNSManager(accessKeyID, secretAccessKey, spec.Zones)
+	case spec.GoogleCloudDNS != nil:
+		credentials, err := getDNSProviderSecretValue(secret, DNSProviderSecretKeyCredentials)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewGoogleCloudDNSManager(spec.GoogleCloudDNS.Project, []byte(credentials), spec.Zones)
+	case spec.DigitalOcean != nil:
+		token, err := getDNSProviderSecretValue(secret, DNSProviderSecretKeyToken)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewDigitalOceanDNSManager(token, spec.Zones), nil
+	case spec.RFC2136 != nil:
+		var tsigSecret string
+
+		if spec.RFC2136.TSIGKeyName != "" {
+			value, err := getDNSProviderSecretValue(secret, DNSProviderSecretKeyTSIGSecret)
+			if err != nil {
+				return nil, err
+			}
+
+			tsigSecret = value
+		}
+
+		return NewRFC2136DNSManager(spec.RFC2136.Nameserver, spec.RFC2136.TSIGKeyName, spec.RFC2136.TSIGAlgorithm, tsigSecret, spec.Zones), nil
+	}
+
+	return nil, fmt.Errorf("no provider config in DNSProvider %s", provider.Name)
+}
+
+// getDNSProviderSecretName returns the name of the secret holding the credentials of the provider,
+// empty if it has none, e.g. RFC2136 without TSIG.
+func getDNSProviderSecretName(spec v1alpha1.DNSProviderSpec) string {
+	switch {
+	case spec.Cloudflare != nil:
+		return spec.Cloudflare.APITokenSecretName
+	case spec.Route53 != nil:
+		return spec.Route53.CredentialsSecretName
+	case spec.GoogleCloudDNS != nil:
+		return spec.GoogleCloudDNS.CredentialsSecretName
+	case spec.DigitalOcean != nil:
+		return spec.DigitalOcean.APITokenSecretName
+	case spec.RFC2136 != nil && spec.RFC2136.TSIGKeyName != "":
+		return spec.RFC2136.TSIGSecretName
+	}
+
+	return ""
+}
+
+func getDNSProviderSecretValue(secret *corev1.Secret, key string) (string, error) {
+	value, exist := secret.Data[key]
+	if !exist || len(value) == 0 {
+		return "", fmt.Errorf("key %s not found in secret %s", key, secret.Name)
+	}
+
+	return string(value), nil
+}
+
+// dnsZoneIDCache keeps the ids of zones which are looked up by their names.
+type dnsZoneIDCache struct {
+	mut sync.RWMutex
+	ids map[string]string
+}
+
+func newDNSZoneIDCache() *dnsZoneIDCache {
+	return &dnsZoneIDCache{ids: make(map[string]string)}
+}
+
+func (c *dnsZoneIDCache) get(zone string) (string, bool) {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	id, exist := c.ids[zone]
+	return id, exist
+}
+
+func (c *dnsZoneIDCache) set(zone, id string) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.ids[zone] = id
+}
+
+type dnsProviderManager struct {
+	// generation of the provider and resource version of its secret
+	version string
+	manager DNSManager
+}
+
+// dnsProviderManagerCache keeps the dns managers of DNSProviders by their uids,
+// zone ids looked up by a manager are kept as long as the manager.
+type dnsProviderManagerCache struct {
+	mut      sync.Mutex
+	managers map[types.UID]dnsProviderManager
+}
+
+var dnsProviderManagers = &dnsProviderManagerCache{managers: make(map[types.UID]dnsProviderManager)}
+
+// get returns nil if there is no manager of the version
+func (c *dnsProviderManagerCache) get(uid types.UID, version string) DNSManager {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	cached, exist := c.managers[uid]
+	if !exist || cached.version != version {
+		return nil
+	}
+
+	return cached.manager
+}
+
+func (c *dnsProviderManagerCache) set(uid types.UID, version string, manager DNSManager) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.managers[uid] = dnsProviderManager{version: version, manager: manager}
+}
+
+// prune evicts the managers of deleted providers
+func (c *dnsProviderManagerCache) prune(providers []v1alpha1.DNSProvider) {
+	uids := make(map[types.UID]bool, len(providers))
+	for _, provider := range providers {
+		uids[provider.UID] = true
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	for uid := range c.managers {
+		if !uids[uid] {
+			delete(c.managers, uid)
+		}
+	}
+}
+
+// DNSProviderRecordsMapper reconciles all dns records if a DNSProvider changes, as the provider of their domains may change.
+type DNSProviderRecordsMapper struct {
+	*BaseReconciler
+}
+
+func (m *DNSProviderRecordsMapper) Map(object handler.MapObject) []reconcile.Request {
+	var recordList v1alpha1.DNSRecordList
+
+	if err := m.Reader.List(context.Background(), &recordList); err != nil {
+		m.Log.Error(err, "fail to list dns records")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(recordList.Items))
+
+	for _, record := range recordList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: record.Name}})
+	}
+
+	return requests
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestDNSProviderManagerCache(t *testing.T) {
+	cache := &dnsProviderManagerCache{managers: make(map[types.UID]dnsProviderManager)}
+
+	provider := v1alpha1.DNSProvider{
+		ObjectMeta: metaV1.ObjectMeta{Name: "rfc2136", UID: "uid-1", Generation: 1},
+		Spec: v1alpha1.DNSProviderSpec{
+			Zones:   []string{"example.com"},
+			RFC2136: &v1alpha1.RFC2136DNSProviderConfig{Nameserver: "127.0.0.1:53"},
+		},
+	}
+
+	mgr, err := NewDNSManagerFromProvider(&provider, nil)
+	assert.Nil(t, err)
+
+	cache.set(provider.UID, "1/100", mgr)
+	assert.Equal(t, mgr, cache.get(provider.UID, "1/100"))
+
+	// a new generation or new credentials need a new manager
+	assert.Nil(t, cache.get(provider.UID, "2/100"))
+	assert.Nil(t, cache.get(provider.UID, "1/101"))
+
+	cache.prune([]v1alpha1.DNSProvider{provider})
+	assert.Equal(t, mgr, cache.get(provider.UID, "1/100"))
+
+	// managers of deleted providers are evicted
+	cache.prune(nil)
+	assert.Nil(t, cache.get(provider.UID, "1/100"))
+	assert.Empty(t, cache.managers)
+}
+
+func TestCloudflareDNSManagerZoneIDs(t *testing.T) {
+	lookups := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"success":true,"result":[{"id":"zone-id-1","name":"%s"}]}`, r.URL.Query().Get("name"))
+	}))
+	defer server.Close()
+
+	mgr, err := NewCloudflareDNSManager("token", make(map[string]string))
+	assert.Nil(t, err)
+	mgr.API.BaseURL = server.URL
+
+	assert.Nil(t, mgr.lookupZoneIDs([]string{"Example.com."}))
+
+	// zone ids are looked up once by the manager
+	for i := 0; i < 2; i++ {
+		zoneID, err := mgr.getZoneID("www.example.com")
+		assert.Nil(t, err)
+		assert.Equal(t, "zone-id-1", zoneID)
+	}
+
+	assert.Equal(t, 1, lookups)
+}
@@ -17,10 +17,19 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"sort"
+	"time"
 
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/kalmhq/kalm/controller/api/v1alpha1"
 	corev1alpha1 "github.com/kalmhq/kalm/controller/api/v1alpha1"
@@ -29,10 +38,14 @@ import (
 
 const DNSRecordFinalizer = "dns-record-finalizer"
 
+const dnsRecordDriftCheckInterval = 10 * time.Minute
+
 // DNSRecordReconciler reconciles a DNSRecord object
 type DNSRecordReconciler struct {
 	*BaseReconciler
-	ctx    context.Context
+	ctx context.Context
+
+	// cloudflare account configured by env, used for domains not in any DNSProvider
 	dnsMgr DNSManager
 }
 
@@ -51,20 +64,25 @@ func NewDNSRecordReconciler(mgr ctrl.Manager) *DNSRecordReconciler {
 	}
 }
 
+func (r *DNSRecordReconciler) getDNSManager(domain string) (DNSManager, error) {
+	dnsMgr, err := GetDNSManagerForDomain(r.ctx, r.Client, domain)
+	if err != nil || dnsMgr != nil {
+		return dnsMgr, err
+	}
+
+	return r.dnsMgr, nil
+}
+
 // if this annotation is marked with true, will not delete DNS record at cloudflare
 const SkipRemoveRecordOnDeleteAnnotation = "skip-remove-record-on-delete"
 
 // +kubebuilder:rbac:groups=core.kalm.dev,resources=dnsrecords,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core.kalm.dev,resources=dnsrecords/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core.kalm.dev,resources=dnsproviders,verbs=get;list;watch
 
 func (r *DNSRecordReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	log := r.Log.WithValues("dnsrecord", req.NamespacedName)
 
-	if r.dnsMgr == nil {
-		log.Info("dnsMgr not initialized, reconcile skipped")
-		return ctrl.Result{}, nil
-	}
-
 	record := v1alpha1.DNSRecord{}
 	if err := r.Get(r.ctx, client.ObjectKey{Name: req.Name}, &record); err != nil {
 		if errors.IsNotFound(err) {
@@ -76,6 +94,24 @@ func (r *DNSRecordReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 
 	copied := record.DeepCopy()
 
+	dnsMgr, err := r.getDNSManager(record.Spec.Domain)
+	if err != nil {
+		r.EmitWarningEvent(&record, err, "fail to init dns manager")
+		return ctrl.Result{}, err
+	}
+
+	if dnsMgr == nil {
+		log.Info("no dns provider for the domain, reconcile skipped", "domain", record.Spec.Domain)
+
+		// the record can't be removed anymore, don't block the deletion
+		if record.DeletionTimestamp != nil && utils.ContainsString(copied.Finalizers, DNSRecordFinalizer) {
+			copied.Finalizers = utils.RemoveString(copied.Finalizers, DNSRecordFinalizer)
+			return ctrl.Result{}, r.Update(r.ctx, copied)
+		}
+
+		return ctrl.Result{}, nil
+	}
+
 	if record.DeletionTimestamp != nil {
 		skipRemoveRecord := false
 		if record.Annotations[SkipRemoveRecordOnDeleteAnnotation] == "true" {
@@ -83,14 +119,14 @@ func (r *DNSRecordReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		}
 
 		if !skipRemoveRecord {
-		  // ensure DNS Record is deleted before deletion of CDR
-		  if exist, err := r.dnsMgr.Exist(record.Spec.DNSType, record.Spec.Domain, record.Spec.DNSTarget); err != nil {
-		  	return ctrl.Result{}, err
-		  } else if exist {
-		  	if err := r.dnsMgr.DeleteDNSRecord(record.Spec.DNSType, record.Spec.Domain); err != nil {
-		  		return ctrl.Result{}, err
-		  	}
-		  }
+			// ensure DNS Record is deleted before deletion of CDR
+			if records, err := getDNSRecordSet(dnsMgr, record.Spec.DNSType, record.Spec.Domain); err != nil {
+				return ctrl.Result{}, err
+			} else if len(records) > 0 {
+				if err := dnsMgr.DeleteDNSRecord(record.Spec.DNSType, record.Spec.Domain); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
 		}
 
 		copied.Finalizers = utils.RemoveString(copied.Finalizers, DNSRecordFinalizer)
@@ -103,30 +139,108 @@ func (r *DNSRecordReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		}
 	}
 
-	err := r.dnsMgr.UpsertDNSRecord(record.Spec.DNSType, record.Spec.Domain, record.Spec.DNSTarget)
+	contents := getDNSRecordContents(record.Spec)
+
+	actual, err := getDNSRecordSet(dnsMgr, record.Spec.DNSType, record.Spec.Domain)
 	if err != nil {
-		if err == NoCloudflareZoneIDForDomainError {
+		if err == NoDNSZoneForDomainError {
 			log.Error(err, "unknown domain for this dnsManager, ignored", "domain", record.Spec.Domain)
 
 			return ctrl.Result{}, nil
 		}
 
-		log.Error(err, "fail to UpsertDNSRecord", "record", record.Spec)
 		return ctrl.Result{}, err
 	}
 
-	recordExist, err := r.dnsMgr.Exist(record.Spec.DNSType, record.Spec.Domain, record.Spec.DNSTarget)
-	if err != nil {
-		return ctrl.Result{}, err
+	if !isDNSRecordSetSynced(actual, contents, record.Spec.TTL) {
+		// the records were configured, but changed at the dns provider since last check
+		if record.Status.IsConfigured && !utils.IsStringSliceEqual(getDNSRecordSetContents(actual), record.Status.ActualTargets) {
+			now := metav1.Now()
+			copied.Status.LastDriftDetectedAt = &now
+
+			r.Recorder.Eventf(&record, coreV1.EventTypeWarning, "DNSRecordDrift",
+				"records at the dns provider changed to %v, reverting to %v", getDNSRecordSetContents(actual), contents)
+		}
+
+		if err := dnsMgr.UpsertDNSRecord(record.Spec.DNSType, record.Spec.Domain, contents, record.Spec.TTL); err != nil {
+			log.Error(err, "fail to UpsertDNSRecord", "record", record.Spec)
+			return ctrl.Result{}, err
+		}
+
+		if actual, err = getDNSRecordSet(dnsMgr, record.Spec.DNSType, record.Spec.Domain); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	copied.Status.IsConfigured = isDNSRecordSetSynced(actual, contents, record.Spec.TTL)
+	copied.Status.ActualTargets = getDNSRecordSetContents(actual)
+
+	if !equality.Semantic.DeepEqual(record.Status, copied.Status) {
+		if err := r.Status().Update(r.ctx, copied); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// check the records periodically to detect drift
+	return ctrl.Result{RequeueAfter: dnsRecordDriftCheckInterval}, nil
+}
+
+// getDNSRecordContents returns the contents of the record set in the format of DNSRecord.Content
+func getDNSRecordContents(spec v1alpha1.DNSRecordSpec) []string {
+	var rst []string
+
+	for _, target := range spec.GetDNSTargets() {
+		switch spec.DNSType {
+		case v1alpha1.DNSTypeAAAA:
+			// compressed form, as returned by the dns providers
+			if ip := net.ParseIP(target); ip != nil {
+				target = ip.String()
+			}
+		case v1alpha1.DNSTypeCNAME, v1alpha1.DNSTypeNS:
+			target = normalizeDNSName(target)
+		case v1alpha1.DNSTypeMX:
+			target = fmt.Sprintf("%d %s", spec.Priority, normalizeDNSName(target))
+		case v1alpha1.DNSTypeSRV:
+			target = fmt.Sprintf("%d %d %d %s", spec.Priority, spec.Weight, spec.Port, normalizeDNSName(target))
+		}
+
+		rst = append(rst, target)
+	}
+
+	return rst
+}
+
+func getDNSRecordSetContents(records []DNSRecord) []string {
+	rst := make([]string, 0, len(records))
+
+	for _, r := range records {
+		rst = append(rst, r.Content)
+	}
+
+	sort.Strings(rst)
+
+	return rst
+}
+
+// isDNSRecordSetSynced checks if the records have exactly the contents, and the ttl if it's not 0
+func isDNSRecordSetSynced(records []DNSRecord, contents []string, ttl int) bool {
+	for _, r := range records {
+		if ttl != 0 && r.TTL != ttl {
+			return false
+		}
 	}
 
-	copied.Status.IsConfigured = recordExist
+	expected := append([]string{}, contents...)
+	sort.Strings(expected)
 
-	return ctrl.Result{}, r.Status().Update(r.ctx, copied)
+	return utils.IsStringSliceEqual(getDNSRecordSetContents(records), expected)
 }
 
 func (r *DNSRecordReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&corev1alpha1.DNSRecord{}).
+		Watches(&source.Kind{Type: &corev1alpha1.DNSProvider{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: &DNSProviderRecordsMapper{r.BaseReconciler},
+		}).
 		Complete(r)
 }
@@ -17,17 +17,34 @@ package controllers
 
 import (
 	"context"
+	"time"
 
+	"github.com/kalmhq/kalm/controller/utils"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	corev1alpha1 "github.com/kalmhq/kalm/controller/api/v1alpha1"
 )
 
+const (
+	domainVerificationTokenLength = 32
+	// a pending domain is marked as failed if not verified in this duration
+	domainVerificationTimeout = 72 * time.Hour
+
+	domainVerificationPendingCheckInterval = time.Minute
+	domainVerificationFailedCheckInterval  = time.Hour
+)
+
 // DomainReconciler reconciles a Domain object
 type DomainReconciler struct {
 	*BaseReconciler
-	ctx    context.Context
-	dnsMgr DNSManager
+	ctx      context.Context
+	dnsMgr   DNSManager
+	verifier *DomainVerifier
 }
 
 func NewDomainReconciler(mgr ctrl.Manager) *DomainReconciler {
@@ -42,6 +59,7 @@ func NewDomainReconciler(mgr ctrl.Manager) *DomainReconciler {
 		BaseReconciler: NewBaseReconciler(mgr, "Domain"),
 		ctx:            context.Background(),
 		// dnsMgr:         dnsMgr,
+		verifier: NewDomainVerifier(corev1alpha1.GetEnvDomainVerificationResolver()),
 	}
 }
 
@@ -49,8 +67,105 @@ func NewDomainReconciler(mgr ctrl.Manager) *DomainReconciler {
 // +kubebuilder:rbac:groups=core.kalm.dev,resources=domains/status,verbs=get;update;patch
 
 func (r *DomainReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
-	_ = r.Log.WithValues("domain", req.NamespacedName)
-	return ctrl.Result{}, nil
+	log := r.Log.WithValues("domain", req.NamespacedName)
+
+	domain := corev1alpha1.Domain{}
+	if err := r.Get(r.ctx, client.ObjectKey{Name: req.Name}, &domain); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+
+		return ctrl.Result{}, err
+	}
+
+	if domain.DeletionTimestamp != nil || domain.IsVerified() {
+		return ctrl.Result{}, nil
+	}
+
+	copied := domain.DeepCopy()
+
+	// the routes and certs of Domains created before the ownership verification are already in use
+	if !copied.IsVerificationRequired() {
+		now := metav1.Now()
+		copied.Status.VerificationStatus = corev1alpha1.DomainVerificationVerified
+		copied.Status.VerificationMessage = "created before the ownership verification was required"
+		copied.Status.VerifiedAt = &now
+
+		return ctrl.Result{}, r.Status().Update(r.ctx, copied)
+	}
+
+	// issue the token first, so that users can set the TXT record
+	if copied.Status.VerificationToken == "" {
+		copied.Status.VerificationToken = utils.RandString(domainVerificationTokenLength)
+		copied.Status.VerificationStatus = corev1alpha1.DomainVerificationPending
+		copied.Status.VerificationMessage = "waiting for the TXT record " + copied.VerificationTXTRecordName()
+
+		return ctrl.Result{}, r.Status().Update(r.ctx, copied)
+	}
+
+	gatewayHostname, err := r.getIngressGatewayHostname()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	verified, msg, err := r.verifier.Verify(copied, gatewayHostname)
+	if err != nil {
+		log.Error(err, "fail to verify domain", "domain", domain.Spec.Domain)
+		msg = "fail to query the resolver: " + err.Error()
+	}
+
+	now := metav1.Now()
+	copied.Status.LastCheckedAt = &now
+	copied.Status.VerificationMessage = msg
+
+	switch {
+	case verified:
+		copied.Status.VerificationStatus = corev1alpha1.DomainVerificationVerified
+		copied.Status.VerifiedAt = &now
+		copied.Status.FailedAt = nil
+
+		r.EmitNormalEvent(&domain, "DomainVerified", msg)
+	case copied.Status.VerificationStatus != corev1alpha1.DomainVerificationFailed &&
+		now.Sub(domain.CreationTimestamp.Time) > domainVerificationTimeout:
+
+		copied.Status.VerificationStatus = corev1alpha1.DomainVerificationFailed
+		copied.Status.FailedAt = &now
+
+		r.Recorder.Eventf(&domain, coreV1.EventTypeWarning, "DomainVerificationFailed",
+			"domain is not verified in %s: %s", domainVerificationTimeout, msg)
+	}
+
+	if !equality.Semantic.DeepEqual(domain.Status, copied.Status) {
+		if err := r.Status().Update(r.ctx, copied); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	switch copied.Status.VerificationStatus {
+	case corev1alpha1.DomainVerificationVerified:
+		return ctrl.Result{}, nil
+	case corev1alpha1.DomainVerificationFailed:
+		// the records may still be set later
+		return ctrl.Result{RequeueAfter: domainVerificationFailedCheckInterval}, nil
+	default:
+		return ctrl.Result{RequeueAfter: domainVerificationPendingCheckInterval}, nil
+	}
+}
+
+// the hostname of the ingress gateway load balancer, empty if it has an IP only
+func (r *DomainReconciler) getIngressGatewayHostname() (string, error) {
+	var svc coreV1.Service
+	if err := r.Get(r.ctx, corev1alpha1.IngressGatewayServiceName, &svc); err != nil {
+		return "", client.IgnoreNotFound(err)
+	}
+
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ingress.Hostname != "" {
+			return ingress.Hostname, nil
+		}
+	}
+
+	return "", nil
 }
 
 func (r *DomainReconciler) SetupWithManager(mgr ctrl.Manager) error {
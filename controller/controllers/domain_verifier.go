@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/miekg/dns"
+)
+
+// public resolvers are preferred, in-cluster resolvers may cache negative answers for a long time
+const defaultDomainVerificationResolver = "8.8.8.8:53"
+
+// DomainVerifier checks the ownership of a domain by querying a recursive resolver.
+// The domain is verified if the TXT record _kalm-verification.<domain> contains the verification token,
+// or if the domain is a CNAME to the hostname of the ingress gateway load balancer.
+// The dnsTarget in the spec of the Domain is set by users, it's never trusted.
+type DomainVerifier struct {
+	Resolver string
+}
+
+func NewDomainVerifier(resolver string) *DomainVerifier {
+	if resolver == "" {
+		resolver = defaultDomainVerificationResolver
+	}
+
+	if !strings.Contains(resolver, ":") {
+		resolver += ":53"
+	}
+
+	return &DomainVerifier{Resolver: resolver}
+}
+
+// Verify returns whether the domain is verified and a message describing the result.
+// The gatewayHostname is the hostname of the ingress gateway load balancer resolved at check time,
+// it's empty if the load balancer has an IP only, then CNAMEs are not checked.
+// The error is only returned if the resolver can't be reached.
+func (v *DomainVerifier) Verify(domain *v1alpha1.Domain, gatewayHostname string) (bool, string, error) {
+	if domain.Status.VerificationToken != "" {
+		txts, err := v.lookupTXT(domain.VerificationTXTRecordName())
+		if err != nil {
+			return false, "", err
+		}
+
+		for _, txt := range txts {
+			if txt == domain.Status.VerificationToken {
+				return true, fmt.Sprintf("found verification token in TXT record %s", domain.VerificationTXTRecordName()), nil
+			}
+		}
+	}
+
+	if gatewayHostname == "" {
+		return false, fmt.Sprintf("TXT record %s with the verification token is not found", domain.VerificationTXTRecordName()), nil
+	}
+
+	target, err := v.lookupCNAME(domain.Spec.Domain)
+	if err != nil {
+		return false, "", err
+	}
+
+	if target != "" && target == normalizeDNSName(gatewayHostname) {
+		return true, fmt.Sprintf("%s is a CNAME to %s", domain.Spec.Domain, target), nil
+	}
+
+	return false, fmt.Sprintf(
+		"neither TXT record %s with the verification token nor CNAME to %s is found",
+		domain.VerificationTXTRecordName(),
+		gatewayHostname,
+	), nil
+}
+
+func (v *DomainVerifier) lookupTXT(name string) ([]string, error) {
+	answer, err := v.query(name, dns.TypeTXT)
+	if err != nil {
+		return nil, err
+	}
+
+	var rst []string
+	for _, rr := range answer {
+		if txt, ok := rr.(*dns.TXT); ok {
+			rst = append(rst, strings.Join(txt.Txt, ""))
+		}
+	}
+
+	return rst, nil
+}
+
+func (v *DomainVerifier) lookupCNAME(name string) (string, error) {
+	answer, err := v.query(name, dns.TypeCNAME)
+	if err != nil {
+		return "", err
+	}
+
+	for _, rr := range answer {
+		// skip the rest of the CNAME chain
+		if cname, ok := rr.(*dns.CNAME); ok && normalizeDNSName(cname.Hdr.Name) == normalizeDNSName(name) {
+			return normalizeDNSName(cname.Target), nil
+		}
+	}
+
+	return "", nil
+}
+
+func (v *DomainVerifier) query(name string, rrType uint16) ([]dns.RR, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), rrType)
+	msg.RecursionDesired = true
+
+	// tcp avoids truncated responses
+	c := &dns.Client{Net: "tcp", Timeout: 10 * time.Second}
+
+	resp, _, err := c.Exchange(msg, v.Resolver)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.Rcode {
+	case dns.RcodeSuccess:
+		return resp.Answer, nil
+	case dns.RcodeNameError:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("query %s %s failed, rcode: %s", dns.TypeToString[rrType], name, dns.RcodeToString[resp.Rcode])
+	}
+}
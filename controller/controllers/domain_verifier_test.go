@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomainVerifier(t *testing.T) {
+	server, addr := startTestRFC2136Server(t)
+	verifier := NewDomainVerifier(addr)
+
+	domain := &v1alpha1.Domain{
+		Spec: v1alpha1.DomainSpec{
+			Domain:    "www.example.com",
+			DNSType:   v1alpha1.DNSTypeCNAME,
+			DNSTarget: "lb.kalm.dev",
+		},
+		Status: v1alpha1.DomainStatus{
+			VerificationToken: "kalm-token",
+		},
+	}
+
+	verified, _, err := verifier.Verify(domain, "lb.kalm.dev")
+	assert.Nil(t, err)
+	assert.False(t, verified)
+
+	addTestRR(t, server, `_kalm-verification.www.example.com. 300 IN TXT "other-token"`)
+	verified, _, err = verifier.Verify(domain, "lb.kalm.dev")
+	assert.Nil(t, err)
+	assert.False(t, verified)
+
+	addTestRR(t, server, `_kalm-verification.www.example.com. 300 IN TXT "kalm-" "token"`)
+	verified, _, err = verifier.Verify(domain, "lb.kalm.dev")
+	assert.Nil(t, err)
+	assert.True(t, verified)
+
+	// cname to the ingress gateway
+	domain.Spec.Domain = "api.example.com"
+	addTestRR(t, server, "api.example.com. 300 IN CNAME other.kalm.dev.")
+	verified, _, err = verifier.Verify(domain, "lb.kalm.dev")
+	assert.Nil(t, err)
+	assert.False(t, verified)
+
+	domain.Spec.Domain = "app.example.com"
+	addTestRR(t, server, "app.example.com. 300 IN CNAME LB.kalm.dev.")
+	verified, _, err = verifier.Verify(domain, "lb.kalm.dev")
+	assert.Nil(t, err)
+	assert.True(t, verified)
+
+	// the dns target in the spec is set by users, it's not trusted
+	domain.Spec.Domain = "evil.example.com"
+	domain.Spec.DNSTarget = "victim.example.org"
+	addTestRR(t, server, "evil.example.com. 300 IN CNAME victim.example.org.")
+	verified, _, err = verifier.Verify(domain, "lb.kalm.dev")
+	assert.Nil(t, err)
+	assert.False(t, verified)
+
+	// cname is not checked if the ingress gateway has an IP only
+	domain.Spec.Domain = "app.example.com"
+	verified, _, err = verifier.Verify(domain, "")
+	assert.Nil(t, err)
+	assert.False(t, verified)
+}
+
+func addTestRR(t *testing.T, server *testRFC2136Server, s string) {
+	rr, err := dns.NewRR(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server.Lock()
+	defer server.Unlock()
+	server.records = append(server.records, rr)
+}
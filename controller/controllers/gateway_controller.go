@@ -18,6 +18,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	istioNetworkingV1Beta1 "istio.io/api/networking/v1beta1"
 	"istio.io/client-go/pkg/apis/networking/v1beta1"
@@ -30,6 +31,7 @@ import (
 
 	"github.com/kalmhq/kalm/controller/api/v1alpha1"
 	corev1alpha1 "github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/kalmhq/kalm/controller/utils"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
@@ -39,11 +41,13 @@ const (
 
 	HTTPS_GATEWAY_NAME = "kalm-https-gateway"
 	HTTP_GATEWAY_NAME  = "kalm-http-gateway"
+	TCP_GATEWAY_NAME   = "kalm-tcp-gateway"
 )
 
 var (
 	HTTPS_GATEWAY_NAMESPACED_NAME = types.NamespacedName{Namespace: KALM_GATEWAY_NAMESPACE, Name: HTTPS_GATEWAY_NAME}
 	HTTP_GATEWAY_NAMESPACED_NAME  = types.NamespacedName{Namespace: KALM_GATEWAY_NAMESPACE, Name: HTTP_GATEWAY_NAME}
+	TCP_GATEWAY_NAMESPACED_NAME   = types.NamespacedName{Namespace: KALM_GATEWAY_NAMESPACE, Name: TCP_GATEWAY_NAME}
 )
 
 type GatewayReconcilerTask struct {
@@ -107,26 +111,36 @@ func (r *GatewayReconcilerTask) HttpsGateway() error {
 			continue
 		}
 
-		_, secretName := getCertAndCertSecretName(cert)
-		server := &istioNetworkingV1Beta1.Server{
-			Hosts: cert.Spec.Domains,
-			Port: &istioNetworkingV1Beta1.Port{
-				Number:   443,
-				Protocol: "HTTPS",
-				Name:     fmt.Sprintf("https-%s", secretName),
-			},
-			Tls: &istioNetworkingV1Beta1.ServerTLSSettings{
-				Mode:           istioNetworkingV1Beta1.ServerTLSSettings_SIMPLE,
-				CredentialName: secretName,
-			},
-		}
-
-		gw.Spec.Servers = append(gw.Spec.Servers, server)
+		gw.Spec.Servers = append(gw.Spec.Servers, buildHttpsGatewayServer(cert))
 	}
 
 	return r.updateGateway(isCreate, gw)
 }
 
+// certs with a client CA run in mutual TLS mode,
+// the gateway reads the CA from the <credentialName>-cacert secret saved by the httpsCert controller
+func buildHttpsGatewayServer(cert corev1alpha1.HttpsCert) *istioNetworkingV1Beta1.Server {
+	_, secretName := getCertAndCertSecretName(cert)
+
+	mode := istioNetworkingV1Beta1.ServerTLSSettings_SIMPLE
+	if cert.Spec.IsMutualTLS() {
+		mode = istioNetworkingV1Beta1.ServerTLSSettings_MUTUAL
+	}
+
+	return &istioNetworkingV1Beta1.Server{
+		Hosts: cert.Spec.Domains,
+		Port: &istioNetworkingV1Beta1.Port{
+			Number:   443,
+			Protocol: "HTTPS",
+			Name:     fmt.Sprintf("https-%s", secretName),
+		},
+		Tls: &istioNetworkingV1Beta1.ServerTLSSettings{
+			Mode:           mode,
+			CredentialName: secretName,
+		},
+	}
+}
+
 func (r *GatewayReconcilerTask) HttpGateway() error {
 	isCreate := false
 
@@ -161,6 +175,92 @@ func (r *GatewayReconcilerTask) HttpGateway() error {
 	return r.updateGateway(isCreate, gw)
 }
 
+func (r *GatewayReconcilerTask) TcpGateway() error {
+	isCreate := false
+
+	gw := &v1beta1.Gateway{}
+	if err := r.Reader.Get(r.ctx, TCP_GATEWAY_NAMESPACED_NAME, gw); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+
+		isCreate = true
+	}
+
+	gw.Name = TCP_GATEWAY_NAMESPACED_NAME.Name
+	gw.Namespace = TCP_GATEWAY_NAMESPACED_NAME.Namespace
+
+	var routes corev1alpha1.TcpRouteList
+	if err := r.Reader.List(r.ctx, &routes); err != nil {
+		return err
+	}
+
+	if gw.Spec.Selector == nil {
+		gw.Spec.Selector = make(map[string]string)
+	}
+
+	gw.Spec.Selector["istio"] = "ingressgateway"
+	gw.Spec.Servers = buildTcpGatewayServers(routes.Items)
+
+	return r.updateGateway(isCreate, gw)
+}
+
+// each port of tcp routes has a server, tls routes on the same port share the server
+func buildTcpGatewayServers(routes []corev1alpha1.TcpRoute) []*istioNetworkingV1Beta1.Server {
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Name < routes[j].Name })
+
+	portServers := make(map[int]*istioNetworkingV1Beta1.Server)
+	var ports []int
+
+	for _, route := range routes {
+		port := route.Spec.Port
+		server, exist := portServers[port]
+
+		if !exist {
+			server = &istioNetworkingV1Beta1.Server{
+				Port: &istioNetworkingV1Beta1.Port{
+					Number:   uint32(port),
+					Protocol: "TCP",
+					Name:     fmt.Sprintf("tcp-%d", port),
+				},
+				Hosts: []string{"*"},
+			}
+
+			if route.Spec.Protocol == corev1alpha1.TcpRouteProtocolTLS {
+				server.Port.Protocol = "TLS"
+				server.Port.Name = fmt.Sprintf("tls-%d", port)
+				server.Hosts = nil
+				server.Tls = &istioNetworkingV1Beta1.ServerTLSSettings{
+					Mode: istioNetworkingV1Beta1.ServerTLSSettings_PASSTHROUGH,
+				}
+			}
+
+			portServers[port] = server
+			ports = append(ports, port)
+		}
+
+		// conflicts are refused by the webhook, the first route takes the port if there are any
+		if server.Tls == nil || route.Spec.Protocol != corev1alpha1.TcpRouteProtocolTLS {
+			continue
+		}
+
+		for _, host := range route.Spec.Hosts {
+			if !utils.ContainsString(server.Hosts, host) {
+				server.Hosts = append(server.Hosts, host)
+			}
+		}
+	}
+
+	sort.Ints(ports)
+
+	servers := make([]*istioNetworkingV1Beta1.Server, 0, len(ports))
+	for _, port := range ports {
+		servers = append(servers, portServers[port])
+	}
+
+	return servers
+}
+
 func (r *GatewayReconcilerTask) updateGateway(isCreate bool, gw *v1beta1.Gateway) error {
 	if isCreate {
 		if len(gw.Spec.Servers) == 0 {
@@ -202,6 +302,10 @@ func (r *GatewayReconcilerTask) Run(req ctrl.Request) error {
 		return err
 	}
 
+	if err := r.TcpGateway(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -252,5 +356,11 @@ func (r *GatewayReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				ToRequests: &KalmGatewayRequestMapper{r.BaseReconciler},
 			},
 		).
+		Watches(
+			&source.Kind{Type: &corev1alpha1.TcpRoute{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: &KalmGatewayRequestMapper{r.BaseReconciler},
+			},
+		).
 		Complete(r)
 }
@@ -5,7 +5,9 @@ import (
 	"testing"
 
 	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	istioNetworkingV1Beta1 "istio.io/api/networking/v1beta1"
 	"istio.io/client-go/pkg/apis/networking/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -69,3 +71,23 @@ func (suite *GatewayControllerSuite) TestBasicHttpRoute() {
 		return len(gw.Spec.Servers) == 1
 	})
 }
+
+func TestBuildHttpsGatewayServer(t *testing.T) {
+	cert := v1alpha1.HttpsCert{
+		ObjectMeta: v1.ObjectMeta{Name: "example"},
+		Spec: v1alpha1.HttpsCertSpec{
+			Domains: []string{"example.com"},
+		},
+	}
+
+	server := buildHttpsGatewayServer(cert)
+	assert.Equal(t, istioNetworkingV1Beta1.ServerTLSSettings_SIMPLE, server.Tls.Mode)
+	assert.Equal(t, "example", server.Tls.CredentialName)
+	assert.Equal(t, []string{"example.com"}, server.Hosts)
+
+	cert.Spec.ClientCACert = "ca bundle"
+	server = buildHttpsGatewayServer(cert)
+	assert.Equal(t, istioNetworkingV1Beta1.ServerTLSSettings_MUTUAL, server.Tls.Mode)
+	assert.Equal(t, "example", server.Tls.CredentialName)
+	assert.Equal(t, "example-cacert", getClientCACertSecretName(server.Tls.CredentialName))
+}
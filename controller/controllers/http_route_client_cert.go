@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"istio.io/api/networking/v1alpha3"
+	v1alpha32 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+)
+
+const KALM_CLIENT_CERT_SUBJECT_ENVOY_FILTER_NAME = "kalm-client-cert-subject"
+
+// The ingress gateway sets the subject of the verified client certificate to requests of all routes.
+// The value is empty for connections without a client certificate, so envoy won't add the header.
+// Values sent by clients are removed in the routes as the header is one of DANGEROUS_HEADERS,
+// route level headers are processed before the route configuration level ones.
+func buildClientCertSubjectEnvoyFilter() *v1alpha32.EnvoyFilter {
+	return buildIngressGatewayEnvoyFilter(KALM_CLIENT_CERT_SUBJECT_ENVOY_FILTER_NAME, []*v1alpha3.EnvoyFilter_EnvoyConfigObjectPatch{
+		{
+			ApplyTo: v1alpha3.EnvoyFilter_ROUTE_CONFIGURATION,
+			Match: &v1alpha3.EnvoyFilter_EnvoyConfigObjectMatch{
+				Context: v1alpha3.EnvoyFilter_GATEWAY,
+				ObjectTypes: &v1alpha3.EnvoyFilter_EnvoyConfigObjectMatch_RouteConfiguration{
+					RouteConfiguration: &v1alpha3.EnvoyFilter_RouteConfigurationMatch{},
+				},
+			},
+			Patch: &v1alpha3.EnvoyFilter_Patch{
+				Operation: v1alpha3.EnvoyFilter_Patch_MERGE,
+				Value: golangMapToProtoStruct(map[string]interface{}{
+					"request_headers_to_add": []interface{}{
+						map[string]interface{}{
+							"header": map[string]interface{}{
+								"key":   KALM_CLIENT_CERT_SUBJECT_HEADER,
+								"value": "%DOWNSTREAM_PEER_SUBJECT%",
+							},
+							"append": false,
+						},
+					},
+				}),
+			},
+		},
+	})
+}
@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"istio.io/api/networking/v1alpha3"
+)
+
+func TestBuildClientCertSubjectEnvoyFilter(t *testing.T) {
+	filter := buildClientCertSubjectEnvoyFilter()
+
+	assert.Equal(t, KALM_CLIENT_CERT_SUBJECT_ENVOY_FILTER_NAME, filter.Name)
+	assert.Equal(t, istioNamespace, filter.Namespace)
+	assert.Equal(t, "true", filter.Labels[KALM_ROUTE_LABEL])
+	assert.Len(t, filter.Spec.ConfigPatches, 1)
+
+	patch := filter.Spec.ConfigPatches[0]
+	assert.Equal(t, v1alpha3.EnvoyFilter_ROUTE_CONFIGURATION, patch.ApplyTo)
+	assert.Equal(t, v1alpha3.EnvoyFilter_GATEWAY, patch.Match.Context)
+	assert.Equal(t, v1alpha3.EnvoyFilter_Patch_MERGE, patch.Patch.Operation)
+
+	headers := patch.Patch.Value.Fields["request_headers_to_add"].GetListValue().Values
+	assert.Len(t, headers, 1)
+
+	header := headers[0].GetStructValue()
+	assert.False(t, header.Fields["append"].GetBoolValue())
+
+	headerValue := header.Fields["header"].GetStructValue()
+	assert.Equal(t, KALM_CLIENT_CERT_SUBJECT_HEADER, headerValue.Fields["key"].GetStringValue())
+	assert.Equal(t, "%DOWNSTREAM_PEER_SUBJECT%", headerValue.Fields["value"].GetStringValue())
+
+	// clients can't set the header
+	assert.Contains(t, DANGEROUS_HEADERS, KALM_CLIENT_CERT_SUBJECT_HEADER)
+}
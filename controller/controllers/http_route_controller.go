@@ -30,14 +30,20 @@ import (
 	v1alpha32 "istio.io/client-go/pkg/apis/networking/v1alpha3"
 	"istio.io/client-go/pkg/apis/networking/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	coreListers "k8s.io/client-go/listers/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	corev1alpha1 "github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/kalmhq/kalm/controller/lib/files"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
@@ -54,21 +60,38 @@ const KALM_ALLOW_TO_PASS_IF_HAS_BEARER_TOKEN_HEADER = "allow-to-pass-if-has-bear
 
 const KALM_AUTH_EMAIL = "kalm-auth-email"
 
+// the subject of the client certificate verified by the ingress gateway
+const KALM_CLIENT_CERT_SUBJECT_HEADER = "kalm-client-cert-subject"
+const KALM_SSO_GRANTED_CERT_SUBJECTS_HEADER = "kalm-sso-granted-cert-subjects"
+
+// the sidecar appends the identity of the peer of mutual TLS connections to the header,
+// the client cert subject is only trusted if the peer is the ingress gateway
+const XFCC_HEADER = "x-forwarded-client-cert"
+const KALM_INGRESS_GATEWAY_PRINCIPAL = "spiffe://cluster.local/ns/istio-system/sa/istio-ingressgateway-service-account"
+
 var DANGEROUS_HEADERS = []string{
 	KALM_SSO_USERINFO_HEADER,
 	KALM_ALLOW_TO_PASS_IF_HAS_BEARER_TOKEN_HEADER,
 	KALM_ROUTE_HEADER,
 	KALM_SSO_SET_COOKIE_PAYLOAD_HEADER,
 	KALM_AUTH_EMAIL,
+	KALM_CLIENT_CERT_SUBJECT_HEADER,
+	KALM_RATE_LIMIT_SUBJECT_HEADER,
 }
 
 type HttpRouteReconcilerTask struct {
 	*HttpRouteReconciler
-	ctx                       context.Context
-	routes                    []corev1alpha1.HttpRoute
-	gateways                  []v1beta1.Gateway
-	virtualServices           []v1beta1.VirtualService
-	httpsRedirectEnvoyFilters []v1alpha32.EnvoyFilter
+	ctx             context.Context
+	routes          []corev1alpha1.HttpRoute
+	gateways        []v1beta1.Gateway
+	virtualServices []v1beta1.VirtualService
+	envoyFilters    []v1alpha32.EnvoyFilter
+
+	// percentage of traffic to the canary subset of each component host during a rollout
+	canaryWeights map[string]int
+
+	// maintenance page paths of applications in maintenance, the path is empty if the default page is used
+	maintenancePages map[string]string
 }
 
 func getIstioHttpRouteName(route *corev1alpha1.HttpRoute) string {
@@ -95,12 +118,60 @@ func (r *HttpRouteReconcilerTask) buildIstioHttpRoute(route *corev1alpha1.HttpRo
 		},
 	}
 
+	if spec.Headers != nil {
+		if spec.Headers.Request != nil {
+			ops := httpRoute.Headers.Request
+			ops.Add = spec.Headers.Request.Add
+			ops.Remove = append(append([]string{}, ops.Remove...), spec.Headers.Request.Remove...)
+
+			for k, v := range spec.Headers.Request.Set {
+				ops.Set[k] = v
+			}
+		}
+
+		if spec.Headers.Response != nil {
+			httpRoute.Headers.Response = &istioNetworkingV1Beta1.Headers_HeaderOperations{
+				Set:    spec.Headers.Response.Set,
+				Add:    spec.Headers.Response.Add,
+				Remove: spec.Headers.Response.Remove,
+			}
+		}
+	}
+
 	if spec.StripPath {
 		httpRoute.Rewrite = &istioNetworkingV1Beta1.HTTPRewrite{
 			Uri: "/",
 		}
 	}
 
+	// regex rewrite is not supported by istio, it's patched by an envoy filter, see buildPathRewriteEnvoyFilter
+	if spec.Rewrite != nil && (spec.Rewrite.Prefix != "" || spec.Rewrite.Host != "") {
+		if httpRoute.Rewrite == nil {
+			httpRoute.Rewrite = &istioNetworkingV1Beta1.HTTPRewrite{}
+		}
+
+		if spec.Rewrite.Prefix != "" {
+			httpRoute.Rewrite.Uri = spec.Rewrite.Prefix
+		}
+
+		httpRoute.Rewrite.Authority = spec.Rewrite.Host
+	}
+
+	if spec.Redirect != nil {
+		code := spec.Redirect.Code
+		if code == 0 {
+			code = http.StatusMovedPermanently
+		}
+
+		httpRoute.Route = nil
+		httpRoute.Rewrite = nil
+		httpRoute.Redirect = &istioNetworkingV1Beta1.HTTPRedirect{
+			Uri:          spec.Redirect.Path,
+			Authority:    spec.Redirect.Host,
+			RedirectCode: uint32(code),
+		}
+	}
+
 	// Disable 5s timeout bug
 	// if spec.Timeout != nil {
 	// 	httpRoute.Timeout = &protoTypes.Duration{
@@ -256,6 +327,45 @@ func (r *HttpRouteReconcilerTask) buildHttpsRedirectEnvoyFilter(route *corev1alp
 	return filter, nil
 }
 
+func getPathRewriteEnvoyFilterName(route *corev1alpha1.HttpRoute) string {
+	return fmt.Sprintf("path-rewrite-%s", route.Name)
+}
+
+// Istio only supports prefix rewrites, the regex rewrite is merged into the envoy route of the http route.
+func buildPathRewriteEnvoyFilter(route *corev1alpha1.HttpRoute) *v1alpha32.EnvoyFilter {
+	return buildIngressGatewayEnvoyFilter(getPathRewriteEnvoyFilterName(route), []*v1alpha3.EnvoyFilter_EnvoyConfigObjectPatch{
+		{
+			ApplyTo: v1alpha3.EnvoyFilter_HTTP_ROUTE,
+			Match: &v1alpha3.EnvoyFilter_EnvoyConfigObjectMatch{
+				Context: v1alpha3.EnvoyFilter_GATEWAY,
+				ObjectTypes: &v1alpha3.EnvoyFilter_EnvoyConfigObjectMatch_RouteConfiguration{
+					RouteConfiguration: &v1alpha3.EnvoyFilter_RouteConfigurationMatch{
+						Vhost: &v1alpha3.EnvoyFilter_RouteConfigurationMatch_VirtualHostMatch{
+							Route: &v1alpha3.EnvoyFilter_RouteConfigurationMatch_RouteMatch{
+								Name: getIstioHttpRouteName(route),
+							},
+						},
+					},
+				},
+			},
+			Patch: &v1alpha3.EnvoyFilter_Patch{
+				Operation: v1alpha3.EnvoyFilter_Patch_MERGE,
+				Value: golangMapToProtoStruct(map[string]interface{}{
+					"route": map[string]interface{}{
+						"regex_rewrite": map[string]interface{}{
+							"pattern": map[string]interface{}{
+								"google_re2": map[string]interface{}{},
+								"regex":      route.Spec.Rewrite.Regex,
+							},
+							"substitution": route.Spec.Rewrite.Substitution,
+						},
+					},
+				}),
+			},
+		},
+	})
+}
+
 func (r *HttpRouteReconcilerTask) buildIstioHttpRoutes(route *corev1alpha1.HttpRoute) []*istioNetworkingV1Beta1.HTTPRoute {
 	matches := r.BuildMatches(route)
 	res := make([]*istioNetworkingV1Beta1.HTTPRoute, 0)
@@ -326,11 +436,41 @@ func (r *HttpRouteReconcilerTask) Run(ctrl.Request) error {
 	}
 	r.virtualServices = virtualServices.Items
 
-	var httpsRedirectEnvoyFilters v1alpha32.EnvoyFilterList
-	if err := r.Reader.List(r.ctx, &httpsRedirectEnvoyFilters, client.MatchingLabels{KALM_ROUTE_LABEL: "true"}); err != nil {
+	var envoyFilters v1alpha32.EnvoyFilterList
+	if err := r.Reader.List(r.ctx, &envoyFilters, client.MatchingLabels{KALM_ROUTE_LABEL: "true"}); err != nil {
 		return err
 	}
-	r.httpsRedirectEnvoyFilters = httpsRedirectEnvoyFilters.Items
+	r.envoyFilters = envoyFilters.Items
+
+	// only components which are destinations of routes can have their traffic split
+	r.canaryWeights = make(map[string]int)
+	for key := range getDestinationComponents(r.routes) {
+		var component corev1alpha1.Component
+		if err := r.Reader.Get(r.ctx, key, &component); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if weight := getCanaryWeight(&component); weight > 0 {
+			host := fmt.Sprintf("%s.%s.svc.cluster.local", component.Name, component.Namespace)
+			r.canaryWeights[host] = weight
+		}
+	}
+
+	var namespaces corev1.NamespaceList
+	if err := r.Reader.List(r.ctx, &namespaces); err != nil {
+		return err
+	}
+
+	r.maintenancePages = make(map[string]string)
+	for _, namespace := range namespaces.Items {
+		if namespace.Annotations[corev1alpha1.KalmMaintenanceAnnotationName] == corev1alpha1.KalmMaintenanceAnnotationValue {
+			r.maintenancePages[namespace.Name] = namespace.Annotations[corev1alpha1.KalmMaintenancePageAnnotationName]
+		}
+	}
 
 	// Each host will has a virtual service
 	// Kalm will order http route rules, and set them in the virtual service http field.
@@ -403,11 +543,12 @@ func (r *HttpRouteReconcilerTask) Run(ctrl.Request) error {
 		}
 	}
 
-	httpsRedirectFilterMap := make(map[string]*v1alpha32.EnvoyFilter)
+	// https redirect, path rewrite, rate limit, direct response and client cert envoy filters of routes
+	envoyFilterMap := make(map[string]*v1alpha32.EnvoyFilter)
 
-	for i := range r.httpsRedirectEnvoyFilters {
-		filter := r.httpsRedirectEnvoyFilters[i]
-		httpsRedirectFilterMap[filter.Name] = &filter
+	for i := range r.envoyFilters {
+		filter := r.envoyFilters[i]
+		envoyFilterMap[filter.Name] = &filter
 	}
 
 	// Create or delete envoy filter on gateway for routes
@@ -416,7 +557,7 @@ func (r *HttpRouteReconcilerTask) Run(ctrl.Request) error {
 
 		filterName := getHttpsRedirectEnvoyFilterName(&route)
 		if route.Spec.HttpRedirectToHttps {
-			if _, ok := httpsRedirectFilterMap[filterName]; !ok {
+			if _, ok := envoyFilterMap[filterName]; !ok {
 				filter, err := r.buildHttpsRedirectEnvoyFilter(&route)
 
 				if err != nil {
@@ -428,14 +569,84 @@ func (r *HttpRouteReconcilerTask) Run(ctrl.Request) error {
 					return err
 				}
 			} else {
-				delete(httpsRedirectFilterMap, filterName)
+				delete(envoyFilterMap, filterName)
 			}
 		}
 	}
 
+	for i := range r.routes {
+		route := r.routes[i]
+
+		if route.Spec.Rewrite == nil || route.Spec.Rewrite.Regex == "" || route.Spec.Redirect != nil {
+			continue
+		}
+
+		if err := r.saveEnvoyFilter(envoyFilterMap, buildPathRewriteEnvoyFilter(&route)); err != nil {
+			r.EmitWarningEvent(&route, err, "Save Path Rewrite filter Error")
+			return err
+		}
+	}
+
+	var protectedEndpoints corev1alpha1.ProtectedEndpointList
+	if err := r.Reader.List(r.ctx, &protectedEndpoints); err != nil {
+		return err
+	}
+
+	for i := range r.routes {
+		route := r.routes[i]
+
+		if route.Spec.RateLimit == nil {
+			continue
+		}
+
+		if err := r.saveEnvoyFilter(envoyFilterMap, buildRateLimitEnvoyFilter(&route, isRouteProtected(&route, protectedEndpoints.Items))); err != nil {
+			r.EmitWarningEvent(&route, err, "Save Rate Limit filter Error")
+			return err
+		}
+	}
+
+	// the lua filter is always present to remove the rate limit header sent by clients, which is trusted by the sidecars
+	if err := r.saveEnvoyFilter(envoyFilterMap, buildRateLimitLuaEnvoyFilter()); err != nil {
+		return err
+	}
+
+	hasDirectResponse := false
+
+	for i := range r.routes {
+		route := r.routes[i]
+
+		response, err := r.getRouteDirectResponse(&route)
+		if err != nil {
+			return err
+		}
+
+		if response == nil {
+			continue
+		}
+
+		hasDirectResponse = true
+
+		if err := r.saveEnvoyFilter(envoyFilterMap, buildDirectResponseEnvoyFilter(&route, response)); err != nil {
+			r.EmitWarningEvent(&route, err, "Save Direct Response filter Error")
+			return err
+		}
+	}
+
+	if hasDirectResponse {
+		if err := r.saveEnvoyFilter(envoyFilterMap, buildDirectResponseLuaEnvoyFilter()); err != nil {
+			return err
+		}
+	}
+
+	if len(r.routes) > 0 {
+		if err := r.saveEnvoyFilter(envoyFilterMap, buildClientCertSubjectEnvoyFilter()); err != nil {
+			return err
+		}
+	}
+
 	// clean left unused envoy filters
-	for filterName := range httpsRedirectFilterMap {
-		filter := httpsRedirectFilterMap[filterName]
+	for filterName := range envoyFilterMap {
+		filter := envoyFilterMap[filterName]
 
 		if err := r.Delete(r.ctx, filter); err != nil {
 			return err
@@ -456,6 +667,26 @@ func (r *HttpRouteReconcilerTask) Run(ctrl.Request) error {
 	return nil
 }
 
+// create or update the envoy filter, the filter is removed from the existing filters map so that it won't be cleaned
+func (r *HttpRouteReconcilerTask) saveEnvoyFilter(existingFilters map[string]*v1alpha32.EnvoyFilter, filter *v1alpha32.EnvoyFilter) error {
+	existing, ok := existingFilters[filter.Name]
+
+	if !ok {
+		return r.Create(r.ctx, filter)
+	}
+
+	delete(existingFilters, filter.Name)
+
+	if equality.Semantic.DeepEqual(existing.Spec, filter.Spec) {
+		return nil
+	}
+
+	copied := existing.DeepCopy()
+	copied.Spec = filter.Spec
+
+	return r.Update(r.ctx, copied)
+}
+
 func (r *HttpRouteReconcilerTask) SaveVirtualService(host string, routes []*istioNetworkingV1Beta1.HTTPRoute) error {
 	virtualServiceName := fmt.Sprintf("vs-%s", strings.ReplaceAll(strings.ReplaceAll(host, "*", "wildcard"), ".", "-"))
 	virtualServiceNamespace := "kalm-system"
@@ -708,6 +939,45 @@ func (r *HttpRouteReconcilerTask) BuildDestinations(route *corev1alpha1.HttpRout
 		res = append(res, toHttpRouteDestination(destination, weight))
 	}
 
+	return splitCanaryDestinations(res, r.canaryWeights)
+}
+
+// splitCanaryDestinations sends part of the traffic of a component in rollout to its canary subset.
+func splitCanaryDestinations(destinations []*istioNetworkingV1Beta1.HTTPRouteDestination, canaryWeights map[string]int) []*istioNetworkingV1Beta1.HTTPRouteDestination {
+	if len(canaryWeights) == 0 {
+		return destinations
+	}
+
+	res := make([]*istioNetworkingV1Beta1.HTTPRouteDestination, 0, len(destinations))
+	var originWeights []int
+
+	for _, destination := range destinations {
+		canaryWeight, exist := canaryWeights[destination.Destination.Host]
+
+		if !exist {
+			res = append(res, destination)
+			originWeights = append(originWeights, int(destination.Weight)*100)
+			continue
+		}
+
+		if canaryWeight < 100 {
+			stable := destination.DeepCopy()
+			stable.Destination.Subset = RolloutTrackStable
+			res = append(res, stable)
+			originWeights = append(originWeights, int(destination.Weight)*(100-canaryWeight))
+		}
+
+		canary := destination.DeepCopy()
+		canary.Destination.Subset = RolloutTrackCanary
+		res = append(res, canary)
+		originWeights = append(originWeights, int(destination.Weight)*canaryWeight)
+	}
+
+	weights := adjustWeightToSumTo100(originWeights)
+	for i := range res {
+		res[i].Weight = weights[i]
+	}
+
 	return res
 }
 
@@ -772,12 +1042,18 @@ func sum(nums []int32) int32 {
 // HttpRouteReconciler reconciles a HttpRoute object
 type HttpRouteReconciler struct {
 	*BaseReconciler
+
+	// listers of the informers set up by setupErrorPageInformers
+	configMaps coreListers.ConfigMapLister
+	endpoints  coreListers.EndpointsLister
 }
 
 // +kubebuilder:rbac:groups=core.kalm.dev,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core.kalm.dev,resources=httproutes/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=networking.istio.io,resources=virtualservices,verbs=*
 // +kubebuilder:rbac:groups=networking.istio.io,resources=gateways,verbs=*
+// +kubebuilder:rbac:groups=core.kalm.dev,resources=components,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces;endpoints;configmaps,verbs=get;list;watch
 
 func (r *HttpRouteReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	task := &HttpRouteReconcilerTask{
@@ -789,13 +1065,17 @@ func (r *HttpRouteReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 }
 
 func NewHttpRouteReconciler(mgr ctrl.Manager) *HttpRouteReconciler {
-	return &HttpRouteReconciler{NewBaseReconciler(mgr, "HttpRoute")}
+	return &HttpRouteReconciler{BaseReconciler: NewBaseReconciler(mgr, "HttpRoute")}
 }
 
 type WatchAllKalmGateway struct{}
 type WatchAllKalmVirtualService struct{}
 type WatchAllKalmEnvoyFilter struct{}
 type WatchAllService struct{}
+type WatchAllKalmNamespace struct{}
+type WatchAllKalmFiles struct{}
+type WatchAllEndpoints struct{}
+type WatchAllProtectedEndpoint struct{}
 
 func (*WatchAllKalmGateway) Map(object handler.MapObject) []reconcile.Request {
 	gateway, ok := object.Object.(*v1beta1.Gateway)
@@ -830,7 +1110,152 @@ func (*WatchAllService) Map(object handler.MapObject) []reconcile.Request {
 	return []reconcile.Request{{NamespacedName: types.NamespacedName{}}}
 }
 
+// getCanaryWeight returns the percentage of traffic to the canary of the component, 0 if it's not in rollout.
+func getCanaryWeight(component *corev1alpha1.Component) int {
+	if rollout := component.Status.Rollout; rollout.IsInProgress() {
+		return rollout.CanaryWeight
+	}
+
+	return 0
+}
+
+// getDestinationComponents returns the components which are destinations of the routes,
+// their hosts are in the form of <component>.<namespace>.svc.cluster.local with an optional port.
+func getDestinationComponents(routes []corev1alpha1.HttpRoute) map[types.NamespacedName]bool {
+	res := make(map[types.NamespacedName]bool)
+
+	for _, route := range routes {
+		for _, destination := range route.Spec.Destinations {
+			host := destination.Host
+
+			if colon := strings.LastIndexByte(host, ':'); colon != -1 {
+				host = host[:colon]
+			}
+
+			parts := strings.Split(host, ".")
+			if len(parts) != 5 || strings.Join(parts[2:], ".") != "svc.cluster.local" {
+				continue
+			}
+
+			res[types.NamespacedName{Namespace: parts[1], Name: parts[0]}] = true
+		}
+	}
+
+	return res
+}
+
+// WatchRolloutComponentRoutes enqueues the routes only if the changed component is a destination of any of them.
+type WatchRolloutComponentRoutes struct {
+	*BaseReconciler
+}
+
+func (w *WatchRolloutComponentRoutes) Map(object handler.MapObject) []reconcile.Request {
+	component, ok := object.Object.(*corev1alpha1.Component)
+	if !ok {
+		return nil
+	}
+
+	var routes corev1alpha1.HttpRouteList
+	if err := w.Reader.List(context.Background(), &routes); err != nil {
+		w.Log.Error(err, "fail to list http routes")
+		return nil
+	}
+
+	if !getDestinationComponents(routes.Items)[types.NamespacedName{Namespace: component.Namespace, Name: component.Name}] {
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{}}}
+}
+
+// namespaces may be put in or out of maintenance
+func (*WatchAllKalmNamespace) Map(object handler.MapObject) []reconcile.Request {
+	namespace, ok := object.Object.(*corev1.Namespace)
+	if !ok || namespace.Labels[KalmEnableLabelName] != KalmEnableLabelValue {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{}}}
+}
+
+// error pages and maintenance pages are files of the kalm-files config maps
+func (*WatchAllKalmFiles) Map(object handler.MapObject) []reconcile.Request {
+	configMap, ok := object.Object.(*corev1.ConfigMap)
+	if !ok || configMap.Name != files.KALM_CONFIG_MAP_NAME {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{}}}
+}
+
+func (*WatchAllEndpoints) Map(object handler.MapObject) []reconcile.Request {
+	_, ok := object.Object.(*corev1.Endpoints)
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{}}}
+}
+
+// the subject rate limit of routes depends on whether their destinations are protected
+func (*WatchAllProtectedEndpoint) Map(object handler.MapObject) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{}}}
+}
+
+// only the changes of canary weights of components affect the routes
+var canaryWeightChangedPredicate = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		component, ok := e.Object.(*corev1alpha1.Component)
+		return ok && getCanaryWeight(component) > 0
+	},
+	DeleteFunc: func(e event.DeleteEvent) bool {
+		component, ok := e.Object.(*corev1alpha1.Component)
+		return ok && getCanaryWeight(component) > 0
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldComponent, ok := e.ObjectOld.(*corev1alpha1.Component)
+		if !ok {
+			return false
+		}
+
+		newComponent, ok := e.ObjectNew.(*corev1alpha1.Component)
+		if !ok {
+			return false
+		}
+
+		return getCanaryWeight(oldComponent) != getCanaryWeight(newComponent)
+	},
+}
+
+// only the changes between having ready addresses or not affect the error pages of routes,
+// endpoints without ready addresses are as unavailable as not existing ones
+var endpointsReadinessChangedPredicate = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		endpoints, ok := e.Object.(*corev1.Endpoints)
+		return ok && hasReadyAddresses(endpoints)
+	},
+	DeleteFunc: func(e event.DeleteEvent) bool {
+		endpoints, ok := e.Object.(*corev1.Endpoints)
+		return ok && hasReadyAddresses(endpoints)
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldEndpoints, ok := e.ObjectOld.(*corev1.Endpoints)
+		if !ok {
+			return false
+		}
+
+		newEndpoints, ok := e.ObjectNew.(*corev1.Endpoints)
+		if !ok {
+			return false
+		}
+
+		return hasReadyAddresses(oldEndpoints) != hasReadyAddresses(newEndpoints)
+	},
+}
+
 func (r *HttpRouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	configMapInformer, endpointsInformer, err := r.setupErrorPageInformers(mgr)
+	if err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&corev1alpha1.HttpRoute{}).
 		Watches(
@@ -857,5 +1282,37 @@ func (r *HttpRouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				ToRequests: &WatchAllService{},
 			},
 		).
+		Watches(
+			&source.Kind{Type: &corev1alpha1.Component{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: &WatchRolloutComponentRoutes{r.BaseReconciler},
+			},
+			builder.WithPredicates(canaryWeightChangedPredicate),
+		).
+		Watches(
+			&source.Kind{Type: &corev1.Namespace{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: &WatchAllKalmNamespace{},
+			},
+		).
+		Watches(
+			&syncedInformerSource{source.Informer{Informer: configMapInformer}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: &WatchAllKalmFiles{},
+			},
+		).
+		Watches(
+			&syncedInformerSource{source.Informer{Informer: endpointsInformer}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: &WatchAllEndpoints{},
+			},
+			builder.WithPredicates(endpointsReadinessChangedPredicate),
+		).
+		Watches(
+			&source.Kind{Type: &corev1alpha1.ProtectedEndpoint{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: &WatchAllProtectedEndpoint{},
+			},
+		).
 		Complete(r)
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/kalmhq/kalm/controller/api/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	istioNetworkingV1Beta1 "istio.io/api/networking/v1beta1"
 	coreV1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -170,3 +171,113 @@ func TestAdjustWeightToSumTo100(t *testing.T) {
 		assert.True(t, 100 == sum(rst))
 	}
 }
+
+func TestSplitCanaryDestinations(t *testing.T) {
+	destinations := []*istioNetworkingV1Beta1.HTTPRouteDestination{
+		toHttpRouteDestination(v1alpha1.HttpRouteDestination{Host: "web.default.svc.cluster.local:80"}, 80),
+		toHttpRouteDestination(v1alpha1.HttpRouteDestination{Host: "api.default.svc.cluster.local:80"}, 20),
+	}
+
+	rst := splitCanaryDestinations(destinations, map[string]int{"web.default.svc.cluster.local": 10})
+
+	assert.Len(t, rst, 3)
+	assert.Equal(t, RolloutTrackStable, rst[0].Destination.Subset)
+	assert.Equal(t, int32(72), rst[0].Weight)
+	assert.Equal(t, RolloutTrackCanary, rst[1].Destination.Subset)
+	assert.Equal(t, int32(8), rst[1].Weight)
+	assert.Equal(t, "", rst[2].Destination.Subset)
+	assert.Equal(t, int32(20), rst[2].Weight)
+
+	rst = splitCanaryDestinations(destinations, map[string]int{"web.default.svc.cluster.local": 100})
+
+	assert.Len(t, rst, 2)
+	assert.Equal(t, RolloutTrackCanary, rst[0].Destination.Subset)
+	assert.Equal(t, int32(80), rst[0].Weight)
+	assert.Equal(t, int32(100), sum([]int32{rst[0].Weight, rst[1].Weight}))
+}
+
+func TestGetDestinationComponents(t *testing.T) {
+	routes := []v1alpha1.HttpRoute{
+		{Spec: v1alpha1.HttpRouteSpec{Destinations: []v1alpha1.HttpRouteDestination{
+			{Host: "web.default.svc.cluster.local:80"},
+			{Host: "api.default.svc.cluster.local"},
+		}}},
+		{Spec: v1alpha1.HttpRouteSpec{Destinations: []v1alpha1.HttpRouteDestination{
+			{Host: "web.staging.svc.cluster.local:8080"},
+			{Host: "example.com"},
+			{Host: "web.default.svc:80"},
+		}}},
+	}
+
+	assert.Equal(t, map[types.NamespacedName]bool{
+		{Namespace: "default", Name: "web"}: true,
+		{Namespace: "default", Name: "api"}: true,
+		{Namespace: "staging", Name: "web"}: true,
+	}, getDestinationComponents(routes))
+}
+
+func TestBuildIstioHttpRouteHeadersAndRewrite(t *testing.T) {
+	task := &HttpRouteReconcilerTask{}
+	route := &v1alpha1.HttpRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "api"},
+		Spec: v1alpha1.HttpRouteSpec{
+			Destinations: []v1alpha1.HttpRouteDestination{{Host: "api.default.svc.cluster.local:80", Weight: 1}},
+			Headers: &v1alpha1.HttpRouteHeaders{
+				Request: &v1alpha1.HttpRouteHeaderOperations{
+					Set:    map[string]string{"x-forwarded-prefix": "/api"},
+					Remove: []string{"x-debug"},
+				},
+				Response: &v1alpha1.HttpRouteHeaderOperations{
+					Add: map[string]string{"x-frame-options": "DENY"},
+				},
+			},
+			Rewrite: &v1alpha1.HttpRouteRewrite{
+				Prefix: "/v2/",
+				Host:   "api.internal",
+			},
+		},
+	}
+
+	httpRoute := task.buildIstioHttpRoute(route)
+
+	assert.Equal(t, "true", httpRoute.Headers.Request.Set[KALM_ROUTE_HEADER])
+	assert.Equal(t, "/api", httpRoute.Headers.Request.Set["x-forwarded-prefix"])
+	assert.Equal(t, append(append([]string{}, DANGEROUS_HEADERS...), "x-debug"), httpRoute.Headers.Request.Remove)
+	assert.Equal(t, "DENY", httpRoute.Headers.Response.Add["x-frame-options"])
+	assert.Equal(t, "/v2/", httpRoute.Rewrite.Uri)
+	assert.Equal(t, "api.internal", httpRoute.Rewrite.Authority)
+	assert.Nil(t, httpRoute.Redirect)
+
+	route.Spec.Rewrite = &v1alpha1.HttpRouteRewrite{Regex: "^/users/([0-9]+)$", Substitution: "/profile/\\1"}
+	httpRoute = task.buildIstioHttpRoute(route)
+	assert.Nil(t, httpRoute.Rewrite)
+
+	filter := buildPathRewriteEnvoyFilter(route)
+	assert.Equal(t, "path-rewrite-api", filter.Name)
+	regexRewrite := filter.Spec.ConfigPatches[0].Patch.Value.Fields["route"].GetStructValue().Fields["regex_rewrite"].GetStructValue()
+	assert.Equal(t, "^/users/([0-9]+)$", regexRewrite.Fields["pattern"].GetStructValue().Fields["regex"].GetStringValue())
+	assert.Equal(t, "/profile/\\1", regexRewrite.Fields["substitution"].GetStringValue())
+}
+
+func TestBuildIstioHttpRouteRedirect(t *testing.T) {
+	task := &HttpRouteReconcilerTask{}
+	route := &v1alpha1.HttpRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "old"},
+		Spec: v1alpha1.HttpRouteSpec{
+			Redirect: &v1alpha1.HttpRouteRedirect{
+				Host: "new.example.com",
+				Path: "/welcome",
+			},
+		},
+	}
+
+	httpRoute := task.buildIstioHttpRoute(route)
+
+	assert.Nil(t, httpRoute.Route)
+	assert.Equal(t, "new.example.com", httpRoute.Redirect.Authority)
+	assert.Equal(t, "/welcome", httpRoute.Redirect.Uri)
+	assert.Equal(t, uint32(301), httpRoute.Redirect.RedirectCode)
+
+	route.Spec.Redirect.Code = 302
+	assert.Equal(t, uint32(302), task.buildIstioHttpRoute(route).Redirect.RedirectCode)
+}
@@ -0,0 +1,310 @@
+package controllers
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	v1alpha32 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	coreInformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	coreListers "k8s.io/client-go/listers/core/v1"
+	toolsCache "k8s.io/client-go/tools/cache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	corev1alpha1 "github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/kalmhq/kalm/controller/lib/files"
+	"github.com/kalmhq/kalm/controller/utils"
+)
+
+// the lua filter shared by all routes with a direct response, it's only present if at least one route needs it
+const KALM_DIRECT_RESPONSE_ENVOY_FILTER_NAME = "kalm-direct-response"
+
+// the key of the route metadata read by the direct response lua script
+const KALM_DIRECT_RESPONSE_METADATA_KEY = "kalm_direct_response"
+
+// Istio 1.7 has no direct response in virtual services, and envoy limits the body of route direct responses to 4KB.
+// So routes in maintenance or without available destinations are responded by a lua filter on the ingress gateway.
+const directResponseLuaScript = `function envoy_on_request(request_handle)
+  local response = request_handle:metadata():get("` + KALM_DIRECT_RESPONSE_METADATA_KEY + `")
+  if response == nil then
+    return
+  end
+
+  request_handle:respond({
+    [":status"] = tostring(response.status),
+    ["content-type"] = response.content_type,
+    ["cache-control"] = "no-store",
+  }, response.body)
+end
+`
+
+const defaultMaintenancePage = `<!DOCTYPE html>
+<html>
+<head><title>Under Maintenance</title></head>
+<body><h1>Under Maintenance</h1><p>The service is under maintenance, please try again later.</p></body>
+</html>
+`
+
+const defaultErrorPage = `<!DOCTYPE html>
+<html>
+<head><title>Service Unavailable</title></head>
+<body><h1>Service Unavailable</h1><p>The service is temporarily unavailable, please try again later.</p></body>
+</html>
+`
+
+const defaultErrorPageContentType = "text/html; charset=utf-8"
+
+type routeDirectResponse struct {
+	status      int
+	contentType string
+	body        string
+}
+
+func getDirectResponseEnvoyFilterName(route *corev1alpha1.HttpRoute) string {
+	return fmt.Sprintf("direct-response-%s", route.Name)
+}
+
+func buildDirectResponseLuaEnvoyFilter() *v1alpha32.EnvoyFilter {
+	return buildIngressGatewayLuaEnvoyFilter(KALM_DIRECT_RESPONSE_ENVOY_FILTER_NAME, directResponseLuaScript)
+}
+
+func buildDirectResponseEnvoyFilter(route *corev1alpha1.HttpRoute, response *routeDirectResponse) *v1alpha32.EnvoyFilter {
+	return buildRouteLuaMetadataEnvoyFilter(getDirectResponseEnvoyFilterName(route), route, KALM_DIRECT_RESPONSE_METADATA_KEY, map[string]interface{}{
+		"status":       response.status,
+		"content_type": response.contentType,
+		"body":         response.body,
+	})
+}
+
+// destination hosts are in the form of <service>.<namespace>.svc.cluster.local[:port]
+func parseDestinationHost(host string) (service, namespace string, ok bool) {
+	host = strings.Split(host, ":")[0]
+
+	if !strings.HasSuffix(host, ".svc.cluster.local") {
+		return "", "", false
+	}
+
+	parts := strings.Split(host, ".")
+	if len(parts) != 5 {
+		return "", "", false
+	}
+
+	return parts[0], parts[1], true
+}
+
+func getDestinationNamespaces(route *corev1alpha1.HttpRoute) []string {
+	var namespaces []string
+
+	for _, destination := range route.Spec.Destinations {
+		_, namespace, ok := parseDestinationHost(destination.Host)
+
+		if !ok {
+			continue
+		}
+
+		if !utils.ContainsString(namespaces, namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+
+	sort.Strings(namespaces)
+
+	return namespaces
+}
+
+// A route is in maintenance if all applications of its destinations are in maintenance.
+// Otherwise, if the route has an error page and no destination has ready endpoints, the error page is responded.
+func (r *HttpRouteReconcilerTask) getRouteDirectResponse(route *corev1alpha1.HttpRoute) (*routeDirectResponse, error) {
+	namespaces := getDestinationNamespaces(route)
+
+	if len(namespaces) > 0 {
+		inMaintenance := true
+
+		for _, namespace := range namespaces {
+			if _, ok := r.maintenancePages[namespace]; !ok {
+				inMaintenance = false
+				break
+			}
+		}
+
+		if inMaintenance {
+			return r.buildMaintenanceResponse(route, namespaces[0])
+		}
+	}
+
+	if route.Spec.ErrorPage == nil {
+		return nil, nil
+	}
+
+	available, err := r.hasAvailableDestination(route)
+	if err != nil || available {
+		return nil, err
+	}
+
+	return r.buildErrorPageResponse(route, route.Spec.ErrorPage.Namespace, route.Spec.ErrorPage.Path, defaultErrorPage)
+}
+
+// the maintenance page of the application takes precedence over the error page of the route
+func (r *HttpRouteReconcilerTask) buildMaintenanceResponse(route *corev1alpha1.HttpRoute, namespace string) (*routeDirectResponse, error) {
+	if page := r.maintenancePages[namespace]; page != "" {
+		return r.buildErrorPageResponse(route, namespace, page, defaultMaintenancePage)
+	}
+
+	if route.Spec.ErrorPage != nil {
+		return r.buildErrorPageResponse(route, route.Spec.ErrorPage.Namespace, route.Spec.ErrorPage.Path, defaultMaintenancePage)
+	}
+
+	return &routeDirectResponse{
+		status:      http.StatusServiceUnavailable,
+		contentType: defaultErrorPageContentType,
+		body:        defaultMaintenancePage,
+	}, nil
+}
+
+// The default page is used if the file doesn't exist in the kalm-files config map.
+func (r *HttpRouteReconcilerTask) buildErrorPageResponse(route *corev1alpha1.HttpRoute, namespace, pagePath, defaultPage string) (*routeDirectResponse, error) {
+	response := &routeDirectResponse{
+		status:      http.StatusServiceUnavailable,
+		contentType: defaultErrorPageContentType,
+		body:        defaultPage,
+	}
+
+	configMap, err := r.configMaps.ConfigMaps(namespace).Get(files.KALM_CONFIG_MAP_NAME)
+
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+
+		configMap = &corev1.ConfigMap{}
+	}
+
+	content, exist := configMap.Data[files.EncodeFilePath(pagePath)]
+
+	if !exist || content == files.KALM_DIR_PLACEHOLDER || content == files.KALM_PERSISTENT_DIR_PLACEHOLDER {
+		r.Recorder.Eventf(route, corev1.EventTypeWarning, "ErrorPageNotFound",
+			"file %s is not found in the %s config map of %s, the default page is used", pagePath, files.KALM_CONFIG_MAP_NAME, namespace)
+		return response, nil
+	}
+
+	response.body = content
+
+	if contentType := mime.TypeByExtension(path.Ext(pagePath)); contentType != "" {
+		response.contentType = contentType
+	}
+
+	return response, nil
+}
+
+// destinations without a service or ready endpoints are not available, e.g. the component is scaled to zero
+func (r *HttpRouteReconcilerTask) hasAvailableDestination(route *corev1alpha1.HttpRoute) (bool, error) {
+	for _, destination := range route.Spec.Destinations {
+		service, namespace, ok := parseDestinationHost(destination.Host)
+
+		// external hosts are assumed to be available
+		if !ok {
+			return true, nil
+		}
+
+		var svc corev1.Service
+		if err := r.Get(r.ctx, types.NamespacedName{Namespace: namespace, Name: service}, &svc); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+
+			return false, err
+		}
+
+		// only the endpoints of the services of components are watched, other services are assumed to be available
+		if svc.Labels[KalmLabelManaged] != "true" {
+			return true, nil
+		}
+
+		endpoints, err := r.endpoints.Endpoints(namespace).Get(service)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+
+			return false, err
+		}
+
+		if hasReadyAddresses(endpoints) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func hasReadyAddresses(endpoints *corev1.Endpoints) bool {
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Error pages only depend on the kalm-files config maps and the endpoints of the services of components.
+// The manager cache can't be scoped by selectors, so they are watched and read with informers of their own
+// instead of caching all config maps and endpoints of the cluster.
+func (r *HttpRouteReconciler) setupErrorPageInformers(mgr ctrl.Manager) (configMaps, endpoints toolsCache.SharedIndexInformer, err error) {
+	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	indexers := toolsCache.Indexers{toolsCache.NamespaceIndex: toolsCache.MetaNamespaceIndexFunc}
+
+	configMaps = coreInformers.NewFilteredConfigMapInformer(clientset, metaV1.NamespaceAll, 0, indexers, func(options *metaV1.ListOptions) {
+		options.FieldSelector = fields.OneTermEqualSelector("metadata.name", files.KALM_CONFIG_MAP_NAME).String()
+	})
+
+	// the labels of services are copied to their endpoints
+	endpoints = coreInformers.NewFilteredEndpointsInformer(clientset, metaV1.NamespaceAll, 0, indexers, func(options *metaV1.ListOptions) {
+		options.LabelSelector = labels.SelectorFromSet(labels.Set{KalmLabelManaged: "true"}).String()
+	})
+
+	for _, informer := range []toolsCache.SharedIndexInformer{configMaps, endpoints} {
+		informer := informer
+
+		if err := mgr.Add(manager.RunnableFunc(func(stop <-chan struct{}) error {
+			informer.Run(stop)
+			return nil
+		})); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	r.configMaps = coreListers.NewConfigMapLister(configMaps.GetIndexer())
+	r.endpoints = coreListers.NewEndpointsLister(endpoints.GetIndexer())
+
+	return configMaps, endpoints, nil
+}
+
+// syncedInformerSource makes the controller wait for the informer to be synced before reconciling
+type syncedInformerSource struct {
+	source.Informer
+}
+
+func (s *syncedInformerSource) WaitForSync(stop <-chan struct{}) error {
+	if !toolsCache.WaitForCacheSync(stop, s.Informer.Informer.HasSynced) {
+		return fmt.Errorf("fail to wait for the caches of %s to sync", s.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestParseDestinationHost(t *testing.T) {
+	service, namespace, ok := parseDestinationHost("web.shop.svc.cluster.local:8080")
+	assert.True(t, ok)
+	assert.Equal(t, "web", service)
+	assert.Equal(t, "shop", namespace)
+
+	service, namespace, ok = parseDestinationHost("web.shop.svc.cluster.local")
+	assert.True(t, ok)
+	assert.Equal(t, "web", service)
+	assert.Equal(t, "shop", namespace)
+
+	_, _, ok = parseDestinationHost("example.com:443")
+	assert.False(t, ok)
+}
+
+func TestGetRouteDirectResponseOfMaintenance(t *testing.T) {
+	route := &v1alpha1.HttpRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "shop"},
+		Spec: v1alpha1.HttpRouteSpec{
+			Destinations: []v1alpha1.HttpRouteDestination{
+				{Host: "web.shop.svc.cluster.local:80", Weight: 1},
+				{Host: "api.shop-api.svc.cluster.local:80", Weight: 1},
+			},
+		},
+	}
+
+	task := &HttpRouteReconcilerTask{
+		maintenancePages: map[string]string{"shop": ""},
+	}
+
+	// not all applications of the destinations are in maintenance
+	response, err := task.getRouteDirectResponse(route)
+	assert.Nil(t, err)
+	assert.Nil(t, response)
+
+	task.maintenancePages["shop-api"] = ""
+
+	response, err = task.getRouteDirectResponse(route)
+	assert.Nil(t, err)
+	assert.Equal(t, 503, response.status)
+	assert.Equal(t, defaultErrorPageContentType, response.contentType)
+	assert.Equal(t, defaultMaintenancePage, response.body)
+}
+
+func TestBuildDirectResponseEnvoyFilter(t *testing.T) {
+	route := &v1alpha1.HttpRoute{ObjectMeta: v1.ObjectMeta{Name: "shop"}}
+
+	filter := buildDirectResponseEnvoyFilter(route, &routeDirectResponse{
+		status:      503,
+		contentType: "text/html; charset=utf-8",
+		body:        "<h1>maintenance</h1>",
+	})
+
+	assert.Equal(t, "direct-response-shop", filter.Name)
+	assert.Len(t, filter.Spec.ConfigPatches, 1)
+
+	patch := filter.Spec.ConfigPatches[0]
+	assert.Equal(t, v1alpha3.EnvoyFilter_HTTP_ROUTE, patch.ApplyTo)
+	assert.Equal(t, "kalm-route-shop", patch.Match.GetRouteConfiguration().Vhost.Route.Name)
+
+	config := patch.Patch.Value.
+		Fields["metadata"].GetStructValue().
+		Fields["filter_metadata"].GetStructValue().
+		Fields["envoy.filters.http.lua"].GetStructValue().
+		Fields[KALM_DIRECT_RESPONSE_METADATA_KEY].GetStructValue()
+
+	assert.Equal(t, float64(503), config.Fields["status"].GetNumberValue())
+	assert.Equal(t, "text/html; charset=utf-8", config.Fields["content_type"].GetStringValue())
+	assert.Equal(t, "<h1>maintenance</h1>", config.Fields["body"].GetStringValue())
+
+	lua := buildDirectResponseLuaEnvoyFilter()
+	assert.Equal(t, KALM_DIRECT_RESPONSE_ENVOY_FILTER_NAME, lua.Name)
+	assert.Contains(t,
+		lua.Spec.ConfigPatches[0].Patch.Value.Fields["typed_config"].GetStructValue().Fields["inline_code"].GetStringValue(),
+		`:get("kalm_direct_response")`)
+}
+
+func TestEndpointsReadinessChangedPredicate(t *testing.T) {
+	notReady := &corev1.Endpoints{
+		Subsets: []corev1.EndpointSubset{
+			{NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}},
+		},
+	}
+
+	ready := &corev1.Endpoints{
+		Subsets: []corev1.EndpointSubset{
+			{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}},
+		},
+	}
+
+	assert.False(t, hasReadyAddresses(notReady))
+	assert.True(t, hasReadyAddresses(ready))
+
+	assert.True(t, endpointsReadinessChangedPredicate.Update(event.UpdateEvent{ObjectOld: notReady, ObjectNew: ready}))
+	assert.True(t, endpointsReadinessChangedPredicate.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: notReady}))
+	assert.False(t, endpointsReadinessChangedPredicate.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: ready.DeepCopy()}))
+
+	assert.True(t, endpointsReadinessChangedPredicate.Create(event.CreateEvent{Object: ready}))
+	assert.False(t, endpointsReadinessChangedPredicate.Create(event.CreateEvent{Object: notReady}))
+	assert.True(t, endpointsReadinessChangedPredicate.Delete(event.DeleteEvent{Object: ready}))
+	assert.False(t, endpointsReadinessChangedPredicate.Delete(event.DeleteEvent{Object: notReady}))
+}
@@ -0,0 +1,352 @@
+package controllers
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"istio.io/api/networking/v1alpha3"
+	v1alpha32 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	corev1alpha1 "github.com/kalmhq/kalm/controller/api/v1alpha1"
+)
+
+// the lua filter shared by all rate limited routes, it's always present to remove the KALM_RATE_LIMIT_HEADER of clients
+const KALM_RATE_LIMIT_ENVOY_FILTER_NAME = "kalm-rate-limit"
+
+// the key of the route metadata read by the rate limit lua script
+const KALM_RATE_LIMIT_METADATA_KEY = "kalm_rate_limit"
+
+// the rate limit of requests with the subject key, set by the ingress gateway for the sidecar of the protected endpoint.
+// The value is "<route>,<requests>,<interval>,<client ip>".
+const KALM_RATE_LIMIT_HEADER = "kalm-rate-limit"
+
+// the subject verified by the ext_authz of the protected endpoint, the email of the sso user,
+// the client cert subject, or "anonymous" if the request is let pass without a verified subject.
+const KALM_RATE_LIMIT_SUBJECT_HEADER = "kalm-rate-limit-subject"
+
+const KALM_RATE_LIMIT_ANONYMOUS_SUBJECT = "anonymous"
+
+// number of proxies in front of the ingress gateway, which append the client address to x-forwarded-for.
+// It should be the same as the numTrustedProxies of the gateway topology of istio.
+var rateLimitXffNumTrustedHops = 0
+
+func init() {
+	if hops, err := strconv.Atoi(os.Getenv(corev1alpha1.ENV_KALM_XFF_NUM_TRUSTED_HOPS)); err == nil && hops > 0 {
+		rateLimitXffNumTrustedHops = hops
+	}
+}
+
+// Envoy 1.15 shipped with istio 1.7 has no http local rate limit filter, it's added in envoy 1.16.
+// So the limits are enforced by lua filters, using fixed window counters.
+// The counters live in the lua state of each envoy worker, which are not shared, so a client may make up to
+// requests x workers x replicas of the ingress gateway (or of the protected endpoint) in a window.
+// Windows of routes are evicted once they end, so the ones of removed routes don't pile up.
+const rateLimitLuaCounter = `-- fixed window counters of this worker, keyed by route name
+local windows = {}
+local last_evicted_at = 0
+
+local function evict_ended_windows(now)
+  if now == last_evicted_at then
+    return
+  end
+
+  last_evicted_at = now
+
+  for route, window in pairs(windows) do
+    if window.start + window.interval <= now then
+      windows[route] = nil
+    end
+  end
+end
+
+-- responds 429 if the requests of the key exceed the limit in the current window
+local function limit(request_handle, route, requests, interval, key)
+  local now = os.time()
+  local start = now - now % interval
+
+  evict_ended_windows(now)
+
+  local window = windows[route]
+
+  if window == nil or window.start ~= start then
+    window = { start = start, interval = interval, counts = {} }
+    windows[route] = window
+  end
+
+  local count = (window.counts[key] or 0) + 1
+  window.counts[key] = count
+
+  if count > requests then
+    request_handle:respond({
+      [":status"] = "429",
+      ["retry-after"] = tostring(start + interval - now),
+    }, "rate limit exceeded")
+  end
+end
+`
+
+// The ingress gateway uses the remote address as the trusted client address, it's appended to x-forwarded-for.
+// Entries added by the trusted proxies in front of the gateway are on the right, the ones on the left are set by clients.
+//
+// The subject can't be verified by the ingress gateway, the sso of protected endpoints is checked by their sidecars.
+// So requests of routes with the subject key are passed to the sidecar with the limit in the KALM_RATE_LIMIT_HEADER,
+// which counts them after the ext_authz. Routes to components which are not protected fall back to the client ip.
+const rateLimitLuaScript = rateLimitLuaCounter + `
+local function get_client_ip(headers, trusted_hops)
+  local xff = headers:get("x-forwarded-for")
+  if xff == nil then
+    return nil
+  end
+
+  local entries = {}
+  for entry in string.gmatch(xff, "[^,%s]+") do
+    entries[#entries + 1] = entry
+  end
+
+  return entries[math.max(#entries - trusted_hops, 1)]
+end
+
+function envoy_on_request(request_handle)
+  local headers = request_handle:headers()
+  headers:remove("` + KALM_RATE_LIMIT_HEADER + `")
+
+  local config = request_handle:metadata():get("` + KALM_RATE_LIMIT_METADATA_KEY + `")
+  if config == nil then
+    return
+  end
+
+  local client_ip = get_client_ip(headers, config.trustedHops) or ""
+  local key
+
+  if config.key == "subject" and config.protected then
+    headers:add("` + KALM_RATE_LIMIT_HEADER + `", string.format("%s,%d,%d,%s", config.route, config.requests, config.interval, client_ip))
+    return
+  elseif config.key == "clientIP" or config.key == "subject" then
+    key = client_ip
+  elseif config.key == "header" then
+    key = headers:get(config.header)
+  end
+
+  limit(request_handle, config.route, config.requests, config.interval, key or "")
+end
+`
+
+// The sidecar filter runs after the ext_authz of the protected endpoint, which sets the verified subject.
+// Both headers are removed before the request is sent to the component.
+// The KALM_RATE_LIMIT_HEADER is only trusted if the request is sent by the ingress gateway, which removes the one of clients.
+// Like auth_proxy.LastXFCCPeerURI, the peer is the last element of x-forwarded-client-cert appended by the sidecar.
+const rateLimitSidecarLuaScript = rateLimitLuaCounter + `
+-- splits s by the separator out of double quotes
+local function split_unquoted(s, sep)
+  local parts = {}
+  local quoted = false
+  local escaped = false
+  local start = 1
+
+  for i = 1, #s do
+    local c = s:sub(i, i)
+
+    if escaped then
+      escaped = false
+    elseif c == "\\" then
+      escaped = true
+    elseif c == '"' then
+      quoted = not quoted
+    elseif c == sep and not quoted then
+      parts[#parts + 1] = s:sub(start, i - 1)
+      start = i + 1
+    end
+  end
+
+  parts[#parts + 1] = s:sub(start)
+  return parts
+end
+
+local function xfcc_peer_uri(xfcc)
+  if xfcc == nil or xfcc == "" then
+    return nil
+  end
+
+  local elements = split_unquoted(xfcc, ",")
+
+  for _, pair in ipairs(split_unquoted(elements[#elements], ";")) do
+    local key, value = pair:match("^%s*(%a+)=(.-)%s*$")
+    if key ~= nil and key:upper() == "URI" then
+      return value:match('^"?(.-)"?$')
+    end
+  end
+
+  return nil
+end
+
+function envoy_on_request(request_handle)
+  local headers = request_handle:headers()
+  local config = headers:get("` + KALM_RATE_LIMIT_HEADER + `")
+  local subject = headers:get("` + KALM_RATE_LIMIT_SUBJECT_HEADER + `")
+  local peer = xfcc_peer_uri(headers:get("` + XFCC_HEADER + `"))
+
+  headers:remove("` + KALM_RATE_LIMIT_HEADER + `")
+  headers:remove("` + KALM_RATE_LIMIT_SUBJECT_HEADER + `")
+
+  if config == nil or peer ~= "` + KALM_INGRESS_GATEWAY_PRINCIPAL + `" then
+    return
+  end
+
+  local route, requests, interval, client_ip = string.match(config, "^([^,]+),(%d+),(%d+),(.*)$")
+  if route == nil then
+    return
+  end
+
+  local key
+  if subject == nil or subject == "" or subject == "` + KALM_RATE_LIMIT_ANONYMOUS_SUBJECT + `" then
+    key = "ip:" .. client_ip
+  else
+    key = "subject:" .. subject
+  end
+
+  limit(request_handle, route, tonumber(requests), tonumber(interval), key)
+end
+`
+
+func getRateLimitEnvoyFilterName(route *corev1alpha1.HttpRoute) string {
+	return fmt.Sprintf("rate-limit-%s", route.Name)
+}
+
+func buildIngressGatewayEnvoyFilter(name string, patches []*v1alpha3.EnvoyFilter_EnvoyConfigObjectPatch) *v1alpha32.EnvoyFilter {
+	return &v1alpha32.EnvoyFilter{
+		ObjectMeta: metaV1.ObjectMeta{
+			Namespace: istioNamespace,
+			Name:      name,
+			Labels: map[string]string{
+				KALM_ROUTE_LABEL: "true",
+			},
+		},
+		Spec: v1alpha3.EnvoyFilter{
+			WorkloadSelector: &v1alpha3.WorkloadSelector{
+				Labels: map[string]string{
+					"app": "istio-ingressgateway",
+				},
+			},
+			ConfigPatches: patches,
+		},
+	}
+}
+
+// The lua filter is inserted before the router of all http listeners of the ingress gateway.
+// Requests of routes without rate limit metadata are passed through.
+func buildRateLimitLuaEnvoyFilter() *v1alpha32.EnvoyFilter {
+	return buildIngressGatewayLuaEnvoyFilter(KALM_RATE_LIMIT_ENVOY_FILTER_NAME, rateLimitLuaScript)
+}
+
+func buildIngressGatewayLuaEnvoyFilter(name, script string) *v1alpha32.EnvoyFilter {
+	return buildIngressGatewayEnvoyFilter(name, []*v1alpha3.EnvoyFilter_EnvoyConfigObjectPatch{
+		{
+			ApplyTo: v1alpha3.EnvoyFilter_HTTP_FILTER,
+			Match: &v1alpha3.EnvoyFilter_EnvoyConfigObjectMatch{
+				Context: v1alpha3.EnvoyFilter_GATEWAY,
+				ObjectTypes: &v1alpha3.EnvoyFilter_EnvoyConfigObjectMatch_Listener{
+					Listener: &v1alpha3.EnvoyFilter_ListenerMatch{
+						FilterChain: &v1alpha3.EnvoyFilter_ListenerMatch_FilterChainMatch{
+							Filter: &v1alpha3.EnvoyFilter_ListenerMatch_FilterMatch{
+								Name: "envoy.http_connection_manager",
+								SubFilter: &v1alpha3.EnvoyFilter_ListenerMatch_SubFilterMatch{
+									Name: "envoy.router",
+								},
+							},
+						},
+					},
+				},
+			},
+			Patch: &v1alpha3.EnvoyFilter_Patch{
+				Operation: v1alpha3.EnvoyFilter_Patch_INSERT_BEFORE,
+				Value: golangMapToProtoStruct(map[string]interface{}{
+					"name": "envoy.filters.http.lua",
+					"typed_config": map[string]interface{}{
+						"@type":       "type.googleapis.com/envoy.extensions.filters.http.lua.v3.Lua",
+						"inline_code": script,
+					},
+				}),
+			},
+		},
+	})
+}
+
+// The rate limit of a route is passed to the lua filter as route metadata.
+// The route match has no port number, so both http and https routes are limited.
+// Protected routes are the ones whose destinations are all protected endpoints, see isRouteProtected.
+func buildRateLimitEnvoyFilter(route *corev1alpha1.HttpRoute, protected bool) *v1alpha32.EnvoyFilter {
+	rateLimit := route.Spec.RateLimit
+
+	return buildRouteLuaMetadataEnvoyFilter(getRateLimitEnvoyFilterName(route), route, KALM_RATE_LIMIT_METADATA_KEY, map[string]interface{}{
+		"route":       route.Name,
+		"requests":    rateLimit.Requests,
+		"interval":    rateLimit.IntervalSeconds,
+		"key":         string(rateLimit.Key),
+		"header":      rateLimit.HeaderName,
+		"trustedHops": rateLimitXffNumTrustedHops,
+		"protected":   protected,
+	})
+}
+
+// the subject of requests is only verified if the destinations have the ext_authz of protected endpoints
+func isRouteProtected(route *corev1alpha1.HttpRoute, endpoints []corev1alpha1.ProtectedEndpoint) bool {
+	if len(route.Spec.Destinations) == 0 {
+		return false
+	}
+
+	for _, destination := range route.Spec.Destinations {
+		service, namespace, ok := parseDestinationHost(destination.Host)
+		if !ok {
+			return false
+		}
+
+		protected := false
+		for _, endpoint := range endpoints {
+			if endpoint.Namespace == namespace && endpoint.Spec.EndpointName == service {
+				protected = true
+				break
+			}
+		}
+
+		if !protected {
+			return false
+		}
+	}
+
+	return true
+}
+
+// the value can be read by lua filters with request_handle:metadata():get(key)
+func buildRouteLuaMetadataEnvoyFilter(name string, route *corev1alpha1.HttpRoute, key string, value map[string]interface{}) *v1alpha32.EnvoyFilter {
+	return buildIngressGatewayEnvoyFilter(name, []*v1alpha3.EnvoyFilter_EnvoyConfigObjectPatch{
+		{
+			ApplyTo: v1alpha3.EnvoyFilter_HTTP_ROUTE,
+			Match: &v1alpha3.EnvoyFilter_EnvoyConfigObjectMatch{
+				Context: v1alpha3.EnvoyFilter_GATEWAY,
+				ObjectTypes: &v1alpha3.EnvoyFilter_EnvoyConfigObjectMatch_RouteConfiguration{
+					RouteConfiguration: &v1alpha3.EnvoyFilter_RouteConfigurationMatch{
+						Vhost: &v1alpha3.EnvoyFilter_RouteConfigurationMatch_VirtualHostMatch{
+							Route: &v1alpha3.EnvoyFilter_RouteConfigurationMatch_RouteMatch{
+								Name: getIstioHttpRouteName(route),
+							},
+						},
+					},
+				},
+			},
+			Patch: &v1alpha3.EnvoyFilter_Patch{
+				Operation: v1alpha3.EnvoyFilter_Patch_MERGE,
+				Value: golangMapToProtoStruct(map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"filter_metadata": map[string]interface{}{
+							"envoy.filters.http.lua": map[string]interface{}{
+								key: value,
+							},
+						},
+					},
+				}),
+			},
+		},
+	})
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"istio.io/api/networking/v1alpha3"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBuildRateLimitEnvoyFilter(t *testing.T) {
+	route := &v1alpha1.HttpRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "api"},
+		Spec: v1alpha1.HttpRouteSpec{
+			RateLimit: &v1alpha1.HttpRouteRateLimit{
+				Requests:        10,
+				IntervalSeconds: 60,
+				Key:             v1alpha1.HttpRouteRateLimitKeyHeader,
+				HeaderName:      "x-api-key",
+			},
+		},
+	}
+
+	filter := buildRateLimitEnvoyFilter(route, false)
+
+	assert.Equal(t, "rate-limit-api", filter.Name)
+	assert.Equal(t, istioNamespace, filter.Namespace)
+	assert.Equal(t, "true", filter.Labels[KALM_ROUTE_LABEL])
+	assert.Len(t, filter.Spec.ConfigPatches, 1)
+
+	patch := filter.Spec.ConfigPatches[0]
+	assert.Equal(t, v1alpha3.EnvoyFilter_HTTP_ROUTE, patch.ApplyTo)
+
+	routeConfiguration := patch.Match.GetRouteConfiguration()
+	assert.Equal(t, uint32(0), routeConfiguration.PortNumber)
+	assert.Equal(t, "kalm-route-api", routeConfiguration.Vhost.Route.Name)
+
+	config := patch.Patch.Value.
+		Fields["metadata"].GetStructValue().
+		Fields["filter_metadata"].GetStructValue().
+		Fields["envoy.filters.http.lua"].GetStructValue().
+		Fields[KALM_RATE_LIMIT_METADATA_KEY].GetStructValue()
+
+	assert.Equal(t, "api", config.Fields["route"].GetStringValue())
+	assert.Equal(t, float64(10), config.Fields["requests"].GetNumberValue())
+	assert.Equal(t, float64(60), config.Fields["interval"].GetNumberValue())
+	assert.Equal(t, "header", config.Fields["key"].GetStringValue())
+	assert.Equal(t, "x-api-key", config.Fields["header"].GetStringValue())
+	assert.Equal(t, float64(0), config.Fields["trustedHops"].GetNumberValue())
+	assert.False(t, config.Fields["protected"].GetBoolValue())
+}
+
+func TestIsRouteProtected(t *testing.T) {
+	endpoints := []v1alpha1.ProtectedEndpoint{
+		{
+			ObjectMeta: v1.ObjectMeta{Namespace: "prod", Name: "api"},
+			Spec:       v1alpha1.ProtectedEndpointSpec{EndpointName: "api"},
+		},
+	}
+
+	route := &v1alpha1.HttpRoute{
+		Spec: v1alpha1.HttpRouteSpec{
+			Destinations: []v1alpha1.HttpRouteDestination{
+				{Host: "api.prod.svc.cluster.local:8080", Weight: 1},
+			},
+		},
+	}
+
+	assert.True(t, isRouteProtected(route, endpoints))
+
+	// subjects of requests to the web component are not verified
+	route.Spec.Destinations = append(route.Spec.Destinations, v1alpha1.HttpRouteDestination{Host: "web.prod.svc.cluster.local:80", Weight: 1})
+	assert.False(t, isRouteProtected(route, endpoints))
+
+	route.Spec.Destinations = []v1alpha1.HttpRouteDestination{{Host: "api.staging.svc.cluster.local:8080", Weight: 1}}
+	assert.False(t, isRouteProtected(route, endpoints))
+
+	route.Spec.Destinations = nil
+	assert.False(t, isRouteProtected(route, endpoints))
+}
+
+func TestBuildRateLimitLuaEnvoyFilter(t *testing.T) {
+	filter := buildRateLimitLuaEnvoyFilter()
+
+	assert.Equal(t, KALM_RATE_LIMIT_ENVOY_FILTER_NAME, filter.Name)
+	assert.Len(t, filter.Spec.ConfigPatches, 1)
+
+	patch := filter.Spec.ConfigPatches[0]
+	assert.Equal(t, v1alpha3.EnvoyFilter_HTTP_FILTER, patch.ApplyTo)
+	assert.Equal(t, v1alpha3.EnvoyFilter_GATEWAY, patch.Match.Context)
+	assert.Equal(t, v1alpha3.EnvoyFilter_Patch_INSERT_BEFORE, patch.Patch.Operation)
+
+	typedConfig := patch.Patch.Value.Fields["typed_config"].GetStructValue()
+	assert.Contains(t, typedConfig.Fields["inline_code"].GetStringValue(), `:get("kalm_rate_limit")`)
+	assert.Contains(t, typedConfig.Fields["inline_code"].GetStringValue(), `headers:remove("kalm-rate-limit")`)
+}
+
+func TestRateLimitSidecarLuaScript(t *testing.T) {
+	// the rate limit header is only trusted if the peer is the ingress gateway
+	assert.Contains(t, rateLimitSidecarLuaScript, `xfcc_peer_uri(headers:get("x-forwarded-client-cert"))`)
+	assert.Contains(t, rateLimitSidecarLuaScript, `peer ~= "`+KALM_INGRESS_GATEWAY_PRINCIPAL+`"`)
+	assert.Contains(t, rateLimitSidecarLuaScript, `headers:remove("kalm-rate-limit")`)
+}
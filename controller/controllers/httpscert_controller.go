@@ -54,21 +54,43 @@ func getCertAndCertSecretName(httpsCert corev1alpha1.HttpsCert) (certName string
 	return name, certSecretName
 }
 
+// the istio ingress gateway loads the CA to verify client certificates
+// from the <credentialName>-cacert secret in mutual TLS mode
+func getClientCACertSecretName(certSecretName string) string {
+	return certSecretName + "-cacert"
+}
+
+const SecretKeyOfClientCACert = "cacert"
+
 const istioNamespace = "istio-system"
 
 // +kubebuilder:rbac:groups=core.kalm.dev,resources=httpscerts,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core.kalm.dev,resources=httpscerts/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 
 func (r *HttpsCertReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	var httpsCert corev1alpha1.HttpsCert
 	if err := r.Get(r.ctx, req.NamespacedName, &httpsCert); err != nil {
+		if errors.IsNotFound(err) {
+			httpsCertDaysToExpiry.DeleteLabelValues(req.Name)
+		}
+
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	previousConditions := httpsCert.DeepCopy().Status.Conditions
 	_, certSecretName := getCertAndCertSecretName(httpsCert)
 
+	if err := r.reconcileClientCACertSecret(httpsCert, certSecretName); err != nil {
+		r.Recorder.Event(&httpsCert, corev1.EventTypeWarning, "Fail to save client CA cert secret", err.Error())
+		return ctrl.Result{}, err
+	}
+
 	var err error
+	var requeueAfter time.Duration
+	now := time.Now()
+
 	// self-managed httpsCert has only secret, no corresponding cmv1alpha2.Certificate
 	if httpsCert.Spec.IsSelfManaged {
 		// check if secret present
@@ -105,6 +127,7 @@ func (r *HttpsCertReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 			}
 		}
 
+		requeueAfter = setHttpsCertExpiryConditions(&httpsCert, nil, now)
 		r.Status().Update(r.ctx, &httpsCert)
 	} else {
 		// if is wildcard cert, check if acme-dns is ready
@@ -121,10 +144,12 @@ func (r *HttpsCertReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 			}
 		}
 
-		err = r.reconcileForAutoManagedHttpsCert(r.ctx, httpsCert)
+		requeueAfter, err = r.reconcileForAutoManagedHttpsCert(r.ctx, &httpsCert, now)
 	}
 
-	return ctrl.Result{}, err
+	r.reportHttpsCertExpiry(&httpsCert, previousConditions, now)
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, err
 }
 
 func NewHttpsCertReconciler(mgr ctrl.Manager) *HttpsCertReconciler {
@@ -170,16 +195,17 @@ func (r *HttpsCertReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&corev1alpha1.HttpsCert{}).
 		Owns(&cmv1alpha2.Certificate{}).
+		Owns(&corev1.Secret{}).
 		Watches(genSourceForObject(&corev1alpha1.ACMEServer{}), &handler.EnqueueRequestsFromMapFunc{
 			ToRequests: ACMEServerMapper{*r},
 		}).
 		Complete(r)
 }
 
-func (r *HttpsCertReconciler) reconcileForAutoManagedHttpsCert(ctx context.Context, httpsCert corev1alpha1.HttpsCert) error {
-	certName, certSecretName := getCertAndCertSecretName(httpsCert)
+func (r *HttpsCertReconciler) reconcileForAutoManagedHttpsCert(ctx context.Context, httpsCert *corev1alpha1.HttpsCert, now time.Time) (time.Duration, error) {
+	certName, certSecretName := getCertAndCertSecretName(*httpsCert)
 
-	dnsNames := getDNSNames(httpsCert)
+	dnsNames := getDNSNames(*httpsCert)
 	commonName := pickCommonName(dnsNames)
 
 	desiredCert := cmv1alpha2.Certificate{
@@ -208,7 +234,7 @@ func (r *HttpsCertReconciler) reconcileForAutoManagedHttpsCert(ctx context.Conte
 
 	if err != nil {
 		if !errors.IsNotFound(err) {
-			return err
+			return 0, err
 		}
 
 		isNew = true
@@ -218,8 +244,8 @@ func (r *HttpsCertReconciler) reconcileForAutoManagedHttpsCert(ctx context.Conte
 	}
 
 	if isNew {
-		if err := ctrl.SetControllerReference(&httpsCert, &cert, r.Scheme); err != nil {
-			return err
+		if err := ctrl.SetControllerReference(httpsCert, &cert, r.Scheme); err != nil {
+			return 0, err
 		}
 
 		err = r.Create(ctx, &cert)
@@ -254,7 +280,7 @@ func (r *HttpsCertReconciler) reconcileForAutoManagedHttpsCert(ctx context.Conte
 						&certSec,
 					)
 					if err != nil {
-						return err
+						return 0, err
 					}
 
 					cert, interCert, err := ParseCert(string(certSec.Data[SecretKeyOfTLSCert]))
@@ -268,6 +294,11 @@ func (r *HttpsCertReconciler) reconcileForAutoManagedHttpsCert(ctx context.Conte
 					// cert is not ready yet, reset fields
 					httpsCert.Status.ExpireTimestamp = 0
 					httpsCert.Status.IsSignedByPublicTrustedCA = false
+
+					// the previous cert is still served if the renewal fails
+					if cert.Status.NotAfter != nil {
+						httpsCert.Status.ExpireTimestamp = cert.Status.NotAfter.Unix()
+					}
 				}
 
 				break
@@ -275,9 +306,61 @@ func (r *HttpsCertReconciler) reconcileForAutoManagedHttpsCert(ctx context.Conte
 		}
 	}
 
-	r.Status().Update(ctx, &httpsCert)
+	requeueAfter := setHttpsCertExpiryConditions(httpsCert, cert.Status.LastFailureTime, now)
+	r.Status().Update(ctx, httpsCert)
+
+	return requeueAfter, err
+}
+
+// The client CA secret is owned by the httpsCert, it's deleted if the cert is no longer in mutual TLS mode.
+func (r *HttpsCertReconciler) reconcileClientCACertSecret(httpsCert corev1alpha1.HttpsCert, certSecretName string) error {
+	var secret corev1.Secret
+	err := r.Get(r.ctx, types.NamespacedName{
+		Namespace: istioNamespace,
+		Name:      getClientCACertSecretName(certSecretName),
+	}, &secret)
+
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	isNew := errors.IsNotFound(err)
+
+	if !httpsCert.Spec.IsMutualTLS() {
+		if isNew || !metav1.IsControlledBy(&secret, &httpsCert) {
+			return nil
+		}
+
+		return client.IgnoreNotFound(r.Delete(r.ctx, &secret))
+	}
+
+	data := map[string][]byte{
+		SecretKeyOfClientCACert: []byte(httpsCert.Spec.ClientCACert),
+	}
+
+	if isNew {
+		secret = corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: istioNamespace,
+				Name:      getClientCACertSecretName(certSecretName),
+			},
+			Data: data,
+		}
+
+		if err := ctrl.SetControllerReference(&httpsCert, &secret, r.Scheme); err != nil {
+			return err
+		}
+
+		return r.Create(r.ctx, &secret)
+	}
+
+	if string(secret.Data[SecretKeyOfClientCACert]) == httpsCert.Spec.ClientCACert {
+		return nil
+	}
+
+	secret.Data = data
 
-	return err
+	return r.Update(r.ctx, &secret)
 }
 
 func (r *HttpsCertReconciler) isACMEServerReadyForWildcardCert() (bool, error) {
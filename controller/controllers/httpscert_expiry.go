@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	corev1alpha1 "github.com/kalmhq/kalm/controller/api/v1alpha1"
+)
+
+const day = 24 * time.Hour
+
+var (
+	httpsCertExpiringSoonThreshold   = 14 * day
+	httpsCertRenewalFailingThreshold = 21 * day
+)
+
+// served with the metrics of the controller manager
+var httpsCertDaysToExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "kalm_httpscert_days_to_expiry",
+	Help: "Days before the https cert expires, negative if it's expired",
+}, []string{"name"})
+
+func init() {
+	if days, err := strconv.Atoi(os.Getenv(corev1alpha1.ENV_HTTPS_CERT_EXPIRING_SOON_DAYS)); err == nil && days > 0 {
+		httpsCertExpiringSoonThreshold = time.Duration(days) * day
+	}
+
+	if days, err := strconv.Atoi(os.Getenv(corev1alpha1.ENV_HTTPS_CERT_RENEWAL_FAILING_DAYS)); err == nil && days > 0 {
+		httpsCertRenewalFailingThreshold = time.Duration(days) * day
+	}
+
+	metrics.Registry.MustRegister(httpsCertDaysToExpiry)
+}
+
+// getHttpsCertExpiryConditions returns the ExpiringSoon and RenewalFailing conditions of the cert,
+// and the duration after which the conditions should be checked again.
+// Certs without an expire timestamp, e.g. not issued yet, have no expiry conditions.
+func getHttpsCertExpiryConditions(httpsCert *corev1alpha1.HttpsCert, lastFailureTime *metav1.Time, now time.Time) ([]corev1alpha1.HttpsCertCondition, time.Duration) {
+	if httpsCert.Status.ExpireTimestamp == 0 {
+		return nil, 0
+	}
+
+	left := time.Unix(httpsCert.Status.ExpireTimestamp, 0).Sub(now)
+
+	expiringSoon := corev1alpha1.HttpsCertCondition{
+		Type:   corev1alpha1.HttpsCertConditionExpiringSoon,
+		Status: corev1.ConditionFalse,
+	}
+
+	if left <= 0 {
+		expiringSoon.Status = corev1.ConditionTrue
+		expiringSoon.Reason = "Expired"
+		expiringSoon.Message = "cert is expired"
+	} else if left < httpsCertExpiringSoonThreshold {
+		expiringSoon.Status = corev1.ConditionTrue
+		expiringSoon.Reason = "ExpiringSoon"
+		expiringSoon.Message = fmt.Sprintf("cert expires in %d days", int(left/day))
+	}
+
+	conditions := []corev1alpha1.HttpsCertCondition{expiringSoon}
+	thresholds := []time.Duration{httpsCertExpiringSoonThreshold}
+
+	// self-managed certs are renewed by uploading new ones
+	if !httpsCert.Spec.IsSelfManaged {
+		renewalFailing := corev1alpha1.HttpsCertCondition{
+			Type:   corev1alpha1.HttpsCertConditionRenewalFailing,
+			Status: corev1.ConditionFalse,
+		}
+
+		if lastFailureTime != nil {
+			renewalFailing.Status = corev1.ConditionTrue
+			renewalFailing.Reason = "IssuingFailed"
+			renewalFailing.Message = fmt.Sprintf("the last issuing failed at %s", lastFailureTime.UTC().Format(time.RFC3339))
+		} else if left < httpsCertRenewalFailingThreshold {
+			renewalFailing.Status = corev1.ConditionTrue
+			renewalFailing.Reason = "NotRenewed"
+			renewalFailing.Message = fmt.Sprintf("cert is not renewed %d days before expiry", int(httpsCertRenewalFailingThreshold/day))
+		}
+
+		conditions = append(conditions, renewalFailing)
+		thresholds = append(thresholds, httpsCertRenewalFailingThreshold)
+	}
+
+	// check again when the next threshold is reached, or daily to refresh the days left
+	requeueAfter := day
+
+	for _, threshold := range thresholds {
+		if left > threshold && left-threshold < requeueAfter {
+			requeueAfter = left - threshold
+		}
+	}
+
+	return conditions, requeueAfter
+}
+
+// setHttpsCertExpiryConditions replaces the expiry conditions of the cert, the Ready condition is kept.
+func setHttpsCertExpiryConditions(httpsCert *corev1alpha1.HttpsCert, lastFailureTime *metav1.Time, now time.Time) time.Duration {
+	var conditions []corev1alpha1.HttpsCertCondition
+
+	for _, cond := range httpsCert.Status.Conditions {
+		if cond.Type == corev1alpha1.HttpsCertConditionExpiringSoon || cond.Type == corev1alpha1.HttpsCertConditionRenewalFailing {
+			continue
+		}
+
+		conditions = append(conditions, cond)
+	}
+
+	expiryConditions, requeueAfter := getHttpsCertExpiryConditions(httpsCert, lastFailureTime, now)
+	httpsCert.Status.Conditions = append(conditions, expiryConditions...)
+
+	return requeueAfter
+}
+
+// reportHttpsCertExpiry emits warning events for the expiry conditions turned true and updates the days to expiry metric.
+// The previous conditions are the ones before the reconciliation, so that the events are not repeated on every reconcile.
+func (r *HttpsCertReconciler) reportHttpsCertExpiry(httpsCert *corev1alpha1.HttpsCert, previousConditions []corev1alpha1.HttpsCertCondition, now time.Time) {
+	if httpsCert.Status.ExpireTimestamp == 0 {
+		httpsCertDaysToExpiry.DeleteLabelValues(httpsCert.Name)
+		return
+	}
+
+	left := time.Unix(httpsCert.Status.ExpireTimestamp, 0).Sub(now)
+	httpsCertDaysToExpiry.WithLabelValues(httpsCert.Name).Set(float64(left) / float64(day))
+
+	for _, cond := range getHttpsCertExpiryConditionsTurnedTrue(previousConditions, httpsCert.Status.Conditions) {
+		r.Recorder.Event(httpsCert, corev1.EventTypeWarning, string(cond.Type), cond.Message)
+	}
+}
+
+// A condition is turned true if it wasn't true, or the reason is changed, e.g. from ExpiringSoon to Expired.
+// The message of days left changes daily, it's not a change of the condition.
+func getHttpsCertExpiryConditionsTurnedTrue(previous, current []corev1alpha1.HttpsCertCondition) []corev1alpha1.HttpsCertCondition {
+	var rst []corev1alpha1.HttpsCertCondition
+
+	for _, cond := range current {
+		if cond.Type == corev1alpha1.HttpsCertConditionReady || cond.Status != corev1.ConditionTrue {
+			continue
+		}
+
+		turnedTrue := true
+
+		for _, prev := range previous {
+			if prev.Type == cond.Type && prev.Status == corev1.ConditionTrue && prev.Reason == cond.Reason {
+				turnedTrue = false
+				break
+			}
+		}
+
+		if turnedTrue {
+			rst = append(rst, cond)
+		}
+	}
+
+	return rst
+}
@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetHttpsCertExpiryConditions(t *testing.T) {
+	now := time.Unix(time.Now().Unix(), 0)
+	cert := &v1alpha1.HttpsCert{}
+
+	// not issued yet
+	conditions, requeueAfter := getHttpsCertExpiryConditions(cert, nil, now)
+	assert.Nil(t, conditions)
+	assert.Equal(t, time.Duration(0), requeueAfter)
+
+	// checked daily, or when the next threshold is reached
+	cert.Status.ExpireTimestamp = now.Add(60 * day).Unix()
+	conditions, requeueAfter = getHttpsCertExpiryConditions(cert, nil, now)
+	assert.Len(t, conditions, 2)
+	assert.Equal(t, corev1.ConditionFalse, conditions[0].Status)
+	assert.Equal(t, corev1.ConditionFalse, conditions[1].Status)
+	assert.Equal(t, day, requeueAfter)
+
+	cert.Status.ExpireTimestamp = now.Add(22 * day).Unix()
+	_, requeueAfter = getHttpsCertExpiryConditions(cert, nil, now)
+	assert.Equal(t, day, requeueAfter)
+
+	cert.Status.ExpireTimestamp = now.Add(21*day + time.Hour).Unix()
+	_, requeueAfter = getHttpsCertExpiryConditions(cert, nil, now)
+	assert.Equal(t, time.Hour, requeueAfter)
+
+	// not renewed by cert-manager
+	cert.Status.ExpireTimestamp = now.Add(20 * day).Unix()
+	conditions, requeueAfter = getHttpsCertExpiryConditions(cert, nil, now)
+	assert.Equal(t, v1alpha1.HttpsCertConditionExpiringSoon, conditions[0].Type)
+	assert.Equal(t, corev1.ConditionFalse, conditions[0].Status)
+	assert.Equal(t, v1alpha1.HttpsCertConditionRenewalFailing, conditions[1].Type)
+	assert.Equal(t, corev1.ConditionTrue, conditions[1].Status)
+	assert.Equal(t, "NotRenewed", conditions[1].Reason)
+	assert.Equal(t, day, requeueAfter)
+
+	// failed issuing is reported before the threshold
+	cert.Status.ExpireTimestamp = now.Add(60 * day).Unix()
+	lastFailureTime := metaV1.NewTime(now.Add(-time.Hour))
+	conditions, _ = getHttpsCertExpiryConditions(cert, &lastFailureTime, now)
+	assert.Equal(t, corev1.ConditionTrue, conditions[1].Status)
+	assert.Equal(t, "IssuingFailed", conditions[1].Reason)
+
+	// self-managed certs have no renewal
+	cert.Spec.IsSelfManaged = true
+	cert.Status.ExpireTimestamp = now.Add(3*day + time.Hour).Unix()
+	conditions, _ = getHttpsCertExpiryConditions(cert, nil, now)
+	assert.Len(t, conditions, 1)
+	assert.Equal(t, corev1.ConditionTrue, conditions[0].Status)
+	assert.Equal(t, "cert expires in 3 days", conditions[0].Message)
+
+	cert.Status.ExpireTimestamp = now.Add(-time.Hour).Unix()
+	conditions, _ = getHttpsCertExpiryConditions(cert, nil, now)
+	assert.Equal(t, "Expired", conditions[0].Reason)
+}
+
+func TestSetHttpsCertExpiryConditions(t *testing.T) {
+	now := time.Unix(time.Now().Unix(), 0)
+	cert := &v1alpha1.HttpsCert{
+		Status: v1alpha1.HttpsCertStatus{
+			ExpireTimestamp: now.Add(60 * day).Unix(),
+			Conditions: []v1alpha1.HttpsCertCondition{
+				{Type: v1alpha1.HttpsCertConditionReady, Status: corev1.ConditionTrue},
+				{Type: v1alpha1.HttpsCertConditionExpiringSoon, Status: corev1.ConditionTrue},
+				{Type: v1alpha1.HttpsCertConditionRenewalFailing, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+
+	setHttpsCertExpiryConditions(cert, nil, now)
+
+	assert.Len(t, cert.Status.Conditions, 3)
+	assert.Equal(t, v1alpha1.HttpsCertConditionReady, cert.Status.Conditions[0].Type)
+	assert.Equal(t, corev1.ConditionFalse, cert.Status.Conditions[1].Status)
+	assert.Equal(t, corev1.ConditionFalse, cert.Status.Conditions[2].Status)
+}
+
+func TestGetHttpsCertExpiryConditionsTurnedTrue(t *testing.T) {
+	expiringSoon := v1alpha1.HttpsCertCondition{
+		Type:    v1alpha1.HttpsCertConditionExpiringSoon,
+		Status:  corev1.ConditionTrue,
+		Reason:  "ExpiringSoon",
+		Message: "cert expires in 10 days",
+	}
+
+	current := []v1alpha1.HttpsCertCondition{
+		{Type: v1alpha1.HttpsCertConditionReady, Status: corev1.ConditionTrue},
+		expiringSoon,
+		{Type: v1alpha1.HttpsCertConditionRenewalFailing, Status: corev1.ConditionFalse},
+	}
+
+	assert.Equal(t, []v1alpha1.HttpsCertCondition{expiringSoon}, getHttpsCertExpiryConditionsTurnedTrue(nil, current))
+
+	// the days left in the message change daily
+	previous := []v1alpha1.HttpsCertCondition{expiringSoon}
+	previous[0].Message = "cert expires in 11 days"
+	assert.Empty(t, getHttpsCertExpiryConditionsTurnedTrue(previous, current))
+
+	previous[0].Reason = "Expired"
+	assert.Len(t, getHttpsCertExpiryConditionsTurnedTrue(previous, current), 1)
+
+	previous[0].Reason = "ExpiringSoon"
+	previous[0].Status = corev1.ConditionFalse
+	assert.Len(t, getHttpsCertExpiryConditionsTurnedTrue(previous, current), 1)
+}
@@ -27,11 +27,13 @@ import (
 	"fmt"
 	"math/big"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/jetstack/cert-manager/pkg/apis/acme/v1alpha2"
 	cmmetav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -53,6 +55,7 @@ type HttpsCertIssuerReconciler struct {
 // +kubebuilder:rbac:groups=core.kalm.dev,resources=httpscertissuers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core.kalm.dev,resources=httpscertissuers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=cert-manager.io,resources=clusterissuers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core.kalm.dev,resources=dnsproviders,verbs=get;list;watch
 
 func (r *HttpsCertIssuerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
@@ -90,6 +93,10 @@ func (r *HttpsCertIssuerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, er
 		return r.ReconcileDNS01(ctx, httpsCertIssuer)
 	}
 
+	if httpsCertIssuer.Spec.ACME != nil {
+		return r.ReconcileACME(ctx, httpsCertIssuer)
+	}
+
 	return ctrl.Result{}, nil
 }
 
@@ -137,9 +144,68 @@ func (r *HttpsCertIssuerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Watches(genSourceForObject(&corev1.Namespace{}), &handler.EnqueueRequestsFromMapFunc{
 			ToRequests: CertManagerNSWatcher{r},
 		}).
+		Watches(genSourceForObject(&corev1alpha1.DNSProvider{}), &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: DNS01IssuersMapper{r.BaseReconciler},
+		}).
+		Watches(genSourceForObject(&corev1.Secret{}), &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: DNS01IssuersMapper{r.BaseReconciler},
+		}).
 		Complete(r)
 }
 
+// DNS01IssuersMapper enqueues the ACME issuers whose dns01 solvers use the changed DNSProvider,
+// or a DNSProvider holding its credentials in the changed secret.
+type DNS01IssuersMapper struct {
+	*BaseReconciler
+}
+
+func (m DNS01IssuersMapper) Map(object handler.MapObject) []reconcile.Request {
+	providerNames := make(map[string]bool)
+
+	switch obj := object.Object.(type) {
+	case *corev1alpha1.DNSProvider:
+		providerNames[obj.Name] = true
+	case *corev1.Secret:
+		if obj.Namespace != KalmSystemNamespace {
+			return nil
+		}
+
+		var providerList corev1alpha1.DNSProviderList
+		if err := m.Reader.List(context.Background(), &providerList); err != nil {
+			m.Log.Error(err, "fail to list dns providers")
+			return nil
+		}
+
+		for _, provider := range providerList.Items {
+			if getDNSProviderSecretName(provider.Spec) == obj.Name {
+				providerNames[provider.Name] = true
+			}
+		}
+	}
+
+	if len(providerNames) == 0 {
+		return nil
+	}
+
+	var issuerList corev1alpha1.HttpsCertIssuerList
+	if err := m.Reader.List(context.Background(), &issuerList); err != nil {
+		m.Log.Error(err, "fail to list httpsCertIssuers")
+		return nil
+	}
+
+	var reqs []reconcile.Request
+
+	for _, issuer := range issuerList.Items {
+		if issuer.Spec.ACME == nil || issuer.Spec.ACME.DNS01 == nil || !providerNames[issuer.Spec.ACME.DNS01.DNSProvider] {
+			continue
+		}
+
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: issuer.Name}})
+	}
+
+	return reqs
+}
+
 const (
 	SecretKeyOfTLSCert = "tls.crt"
 	SecretKeyOfTLSKey  = "tls.key"
@@ -620,3 +686,317 @@ func (r *HttpsCertIssuerReconciler) reconcileSecForDNS01Issuer(
 
 	return err
 }
+
+// buildACMEClusterIssuerSpec builds the cert-manager issuer of a generic ACME issuer.
+// The secret of the external account binding is in the cert-manager namespace, dns01 is the solver
+// built from the DNSProvider of the issuer, nil for http01 issuers.
+func buildACMEClusterIssuerSpec(issuer corev1alpha1.HttpsCertIssuer, dns01 *v1alpha2.ACMEChallengeSolverDNS01) cmv1alpha2.IssuerSpec {
+	acme := issuer.Spec.ACME
+
+	acmeIssuer := &v1alpha2.ACMEIssuer{
+		Email:         acme.Email,
+		Server:        acme.Server,
+		SkipTLSVerify: acme.SkipTLSVerify,
+		PrivateKey: cmmetav1.SecretKeySelector{ // prv key for this acme account
+			LocalObjectReference: cmmetav1.LocalObjectReference{
+				Name: getPrvKeyNameForIssuer(issuer),
+			},
+		},
+	}
+
+	if eab := acme.ExternalAccountBinding; eab != nil {
+		keyAlgorithm := v1alpha2.HS256
+		if eab.KeyAlgorithm != "" {
+			keyAlgorithm = v1alpha2.HMACKeyAlgorithm(eab.KeyAlgorithm)
+		}
+
+		acmeIssuer.ExternalAccountBinding = &v1alpha2.ACMEExternalAccountBinding{
+			KeyID: eab.KeyID,
+			Key: cmmetav1.SecretKeySelector{
+				LocalObjectReference: cmmetav1.LocalObjectReference{
+					Name: eab.KeySecretName,
+				},
+				Key: corev1alpha1.ACMEExternalAccountBindingSecretKey,
+			},
+			KeyAlgorithm: keyAlgorithm,
+		}
+	}
+
+	if acme.HTTP01 != nil {
+		acmeChallengeSolverHTTP01IngressClass := "istio"
+
+		acmeIssuer.Solvers = append(acmeIssuer.Solvers, v1alpha2.ACMEChallengeSolver{
+			HTTP01: &v1alpha2.ACMEChallengeSolverHTTP01{
+				Ingress: &v1alpha2.ACMEChallengeSolverHTTP01Ingress{
+					Class: &acmeChallengeSolverHTTP01IngressClass,
+				},
+			},
+		})
+	}
+
+	if dns01 != nil {
+		acmeIssuer.Solvers = append(acmeIssuer.Solvers, v1alpha2.ACMEChallengeSolver{
+			DNS01: dns01,
+		})
+	}
+
+	return cmv1alpha2.IssuerSpec{
+		IssuerConfig: cmv1alpha2.IssuerConfig{
+			ACME: acmeIssuer,
+		},
+	}
+}
+
+func (r *HttpsCertIssuerReconciler) ReconcileACME(ctx context.Context, issuer corev1alpha1.HttpsCertIssuer) (ctrl.Result, error) {
+	acme := issuer.Spec.ACME
+
+	// the referenced secrets are required, or cert-manager fails to register the account or solve challenges
+	if acme.ExternalAccountBinding != nil {
+		if err := r.checkSecretKeyOfIssuer(ctx, &issuer, acme.ExternalAccountBinding.KeySecretName, corev1alpha1.ACMEExternalAccountBindingSecretKey); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	var dns01 *v1alpha2.ACMEChallengeSolverDNS01
+
+	if acme.DNS01 != nil {
+		solver, err := r.reconcileACMEDNS01Solver(ctx, issuer)
+		if err != nil {
+			return ctrl.Result{}, r.markIssuerNotOK(ctx, &issuer, err, fmt.Sprintf("fail to build dns01 solver from DNSProvider %s", acme.DNS01.DNSProvider))
+		}
+
+		dns01 = solver
+	}
+
+	expectedClusterIssuer := cmv1alpha2.ClusterIssuer{
+		ObjectMeta: v1.ObjectMeta{
+			Name: issuer.Name,
+		},
+		Spec: buildACMEClusterIssuerSpec(issuer, dns01),
+	}
+
+	var clusterIssuer cmv1alpha2.ClusterIssuer
+	if err := r.Get(ctx, client.ObjectKey{Name: issuer.Name}, &clusterIssuer); err != nil {
+		if !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+
+		clusterIssuer = expectedClusterIssuer
+
+		if err := ctrl.SetControllerReference(&issuer, &clusterIssuer, r.Scheme); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if err := r.Create(ctx, &clusterIssuer); err != nil {
+			r.EmitWarningEvent(&issuer, err, "fail create issuer")
+			return ctrl.Result{}, err
+		}
+
+		r.EmitNormalEvent(&issuer, "IssuerCreated", "Cert manager issuer is created")
+	} else if !equality.Semantic.DeepEqual(clusterIssuer.Spec, expectedClusterIssuer.Spec) {
+		clusterIssuer.Spec = expectedClusterIssuer.Spec
+
+		if err := r.Update(ctx, &clusterIssuer); err != nil {
+			r.EmitWarningEvent(&issuer, err, "fail update issuer")
+			return ctrl.Result{}, err
+		}
+
+		r.EmitNormalEvent(&issuer, "IssuerUpdated", "Cert manager issuer is Updated.")
+	}
+
+	if !issuer.Status.OK {
+		issuer.Status.OK = true
+		if err := r.Status().Update(ctx, &issuer); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *HttpsCertIssuerReconciler) checkSecretKeyOfIssuer(ctx context.Context, issuer *corev1alpha1.HttpsCertIssuer, secretName, key string) error {
+	var secret corev1.Secret
+
+	err := r.Get(ctx, types.NamespacedName{Namespace: CertManagerNamespace, Name: secretName}, &secret)
+	if err == nil && len(secret.Data[key]) == 0 {
+		err = fmt.Errorf("secret %s has no key %s", secretName, key)
+	}
+
+	if err != nil {
+		return r.markIssuerNotOK(ctx, issuer, err, fmt.Sprintf("fail to get key %s of secret %s", key, secretName))
+	}
+
+	return nil
+}
+
+// markIssuerNotOK reports err of the issuer and returns it, or the error of the status update.
+func (r *HttpsCertIssuerReconciler) markIssuerNotOK(ctx context.Context, issuer *corev1alpha1.HttpsCertIssuer, err error, msg string) error {
+	r.EmitWarningEvent(issuer, err, msg)
+
+	if issuer.Status.OK {
+		issuer.Status.OK = false
+
+		if err := r.Status().Update(ctx, issuer); err != nil {
+			return err
+		}
+	}
+
+	return err
+}
+
+func getDNS01SecretNameForIssuer(issuer corev1alpha1.HttpsCertIssuer) string {
+	return "kalm-dns01-" + issuer.Name
+}
+
+// reconcileACMEDNS01Solver copies the credentials of the DNSProvider of the issuer into the cert-manager namespace,
+// where cert-manager reads the secrets of cluster issuers, and returns the solver using them.
+func (r *HttpsCertIssuerReconciler) reconcileACMEDNS01Solver(ctx context.Context, issuer corev1alpha1.HttpsCertIssuer) (*v1alpha2.ACMEChallengeSolverDNS01, error) {
+	var provider corev1alpha1.DNSProvider
+	if err := r.Get(ctx, client.ObjectKey{Name: issuer.Spec.ACME.DNS01.DNSProvider}, &provider); err != nil {
+		return nil, err
+	}
+
+	providerSecretName := getDNSProviderSecretName(provider.Spec)
+	secretName := getDNS01SecretNameForIssuer(issuer)
+
+	var data map[string][]byte
+
+	if providerSecretName != "" {
+		var providerSecret corev1.Secret
+		if err := r.Get(ctx, types.NamespacedName{Namespace: KalmSystemNamespace, Name: providerSecretName}, &providerSecret); err != nil {
+			return nil, err
+		}
+
+		data = providerSecret.Data
+	}
+
+	solver, err := buildACMEDNS01Solver(provider.Spec, secretName, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if providerSecretName == "" {
+		return solver, nil
+	}
+
+	var secret corev1.Secret
+	err = r.Get(ctx, types.NamespacedName{Namespace: CertManagerNamespace, Name: secretName}, &secret)
+
+	if errors.IsNotFound(err) {
+		secret = corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{
+				Namespace: CertManagerNamespace,
+				Name:      secretName,
+			},
+			Data: data,
+		}
+
+		if err := ctrl.SetControllerReference(&issuer, &secret, r.Scheme); err != nil {
+			return nil, err
+		}
+
+		err = r.Create(ctx, &secret)
+	} else if err == nil && !equality.Semantic.DeepEqual(secret.Data, data) {
+		secret.Data = data
+		err = r.Update(ctx, &secret)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return solver, nil
+}
+
+// buildACMEDNS01Solver maps a DNSProvider to the matching cert-manager solver, data is the content of the
+// secret of the provider, copied to secretName in the cert-manager namespace.
+func buildACMEDNS01Solver(spec corev1alpha1.DNSProviderSpec, secretName string, data map[string][]byte) (*v1alpha2.ACMEChallengeSolverDNS01, error) {
+	secretKey := func(key string) (cmmetav1.SecretKeySelector, error) {
+		if len(data[key]) == 0 {
+			return cmmetav1.SecretKeySelector{}, fmt.Errorf("key %s not found in secret of the dns provider", key)
+		}
+
+		return cmmetav1.SecretKeySelector{
+			LocalObjectReference: cmmetav1.LocalObjectReference{
+				Name: secretName,
+			},
+			Key: key,
+		}, nil
+	}
+
+	switch {
+	case spec.Cloudflare != nil:
+		token, err := secretKey(DNSProviderSecretKeyToken)
+		if err != nil {
+			return nil, err
+		}
+
+		return &v1alpha2.ACMEChallengeSolverDNS01{
+			Cloudflare: &v1alpha2.ACMEIssuerDNS01ProviderCloudflare{APIToken: &token},
+		}, nil
+	case spec.Route53 != nil:
+		if len(data[DNSProviderSecretKeyAccessKeyID]) == 0 {
+			return nil, fmt.Errorf("key %s not found in secret of the dns provider", DNSProviderSecretKeyAccessKeyID)
+		}
+
+		secretAccessKey, err := secretKey(DNSProviderSecretKeySecretAccessKey)
+		if err != nil {
+			return nil, err
+		}
+
+		return &v1alpha2.ACMEChallengeSolverDNS01{
+			Route53: &v1alpha2.ACMEIssuerDNS01ProviderRoute53{
+				AccessKeyID:     string(data[DNSProviderSecretKeyAccessKeyID]),
+				SecretAccessKey: secretAccessKey,
+				// route53 is a global service, the region is only used to sign the requests
+				Region: "us-east-1",
+			},
+		}, nil
+	case spec.GoogleCloudDNS != nil:
+		credentials, err := secretKey(DNSProviderSecretKeyCredentials)
+		if err != nil {
+			return nil, err
+		}
+
+		return &v1alpha2.ACMEChallengeSolverDNS01{
+			CloudDNS: &v1alpha2.ACMEIssuerDNS01ProviderCloudDNS{
+				Project:        spec.GoogleCloudDNS.Project,
+				ServiceAccount: &credentials,
+			},
+		}, nil
+	case spec.DigitalOcean != nil:
+		token, err := secretKey(DNSProviderSecretKeyToken)
+		if err != nil {
+			return nil, err
+		}
+
+		return &v1alpha2.ACMEChallengeSolverDNS01{
+			DigitalOcean: &v1alpha2.ACMEIssuerDNS01ProviderDigitalOcean{Token: token},
+		}, nil
+	case spec.RFC2136 != nil:
+		rfc2136 := &v1alpha2.ACMEIssuerDNS01ProviderRFC2136{
+			Nameserver: spec.RFC2136.Nameserver,
+		}
+
+		if spec.RFC2136.TSIGKeyName != "" {
+			tsigSecret, err := secretKey(DNSProviderSecretKeyTSIGSecret)
+			if err != nil {
+				return nil, err
+			}
+
+			algorithm := spec.RFC2136.TSIGAlgorithm
+			if algorithm == "" {
+				algorithm = "hmac-sha256"
+			}
+
+			rfc2136.TSIGKeyName = spec.RFC2136.TSIGKeyName
+			rfc2136.TSIGSecret = tsigSecret
+			// e.g. hmac-sha256 is HMACSHA256 in cert-manager, which defaults to HMACMD5
+			rfc2136.TSIGAlgorithm = strings.ToUpper(strings.ReplaceAll(algorithm, "-", ""))
+		}
+
+		return &v1alpha2.ACMEChallengeSolverDNS01{RFC2136: rfc2136}, nil
+	}
+
+	return nil, fmt.Errorf("no provider config in the dns provider")
+}
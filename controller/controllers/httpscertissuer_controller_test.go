@@ -2,13 +2,18 @@ package controllers
 
 import (
 	"context"
+	"os"
+	"testing"
+
+	acmev1alpha2 "github.com/jetstack/cert-manager/pkg/apis/acme/v1alpha2"
 	"github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
 	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"testing"
 )
 
 type HttpsCertIssuerControllerSuite struct {
@@ -93,6 +98,167 @@ func (suite *HttpsCertIssuerControllerSuite) TestBasicCRUD() {
 	//})
 }
 
+// the directory url of a local Pebble server, e.g. started with the docker image letsencrypt/pebble
+func getPebbleDirectoryURL() string {
+	if url := os.Getenv("PEBBLE_DIRECTORY_URL"); url != "" {
+		return url
+	}
+
+	return "https://localhost:14000/dir"
+}
+
+func (suite *HttpsCertIssuerControllerSuite) TestACMEIssuer() {
+	issuer := v1alpha1.HttpsCertIssuer{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name: randomName()[:12],
+		},
+		Spec: v1alpha1.HttpsCertIssuerSpec{
+			ACME: &v1alpha1.ACMEIssuer{
+				Server:        getPebbleDirectoryURL(),
+				SkipTLSVerify: true,
+				ExternalAccountBinding: &v1alpha1.ACMEExternalAccountBinding{
+					KeyID:         "kid-1",
+					KeySecretName: "pebble-eab",
+				},
+				HTTP01: &v1alpha1.ACMEHTTP01Solver{},
+			},
+		},
+	}
+
+	suite.createHttpsCertIssuer(issuer)
+
+	// the issuer waits for the secret of the external account binding
+	suite.Nil(suite.K8sClient.Create(context.Background(), &corev1.Secret{
+		ObjectMeta: metaV1.ObjectMeta{
+			Namespace: CertManagerNamespace,
+			Name:      "pebble-eab",
+		},
+		StringData: map[string]string{
+			v1alpha1.ACMEExternalAccountBindingSecretKey: "zWNDZM6eQGHWpSRTPal5eIUYFTu7EajVIoguysqZ9wG44nMEtx3MUAsUDkMTQ12W",
+		},
+	}))
+
+	var clusterIssuer v1alpha2.ClusterIssuer
+	suite.Eventually(func() bool {
+		return suite.K8sClient.Get(context.Background(), types.NamespacedName{Name: issuer.Name}, &clusterIssuer) == nil
+	})
+
+	suite.Equal(getPebbleDirectoryURL(), clusterIssuer.Spec.ACME.Server)
+	suite.Equal("kid-1", clusterIssuer.Spec.ACME.ExternalAccountBinding.KeyID)
+
+	suite.Eventually(func() bool {
+		suite.reloadHttpsCertIssuer(&issuer)
+		return issuer.Status.OK
+	})
+}
+
+func TestBuildACMEClusterIssuerSpec(t *testing.T) {
+	issuer := v1alpha1.HttpsCertIssuer{
+		ObjectMeta: metaV1.ObjectMeta{Name: "zerossl"},
+		Spec: v1alpha1.HttpsCertIssuerSpec{
+			ACME: &v1alpha1.ACMEIssuer{
+				Server: "https://acme.zerossl.com/v2/DV90",
+				Email:  "admin@example.com",
+				ExternalAccountBinding: &v1alpha1.ACMEExternalAccountBinding{
+					KeyID:         "kid",
+					KeySecretName: "zerossl-eab",
+				},
+				DNS01: &v1alpha1.ACMEDNS01Solver{DNSProvider: "cloudflare"},
+			},
+		},
+	}
+
+	dns01, err := buildACMEDNS01Solver(
+		v1alpha1.DNSProviderSpec{Cloudflare: &v1alpha1.CloudflareDNSProviderConfig{APITokenSecretName: "cloudflare"}},
+		getDNS01SecretNameForIssuer(issuer),
+		map[string][]byte{DNSProviderSecretKeyToken: []byte("token")},
+	)
+	assert.Nil(t, err)
+
+	acme := buildACMEClusterIssuerSpec(issuer, dns01).ACME
+
+	assert.Equal(t, "https://acme.zerossl.com/v2/DV90", acme.Server)
+	assert.Equal(t, "admin@example.com", acme.Email)
+	assert.Equal(t, "kalm-prvkey-zerossl", acme.PrivateKey.Name)
+
+	eab := acme.ExternalAccountBinding
+	assert.Equal(t, "kid", eab.KeyID)
+	assert.Equal(t, "zerossl-eab", eab.Key.Name)
+	assert.Equal(t, v1alpha1.ACMEExternalAccountBindingSecretKey, eab.Key.Key)
+	assert.Equal(t, acmev1alpha2.HS256, eab.KeyAlgorithm)
+
+	assert.Len(t, acme.Solvers, 1)
+	assert.Nil(t, acme.Solvers[0].HTTP01)
+	assert.Equal(t, "kalm-dns01-zerossl", acme.Solvers[0].DNS01.Cloudflare.APIToken.Name)
+	assert.Equal(t, DNSProviderSecretKeyToken, acme.Solvers[0].DNS01.Cloudflare.APIToken.Key)
+
+	issuer.Spec.ACME.ExternalAccountBinding.KeyAlgorithm = "HS512"
+	issuer.Spec.ACME.DNS01 = nil
+	issuer.Spec.ACME.HTTP01 = &v1alpha1.ACMEHTTP01Solver{}
+	acme = buildACMEClusterIssuerSpec(issuer, nil).ACME
+
+	assert.Equal(t, acmev1alpha2.HS512, acme.ExternalAccountBinding.KeyAlgorithm)
+	assert.Len(t, acme.Solvers, 1)
+	assert.Equal(t, "istio", *acme.Solvers[0].HTTP01.Ingress.Class)
+}
+
+func TestBuildACMEDNS01Solver(t *testing.T) {
+	data := map[string][]byte{
+		DNSProviderSecretKeyToken:           []byte("token"),
+		DNSProviderSecretKeyAccessKeyID:     []byte("AKID"),
+		DNSProviderSecretKeySecretAccessKey: []byte("secret-access-key"),
+		DNSProviderSecretKeyCredentials:     []byte("{}"),
+		DNSProviderSecretKeyTSIGSecret:      []byte("c2VjcmV0"),
+	}
+
+	solver, err := buildACMEDNS01Solver(v1alpha1.DNSProviderSpec{
+		Route53: &v1alpha1.Route53DNSProviderConfig{CredentialsSecretName: "aws"},
+	}, "kalm-dns01-issuer", data)
+	assert.Nil(t, err)
+	assert.Equal(t, "AKID", solver.Route53.AccessKeyID)
+	assert.Equal(t, "kalm-dns01-issuer", solver.Route53.SecretAccessKey.Name)
+	assert.Equal(t, DNSProviderSecretKeySecretAccessKey, solver.Route53.SecretAccessKey.Key)
+	assert.NotEmpty(t, solver.Route53.Region)
+
+	solver, err = buildACMEDNS01Solver(v1alpha1.DNSProviderSpec{
+		GoogleCloudDNS: &v1alpha1.GoogleCloudDNSProviderConfig{Project: "my-project", CredentialsSecretName: "gcp"},
+	}, "kalm-dns01-issuer", data)
+	assert.Nil(t, err)
+	assert.Equal(t, "my-project", solver.CloudDNS.Project)
+	assert.Equal(t, DNSProviderSecretKeyCredentials, solver.CloudDNS.ServiceAccount.Key)
+
+	solver, err = buildACMEDNS01Solver(v1alpha1.DNSProviderSpec{
+		DigitalOcean: &v1alpha1.DigitalOceanDNSProviderConfig{APITokenSecretName: "do"},
+	}, "kalm-dns01-issuer", data)
+	assert.Nil(t, err)
+	assert.Equal(t, DNSProviderSecretKeyToken, solver.DigitalOcean.Token.Key)
+
+	solver, err = buildACMEDNS01Solver(v1alpha1.DNSProviderSpec{
+		RFC2136: &v1alpha1.RFC2136DNSProviderConfig{Nameserver: "127.0.0.1:53", TSIGKeyName: "kalm", TSIGSecretName: "tsig"},
+	}, "kalm-dns01-issuer", data)
+	assert.Nil(t, err)
+	assert.Equal(t, "127.0.0.1:53", solver.RFC2136.Nameserver)
+	assert.Equal(t, "kalm", solver.RFC2136.TSIGKeyName)
+	assert.Equal(t, "HMACSHA256", solver.RFC2136.TSIGAlgorithm)
+	assert.Equal(t, DNSProviderSecretKeyTSIGSecret, solver.RFC2136.TSIGSecret.Key)
+
+	// updates are not signed without a TSIG key
+	solver, err = buildACMEDNS01Solver(v1alpha1.DNSProviderSpec{
+		RFC2136: &v1alpha1.RFC2136DNSProviderConfig{Nameserver: "127.0.0.1:53"},
+	}, "kalm-dns01-issuer", nil)
+	assert.Nil(t, err)
+	assert.Empty(t, solver.RFC2136.TSIGKeyName)
+
+	// credentials are required
+	_, err = buildACMEDNS01Solver(v1alpha1.DNSProviderSpec{
+		Route53: &v1alpha1.Route53DNSProviderConfig{CredentialsSecretName: "aws"},
+	}, "kalm-dns01-issuer", map[string][]byte{DNSProviderSecretKeyAccessKeyID: []byte("AKID")})
+	assert.NotNil(t, err)
+
+	_, err = buildACMEDNS01Solver(v1alpha1.DNSProviderSpec{Zones: []string{"example.com"}}, "kalm-dns01-issuer", data)
+	assert.NotNil(t, err)
+}
+
 func (suite *HttpsCertIssuerControllerSuite) reloadHttpsCertIssuer(issuer *v1alpha1.HttpsCertIssuer) {
 	err := suite.K8sClient.Get(
 		context.Background(),
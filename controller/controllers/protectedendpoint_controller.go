@@ -282,6 +282,12 @@ func (r *ProtectedEndpointReconcilerTask) BuildEnvoyFilterListenerPatches(req ct
 								map[string]interface{}{
 									"exact": "x-envoy-original-path",
 								},
+								map[string]interface{}{
+									"exact": KALM_CLIENT_CERT_SUBJECT_HEADER,
+								},
+								map[string]interface{}{
+									"exact": XFCC_HEADER,
+								},
 							},
 						},
 						"headersToAdd": []interface{}{
@@ -297,6 +303,10 @@ func (r *ProtectedEndpointReconcilerTask) BuildEnvoyFilterListenerPatches(req ct
 								"key":   KALM_ALLOW_TO_PASS_IF_HAS_BEARER_TOKEN_HEADER,
 								"value": strconv.FormatBool(r.endpoint.Spec.AllowToPassIfHasBearerToken),
 							},
+							map[string]interface{}{
+								"key":   KALM_SSO_GRANTED_CERT_SUBJECTS_HEADER,
+								"value": strings.Join(r.endpoint.Spec.AllowedCertSubjects, "|"),
+							},
 						},
 					},
 					"authorizationResponse": map[string]interface{}{
@@ -311,6 +321,9 @@ func (r *ProtectedEndpointReconcilerTask) BuildEnvoyFilterListenerPatches(req ct
 								map[string]interface{}{
 									"exact": KALM_AUTH_EMAIL,
 								},
+								map[string]interface{}{
+									"exact": KALM_RATE_LIMIT_SUBJECT_HEADER,
+								},
 							},
 						},
 					},
@@ -348,14 +361,34 @@ func (r *ProtectedEndpointReconcilerTask) BuildEnvoyFilterListenerPatches(req ct
 		matches = append(matches, baseMatch.DeepCopy())
 	}
 
-	configPatches := make([]*v1alpha32.EnvoyFilter_EnvoyConfigObjectPatch, len(matches))
+	// the rate limit filter is inserted before the router after the ext_authz, so it counts the verified subjects
+	rateLimitPatch := &v1alpha32.EnvoyFilter_Patch{
+		Operation: v1alpha32.EnvoyFilter_Patch_INSERT_BEFORE,
+		Value: golangMapToProtoStruct(map[string]interface{}{
+			"name": "envoy.filters.http.lua",
+			"typed_config": map[string]interface{}{
+				"@type":       "type.googleapis.com/envoy.extensions.filters.http.lua.v3.Lua",
+				"inline_code": rateLimitSidecarLuaScript,
+			},
+		}),
+	}
+
+	configPatches := make([]*v1alpha32.EnvoyFilter_EnvoyConfigObjectPatch, 0, 2*len(matches))
 
 	for i := range matches {
-		configPatches[i] = &v1alpha32.EnvoyFilter_EnvoyConfigObjectPatch{
+		configPatches = append(configPatches, &v1alpha32.EnvoyFilter_EnvoyConfigObjectPatch{
 			ApplyTo: v1alpha32.EnvoyFilter_HTTP_FILTER,
 			Match:   matches[i],
 			Patch:   patch,
-		}
+		})
+	}
+
+	for i := range matches {
+		configPatches = append(configPatches, &v1alpha32.EnvoyFilter_EnvoyConfigObjectPatch{
+			ApplyTo: v1alpha32.EnvoyFilter_HTTP_FILTER,
+			Match:   matches[i],
+			Patch:   rateLimitPatch,
+		})
 	}
 
 	return configPatches
@@ -468,6 +501,12 @@ func golangValueToProtoValue(val interface{}) *protoTypes.Value {
 				StringValue: typeVal,
 			},
 		}
+	case int:
+		return &protoTypes.Value{
+			Kind: &protoTypes.Value_NumberValue{
+				NumberValue: float64(typeVal),
+			},
+		}
 	case []interface{}:
 		values := make([]*protoTypes.Value, len(typeVal))
 
@@ -1 +1,56 @@
 package controllers
+
+import (
+	"testing"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestBuildEnvoyFilterListenerPatchesWithCertSubjects(t *testing.T) {
+	task := &ProtectedEndpointReconcilerTask{
+		endpoint: &v1alpha1.ProtectedEndpoint{
+			Spec: v1alpha1.ProtectedEndpointSpec{
+				EndpointName:        "web",
+				AllowedCertSubjects: []string{"CN=a,O=Example", "CN=b,O=Example"},
+			},
+		},
+		ssoConfig: &v1alpha1.SingleSignOnConfig{
+			Spec: v1alpha1.SingleSignOnConfigSpec{Domain: "sso.example.com"},
+		},
+	}
+
+	patches := task.BuildEnvoyFilterListenerPatches(ctrl.Request{})
+	assert.Len(t, patches, 2)
+
+	// the rate limit filter is inserted after the ext_authz
+	assert.Equal(t, "envoy.filters.http.ext_authz", patches[0].Patch.Value.Fields["name"].GetStringValue())
+	assert.Equal(t, "envoy.filters.http.lua", patches[1].Patch.Value.Fields["name"].GetStringValue())
+	assert.Contains(
+		t,
+		patches[1].Patch.Value.Fields["typed_config"].GetStructValue().Fields["inline_code"].GetStringValue(),
+		KALM_RATE_LIMIT_SUBJECT_HEADER,
+	)
+
+	authorizationRequest := patches[0].Patch.Value.
+		Fields["typed_config"].GetStructValue().
+		Fields["httpService"].GetStructValue().
+		Fields["authorizationRequest"].GetStructValue()
+
+	var allowedHeaders []string
+	for _, pattern := range authorizationRequest.Fields["allowedHeaders"].GetStructValue().Fields["patterns"].GetListValue().Values {
+		allowedHeaders = append(allowedHeaders, pattern.GetStructValue().Fields["exact"].GetStringValue())
+	}
+
+	assert.Contains(t, allowedHeaders, KALM_CLIENT_CERT_SUBJECT_HEADER)
+	assert.Contains(t, allowedHeaders, XFCC_HEADER)
+
+	headersToAdd := make(map[string]string)
+	for _, header := range authorizationRequest.Fields["headersToAdd"].GetListValue().Values {
+		fields := header.GetStructValue().Fields
+		headersToAdd[fields["key"].GetStringValue()] = fields["value"].GetStringValue()
+	}
+
+	assert.Equal(t, "CN=a,O=Example|CN=b,O=Example", headersToAdd[KALM_SSO_GRANTED_CERT_SUBJECTS_HEADER])
+}
@@ -647,6 +647,21 @@ func (r *SingleSignOnConfigReconcilerTask) ReconcileInternalAuthProxyComponent()
 		},
 	}
 
+	// the group mappings are applied by the auth proxy before checking the granted groups of protected endpoints
+	if len(r.ssoConfig.Spec.GroupMappings) > 0 {
+		groupMappings, err := json.Marshal(r.ssoConfig.Spec.GroupMappings)
+
+		if err != nil {
+			return err
+		}
+
+		authProxyComponent.Spec.Env = append(authProxyComponent.Spec.Env, v1alpha1.EnvVar{
+			Type:  v1alpha1.EnvVarTypeStatic,
+			Name:  v1alpha1.ENV_KALM_SSO_GROUP_MAPPINGS,
+			Value: string(groupMappings),
+		})
+	}
+
 	if r.authProxyComponent != nil {
 		copied := r.authProxyComponent.DeepCopy()
 		copied.Spec = authProxyComponent.Spec
@@ -0,0 +1,256 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	istioNetworkingV1Beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	corev1alpha1 "github.com/kalmhq/kalm/controller/api/v1alpha1"
+)
+
+// virtual services of tcp routes are labeled differently, so that they are not cleaned by the http route controller
+const KALM_TCP_ROUTE_LABEL = "kalm-tcp-route"
+
+func getTcpRouteVirtualServiceName(route *corev1alpha1.TcpRoute) string {
+	return fmt.Sprintf("tcp-route-%s", route.Name)
+}
+
+// TcpRouteReconciler reconciles a TcpRoute object
+type TcpRouteReconciler struct {
+	*BaseReconciler
+	ctx context.Context
+}
+
+func NewTcpRouteReconciler(mgr ctrl.Manager) *TcpRouteReconciler {
+	return &TcpRouteReconciler{
+		BaseReconciler: NewBaseReconciler(mgr, "TcpRoute"),
+		ctx:            context.Background(),
+	}
+}
+
+// +kubebuilder:rbac:groups=core.kalm.dev,resources=tcproutes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core.kalm.dev,resources=tcproutes/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=networking.istio.io,resources=virtualservices,verbs=*
+
+func (r *TcpRouteReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	var route corev1alpha1.TcpRoute
+	if err := r.Get(r.ctx, types.NamespacedName{Name: req.Name}, &route); err != nil {
+		// the virtual service is deleted by the garbage collector
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+
+		return ctrl.Result{}, err
+	}
+
+	if route.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.reconcileVirtualService(&route); err != nil {
+		r.EmitWarningEvent(&route, err, "fail to reconcile virtual service of tcp route")
+		return ctrl.Result{}, err
+	}
+
+	if err := r.reconcileStatus(&route); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *TcpRouteReconciler) reconcileVirtualService(route *corev1alpha1.TcpRoute) error {
+	isCreate := false
+
+	var vs v1beta1.VirtualService
+	key := types.NamespacedName{Namespace: KalmSystemNamespace, Name: getTcpRouteVirtualServiceName(route)}
+	if err := r.Get(r.ctx, key, &vs); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+
+		isCreate = true
+	}
+
+	vs.Name = key.Name
+	vs.Namespace = key.Namespace
+
+	if vs.Labels == nil {
+		vs.Labels = make(map[string]string)
+	}
+
+	vs.Labels[KALM_TCP_ROUTE_LABEL] = "true"
+	vs.Spec = *buildTcpRouteVirtualServiceSpec(route)
+
+	if err := ctrl.SetControllerReference(route, &vs, r.Scheme); err != nil {
+		return err
+	}
+
+	if isCreate {
+		return r.Create(r.ctx, &vs)
+	}
+
+	return r.Update(r.ctx, &vs)
+}
+
+func buildTcpRouteVirtualServiceSpec(route *corev1alpha1.TcpRoute) *istioNetworkingV1Beta1.VirtualService {
+	weights := adjustDestinationWeightToSumTo100(route.Spec.Destinations)
+	destinations := make([]*istioNetworkingV1Beta1.RouteDestination, 0, len(route.Spec.Destinations))
+
+	for i, destination := range route.Spec.Destinations {
+		dest := toHttpRouteDestination(destination, weights[i])
+
+		destinations = append(destinations, &istioNetworkingV1Beta1.RouteDestination{
+			Destination: dest.Destination,
+			Weight:      dest.Weight,
+		})
+	}
+
+	spec := &istioNetworkingV1Beta1.VirtualService{
+		Gateways: []string{TCP_GATEWAY_NAMESPACED_NAME.String()},
+		ExportTo: []string{"*"},
+	}
+
+	port := uint32(route.Spec.Port)
+
+	switch route.Spec.Protocol {
+	case corev1alpha1.TcpRouteProtocolTLS:
+		spec.Hosts = route.Spec.Hosts
+		spec.Tls = []*istioNetworkingV1Beta1.TLSRoute{
+			{
+				Match: []*istioNetworkingV1Beta1.TLSMatchAttributes{
+					{
+						Port:     port,
+						SniHosts: route.Spec.Hosts,
+					},
+				},
+				Route: destinations,
+			},
+		}
+	default:
+		spec.Hosts = []string{"*"}
+		spec.Tcp = []*istioNetworkingV1Beta1.TCPRoute{
+			{
+				Match: []*istioNetworkingV1Beta1.L4MatchAttributes{
+					{
+						Port: port,
+					},
+				},
+				Route: destinations,
+			},
+		}
+	}
+
+	return spec
+}
+
+func (r *TcpRouteReconciler) reconcileStatus(route *corev1alpha1.TcpRoute) error {
+	var serviceList corev1.ServiceList
+	if err := r.List(r.ctx, &serviceList); err != nil {
+		return err
+	}
+
+	hostsMap := make(map[string]bool)
+	for _, service := range serviceList.Items {
+		for _, servicePort := range service.Spec.Ports {
+			hostsMap[fmt.Sprintf("%s.%s.svc.cluster.local:%d", service.Name, service.Namespace, servicePort.Port)] = true
+		}
+	}
+
+	copied := route.DeepCopy()
+	copied.Status.DestinationsStatus = make([]corev1alpha1.HttpRouteDestinationStatus, 0, len(route.Spec.Destinations))
+
+	for _, destination := range route.Spec.Destinations {
+		status := corev1alpha1.HttpRouteDestinationStatus{
+			DestinationHost: destination.Host,
+			Status:          "normal",
+		}
+
+		if !hostsMap[destination.Host] {
+			status.Status = "error"
+			status.Error = "No TcpRoute destination matched"
+		}
+
+		copied.Status.DestinationsStatus = append(copied.Status.DestinationsStatus, status)
+	}
+
+	copied.Status.PortExposed = false
+	copied.Status.PortMessage = ""
+
+	var gatewayService corev1.Service
+	if err := r.Get(r.ctx, corev1alpha1.IngressGatewayServiceName, &gatewayService); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+
+		copied.Status.PortMessage = fmt.Sprintf("service %s is not found", corev1alpha1.IngressGatewayServiceName)
+	} else if err := corev1alpha1.CheckIngressGatewayPort(&gatewayService, route.Spec.Port); err != nil {
+		copied.Status.PortMessage = err.Error()
+	} else {
+		copied.Status.PortExposed = true
+	}
+
+	if equality.Semantic.DeepEqual(route.Status, copied.Status) {
+		return nil
+	}
+
+	return r.Status().Update(r.ctx, copied)
+}
+
+type TcpRouteServiceMapper struct {
+	*BaseReconciler
+}
+
+// the destinations and port status of all routes are refreshed on service changes
+func (m *TcpRouteServiceMapper) Map(handler.MapObject) []reconcile.Request {
+	var routes corev1alpha1.TcpRouteList
+	if err := m.List(context.Background(), &routes); err != nil {
+		m.Log.Error(err, "fail to list tcp routes")
+		return nil
+	}
+
+	var rst []reconcile.Request
+	for _, route := range routes.Items {
+		rst = append(rst, reconcile.Request{NamespacedName: types.NamespacedName{Name: route.Name}})
+	}
+
+	return rst
+}
+
+func (r *TcpRouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1alpha1.TcpRoute{}).
+		Owns(&v1beta1.VirtualService{}).
+		Watches(
+			&source.Kind{Type: &corev1.Service{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: &TcpRouteServiceMapper{r.BaseReconciler},
+			},
+		).
+		Complete(r)
+}
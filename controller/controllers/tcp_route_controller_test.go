@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/kalmhq/kalm/controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	istioNetworkingV1Beta1 "istio.io/api/networking/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBuildTcpRouteVirtualServiceSpec(t *testing.T) {
+	route := &v1alpha1.TcpRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "postgres"},
+		Spec: v1alpha1.TcpRouteSpec{
+			Protocol: v1alpha1.TcpRouteProtocolTCP,
+			Port:     5432,
+			Destinations: []v1alpha1.HttpRouteDestination{
+				{Host: "primary.db.svc.cluster.local:5432", Weight: 3},
+				{Host: "replica.db.svc.cluster.local:5432", Weight: 1},
+			},
+		},
+	}
+
+	spec := buildTcpRouteVirtualServiceSpec(route)
+
+	assert.Equal(t, []string{"*"}, spec.Hosts)
+	assert.Equal(t, []string{TCP_GATEWAY_NAMESPACED_NAME.String()}, spec.Gateways)
+	assert.Len(t, spec.Tcp, 1)
+	assert.Len(t, spec.Tls, 0)
+	assert.Equal(t, uint32(5432), spec.Tcp[0].Match[0].Port)
+	assert.Equal(t, "primary.db.svc.cluster.local", spec.Tcp[0].Route[0].Destination.Host)
+	assert.Equal(t, uint32(5432), spec.Tcp[0].Route[0].Destination.Port.Number)
+	assert.Equal(t, int32(75), spec.Tcp[0].Route[0].Weight)
+	assert.Equal(t, int32(25), spec.Tcp[0].Route[1].Weight)
+
+	route.Spec.Protocol = v1alpha1.TcpRouteProtocolTLS
+	route.Spec.Hosts = []string{"db.example.com"}
+
+	spec = buildTcpRouteVirtualServiceSpec(route)
+
+	assert.Equal(t, []string{"db.example.com"}, spec.Hosts)
+	assert.Len(t, spec.Tcp, 0)
+	assert.Len(t, spec.Tls, 1)
+	assert.Equal(t, []string{"db.example.com"}, spec.Tls[0].Match[0].SniHosts)
+	assert.Equal(t, uint32(5432), spec.Tls[0].Match[0].Port)
+}
+
+func TestBuildTcpGatewayServers(t *testing.T) {
+	routes := []v1alpha1.TcpRoute{
+		{
+			ObjectMeta: v1.ObjectMeta{Name: "mqtt-b"},
+			Spec:       v1alpha1.TcpRouteSpec{Protocol: v1alpha1.TcpRouteProtocolTLS, Port: 8883, Hosts: []string{"b.example.com"}},
+		},
+		{
+			ObjectMeta: v1.ObjectMeta{Name: "postgres"},
+			Spec:       v1alpha1.TcpRouteSpec{Protocol: v1alpha1.TcpRouteProtocolTCP, Port: 5432},
+		},
+		{
+			ObjectMeta: v1.ObjectMeta{Name: "mqtt-a"},
+			Spec:       v1alpha1.TcpRouteSpec{Protocol: v1alpha1.TcpRouteProtocolTLS, Port: 8883, Hosts: []string{"a.example.com", "b.example.com"}},
+		},
+	}
+
+	servers := buildTcpGatewayServers(routes)
+
+	assert.Len(t, servers, 2)
+
+	assert.Equal(t, uint32(5432), servers[0].Port.Number)
+	assert.Equal(t, "TCP", servers[0].Port.Protocol)
+	assert.Equal(t, "tcp-5432", servers[0].Port.Name)
+	assert.Equal(t, []string{"*"}, servers[0].Hosts)
+	assert.Nil(t, servers[0].Tls)
+
+	assert.Equal(t, uint32(8883), servers[1].Port.Number)
+	assert.Equal(t, "TLS", servers[1].Port.Protocol)
+	assert.Equal(t, []string{"a.example.com", "b.example.com"}, servers[1].Hosts)
+	assert.Equal(t, istioNetworkingV1Beta1.ServerTLSSettings_PASSTHROUGH, servers[1].Tls.Mode)
+
+	assert.Len(t, buildTcpGatewayServers(nil), 0)
+}
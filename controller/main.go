@@ -114,6 +114,11 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = controllers.NewComponentOutlierDetectionReconciler(mgr).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ComponentOutlierDetection")
+		os.Exit(1)
+	}
+
 	if err = controllers.NewComponentPluginBindingReconciler(mgr).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ComponentPluginBinding")
 		os.Exit(1)
@@ -138,6 +143,11 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = controllers.NewTcpRouteReconciler(mgr).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TcpRoute")
+		os.Exit(1)
+	}
+
 	if err = controllers.NewGatewayReconciler(mgr).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Gateway")
 		os.Exit(1)
@@ -201,6 +211,11 @@ func main() {
 			os.Exit(1)
 		}
 
+		if err = (&corev1alpha1.Role{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Role")
+			os.Exit(1)
+		}
+
 		if err = (&corev1alpha1.Component{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "Component")
 			os.Exit(1)
@@ -221,6 +236,11 @@ func main() {
 			os.Exit(1)
 		}
 
+		if err = (&corev1alpha1.TcpRoute{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "TcpRoute")
+			os.Exit(1)
+		}
+
 		if err = (&corev1alpha1.HttpsCert{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "HttpsCert")
 			os.Exit(1)
@@ -256,6 +276,16 @@ func main() {
 			os.Exit(1)
 		}
 
+		if err = (&corev1alpha1.DNSProvider{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DNSProvider")
+			os.Exit(1)
+		}
+
+		if err = (&corev1alpha1.DNSRecord{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DNSRecord")
+			os.Exit(1)
+		}
+
 		setupLog.Info("WEBHOOK enabled")
 	} else {
 		setupLog.Info("WEBHOOK not enabled")
@@ -26,6 +26,21 @@ func RemoveString(slice []string, s string) (result []string) {
 	return
 }
 
+// IsStringSliceEqual compares the items in order, nil and empty slices are equal.
+func IsStringSliceEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
 // The following exists in api project. Consider merge them together later.
 const letterBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
 
@@ -31,6 +31,9 @@ var (
 	labelValueRegexp     = regexp.MustCompile("^" + "(" + qualifiedNameFmt + ")?" + "$")
 	dns1123LabelRegexp   = regexp.MustCompile("^" + dns1123LabelFmt + "$")
 	wildcardPrefixRegexp = regexp.MustCompile("^" + wildcardPrefix + "$")
+
+	// labels of dns record names may start with an underscore, e.g. _dmarc or _sip._tcp
+	dnsRecordLabelRegexp = regexp.MustCompile("^[a-zA-Z0-9_](?:[-a-zA-Z0-9_]*[a-zA-Z0-9])?$")
 )
 
 // IsDNS1123Label tests for a string that conforms to the definition of a label in
@@ -68,6 +71,27 @@ func ValidateFQDN(fqdn string) error {
 	return validateDNS1123Labels(fqdn)
 }
 
+// ValidateDNSRecordName checks the name of a dns record, which may have a wildcard prefix,
+// or labels with underscores like the names of SRV and TXT records.
+func ValidateDNSRecordName(name string) error {
+	if err := checkDNS1123Preconditions(name); err != nil {
+		return err
+	}
+
+	parts := strings.Split(strings.TrimSuffix(name, "."), ".")
+	for i, label := range parts {
+		if i == 0 && label == "*" {
+			continue
+		}
+
+		if len(label) > DNS1123LabelMaxLength || !dnsRecordLabelRegexp.MatchString(label) {
+			return fmt.Errorf("dns record name %q invalid (label %q invalid)", name, label)
+		}
+	}
+
+	return nil
+}
+
 // encapsulates DNS 1123 checks common to both wildcarded hosts and FQDNs
 func checkDNS1123Preconditions(name string) error {
 	if len(name) > 255 {